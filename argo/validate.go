@@ -0,0 +1,373 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argo
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+	"github.com/reinventingscience/ivcap-core-api/resource"
+
+	goa "goa.design/goa/v3/pkg"
+)
+
+// InvalidReference is the name of the validation errors about references to
+// undefined templates, tasks and parameters.
+const InvalidReference = "invalid_reference"
+
+// ValidateService checks the workflow of desc if it is of type "argo". The
+// workflow is taken from the Argo field or, if that is unset, from the Opts
+// left for backward compatibility. name is the path of desc in errors, e.g.
+// "body", or empty. Errors are reported as goa validation errors.
+func ValidateService(name string, desc *service.ServiceDescriptionT) error {
+	if desc == nil || desc.Workflow == nil || desc.Workflow.Type == nil || *desc.Workflow.Type != "argo" {
+		return nil
+	}
+	path, spec := join(name, "workflow.argo"), desc.Workflow.Argo
+	if spec == nil {
+		path, spec = join(name, "workflow.opts"), desc.Workflow.Opts
+	}
+	if spec == nil {
+		return goa.MissingFieldError("argo", join(name, "workflow"))
+	}
+	wf, err := Parse(spec)
+	if err != nil {
+		return goa.PermanentError(goa.InvalidFieldType, "%s must be an Argo workflow: %s", path, err)
+	}
+	return Validate(path, wf, desc.Parameters)
+}
+
+// Validate checks that the templates of wf are well formed, that its
+// entrypoint, steps and tasks refer to defined templates, that parameter
+// references such as "{{workflow.parameters.region}}" name parameters of the
+// service or the workflow, that output parameters have a value or valueFrom
+// and that resource requests and limits are valid.
+// params are the parameters of the service. name is the path of wf in
+// errors, e.g. "body.workflow.argo".
+func Validate(name string, wf *Workflow, params []*service.ParameterDefT) error {
+	v := &validator{
+		wfParams:  map[string]bool{},
+		templates: map[string]*Template{},
+	}
+	spec := &wf.Spec
+	if !wf.specOnly {
+		name = join(name, "spec")
+	}
+	v.declareParams(name, spec.Arguments, params)
+
+	if len(spec.Templates) == 0 {
+		v.add(goa.MissingFieldError("templates", name))
+	}
+	for i := range spec.Templates {
+		t := &spec.Templates[i]
+		path := fmt.Sprintf("%s.templates[%d]", name, i)
+		switch {
+		case t.Name == "":
+			v.add(goa.MissingFieldError("name", path))
+		case v.templates[t.Name] != nil:
+			v.add(goa.PermanentError(goa.InvalidFieldType, "%s.name %q is not unique", path, t.Name))
+		default:
+			v.templates[t.Name] = t
+		}
+	}
+	if spec.Entrypoint == "" {
+		v.add(goa.MissingFieldError("entrypoint", name))
+	} else if v.templates[spec.Entrypoint] == nil {
+		v.add(goa.PermanentError(InvalidReference, "%s.entrypoint refers to undefined template %q", name, spec.Entrypoint))
+	}
+	for i := range spec.Templates {
+		v.template(fmt.Sprintf("%s.templates[%d]", name, i), &spec.Templates[i])
+	}
+	return v.err
+}
+
+// validator collects the errors found in a workflow.
+type validator struct {
+	err       error
+	wfParams  map[string]bool
+	templates map[string]*Template
+}
+
+func (v *validator) add(err error) {
+	v.err = goa.MergeErrors(v.err, err)
+}
+
+// declareParams records the workflow parameters, which are the parameters
+// of the service and the arguments of the workflow. Arguments without a
+// value must be parameters of the service, which IVCAP sets them from.
+func (v *validator) declareParams(name string, args *Arguments, params []*service.ParameterDefT) {
+	for _, p := range params {
+		if p != nil && p.Name != nil {
+			v.wfParams[*p.Name] = true
+		}
+	}
+	if args == nil {
+		return
+	}
+	for i, p := range args.Parameters {
+		path := fmt.Sprintf("%s.arguments.parameters[%d]", name, i)
+		switch {
+		case p.Name == "":
+			v.add(goa.MissingFieldError("name", path))
+		case !p.hasValue() && !v.wfParams[p.Name]:
+			v.add(goa.PermanentError(InvalidReference, "%s has no value and %q is not a parameter of the service", path, p.Name))
+		}
+	}
+	for _, p := range args.Parameters {
+		v.wfParams[p.Name] = true
+	}
+}
+
+// template checks template t at path.
+func (v *validator) template(path string, t *Template) {
+	inputs := map[string]bool{}
+	if t.Inputs != nil {
+		for i, p := range t.Inputs.Parameters {
+			if p.Name == "" {
+				v.add(goa.MissingFieldError("name", fmt.Sprintf("%s.inputs.parameters[%d]", path, i)))
+			}
+			inputs[p.Name] = true
+		}
+	}
+	if t.Outputs != nil {
+		for i, p := range t.Outputs.Parameters {
+			pp := fmt.Sprintf("%s.outputs.parameters[%d]", path, i)
+			if p.Name == "" {
+				v.add(goa.MissingFieldError("name", pp))
+			}
+			if p.Value == nil && p.ValueFrom == nil {
+				v.add(goa.MissingFieldError("valueFrom", pp))
+			}
+		}
+	}
+	kinds := 0
+	for _, set := range []bool{
+		t.Container != nil, t.Script != nil, len(t.Steps) > 0, t.DAG != nil,
+		t.Suspend != nil, t.Resource != nil, t.HTTP != nil, t.ContainerSet != nil, t.Data != nil, t.Plugin != nil,
+	} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		v.add(goa.PermanentError(goa.InvalidFieldType, "%s must define exactly one of container, script, steps, dag, suspend, resource, http, containerSet, data and plugin", path))
+	}
+	if t.Container != nil {
+		v.container(path+".container", t.Container, inputs)
+	}
+	if t.Script != nil {
+		v.container(path+".script", &t.Script.Container, inputs)
+		v.refs(path+".script.source", t.Script.Source, inputs)
+	}
+	for i, group := range t.Steps {
+		for j, s := range group {
+			sp := fmt.Sprintf("%s.steps[%d][%d]", path, i, j)
+			if s.Name == "" {
+				v.add(goa.MissingFieldError("name", sp))
+			}
+			v.call(sp, s.Template, s.Arguments, inputs)
+			v.refs(sp+".withParam", s.WithParam, inputs)
+		}
+	}
+	if t.DAG != nil {
+		v.dag(path+".dag", t.DAG, inputs)
+	}
+}
+
+// container checks the container c at path of a template with inputs.
+func (v *validator) container(path string, c *Container, inputs map[string]bool) {
+	if c.Image == "" {
+		v.add(goa.MissingFieldError("image", path))
+	}
+	v.refs(path+".image", c.Image, inputs)
+	v.refs(path+".workingDir", c.WorkingDir, inputs)
+	for i, s := range c.Command {
+		v.refs(fmt.Sprintf("%s.command[%d]", path, i), s, inputs)
+	}
+	for i, s := range c.Args {
+		v.refs(fmt.Sprintf("%s.args[%d]", path, i), s, inputs)
+	}
+	for i, e := range c.Env {
+		ep := fmt.Sprintf("%s.env[%d]", path, i)
+		if e.Name == "" {
+			v.add(goa.MissingFieldError("name", ep))
+		}
+		v.refs(ep+".value", e.Value, inputs)
+	}
+	if c.Resources == nil {
+		return
+	}
+	names := map[string]bool{}
+	for _, l := range []map[string]Quantity{c.Resources.Requests, c.Resources.Limits} {
+		for n := range l {
+			names[n] = true
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for n := range names {
+		sorted = append(sorted, n)
+	}
+	sort.Strings(sorted)
+	for _, n := range sorted {
+		rp := path + ".resources." + n
+		v.add(resource.ValidateRequirements(rp, optQuantity(c.Resources.Requests, n), optQuantity(c.Resources.Limits, n)))
+	}
+}
+
+// dag checks the tasks of d at path.
+func (v *validator) dag(path string, d *DAG, inputs map[string]bool) {
+	if len(d.Tasks) == 0 {
+		v.add(goa.MissingFieldError("tasks", path))
+	}
+	deps := map[string][]string{}
+	for i, t := range d.Tasks {
+		tp := fmt.Sprintf("%s.tasks[%d]", path, i)
+		switch _, dup := deps[t.Name]; {
+		case t.Name == "":
+			v.add(goa.MissingFieldError("name", tp))
+		case dup:
+			v.add(goa.PermanentError(goa.InvalidFieldType, "%s.name %q is not unique", tp, t.Name))
+		default:
+			deps[t.Name] = t.Dependencies
+		}
+		v.call(tp, t.Template, t.Arguments, inputs)
+		v.refs(tp+".withParam", t.WithParam, inputs)
+	}
+	for i, t := range d.Tasks {
+		for j, dep := range t.Dependencies {
+			if _, ok := deps[dep]; !ok {
+				v.add(goa.PermanentError(InvalidReference, "%s.tasks[%d].dependencies[%d] refers to undefined task %q", path, i, j, dep))
+			}
+		}
+	}
+	// Depth first search for a task reachable from itself.
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[string]int{}
+	var visit func(n string) bool
+	visit = func(n string) bool {
+		switch state[n] {
+		case visiting:
+			return true
+		case done:
+			return false
+		}
+		state[n] = visiting
+		for _, dep := range deps[n] {
+			if visit(dep) {
+				return true
+			}
+		}
+		state[n] = done
+		return false
+	}
+	for _, t := range d.Tasks {
+		if t.Name != "" && visit(t.Name) {
+			v.add(goa.PermanentError(goa.InvalidFieldType, "%s has a dependency cycle through task %q", path, t.Name))
+			return
+		}
+	}
+}
+
+// call checks a step or task at path calling template name with args from
+// a template with inputs.
+func (v *validator) call(path, name string, args *Arguments, inputs map[string]bool) {
+	if name == "" {
+		v.add(goa.MissingFieldError("template", path))
+		return
+	}
+	t := v.templates[name]
+	if t == nil {
+		v.add(goa.PermanentError(InvalidReference, "%s.template refers to undefined template %q", path, name))
+		return
+	}
+	given := map[string]bool{}
+	if args != nil {
+		for i, p := range args.Parameters {
+			ap := fmt.Sprintf("%s.arguments.parameters[%d]", path, i)
+			given[p.Name] = true
+			if !hasInput(t, p.Name) {
+				v.add(goa.PermanentError(InvalidReference, "%s refers to parameter %q, which template %q does not take", ap, p.Name, name))
+			}
+			if p.Value != nil {
+				v.refs(ap+".value", *p.Value, inputs)
+			}
+		}
+	}
+	if t.Inputs != nil {
+		for _, p := range t.Inputs.Parameters {
+			if p.Name != "" && !p.hasValue() && !given[p.Name] {
+				v.add(goa.PermanentError(InvalidReference, "%s misses parameter %q of template %q", path, p.Name, name))
+			}
+		}
+	}
+}
+
+// paramRef matches references to workflow and input parameters.
+var paramRef = regexp.MustCompile(`\{\{\s*(workflow|inputs)\.parameters\.([^\s}]+)\s*\}\}`)
+
+// refs checks the parameter references in s at path of a template with
+// inputs.
+func (v *validator) refs(path, s string, inputs map[string]bool) {
+	for _, m := range paramRef.FindAllStringSubmatch(s, -1) {
+		defined := v.wfParams
+		if m[1] == "inputs" {
+			defined = inputs
+		}
+		if !defined[m[2]] {
+			v.add(goa.PermanentError(InvalidReference, "%s refers to undefined parameter %q", path, strings.TrimSpace(m[0])))
+		}
+	}
+}
+
+// hasValue returns true if p has a value without being passed one.
+func (p *Parameter) hasValue() bool {
+	return p.Value != nil || p.Default != nil || p.ValueFrom != nil
+}
+
+// hasInput returns true if template t takes parameter name.
+func hasInput(t *Template, name string) bool {
+	if t.Inputs == nil {
+		return false
+	}
+	for _, p := range t.Inputs.Parameters {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func optQuantity(l map[string]Quantity, name string) *string {
+	q, ok := l[name]
+	if !ok {
+		return nil
+	}
+	s := string(q)
+	return &s
+}
+
+// join appends field to path name, which may be empty.
+func join(name, field string) string {
+	if name == "" {
+		return field
+	}
+	return name + "." + field
+}
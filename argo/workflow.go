@@ -0,0 +1,284 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package argo models the subset of the Argo Workflow schema checked for
+// IVCAP services with an "argo" workflow, whose definition the generated
+// service.WorkflowT holds untyped. Parse turns it into a Workflow and
+// Validate checks that against the parameters of the service before it is
+// registered. Fields outside of the subset are left to Argo.
+package argo
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Workflow is an Argo Workflow resource. Only its spec is used by IVCAP.
+type Workflow struct {
+	APIVersion string       `json:"apiVersion,omitempty"`
+	Kind       string       `json:"kind,omitempty"`
+	Metadata   *ObjectMeta  `json:"metadata,omitempty"`
+	Spec       WorkflowSpec `json:"spec"`
+
+	// specOnly is set if the spec was parsed without the resource around it.
+	specOnly bool
+}
+
+// ObjectMeta is the metadata of a Workflow resource.
+type ObjectMeta struct {
+	Name         string            `json:"name,omitempty"`
+	GenerateName string            `json:"generateName,omitempty"`
+	Namespace    string            `json:"namespace,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+}
+
+// WorkflowSpec defines the templates of a workflow and where it starts.
+type WorkflowSpec struct {
+	// Entrypoint is the name of the template executed first.
+	Entrypoint string `json:"entrypoint"`
+	// Arguments are the workflow parameters, referred to as
+	// "{{workflow.parameters.<name>}}". IVCAP sets them from the parameters
+	// of an order.
+	Arguments *Arguments `json:"arguments,omitempty"`
+	Templates []Template `json:"templates"`
+	// ActiveDeadlineSeconds limits the duration of the workflow.
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+}
+
+// Arguments are passed to a workflow or template.
+type Arguments struct {
+	Parameters []Parameter `json:"parameters,omitempty"`
+	Artifacts  []Artifact  `json:"artifacts,omitempty"`
+}
+
+// Parameter is a named string value.
+type Parameter struct {
+	Name    string  `json:"name"`
+	Value   *string `json:"value,omitempty"`
+	Default *string `json:"default,omitempty"`
+	// ValueFrom is where the value comes from if Value is unset, e.g. a
+	// file written by the container of an output parameter.
+	ValueFrom   *ValueFrom `json:"valueFrom,omitempty"`
+	Enum        []string   `json:"enum,omitempty"`
+	Description string     `json:"description,omitempty"`
+}
+
+// ValueFrom is the source of the value of a parameter. Sources other than
+// those below, such as configMapKeyRef, are accepted but not modelled.
+type ValueFrom struct {
+	// Path is a file written by the container of an output parameter.
+	Path string `json:"path,omitempty"`
+	// Parameter refers to an output parameter of a step or task, e.g.
+	// "{{steps.generate.outputs.parameters.result}}".
+	Parameter string `json:"parameter,omitempty"`
+	// Expression is evaluated by Argo to obtain the value.
+	Expression string `json:"expression,omitempty"`
+	// Default is used if the value cannot be obtained.
+	Default *string `json:"default,omitempty"`
+}
+
+// Artifact is a file passed into or out of a template.
+type Artifact struct {
+	Name     string `json:"name"`
+	Path     string `json:"path,omitempty"`
+	From     string `json:"from,omitempty"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// Template is a step of a workflow. It must be of exactly one kind, one of
+// Container, Script, Steps, DAG or a kind that is not modelled.
+type Template struct {
+	Name    string `json:"name"`
+	Inputs  *IO    `json:"inputs,omitempty"`
+	Outputs *IO    `json:"outputs,omitempty"`
+	// Container runs an image.
+	Container *Container `json:"container,omitempty"`
+	// Script runs Source with the command of an image.
+	Script *Script `json:"script,omitempty"`
+	// Steps are groups of steps executed in sequence, the steps of each
+	// group in parallel.
+	Steps [][]WorkflowStep `json:"steps,omitempty"`
+	// DAG executes tasks as their dependencies allow.
+	DAG *DAG `json:"dag,omitempty"`
+	// Suspend, Resource, HTTP, ContainerSet, Data and Plugin are the kinds
+	// of templates Argo supports beyond the above. They are kept as JSON and
+	// not checked.
+	Suspend      json.RawMessage `json:"suspend,omitempty"`
+	Resource     json.RawMessage `json:"resource,omitempty"`
+	HTTP         json.RawMessage `json:"http,omitempty"`
+	ContainerSet json.RawMessage `json:"containerSet,omitempty"`
+	Data         json.RawMessage `json:"data,omitempty"`
+	Plugin       json.RawMessage `json:"plugin,omitempty"`
+
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+}
+
+// IO are the inputs or outputs of a template.
+type IO struct {
+	Parameters []Parameter `json:"parameters,omitempty"`
+	Artifacts  []Artifact  `json:"artifacts,omitempty"`
+}
+
+// Container is the container run by a template.
+type Container struct {
+	Image           string                `json:"image"`
+	ImagePullPolicy string                `json:"imagePullPolicy,omitempty"`
+	Command         []string              `json:"command,omitempty"`
+	Args            []string              `json:"args,omitempty"`
+	WorkingDir      string                `json:"workingDir,omitempty"`
+	Env             []EnvVar              `json:"env,omitempty"`
+	Resources       *ResourceRequirements `json:"resources,omitempty"`
+	VolumeMounts    []VolumeMount         `json:"volumeMounts,omitempty"`
+}
+
+// Script is a container running Source.
+type Script struct {
+	Container
+	Source string `json:"source"`
+}
+
+// EnvVar is an environment variable of a container.
+type EnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+}
+
+// VolumeMount mounts a volume of the workflow into a container.
+type VolumeMount struct {
+	Name      string `json:"name"`
+	MountPath string `json:"mountPath"`
+	SubPath   string `json:"subPath,omitempty"`
+	ReadOnly  bool   `json:"readOnly,omitempty"`
+}
+
+// ResourceRequirements are the resource requests and limits of a container
+// by resource name, e.g. "cpu", "memory", "ephemeral-storage" or extended
+// resources such as "nvidia.com/gpu".
+type ResourceRequirements struct {
+	Limits   map[string]Quantity `json:"limits,omitempty"`
+	Requests map[string]Quantity `json:"requests,omitempty"`
+}
+
+// Quantity is a resource quantity such as "100Mi", which may also be
+// written as a JSON number, see package resource.
+type Quantity string
+
+// UnmarshalJSON accepts strings and numbers.
+func (q *Quantity) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] != '"' {
+		var n json.Number
+		if err := json.Unmarshal(b, &n); err != nil {
+			return err
+		}
+		*q = Quantity(n)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	*q = Quantity(s)
+	return nil
+}
+
+// WorkflowStep calls a template from a steps template.
+type WorkflowStep struct {
+	Name      string     `json:"name"`
+	Template  string     `json:"template"`
+	Arguments *Arguments `json:"arguments,omitempty"`
+	When      string     `json:"when,omitempty"`
+	// WithItems calls the template once for every item, referred to as
+	// "{{item}}" in the arguments. Items are strings, numbers or objects,
+	// whose fields are referred to as "{{item.<name>}}", and kept as JSON.
+	WithItems []json.RawMessage `json:"withItems,omitempty"`
+	// WithParam calls the template once for every element of a JSON list,
+	// usually the output parameter of an earlier step.
+	WithParam string `json:"withParam,omitempty"`
+}
+
+// DAG is a template executing tasks as their dependencies allow.
+type DAG struct {
+	Tasks []DAGTask `json:"tasks"`
+}
+
+// DAGTask calls a template from a DAG once the tasks it depends on are
+// done.
+type DAGTask struct {
+	Name         string     `json:"name"`
+	Template     string     `json:"template"`
+	Arguments    *Arguments `json:"arguments,omitempty"`
+	Dependencies []string   `json:"dependencies,omitempty"`
+	When         string     `json:"when,omitempty"`
+	// WithItems and WithParam loop over a task as over a WorkflowStep.
+	WithItems []json.RawMessage `json:"withItems,omitempty"`
+	WithParam string            `json:"withParam,omitempty"`
+}
+
+// Parse returns the workflow defined by v, the Argo field of a
+// service.WorkflowT. v is either decoded JSON or a JSON or YAML document as
+// string or []byte, holding a Workflow resource or just its spec. Fields
+// outside of the modelled subset, such as volumes or retryStrategy, are
+// ignored.
+func Parse(v interface{}) (*Workflow, error) {
+	switch d := v.(type) {
+	case nil:
+		return nil, fmt.Errorf("missing workflow")
+	case string:
+		return parseDocument([]byte(d))
+	case []byte:
+		return parseDocument(d)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return decode(b)
+}
+
+// parseDocument parses a JSON or YAML document.
+func parseDocument(b []byte) (*Workflow, error) {
+	var v interface{}
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	if _, ok := v.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("workflow must be an object")
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return decode(b)
+}
+
+// decode decodes a Workflow or a WorkflowSpec from JSON.
+func decode(b []byte) (*Workflow, error) {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(b, &probe); err != nil {
+		return nil, fmt.Errorf("workflow must be an object: %w", err)
+	}
+	wf := &Workflow{}
+	var target interface{} = wf
+	if _, ok := probe["spec"]; !ok {
+		target = &wf.Spec
+		wf.specOnly = true
+	}
+	if err := json.Unmarshal(b, target); err != nil {
+		return nil, err
+	}
+	return wf, nil
+}
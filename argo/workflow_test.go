@@ -0,0 +1,525 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argo
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+)
+
+// Examples from the Argo Workflows repository, unchanged apart from
+// hello-world, which also sets a namespace and an imagePullPolicy, and
+// gpu, which is hello-world asking for a GPU.
+const (
+	helloWorld = `
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+metadata:
+  generateName: hello-world-
+  namespace: argo
+  labels:
+    workflows.argoproj.io/archive-strategy: "false"
+  annotations:
+    workflows.argoproj.io/description: |
+      This is a simple hello world example.
+spec:
+  entrypoint: hello-world
+  templates:
+  - name: hello-world
+    container:
+      image: busybox
+      imagePullPolicy: IfNotPresent
+      command: [echo]
+      args: ["hello world"]
+      resources:
+        limits:
+          memory: 32Mi
+          cpu: 100m
+`
+	steps = `
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+metadata:
+  generateName: steps-
+spec:
+  entrypoint: hello-hello-hello
+  templates:
+  - name: hello-hello-hello
+    steps:
+    - - name: hello1
+        template: print-message
+        arguments:
+          parameters: [{name: message, value: "hello1"}]
+    - - name: hello2a
+        template: print-message
+        arguments:
+          parameters: [{name: message, value: "hello2a"}]
+      - name: hello2b
+        template: print-message
+        arguments:
+          parameters: [{name: message, value: "hello2b"}]
+
+  - name: print-message
+    inputs:
+      parameters:
+      - name: message
+    container:
+      image: busybox
+      command: [echo]
+      args: ["{{inputs.parameters.message}}"]
+`
+	dagDiamond = `
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+metadata:
+  generateName: dag-diamond-
+spec:
+  entrypoint: diamond
+  templates:
+  - name: echo
+    inputs:
+      parameters:
+      - name: message
+    container:
+      image: alpine:3.7
+      command: [echo, "{{inputs.parameters.message}}"]
+  - name: diamond
+    dag:
+      tasks:
+      - name: A
+        template: echo
+        arguments:
+          parameters: [{name: message, value: A}]
+      - name: B
+        dependencies: [A]
+        template: echo
+        arguments:
+          parameters: [{name: message, value: B}]
+      - name: C
+        dependencies: [A]
+        template: echo
+        arguments:
+          parameters: [{name: message, value: C}]
+      - name: D
+        dependencies: [B, C]
+        template: echo
+        arguments:
+          parameters: [{name: message, value: D}]
+`
+	loopsMaps = `
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+metadata:
+  generateName: loops-maps-
+spec:
+  entrypoint: loop-map-example
+  templates:
+  - name: loop-map-example
+    steps:
+    - - name: test-linux
+        template: cat-os-release
+        arguments:
+          parameters:
+          - name: image
+            value: "{{item.image}}"
+          - name: tag
+            value: "{{item.tag}}"
+        withItems:
+        - { image: 'debian', tag: '9.1' }
+        - { image: 'debian', tag: '8.9' }
+        - { image: 'alpine', tag: '3.6' }
+        - { image: 'ubuntu', tag: '17.10' }
+
+  - name: cat-os-release
+    inputs:
+      parameters:
+      - name: image
+      - name: tag
+    container:
+      image: "{{inputs.parameters.image}}:{{inputs.parameters.tag}}"
+      command: [cat]
+      args: [/etc/os-release]
+`
+	outputParameter = `
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+metadata:
+  generateName: output-parameter-
+spec:
+  entrypoint: output-parameter
+  templates:
+  - name: output-parameter
+    steps:
+    - - name: generate-parameter
+        template: hello-world-to-file
+    - - name: consume-parameter
+        template: print-message
+        arguments:
+          parameters:
+          - name: message
+            value: "{{steps.generate-parameter.outputs.parameters.hello-param}}"
+
+  - name: hello-world-to-file
+    container:
+      image: busybox
+      command: [sh, -c]
+      args: ["echo -n hello world > /tmp/hello_world.txt"]
+    outputs:
+      parameters:
+      - name: hello-param
+        valueFrom:
+          default: "Foobar"
+          path: /tmp/hello_world.txt
+
+  - name: print-message
+    inputs:
+      parameters:
+      - name: message
+    container:
+      image: busybox
+      command: [echo]
+      args: ["{{inputs.parameters.message}}"]
+`
+	volumesEmptyDir = `
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+metadata:
+  generateName: volumes-emptydir-
+spec:
+  entrypoint: volumes-emptydir-example
+  volumes:
+  - name: workdir
+    emptyDir: {}
+
+  templates:
+  - name: volumes-emptydir-example
+    container:
+      image: debian:latest
+      command: ["/bin/bash", "-c"]
+      args: [" vol_found=` + "`mount | grep /mnt/vol`" + ` && \
+        if [[ -n $vol_found ]]; then echo \"Volume mounted and found\"; else echo \"Not found\"; fi "]
+      volumeMounts:
+      - name: workdir
+        mountPath: /mnt/vol
+`
+	retryContainer = `
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+metadata:
+  generateName: retry-container-
+spec:
+  entrypoint: retry-container
+  templates:
+  - name: retry-container
+    retryStrategy:
+      limit: "10"
+    container:
+      image: python:alpine3.6
+      command: ["python", -c]
+      # fail with a 66% probability
+      args: ["import random; import sys; exit_code = random.choice([0, 1, 1]); sys.exit(exit_code)"]
+`
+	suspendTemplate = `
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+metadata:
+  generateName: suspend-template-
+spec:
+  entrypoint: suspend
+  templates:
+  - name: suspend
+    steps:
+    - - name: build
+        template: hello-world
+    - - name: approve
+        template: approve
+    - - name: delay
+        template: delay
+    - - name: release
+        template: hello-world
+
+  - name: approve
+    suspend: {}
+
+  - name: delay
+    suspend:
+      duration: "20"    # Must be a string. Default unit is seconds. Could also be a Duration, e.g.: "2m", "6h", "1d"
+
+  - name: hello-world
+    container:
+      image: busybox
+      command: [echo]
+      args: ["hello world"]
+`
+	k8sJobs = `
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+metadata:
+  generateName: k8s-jobs-
+spec:
+  entrypoint: pi-tmpl
+  templates:
+  - name: pi-tmpl
+    resource:
+      action: create
+      successCondition: status.succeeded > 0
+      failureCondition: status.failed > 3
+      manifest: |
+        apiVersion: batch/v1
+        kind: Job
+        metadata:
+          generateName: pi-job-
+        spec:
+          template:
+            metadata:
+              name: pi
+            spec:
+              containers:
+              - name: pi
+                image: perl
+                command: ["perl",  "-Mbignum=bpi", "-wle", "print bpi(2000)"]
+              restartPolicy: Never
+          backoffLimit: 4
+    outputs:
+      parameters:
+      - name: job-name
+        valueFrom:
+          jsonPath: '{.metadata.name}'
+      - name: job-obj
+        valueFrom:
+          jqFilter: '.'
+`
+	httpHelloWorld = `
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+metadata:
+  generateName: http-template-
+spec:
+  entrypoint: main
+  templates:
+    - name: main
+      steps:
+        - - name: get-google-homepage
+            template: http
+            arguments:
+              parameters: [{name: url, value: "https://www.google.com"}]
+    - name: http
+      inputs:
+        parameters:
+          - name: url
+      http:
+        timeoutSeconds: 20 # Default 30
+        url: "{{inputs.parameters.url}}"
+        method: "GET" # Default GET
+        headers:
+          - name: "x-header-name"
+            value: "test-value"
+        successCondition: "response.body contains \"google\"" # available since v3.3
+        body: "test body" # Change request body
+`
+	containerSetSequence = `
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+metadata:
+  generateName: sequence-
+  annotations:
+    workflows.argoproj.io/description: |
+      This workflow demonstrates running a sequence of containers within a single pod.
+spec:
+  entrypoint: main
+  templates:
+    - name: main
+      containerSet:
+        containers:
+          - name: a
+            image: argoproj/argosay:v2
+          - name: b
+            image: argoproj/argosay:v2
+            dependencies:
+              - a
+          - name: c
+            image: argoproj/argosay:v2
+            dependencies:
+              - b
+`
+	gpu = `
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+metadata:
+  generateName: gpu-
+spec:
+  entrypoint: gpu
+  templates:
+  - name: gpu
+    container:
+      image: nvidia/cuda:12.2.0-base-ubuntu22.04
+      command: [nvidia-smi]
+      resources:
+        requests:
+          memory: 1Gi
+        limits:
+          memory: 1Gi
+          nvidia.com/gpu: 1
+`
+)
+
+func TestParseExamples(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		doc   string
+		check func(t *testing.T, wf *Workflow)
+	}{
+		{"hello-world", helloWorld, func(t *testing.T, wf *Workflow) {
+			if wf.Metadata == nil || wf.Metadata.Namespace != "argo" {
+				t.Errorf("metadata = %+v, want namespace argo", wf.Metadata)
+			}
+			if p := wf.Spec.Templates[0].Container.ImagePullPolicy; p != "IfNotPresent" {
+				t.Errorf("imagePullPolicy = %q", p)
+			}
+		}},
+		{"steps", steps, nil},
+		{"dag-diamond", dagDiamond, func(t *testing.T, wf *Workflow) {
+			if deps := wf.Spec.Templates[1].DAG.Tasks[3].Dependencies; len(deps) != 2 {
+				t.Errorf("dependencies of D = %v", deps)
+			}
+		}},
+		{"loops-maps", loopsMaps, func(t *testing.T, wf *Workflow) {
+			items := wf.Spec.Templates[0].Steps[0][0].WithItems
+			if len(items) != 4 {
+				t.Fatalf("withItems = %s", items)
+			}
+			var item struct{ Image, Tag string }
+			if err := json.Unmarshal(items[3], &item); err != nil || item.Image != "ubuntu" || item.Tag != "17.10" {
+				t.Errorf("withItems[3] = %s, %v", items[3], err)
+			}
+		}},
+		{"output-parameter", outputParameter, func(t *testing.T, wf *Workflow) {
+			p := wf.Spec.Templates[1].Outputs.Parameters[0]
+			if p.ValueFrom == nil || p.ValueFrom.Path != "/tmp/hello_world.txt" || p.ValueFrom.Default == nil || *p.ValueFrom.Default != "Foobar" {
+				t.Errorf("valueFrom = %+v", p.ValueFrom)
+			}
+		}},
+		{"volumes-emptydir", volumesEmptyDir, func(t *testing.T, wf *Workflow) {
+			m := wf.Spec.Templates[0].Container.VolumeMounts
+			if len(m) != 1 || m[0].Name != "workdir" || m[0].MountPath != "/mnt/vol" {
+				t.Errorf("volumeMounts = %+v", m)
+			}
+		}},
+		{"retry-container", retryContainer, nil},
+		{"suspend-template", suspendTemplate, func(t *testing.T, wf *Workflow) {
+			if s := string(wf.Spec.Templates[1].Suspend); s != "{}" {
+				t.Errorf("suspend = %s", s)
+			}
+		}},
+		{"k8s-jobs", k8sJobs, nil},
+		{"http-hello-world", httpHelloWorld, nil},
+		{"container-set-sequence", containerSetSequence, nil},
+		{"gpu", gpu, func(t *testing.T, wf *Workflow) {
+			if q := wf.Spec.Templates[0].Container.Resources.Limits["nvidia.com/gpu"]; q != "1" {
+				t.Errorf("nvidia.com/gpu limit = %q", q)
+			}
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			wf, err := Parse(tc.doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Validate("workflow.argo", wf, nil); err != nil {
+				t.Fatal(err)
+			}
+			if tc.check != nil {
+				tc.check(t, wf)
+			}
+		})
+	}
+}
+
+func TestValidateService(t *testing.T) {
+	// The workflow of a service arrives decoded from JSON.
+	var spec interface{}
+	if err := json.Unmarshal([]byte(`{
+		"entrypoint": "main",
+		"arguments": {"parameters": [{"name": "region"}]},
+		"templates": [{
+			"name": "main",
+			"container": {
+				"image": "busybox",
+				"imagePullPolicy": "Always",
+				"args": ["{{workflow.parameters.region}}"],
+				"securityContext": {"runAsNonRoot": true}
+			}
+		}]
+	}`), &spec); err != nil {
+		t.Fatal(err)
+	}
+	typ, region := "argo", "region"
+	desc := &service.ServiceDescriptionT{
+		Parameters: []*service.ParameterDefT{{Name: &region}},
+		Workflow:   &service.WorkflowT{Type: &typ, Argo: spec},
+	}
+	if err := ValidateService("body", desc); err != nil {
+		t.Fatal(err)
+	}
+	desc.Parameters = nil
+	err := ValidateService("body", desc)
+	if err == nil || !strings.Contains(err.Error(), `"region" is not a parameter of the service`) {
+		t.Errorf("error = %v, want one about the undeclared region", err)
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name, doc, want string
+	}{
+		{"undefined entrypoint", strings.Replace(helloWorld, "entrypoint: hello-world", "entrypoint: hello", 1),
+			`spec.entrypoint refers to undefined template "hello"`},
+		{"undefined template", strings.Replace(steps, "template: print-message", "template: print", 1),
+			`steps[0][0].template refers to undefined template "print"`},
+		{"missing input", strings.Replace(steps, `parameters: [{name: message, value: "hello1"}]`, `parameters: []`, 1),
+			`misses parameter "message" of template "print-message"`},
+		{"undefined input", strings.Replace(steps, "{{inputs.parameters.message}}", "{{inputs.parameters.msg}}", 1),
+			`refers to undefined parameter "{{inputs.parameters.msg}}"`},
+		{"output without valueFrom", strings.Replace(outputParameter, `
+        valueFrom:
+          default: "Foobar"
+          path: /tmp/hello_world.txt`, "", 1),
+			`"valueFrom" is missing from workflow.argo.spec.templates[1].outputs.parameters[0]`},
+		{"dependency cycle", strings.Replace(dagDiamond, "dependencies: [A]", "dependencies: [D]", 1),
+			`has a dependency cycle`},
+		{"invalid resources", strings.Replace(helloWorld, "cpu: 100m", "cpu: lots", 1),
+			`resources.cpu`},
+		{"invalid extended resource", strings.Replace(gpu, "nvidia.com/gpu: 1", "nvidia.com/gpu: one", 1),
+			`resources.nvidia.com/gpu.limit`},
+		{"no kind", strings.Replace(suspendTemplate, "suspend: {}", "inputs: {}", 1),
+			`templates[1] must define exactly one of`},
+		{"two kinds", strings.Replace(suspendTemplate, "suspend: {}", "suspend: {}\n    http: {url: https://example.com}", 1),
+			`templates[1] must define exactly one of`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			wf, err := Parse(tc.doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = Validate("workflow.argo", wf, nil)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("error = %v, want one containing %q", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"context"
+	"io"
+	"iter"
+	"net/url"
+
+	artifact "github.com/reinventingscience/ivcap-core-api/gen/artifact"
+
+	goahttp "goa.design/goa/v3/http"
+)
+
+// ArtifactsClient provides typed access to the artifact service.
+type ArtifactsClient struct {
+	client *artifact.Client
+	// doer sends the requests not covered by the generated client
+	doer goahttp.Doer
+	// base is the URL relative links returned by the service are resolved
+	// against
+	base *url.URL
+}
+
+// List returns a single page of artifacts. p may be nil, in which case the
+// service defaults apply. A malformed filter or order-by is rejected before
+// the request is sent with an error wrapping the *query.SyntaxError.
+func (c *ArtifactsClient) List(ctx context.Context, p *artifact.ListPayload) (*artifact.ArtifactListRT, error) {
+	var lp artifact.ListPayload
+	if p != nil {
+		lp = *p
+	}
+	if lp.Limit == 0 {
+		lp.Limit = 10
+	}
+	if err := checkListQuery(lp.Filter, lp.OrderBy); err != nil {
+		return nil, err
+	}
+	return c.client.List(ctx, &lp)
+}
+
+// ListAll returns an iterator over all artifacts matching p, following the
+// "next" links of the returned pages. At most maxItems artifacts are returned,
+// or all of them if maxItems is zero. p may be nil.
+func (c *ArtifactsClient) ListAll(ctx context.Context, p *artifact.ListPayload, maxItems int) iter.Seq2[*artifact.ArtifactListItem, error] {
+	fetch := func(ctx context.Context, page *string) ([]*artifact.ArtifactListItem, *string, error) {
+		var lp artifact.ListPayload
+		if p != nil {
+			lp = *p
+		}
+		if page != nil {
+			lp.Page = page
+		}
+		res, err := c.List(ctx, &lp)
+		if err != nil {
+			return nil, nil, err
+		}
+		if res.Links == nil {
+			return res.Artifacts, nil, nil
+		}
+		return res.Artifacts, res.Links.Next, nil
+	}
+	return NewPager(fetch, maxItems).All(ctx)
+}
+
+// Read returns the status of artifact id.
+func (c *ArtifactsClient) Read(ctx context.Context, id string) (*artifact.ArtifactStatusRT, error) {
+	return c.client.Read(ctx, &artifact.ReadPayload{ID: id})
+}
+
+// Upload creates a new artifact with the content read from body. The headers
+// of the upload request are taken from p, which may be nil.
+func (c *ArtifactsClient) Upload(ctx context.Context, p *artifact.UploadPayload, body io.Reader) (*artifact.ArtifactStatusRT, error) {
+	var up artifact.UploadPayload
+	if p != nil {
+		up = *p
+	}
+	rc, ok := body.(io.ReadCloser)
+	if !ok {
+		rc = io.NopCloser(body)
+	}
+	return c.client.Upload(ctx, &up, rc)
+}
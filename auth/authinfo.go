@@ -0,0 +1,160 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package auth discovers the identity providers of an IVCAP deployment and
+// logs users in against them. The resulting tokens can be handed to
+// ivcap.NewClient or, through ivcap.NewAuthDoer, to any of the generated HTTP
+// clients.
+package auth
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+
+	goahttp "goa.design/goa/v3/http"
+	"gopkg.in/yaml.v3"
+)
+
+// AuthInfoPath is the path of the document listing the identity providers
+// of a deployment.
+const AuthInfoPath = "/1/authinfo.yaml"
+
+// AuthInfo lists the identity providers accepted by a deployment.
+type AuthInfo struct {
+	// Version of the document format
+	Version int `yaml:"version"`
+	// ID of the provider to use if the user does not select one
+	DefaultProviderID string `yaml:"default-provider-id,omitempty"`
+	// Providers indexed by their ID
+	Providers map[string]*Provider `yaml:"auth-providers"`
+}
+
+// Provider describes an OAuth2 identity provider.
+type Provider struct {
+	// Provider ID
+	ID string `yaml:"id"`
+	// Authorization endpoint used for the authorization code (PKCE) flow
+	LoginURL string `yaml:"login-url"`
+	// Token endpoint
+	TokenURL string `yaml:"token-url"`
+	// Device authorization endpoint used for the device code flow
+	CodeURL string `yaml:"code-url"`
+	// Location of the keys used to sign the tokens
+	JwksURL string `yaml:"jwks-url,omitempty"`
+	// Client ID registered for IVCAP clients
+	ClientID string `yaml:"client-id"`
+	// Audience to request tokens for
+	Audience string `yaml:"audience,omitempty"`
+	// Scopes to request, defaults to DefaultScopes
+	Scopes []string `yaml:"scopes,omitempty"`
+}
+
+// DefaultScopes are requested when a provider does not list any scopes.
+var DefaultScopes = []string{"openid", "profile", "email", "offline_access"}
+
+// FetchAuthInfo retrieves and parses the authinfo document of the deployment
+// at baseURL. If doer is nil, http.DefaultClient is used.
+func FetchAuthInfo(ctx context.Context, baseURL string, doer goahttp.Doer) (*AuthInfo, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL %q: %w", baseURL, err)
+	}
+	u = u.JoinPath(AuthInfoPath)
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if doer == nil {
+		doer = http.DefaultClient
+	}
+	resp, err := doer.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", u, resp.Status)
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return ParseAuthInfo(b)
+}
+
+// ParseAuthInfo parses an authinfo document.
+func ParseAuthInfo(b []byte) (*AuthInfo, error) {
+	var ai AuthInfo
+	if err := yaml.Unmarshal(b, &ai); err != nil {
+		return nil, fmt.Errorf("invalid authinfo document: %w", err)
+	}
+	if len(ai.Providers) == 0 {
+		return nil, fmt.Errorf("invalid authinfo document: no providers listed")
+	}
+	for id, p := range ai.Providers {
+		if p == nil {
+			return nil, fmt.Errorf("invalid authinfo document: empty provider %q", id)
+		}
+		if p.ID == "" {
+			p.ID = id
+		}
+		if p.TokenURL == "" || p.ClientID == "" {
+			return nil, fmt.Errorf("invalid authinfo document: provider %q lacks token-url or client-id", id)
+		}
+	}
+	return &ai, nil
+}
+
+// ProviderIDs returns the sorted IDs of all listed providers.
+func (ai *AuthInfo) ProviderIDs() []string {
+	ids := make([]string, 0, len(ai.Providers))
+	for id := range ai.Providers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Provider returns the provider with the given ID. If id is empty, the
+// default provider is returned, or the only one if just a single provider is
+// listed.
+func (ai *AuthInfo) Provider(id string) (*Provider, error) {
+	if id == "" {
+		id = ai.DefaultProviderID
+	}
+	if id == "" {
+		if len(ai.Providers) != 1 {
+			return nil, fmt.Errorf("no default provider, select one of %v", ai.ProviderIDs())
+		}
+		for _, p := range ai.Providers {
+			return p, nil
+		}
+	}
+	p, ok := ai.Providers[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q, select one of %v", id, ai.ProviderIDs())
+	}
+	return p, nil
+}
+
+func (p *Provider) scopes() []string {
+	if len(p.Scopes) > 0 {
+		return p.Scopes
+	}
+	return DefaultScopes
+}
@@ -0,0 +1,120 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testAuthInfo = `
+version: 1
+default-provider-id: auth0
+auth-providers:
+  auth0:
+    login-url: https://idp.test/authorize
+    token-url: https://idp.test/oauth/token
+    code-url: https://idp.test/oauth/device/code
+    client-id: cli
+    audience: https://api.ivcap.test
+  other:
+    id: other
+    token-url: https://other.test/token
+    client-id: cli2
+    scopes: [openid]
+`
+
+func TestParseAuthInfo(t *testing.T) {
+	ai, err := ParseAuthInfo([]byte(testAuthInfo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := strings.Join(ai.ProviderIDs(), ","); ids != "auth0,other" {
+		t.Errorf("provider IDs = %s", ids)
+	}
+	p, err := ai.Provider("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ID != "auth0" || p.CodeURL != "https://idp.test/oauth/device/code" || p.Audience != "https://api.ivcap.test" {
+		t.Errorf("default provider = %+v", p)
+	}
+	if s := strings.Join(p.scopes(), " "); s != "openid profile email offline_access" {
+		t.Errorf("default scopes = %s", s)
+	}
+	if p, _ := ai.Provider("other"); p == nil || strings.Join(p.scopes(), " ") != "openid" {
+		t.Errorf("provider other = %+v", p)
+	}
+	if _, err := ai.Provider("missing"); err == nil || !strings.Contains(err.Error(), "[auth0 other]") {
+		t.Errorf("unknown provider: err = %v", err)
+	}
+}
+
+func TestParseAuthInfoErrors(t *testing.T) {
+	for _, tc := range []struct {
+		doc, err string
+	}{
+		{"version: [", "invalid authinfo document: yaml"},
+		{"version: 1", "no providers listed"},
+		{"auth-providers:\n  a:\n", `empty provider "a"`},
+		{"auth-providers:\n  a:\n    client-id: x\n", `provider "a" lacks token-url or client-id`},
+		{"auth-providers:\n  a:\n    token-url: https://idp.test/token\n", `provider "a" lacks token-url or client-id`},
+	} {
+		_, err := ParseAuthInfo([]byte(tc.doc))
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%q: err = %v, want %q", tc.doc, err, tc.err)
+		}
+	}
+}
+
+func TestProviderWithoutDefault(t *testing.T) {
+	ai, err := ParseAuthInfo([]byte("auth-providers:\n  a:\n    token-url: https://idp.test/token\n    client-id: x\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, err := ai.Provider(""); err != nil || p.ID != "a" {
+		t.Errorf("only provider = %v, %v", p, err)
+	}
+	ai.Providers["b"] = &Provider{ID: "b"}
+	if _, err := ai.Provider(""); err == nil {
+		t.Error("expected an error selecting among several providers without a default")
+	}
+}
+
+func TestFetchAuthInfo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/1/authinfo.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(testAuthInfo))
+	}))
+	defer srv.Close()
+
+	ai, err := FetchAuthInfo(context.Background(), srv.URL+"/api", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ai.Providers) != 2 || ai.DefaultProviderID != "auth0" {
+		t.Errorf("authinfo = %+v", ai)
+	}
+	_, err = FetchAuthInfo(context.Background(), srv.URL, srv.Client())
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("missing document: err = %v", err)
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	ivcap "github.com/reinventingscience/ivcap-core-api"
+	goahttp "goa.design/goa/v3/http"
+)
+
+// LoginOptions controls the interactive parts of a login.
+type LoginOptions struct {
+	// Doer sends the requests to the identity provider, http.DefaultClient if
+	// nil.
+	Doer goahttp.Doer
+	// Prompt is called by the device flow with the verification URL and the
+	// code the user has to enter there.
+	Prompt func(verificationURI, userCode string)
+	// OpenBrowser is called by the PKCE flow with the URL the user has to
+	// visit. It defaults to printing the URL to stderr.
+	OpenBrowser func(authURL string) error
+	// RedirectAddr is the local address the PKCE flow listens on for the
+	// redirect from the provider, "127.0.0.1:0" if empty.
+	RedirectAddr string
+}
+
+// Login returns a token source for the deployment at baseURL. A token found
+// in cache is reused, otherwise the user is logged in against the provider
+// providerID (or the default one) listed in the deployment's authinfo
+// document. cache and opts may be nil.
+func Login(ctx context.Context, baseURL, providerID string, cache ivcap.TokenCache, opts *LoginOptions) (*ivcap.RefreshingTokenSource, error) {
+	if opts == nil {
+		opts = &LoginOptions{}
+	}
+	ai, err := FetchAuthInfo(ctx, baseURL, opts.Doer)
+	if err != nil {
+		return nil, err
+	}
+	p, err := ai.Provider(providerID)
+	if err != nil {
+		return nil, err
+	}
+	var tok *ivcap.Token
+	if cache != nil {
+		if t, err := cache.Load(); err == nil && (t.Valid() || t.RefreshToken != "") {
+			tok = t
+		}
+	}
+	if tok == nil {
+		if tok, err = p.Login(ctx, opts); err != nil {
+			return nil, err
+		}
+		if cache != nil {
+			if err := cache.Save(tok); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return p.TokenSource(tok, cache, opts), nil
+}
+
+// Login logs the user in, using the device flow if the provider supports it
+// and the PKCE flow otherwise.
+func (p *Provider) Login(ctx context.Context, opts *LoginOptions) (*ivcap.Token, error) {
+	if p.CodeURL != "" {
+		return p.LoginDevice(ctx, opts)
+	}
+	return p.LoginPKCE(ctx, opts)
+}
+
+// TokenSource returns a token source starting with tok which refreshes it
+// through the provider's token endpoint. If the refresh token is no longer
+// valid and the provider supports the device flow, the user is asked to log
+// in again.
+func (p *Provider) TokenSource(tok *ivcap.Token, cache ivcap.TokenCache, opts *LoginOptions) *ivcap.RefreshingTokenSource {
+	if opts == nil {
+		opts = &LoginOptions{}
+	}
+	r := &ivcap.OAuth2Refresher{
+		TokenURL: p.TokenURL,
+		ClientID: p.ClientID,
+		Doer:     opts.Doer,
+	}
+	if p.CodeURL != "" {
+		r.Fallback = p.DeviceFlow(opts)
+	}
+	return ivcap.NewRefreshingTokenSource(tok, r, cache)
+}
+
+// DeviceFlow returns the OAuth2 device authorization flow for the provider.
+func (p *Provider) DeviceFlow(opts *LoginOptions) *ivcap.DeviceFlow {
+	if opts == nil {
+		opts = &LoginOptions{}
+	}
+	return &ivcap.DeviceFlow{
+		DeviceAuthURL: p.CodeURL,
+		TokenURL:      p.TokenURL,
+		ClientID:      p.ClientID,
+		Scopes:        p.scopes(),
+		Audience:      p.Audience,
+		Prompt:        opts.Prompt,
+		Doer:          opts.Doer,
+	}
+}
+
+// LoginDevice logs the user in through the OAuth2 device authorization flow.
+func (p *Provider) LoginDevice(ctx context.Context, opts *LoginOptions) (*ivcap.Token, error) {
+	if p.CodeURL == "" {
+		return nil, fmt.Errorf("provider %q does not support the device flow", p.ID)
+	}
+	return p.DeviceFlow(opts).Login(ctx)
+}
+
+// LoginPKCE logs the user in through the OAuth2 authorization code flow with
+// PKCE (RFC 7636). The provider redirects back to a temporary HTTP server on
+// the local machine.
+func (p *Provider) LoginPKCE(ctx context.Context, opts *LoginOptions) (*ivcap.Token, error) {
+	if opts == nil {
+		opts = &LoginOptions{}
+	}
+	if p.LoginURL == "" {
+		return nil, fmt.Errorf("provider %q does not support the authorization code flow", p.ID)
+	}
+	verifier, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+	state, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	addr := opts.RedirectAddr
+	if addr == "" {
+		addr = "127.0.0.1:0"
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	redirectURI := fmt.Sprintf("http://%s/callback", l.Addr())
+
+	type result struct {
+		code string
+		err  error
+	}
+	done := make(chan result, 1)
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/callback" {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		var res result
+		switch {
+		case q.Get("state") != state:
+			res.err = errors.New("login failed: state mismatch")
+		case q.Get("error") != "":
+			res.err = &ivcap.OAuth2Error{Code: q.Get("error"), Description: q.Get("error_description")}
+		case q.Get("code") == "":
+			res.err = errors.New("login failed: no authorization code returned")
+		default:
+			res.code = q.Get("code")
+		}
+		if res.err != nil {
+			http.Error(w, res.err.Error(), http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "Login successful, you can close this window.")
+		}
+		select {
+		case done <- res:
+		default:
+		}
+	})}
+	go srv.Serve(l)
+	defer srv.Close()
+
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.ClientID},
+		"redirect_uri":          {redirectURI},
+		"scope":                 {strings.Join(p.scopes(), " ")},
+		"state":                 {state},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}
+	if p.Audience != "" {
+		q.Set("audience", p.Audience)
+	}
+	authURL := p.LoginURL + "?" + q.Encode()
+	if strings.Contains(p.LoginURL, "?") {
+		authURL = p.LoginURL + "&" + q.Encode()
+	}
+	open := opts.OpenBrowser
+	if open == nil {
+		open = func(u string) error {
+			_, err := fmt.Fprintf(os.Stderr, "To log in, visit %s\n", u)
+			return err
+		}
+	}
+	if err := open(authURL); err != nil {
+		return nil, err
+	}
+
+	var res result
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res = <-done:
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+	return ivcap.RequestToken(ctx, opts.Doer, p.TokenURL, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {res.code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {p.ClientID},
+		"code_verifier": {verifier},
+	})
+}
+
+// randomString returns n random bytes encoded as unpadded base64url.
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	ivcap "github.com/reinventingscience/ivcap-core-api"
+)
+
+// pkceIdP is an identity provider supporting only the authorization code
+// flow. It issues the code "the-code" for the challenge it last saw.
+type pkceIdP struct {
+	*httptest.Server
+	t         *testing.T
+	challenge string
+	redirect  string
+}
+
+func newPKCEIdP(t *testing.T) *pkceIdP {
+	idp := &pkceIdP{t: t}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/1/authinfo.yaml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "auth-providers:\n  idp:\n    login-url: %[1]s/authorize\n    token-url: %[1]s/token\n    client-id: cli\n", idp.URL)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+		switch {
+		case r.FormValue("grant_type") != "authorization_code" || r.FormValue("client_id") != "cli":
+			t.Errorf("token request %v", r.Form)
+		case r.FormValue("code") != "the-code" || r.FormValue("redirect_uri") != idp.redirect:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		case base64.RawURLEncoding.EncodeToString(sum[:]) != idp.challenge:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant", "error_description": "code verifier mismatch"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "access", "refresh_token": "refresh", "expires_in": 3600})
+	})
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+// browser returns an OpenBrowser function which checks the authorization
+// request and lets the redirect carry query, with state added unless set.
+func (idp *pkceIdP) browser(query url.Values) func(string) error {
+	return func(authURL string) error {
+		u, err := url.Parse(authURL)
+		if err != nil {
+			return err
+		}
+		q := u.Query()
+		if u.Path != "/authorize" || q.Get("response_type") != "code" || q.Get("client_id") != "cli" ||
+			q.Get("code_challenge_method") != "S256" || q.Get("scope") != "openid profile email offline_access" {
+			idp.t.Errorf("authorization request %s", authURL)
+		}
+		idp.challenge, idp.redirect = q.Get("code_challenge"), q.Get("redirect_uri")
+		if query.Get("state") == "" {
+			query.Set("state", q.Get("state"))
+		}
+		resp, err := http.Get(idp.redirect + "?" + query.Encode())
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+}
+
+func (idp *pkceIdP) provider() *Provider {
+	return &Provider{ID: "idp", LoginURL: idp.URL + "/authorize", TokenURL: idp.URL + "/token", ClientID: "cli"}
+}
+
+func TestLoginPKCE(t *testing.T) {
+	idp := newPKCEIdP(t)
+	tok, err := idp.provider().LoginPKCE(context.Background(), &LoginOptions{
+		OpenBrowser: idp.browser(url.Values{"code": {"the-code"}}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.AccessToken != "access" || tok.RefreshToken != "refresh" {
+		t.Errorf("token = %+v", tok)
+	}
+}
+
+func TestLoginPKCEErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		query url.Values
+		err   string
+	}{
+		{"state", url.Values{"code": {"the-code"}, "state": {"forged"}}, "login failed: state mismatch"},
+		{"denied", url.Values{"error": {"access_denied"}, "error_description": {"no"}}, "oauth2: access_denied: no"},
+		{"no code", url.Values{}, "login failed: no authorization code returned"},
+		{"bad code", url.Values{"code": {"other"}}, "oauth2: invalid_grant"},
+	} {
+		idp := newPKCEIdP(t)
+		_, err := idp.provider().LoginPKCE(context.Background(), &LoginOptions{OpenBrowser: idp.browser(tc.query)})
+		if err == nil || err.Error() != tc.err {
+			t.Errorf("%s: err = %v, want %s", tc.name, err, tc.err)
+		}
+	}
+}
+
+func TestLoginPKCECancel(t *testing.T) {
+	idp := newPKCEIdP(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := idp.provider().LoginPKCE(ctx, &LoginOptions{OpenBrowser: func(string) error {
+		cancel()
+		return nil
+	}})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	idp := newPKCEIdP(t)
+	cache := ivcap.FileTokenCache(filepath.Join(t.TempDir(), "token.json"))
+	opened := 0
+	browser := idp.browser(url.Values{"code": {"the-code"}})
+	opts := &LoginOptions{OpenBrowser: func(u string) error {
+		opened++
+		return browser(u)
+	}}
+	for i := 0; i < 2; i++ {
+		ts, err := Login(context.Background(), idp.URL, "", cache, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tok, err := ts.Token(context.Background()); err != nil || tok != "access" {
+			t.Errorf("token = %q, %v", tok, err)
+		}
+	}
+	if opened != 1 {
+		t.Errorf("logged in %d times, want the cached token to be reused", opened)
+	}
+	if tok, err := cache.Load(); err != nil || tok.RefreshToken != "refresh" {
+		t.Errorf("cached token = %+v, %v", tok, err)
+	}
+}
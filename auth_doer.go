@@ -0,0 +1,90 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"net/http"
+	"strings"
+
+	goahttp "goa.design/goa/v3/http"
+)
+
+// authDoer fills in the Authorization header of requests which do not carry
+// a JWT already.
+type authDoer struct {
+	doer goahttp.Doer
+	ts   TokenSource
+}
+
+// NewAuthDoer returns a Doer which sets the Authorization header from ts on
+// every request whose payload JWT was left empty. This allows the generated
+// HTTP clients to be used without setting the JWT field of each payload.
+func NewAuthDoer(doer goahttp.Doer, ts TokenSource) goahttp.Doer {
+	if doer == nil {
+		doer = http.DefaultClient
+	}
+	return &authDoer{doer: doer, ts: ts}
+}
+
+// Do sends req after adding the Authorization header if needed. If the token
+// is rejected and the token source can be invalidated, the request is retried
+// once with a fresh token.
+func (d *authDoer) Do(req *http.Request) (*http.Response, error) {
+	if !needsToken(req) {
+		return d.doer.Do(req)
+	}
+	if err := d.authorize(req); err != nil {
+		return nil, err
+	}
+	resp, err := d.doer.Do(req)
+	inv, ok := d.ts.(interface{ Invalidate() })
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || !ok || !canReplay(req) {
+		return resp, err
+	}
+	resp.Body.Close()
+	inv.Invalidate()
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	if err := d.authorize(retry); err != nil {
+		return nil, err
+	}
+	return d.doer.Do(retry)
+}
+
+func (d *authDoer) authorize(req *http.Request) error {
+	jwt, err := d.ts.Token(req.Context())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	return nil
+}
+
+// needsToken returns true if the Authorization header of req is missing or
+// carries an empty bearer token, which is what the generated encoders produce
+// for an empty payload JWT.
+func needsToken(req *http.Request) bool {
+	h := strings.TrimSpace(req.Header.Get("Authorization"))
+	return h == "" || h == "Bearer"
+}
+
+// canReplay returns true if the body of req can be sent again.
+func canReplay(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
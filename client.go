@@ -0,0 +1,152 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ivcap provides a single client for the IVCAP core API. It wraps the
+// generated HTTP clients for the order, artifact, service and metadata
+// services and exposes typed methods on top of their goa endpoints.
+package ivcap
+
+import (
+	"fmt"
+	"net/url"
+
+	artifact "github.com/reinventingscience/ivcap-core-api/gen/artifact"
+	metadata "github.com/reinventingscience/ivcap-core-api/gen/metadata"
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+	artifactc "github.com/reinventingscience/ivcap-core-api/http/artifact"
+	metadatac "github.com/reinventingscience/ivcap-core-api/http/metadata"
+	orderc "github.com/reinventingscience/ivcap-core-api/http/order"
+	servicec "github.com/reinventingscience/ivcap-core-api/http/service"
+
+	goahttp "goa.design/goa/v3/http"
+	goa "goa.design/goa/v3/pkg"
+)
+
+// Middleware wraps the endpoint of a service method, for instance to
+// instrument it. service and method are the names of the service and method
+// as defined in the design, e.g. "order" and "read".
+type Middleware func(service, method string, e goa.Endpoint) goa.Endpoint
+
+// Client gives access to all IVCAP services through a single base URL,
+// token source and HTTP doer.
+type Client struct {
+	orders    *OrdersClient
+	artifacts *ArtifactsClient
+	services  *ServicesClient
+	metadata  *MetadataClient
+}
+
+// NewClient returns a client for the IVCAP deployment at baseURL
+// (e.g. "https://api.ivcap.net"). Every request is authenticated with a token
+// obtained from ts and sent through doer. If doer is nil, http.DefaultClient
+// is used. To keep long running processes authenticated, ts is typically a
+// *RefreshingTokenSource. To retry requests failing with transient errors,
+// wrap doer with NewRetryDoer, to log them, with NewLoggingDoer. The endpoints of all service methods are
+// wrapped with mw, the first middleware being the outermost.
+func NewClient(baseURL string, ts TokenSource, doer goahttp.Doer, mw ...Middleware) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL %q: %w", baseURL, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q: missing scheme or host", baseURL)
+	}
+	if ts == nil {
+		return nil, fmt.Errorf("missing token source")
+	}
+	doer = NewAuthDoer(doer, ts)
+	var (
+		enc = goahttp.RequestEncoder
+		dec = goahttp.ResponseDecoder
+	)
+
+	w := func(service, method string, e goa.Endpoint) goa.Endpoint {
+		e = wrapErrors(service, method, e)
+		for i := len(mw) - 1; i >= 0; i-- {
+			e = mw[i](service, method, e)
+		}
+		return e
+	}
+	oc := orderc.NewClient(u.Scheme, u.Host, doer, enc, dec, false)
+	ac := artifactc.NewClient(u.Scheme, u.Host, doer, enc, dec, false)
+	sc := servicec.NewClient(u.Scheme, u.Host, doer, enc, dec, false)
+	mc := metadatac.NewClient(u.Scheme, u.Host, doer, enc, dec, false)
+	orderc.SendIdempotencyKeys(oc)
+	artifactc.SendIdempotencyKeys(ac)
+	metadatac.SendIdempotencyKeys(mc)
+
+	artifacts := &ArtifactsClient{
+		client: artifact.NewClient(
+			w(artifact.ServiceName, artifact.MethodNames[0], ac.List()),
+			w(artifact.ServiceName, artifact.MethodNames[1], ac.Read()),
+			w(artifact.ServiceName, artifact.MethodNames[2], ac.Upload()),
+		),
+		doer: doer,
+		base: u,
+	}
+	return &Client{
+		orders: &OrdersClient{
+			client: order.NewClient(
+				w(order.ServiceName, order.MethodNames[0], oc.Read()),
+				w(order.ServiceName, order.MethodNames[1], oc.List()),
+				w(order.ServiceName, order.MethodNames[2], oc.Create()),
+				w(order.ServiceName, order.MethodNames[3], oc.Logs()),
+				w(order.ServiceName, order.MethodNames[4], oc.Top()),
+			),
+			doer:      doer,
+			artifacts: artifacts,
+		},
+		artifacts: artifacts,
+		services: &ServicesClient{
+			client: service.NewClient(
+				w(service.ServiceName, service.MethodNames[0], sc.List()),
+				w(service.ServiceName, service.MethodNames[1], sc.CreateService()),
+				w(service.ServiceName, service.MethodNames[2], sc.Read()),
+				w(service.ServiceName, service.MethodNames[3], sc.Update()),
+				w(service.ServiceName, service.MethodNames[4], sc.Delete()),
+			),
+		},
+		metadata: &MetadataClient{
+			client: metadata.NewClient(
+				w(metadata.ServiceName, metadata.MethodNames[0], mc.Read()),
+				w(metadata.ServiceName, metadata.MethodNames[1], mc.List()),
+				w(metadata.ServiceName, metadata.MethodNames[2], mc.Add()),
+				w(metadata.ServiceName, metadata.MethodNames[3], mc.UpdateOne()),
+				w(metadata.ServiceName, metadata.MethodNames[4], mc.UpdateRecord()),
+				w(metadata.ServiceName, metadata.MethodNames[5], mc.Revoke()),
+			),
+		},
+	}, nil
+}
+
+// Orders returns the client for the order service.
+func (c *Client) Orders() *OrdersClient {
+	return c.orders
+}
+
+// Artifacts returns the client for the artifact service.
+func (c *Client) Artifacts() *ArtifactsClient {
+	return c.artifacts
+}
+
+// Services returns the client for the service service.
+func (c *Client) Services() *ServicesClient {
+	return c.services
+}
+
+// Metadata returns the client for the metadata service.
+func (c *Client) Metadata() *MetadataClient {
+	return c.metadata
+}
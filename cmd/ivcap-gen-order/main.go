@@ -0,0 +1,147 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command ivcap-gen-order generates a Go type for placing orders with an
+// IVCAP service. The parameter definitions are either read from a deployment
+// or from a JSON file holding a service description.
+//
+// Usage:
+//
+//	ivcap-gen-order -service urn:ivcap:service:... [-url https://...] [-o fire_risk.go]
+//	ivcap-gen-order -service urn:ivcap:service:... -file service.json [-o fire_risk.go]
+//
+// The access token is taken from the -token flag or the IVCAP_TOKEN environment
+// variable. Without either, the cached token is used or the user is asked to
+// log in.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	ivcap "github.com/reinventingscience/ivcap-core-api"
+	"github.com/reinventingscience/ivcap-core-api/auth"
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+	"github.com/reinventingscience/ivcap-core-api/orderbuilder"
+)
+
+func main() {
+	var (
+		serviceF = flag.String("service", "", "ID of the service (required)")
+		fileF    = flag.String("file", "", "JSON file with the service description, instead of reading it from -url")
+		urlF     = flag.String("url", os.Getenv("IVCAP_URL"), "base URL of the IVCAP deployment")
+		tokenF   = flag.String("token", os.Getenv("IVCAP_TOKEN"), "access token")
+		pkgF     = flag.String("pkg", "", "package name, defaults to the name of the output directory")
+		typeF    = flag.String("type", "", "name of the generated type, defaults to the service name followed by \"Order\"")
+		outF     = flag.String("o", "", "output file, defaults to stdout")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s -service ID [-file FILE | -url URL] [flags]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(*serviceF, *fileF, *urlF, *tokenF, *pkgF, *typeF, *outF); err != nil {
+		fmt.Fprintf(os.Stderr, "ivcap-gen-order: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(id, file, baseURL, token, pkg, typeName, out string) error {
+	if id == "" {
+		flag.Usage()
+		return fmt.Errorf("missing -service")
+	}
+	var (
+		cfg *orderbuilder.Config
+		err error
+	)
+	if file != "" {
+		cfg, err = fromFile(id, file)
+	} else {
+		cfg, err = fromService(context.Background(), id, baseURL, token)
+	}
+	if err != nil {
+		return err
+	}
+
+	cfg.TypeName = typeName
+	cfg.Package = pkg
+	if cfg.Package == "" {
+		cfg.Package = "main"
+		if out != "" {
+			if abs, err := filepath.Abs(out); err == nil {
+				cfg.Package = filepath.Base(filepath.Dir(abs))
+			}
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return orderbuilder.Generate(w, cfg)
+}
+
+// fromFile reads a service description in the JSON format of the service
+// create and update requests.
+func fromFile(id, file string) (*orderbuilder.Config, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var desc service.ServiceDescriptionT
+	if err := json.Unmarshal(b, &desc); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return orderbuilder.FromDescription(id, &desc), nil
+}
+
+// fromService reads service id from the deployment at baseURL.
+func fromService(ctx context.Context, id, baseURL, token string) (*orderbuilder.Config, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("missing -url or IVCAP_URL")
+	}
+	var ts ivcap.TokenSource
+	if token != "" {
+		ts = ivcap.StaticToken(token)
+	} else {
+		cache, err := ivcap.DefaultTokenCache()
+		if err != nil {
+			return nil, err
+		}
+		if ts, err = auth.Login(ctx, baseURL, "", cache, nil); err != nil {
+			return nil, err
+		}
+	}
+	c, err := ivcap.NewClient(baseURL, ts, nil)
+	if err != nil {
+		return nil, err
+	}
+	st, err := c.Services().Read(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return orderbuilder.FromService(st), nil
+}
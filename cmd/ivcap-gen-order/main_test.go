@@ -0,0 +1,59 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunFile(t *testing.T) {
+	// The package is named after the directory of the output file.
+	dir := filepath.Join(t.TempDir(), "firerisk")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "fire_risk.go")
+	err := run("urn:ivcap:service:0d6ce3f6-5b1b-4bcd-95a5-2a7ca4b0e3ab", "../../orderbuilder/testdata/fire_risk.json", "", "", "", "", out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("../../orderbuilder/testdata/fire_risk.golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("generated code differs from orderbuilder/testdata/fire_risk.golden:\n%s", got)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		id, file, base string
+	}{
+		{"no url", "urn:ivcap:service:1", "", ""},
+		{"missing file", "urn:ivcap:service:1", "testdata/missing.json", ""},
+	} {
+		if err := run(tc.id, tc.file, tc.base, "token", "", "", ""); err == nil {
+			t.Errorf("%s: no error", tc.name)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command ivcap-local serves the IVCAP API on localhost for development,
+// without Argo, Magda or Minio. Services, orders and metadata are kept in a
+// SQLite database and the content of artifacts in files, all in the -dir
+// directory. Orders of services with a basic workflow run its command as a
+// local process, all others are simulated as described by the -simulation
+// file (see package local).
+//
+// Usage:
+//
+//	ivcap-local [-addr localhost:8088] [-dir .ivcap-local] [-simulation sim.yaml] [-simulate-all]
+//
+// Processes get the order parameters as IVCAP_PARAM_<NAME> environment
+// variables and, with -param-args, as "--name=value" arguments. Of the
+// environment of the server, they only get PATH, HOME and the variables
+// listed by -pass-env. Files they write to $IVCAP_OUTPUT_DIR become the
+// products of the order. Memory and cpu limits are enforced in the cgroup v2
+// directory given by -cgroup, which must be writable by the server and have
+// the memory and cpu controllers enabled for its children. Otherwise only
+// memory is limited, by an rlimit.
+//
+// Requests are authorized with the token given by -token, which grants all
+// scopes, or any unsigned JWT carrying the scopes needed. Without -token a
+// JWT for a local account is printed at startup.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/reinventingscience/ivcap-core-api/ivcapfake"
+	"github.com/reinventingscience/ivcap-core-api/local"
+)
+
+// localAccount is the account of the token printed at startup.
+const localAccount = "urn:ivcap:account:local"
+
+func main() {
+	var (
+		addrF    = flag.String("addr", "localhost:8088", "address to listen on")
+		dirF     = flag.String("dir", ".ivcap-local", "directory holding the database, artifacts and order work directories")
+		baseURLF = flag.String("base-url", "", "URL the server is reachable at, defaults to http:// followed by -addr")
+		tokenF   = flag.String("token", os.Getenv("IVCAP_TOKEN"), "token granting all scopes")
+		simF     = flag.String("simulation", "", "YAML file describing what simulated orders do")
+		simAllF  = flag.Bool("simulate-all", false, "simulate all orders instead of running basic workflows")
+		argsF    = flag.Bool("param-args", false, "also pass order parameters to processes as --name=value arguments")
+		cgroupF  = flag.String("cgroup", "", "delegated cgroup v2 directory for enforcing resource limits")
+		envF     = flag.String("pass-env", "", "comma separated environment variables passed on to processes besides PATH and HOME")
+		verboseF = flag.Bool("v", false, "log every request")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	proc := &local.ProcessExecutor{ParamArgs: *argsF, CgroupDir: *cgroupF}
+	for _, k := range strings.Split(*envF, ",") {
+		if v, ok := os.LookupEnv(strings.TrimSpace(k)); ok {
+			proc.Env = append(proc.Env, strings.TrimSpace(k)+"="+v)
+		}
+	}
+	if err := run(*addrF, *dirF, *baseURLF, *tokenF, *simF, *simAllF, proc, *verboseF); err != nil {
+		fmt.Fprintf(os.Stderr, "ivcap-local: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(addr, dir, baseURL, token, simFile string, simulateAll bool, proc local.Executor, verbose bool) error {
+	if baseURL == "" {
+		baseURL = "http://" + addr
+	}
+	cfg := &local.Config{Dir: dir, BaseURL: baseURL, SimulateAll: simulateAll, Executor: proc}
+	if token != "" {
+		cfg.Tokens = map[string][]string{token: {"consumer:read", "consumer:write"}}
+	}
+	if simFile != "" {
+		sim, err := local.LoadSimulation(simFile)
+		if err != nil {
+			return err
+		}
+		cfg.Simulation = sim
+	}
+	srv, err := local.New(cfg)
+	if err != nil {
+		return err
+	}
+	defer srv.Close()
+
+	h := srv.Handler()
+	if verbose {
+		h = logRequests(h)
+	}
+	hs := &http.Server{Addr: addr, Handler: h}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		hs.Shutdown(shutdown)
+	}()
+
+	slog.Info("serving IVCAP API", "url", baseURL, "dir", dir)
+	if token == "" {
+		fmt.Fprintf(os.Stderr, "\nexport IVCAP_URL=%s\nexport IVCAP_TOKEN=%s\n\n",
+			baseURL, ivcapfake.Token(localAccount, "consumer:read", "consumer:write"))
+	}
+	if err := hs.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// logRequests logs the method, path, status and duration of every request.
+func logRequests(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(sw, r)
+		slog.Info("request", "method", r.Method, "path", r.URL.Path, "status", sw.status, "duration", time.Since(start))
+	})
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
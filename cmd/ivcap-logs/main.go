@@ -0,0 +1,96 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command ivcap-logs prints the logs of an IVCAP order. With -f it keeps
+// following them until the order has finished, like "tail -f".
+//
+// Usage:
+//
+//	ivcap-logs [-url https://...] [-container main] [-f] urn:ivcap:order:...
+//
+// The access token is taken from the -token flag or the IVCAP_TOKEN environment
+// variable. Without either, the cached token is used or the user is asked to
+// log in.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	ivcap "github.com/reinventingscience/ivcap-core-api"
+	"github.com/reinventingscience/ivcap-core-api/auth"
+)
+
+func main() {
+	var (
+		urlF       = flag.String("url", os.Getenv("IVCAP_URL"), "base URL of the IVCAP deployment")
+		tokenF     = flag.String("token", os.Getenv("IVCAP_TOKEN"), "access token")
+		containerF = flag.String("container", "", "only print the logs of this container")
+		followF    = flag.Bool("f", false, "follow the logs until the order has finished")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] ORDER_ID\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, os.Stdout, *urlF, *tokenF, flag.Arg(0), *containerF, *followF); err != nil {
+		fmt.Fprintf(os.Stderr, "ivcap-logs: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, w io.Writer, baseURL, token, id, container string, follow bool) error {
+	if baseURL == "" {
+		return fmt.Errorf("missing -url or IVCAP_URL")
+	}
+	var ts ivcap.TokenSource
+	if token != "" {
+		ts = ivcap.StaticToken(token)
+	} else {
+		cache, err := ivcap.DefaultTokenCache()
+		if err != nil {
+			return err
+		}
+		if ts, err = auth.Login(ctx, baseURL, "", cache, nil); err != nil {
+			return err
+		}
+	}
+	c, err := ivcap.NewClient(baseURL, ts, nil)
+	if err != nil {
+		return err
+	}
+	for l, err := range c.Orders().TailLogs(ctx, id, container, follow) {
+		if err != nil {
+			return err
+		}
+		if l.Time.IsZero() {
+			fmt.Fprintf(w, "[%s] %s\n", l.Container, l.Text)
+		} else {
+			fmt.Fprintf(w, "%s [%s] %s\n", l.Time.Format(time.RFC3339), l.Container, l.Text)
+		}
+	}
+	return nil
+}
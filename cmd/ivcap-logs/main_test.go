@@ -0,0 +1,86 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+	"github.com/reinventingscience/ivcap-core-api/ivcapfake"
+)
+
+const testAccount = "urn:ivcap:account:test"
+
+func TestRun(t *testing.T) {
+	now := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	srv := httptest.NewUnstartedServer(nil)
+	f := ivcapfake.New(&ivcapfake.Options{
+		BaseURL: "http://" + srv.Listener.Addr().String(),
+		Now:     func() time.Time { return now },
+	})
+	srv.Config.Handler = f.Handler()
+	srv.Start()
+	defer srv.Close()
+
+	ctx := ivcapfake.WithAccount(context.Background(), testAccount)
+	typ := "basic"
+	svc, _, err := f.Services.CreateService(ctx, &service.CreateServicePayload{Services: &service.ServiceDescriptionT{
+		Workflow:   &service.WorkflowT{Type: &typ, Basic: &service.BasicWorkflowOptsT{Image: "alpine"}},
+		Parameters: []*service.ParameterDefT{},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, _, err := f.Orders.Create(ctx, &order.CreatePayload{Orders: &order.OrderRequestT{ServiceID: svc.ID, Parameters: []*order.ParameterT{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Orders.AppendLog(o.ID, "main", "starting")
+	now = now.Add(time.Second)
+	f.Orders.AppendLog(o.ID, "sidecar", "ready")
+	now = now.Add(time.Second)
+	f.Orders.AppendLog(o.ID, "main", "done")
+	f.Orders.SetStatus(o.ID, "succeeded")
+
+	token := ivcapfake.Token(testAccount, "consumer:read")
+	for _, tc := range []struct {
+		container string
+		follow    bool
+		want      string
+	}{
+		{"", true, "2023-05-01T10:00:00Z [main] starting\n2023-05-01T10:00:01Z [sidecar] ready\n2023-05-01T10:00:02Z [main] done\n"},
+		{"main", false, "2023-05-01T10:00:00Z [main] starting\n2023-05-01T10:00:02Z [main] done\n"},
+	} {
+		var out strings.Builder
+		if err := run(context.Background(), &out, srv.URL, token, o.ID, tc.container, tc.follow); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != tc.want {
+			t.Errorf("container %q, follow %v: printed\n%s\nwant\n%s", tc.container, tc.follow, out.String(), tc.want)
+		}
+	}
+
+	if err := run(context.Background(), &strings.Builder{}, srv.URL, token, "urn:ivcap:order:unknown", "", false); err == nil {
+		t.Error("no error for an unknown order")
+	}
+	if err := run(context.Background(), &strings.Builder{}, "", token, o.ID, "", false); err == nil {
+		t.Error("no error without URL")
+	}
+}
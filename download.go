@@ -0,0 +1,420 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	artifact "github.com/reinventingscience/ivcap-core-api/gen/artifact"
+	"github.com/reinventingscience/ivcap-core-api/ivcaperr"
+	goahttp "goa.design/goa/v3/http"
+)
+
+// DownloadOptions controls how artifact content is retrieved.
+type DownloadOptions struct {
+	// Offset is the position of the first byte to return.
+	Offset int64
+	// Length is the number of bytes to return. Zero returns everything from
+	// Offset to the end of the content.
+	Length int64
+	// Raw disables decoding the content according to its Content-Encoding.
+	// Partial content is never decoded.
+	Raw bool
+}
+
+// IntegrityError is returned when downloaded content does not match the size
+// or ETag recorded for the artifact.
+type IntegrityError struct {
+	// Link is the URL the content was downloaded from
+	Link string
+	// Field is either "size" or "etag"
+	Field string
+	// Expected is the recorded value
+	Expected string
+	// Actual is the value of the downloaded content
+	Actual string
+}
+
+// Error returns an error description.
+func (e *IntegrityError) Error() string {
+	return fmt.Sprintf("content of %s has %s %s, expected %s", e.Link, e.Field, e.Actual, e.Expected)
+}
+
+// Open returns a reader for the content of artifact id. The content is
+// decoded according to its Content-Encoding and verified against the size
+// and ETag of the artifact when the end of the content is reached. The caller
+// must close the reader.
+func (c *ArtifactsClient) Open(ctx context.Context, id string) (io.ReadCloser, error) {
+	return c.OpenRange(ctx, id, nil)
+}
+
+// OpenRange is like Open but only returns the part of the content selected
+// by opts, which may be nil.
+func (c *ArtifactsClient) OpenRange(ctx context.Context, id string, opts *DownloadOptions) (io.ReadCloser, error) {
+	st, err := c.Read(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	link, err := dataLink(st)
+	if err != nil {
+		return nil, err
+	}
+	return c.OpenLink(ctx, link, sizeOf(st.Size), stringOf(st.Etag), opts)
+}
+
+// OpenLink returns a reader for the content behind a data link, such as the
+// data link of an order product. size and etag are the expected size and ETag
+// of the complete content, -1 and "" if unknown.
+func (c *ArtifactsClient) OpenLink(ctx context.Context, link string, size int64, etag string, opts *DownloadOptions) (io.ReadCloser, error) {
+	if opts == nil {
+		opts = &DownloadOptions{}
+	}
+	link = c.resolve(link)
+	resp, err := c.getData(ctx, link, opts.Offset, opts.Length, "")
+	if err != nil {
+		return nil, err
+	}
+	partial := resp.StatusCode == http.StatusPartialContent
+	body := resp.Body
+	if !partial && opts.Offset > 0 {
+		// The server ignored the range, skip to the requested offset
+		if _, err := io.CopyN(io.Discard, resp.Body, opts.Offset); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+	}
+	if !partial && opts.Length > 0 {
+		// and stop after the requested length
+		body = &limitedBody{io.LimitReader(resp.Body, opts.Length), resp.Body}
+	}
+	v := &verifyingReader{body: body, link: link, expected: -1}
+	switch {
+	case opts.Length > 0:
+		v.expected = opts.Length
+		if size >= 0 && opts.Offset+opts.Length > size {
+			v.expected = size - opts.Offset
+		}
+	case size >= 0:
+		v.expected = size - opts.Offset
+	}
+	if opts.Offset == 0 && opts.Length == 0 {
+		// Only the complete content can be checked against the ETag
+		v.etag = etag
+		if isMD5(etag) {
+			v.hash = md5.New()
+		}
+	}
+	var r io.ReadCloser = v
+	if !opts.Raw && !partial && opts.Offset == 0 && opts.Length == 0 {
+		if r, err = decodeContent(v, resp.Header.Get("Content-Encoding")); err != nil {
+			v.Close()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Download writes the content of artifact id to w, see Open.
+func (c *ArtifactsClient) Download(ctx context.Context, id string, w io.Writer) error {
+	r, err := c.Open(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	_, err = io.Copy(w, r)
+	return err
+}
+
+// partSuffix is appended to the path of a file while it is downloaded.
+const partSuffix = ".part"
+
+// DownloadFile saves the content of artifact id to path. The content is first
+// written to path with a ".part" suffix. If such a file exists from an
+// earlier, interrupted download of the same content, the download resumes
+// where it stopped. Content is stored as is, without decoding any
+// Content-Encoding.
+func (c *ArtifactsClient) DownloadFile(ctx context.Context, id string, path string) error {
+	st, err := c.Read(ctx, id)
+	if err != nil {
+		return err
+	}
+	link, err := dataLink(st)
+	if err != nil {
+		return err
+	}
+	return c.downloadLinkToFile(ctx, c.resolve(link), sizeOf(st.Size), stringOf(st.Etag), path)
+}
+
+// downloadLinkToFile implements DownloadFile for an arbitrary data link.
+func (c *ArtifactsClient) downloadLinkToFile(ctx context.Context, link string, size int64, etag string, path string) error {
+	part := path + partSuffix
+	f, err := os.OpenFile(part, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var h hash.Hash
+	if isMD5(etag) {
+		h = md5.New()
+	}
+	var offset int64
+	if etag != "" {
+		// Without an ETag we cannot tell whether the partial file belongs to
+		// the current content, so we start from scratch.
+		if h != nil {
+			offset, err = io.Copy(h, f)
+		} else {
+			offset, err = f.Seek(0, io.SeekEnd)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if size >= 0 && offset > size {
+		offset = 0
+	}
+	if size > 0 && offset == size {
+		// The partial file is complete, but a range starting at its end
+		// would be rejected as unsatisfiable.
+		if h == nil {
+			// Ask for the last byte again instead.
+			offset--
+		} else if (&verifyingReader{link: link, etag: etag, hash: h}).verify() == nil {
+			if err := f.Close(); err != nil {
+				return err
+			}
+			return os.Rename(part, path)
+		} else {
+			offset = 0
+			h.Reset()
+		}
+	}
+
+	resp, err := c.getData(ctx, link, offset, 0, etag)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		// Content changed or range not supported, restart
+		offset = 0
+		if h != nil {
+			h.Reset()
+		}
+	}
+	if err := f.Truncate(offset); err != nil {
+		return err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	v := &verifyingReader{body: resp.Body, link: link, expected: -1, etag: etag, hash: h}
+	if size >= 0 {
+		v.expected = size - offset
+	}
+	if _, err := io.Copy(f, v); err != nil {
+		var ierr *IntegrityError
+		if errors.As(err, &ierr) {
+			// Do not resume from corrupted content next time
+			f.Close()
+			os.Remove(part)
+		}
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(part, path)
+}
+
+// getData requests the content behind link. A range is requested if offset
+// or length are set. If ifRange is set, the range only applies if the content
+// still has that ETag.
+func (c *ArtifactsClient) getData(ctx context.Context, link string, offset, length int64, ifRange string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", link, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Size, ETag and ranges refer to the content as stored. Asking for it
+	// unaltered also stops the transport from transparently decompressing it.
+	req.Header.Set("Accept-Encoding", "identity")
+	if offset > 0 || length > 0 {
+		rng := fmt.Sprintf("bytes=%d-", offset)
+		if length > 0 {
+			rng += strconv.FormatInt(offset+length-1, 10)
+		}
+		req.Header.Set("Range", rng)
+		if ifRange != "" {
+			req.Header.Set("If-Range", quoteETag(ifRange))
+		}
+	}
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return nil, ivcaperr.Wrap("artifact", "download", link, goahttp.ErrRequestError("artifact", "download", err))
+	}
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusPartialContent:
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return nil, ivcaperr.Wrap("artifact", "download", link, &artifact.ResourceNotFoundT{ID: link, Message: "artifact content not found"})
+	case http.StatusUnauthorized:
+		return nil, ivcaperr.Wrap("artifact", "download", link, &artifact.UnauthorizedT{})
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return nil, ivcaperr.FromStatus("artifact", "download", link, resp.StatusCode, string(body))
+}
+
+// verifyingReader checks the size and, if possible, the MD5 based ETag of
+// the content read through it once EOF is reached.
+type verifyingReader struct {
+	body     io.ReadCloser
+	link     string
+	expected int64
+	etag     string
+	hash     hash.Hash
+	n        int64
+}
+
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	n, err := v.body.Read(p)
+	v.n += int64(n)
+	if v.hash != nil {
+		v.hash.Write(p[:n])
+	}
+	if err == io.EOF {
+		if verr := v.verify(); verr != nil {
+			return n, verr
+		}
+	}
+	return n, err
+}
+
+func (v *verifyingReader) verify() error {
+	if v.expected >= 0 && v.n != v.expected {
+		return &IntegrityError{Link: v.link, Field: "size", Expected: strconv.FormatInt(v.expected, 10), Actual: strconv.FormatInt(v.n, 10)}
+	}
+	if v.hash != nil {
+		sum := hex.EncodeToString(v.hash.Sum(nil))
+		if exp := unquoteETag(v.etag); !strings.EqualFold(sum, exp) {
+			return &IntegrityError{Link: v.link, Field: "etag", Expected: exp, Actual: sum}
+		}
+	}
+	return nil
+}
+
+func (v *verifyingReader) Close() error {
+	return v.body.Close()
+}
+
+// limitedBody reads at most a given number of bytes of a response body.
+type limitedBody struct {
+	io.Reader
+	body io.Closer
+}
+
+func (l *limitedBody) Close() error {
+	return l.body.Close()
+}
+
+// decodedReader closes both the decoder and the underlying body.
+type decodedReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (d *decodedReader) Close() error {
+	var err error
+	for _, c := range d.closers {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// decodeContent wraps r to undo the given Content-Encoding.
+func decodeContent(r io.ReadCloser, encoding string) (io.ReadCloser, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", "identity":
+		return r, nil
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return &decodedReader{zr, []io.Closer{zr, r}}, nil
+	case "deflate":
+		fr := flate.NewReader(r)
+		return &decodedReader{fr, []io.Closer{fr, r}}, nil
+	}
+	return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+}
+
+var md5ETag = regexp.MustCompile(`^[0-9a-fA-F]{32}$`)
+
+// isMD5 returns true if etag is the MD5 sum of the content, as is the case
+// for objects uploaded in a single part to S3 compatible stores.
+func isMD5(etag string) bool {
+	return md5ETag.MatchString(unquoteETag(etag))
+}
+
+func unquoteETag(etag string) string {
+	return strings.Trim(strings.TrimPrefix(etag, "W/"), `"`)
+}
+
+func quoteETag(etag string) string {
+	if strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return `"` + etag + `"`
+}
+
+func dataLink(st *artifact.ArtifactStatusRT) (string, error) {
+	if st.Data == nil || st.Data.Self == nil || *st.Data.Self == "" {
+		return "", fmt.Errorf("artifact %s has no data link", st.ID)
+	}
+	return *st.Data.Self, nil
+}
+
+func sizeOf(s *int64) int64 {
+	if s == nil {
+		return -1
+	}
+	return *s
+}
+
+func stringOf(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
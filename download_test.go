@@ -0,0 +1,290 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/reinventingscience/ivcap-core-api/ivcaperr"
+)
+
+// contentServer serves data at /data with http.ServeContent, which honours
+// Range and If-Range, and records the range headers of each request.
+type contentServer struct {
+	data []byte
+	etag string
+	// noRanges makes the server ignore Range headers.
+	noRanges bool
+	// status, if set, is returned instead of the content.
+	status int
+
+	mu       sync.Mutex
+	ranges   []string
+	ifRanges []string
+}
+
+func (s *contentServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.ranges = append(s.ranges, r.Header.Get("Range"))
+	s.ifRanges = append(s.ifRanges, r.Header.Get("If-Range"))
+	s.mu.Unlock()
+	if s.status != 0 {
+		http.Error(w, http.StatusText(s.status), s.status)
+		return
+	}
+	if s.etag != "" {
+		w.Header().Set("ETag", quoteETag(s.etag))
+	}
+	if s.noRanges {
+		r.Header.Del("Range")
+	}
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(s.data))
+}
+
+func newContentServer(t *testing.T, s *contentServer) (*ArtifactsClient, string) {
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	c, err := NewClient(srv.URL, StaticToken("token"), srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c.Artifacts(), srv.URL + "/data"
+}
+
+func md5Hex(b []byte) string {
+	sum := md5.Sum(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestDownloadLinkToFile(t *testing.T) {
+	data := content(3000)
+	other := content(3100)[100:]
+	tests := []struct {
+		name string
+		// part is the content of the .part file left by an earlier download,
+		// nil if there is none.
+		part       []byte
+		etag       string
+		serverETag string
+		noRanges   bool
+		// ranges and ifRanges are the headers of the requests sent, nil if
+		// none is expected.
+		ranges   []string
+		ifRanges []string
+	}{
+		{
+			name:       "fresh",
+			etag:       md5Hex(data),
+			serverETag: md5Hex(data),
+			ranges:     []string{""},
+			ifRanges:   []string{""},
+		},
+		{
+			name:       "resume md5",
+			part:       data[:1000],
+			etag:       md5Hex(data),
+			serverETag: md5Hex(data),
+			ranges:     []string{"bytes=1000-"},
+			ifRanges:   []string{`"` + md5Hex(data) + `"`},
+		},
+		{
+			name:       "resume multipart etag",
+			part:       data[:1000],
+			etag:       "abc-2",
+			serverETag: "abc-2",
+			ranges:     []string{"bytes=1000-"},
+			ifRanges:   []string{`"abc-2"`},
+		},
+		{
+			// The content changed since the partial file was written, so the
+			// server ignores the range and the download restarts.
+			name:       "if-range mismatch",
+			part:       other[:1000],
+			etag:       "abc-2",
+			serverETag: "def-2",
+			ranges:     []string{"bytes=1000-"},
+			ifRanges:   []string{`"abc-2"`},
+		},
+		{
+			name:       "ranges not supported",
+			part:       data[:1000],
+			etag:       "abc-2",
+			serverETag: "abc-2",
+			noRanges:   true,
+			ranges:     []string{"bytes=1000-"},
+			ifRanges:   []string{`"abc-2"`},
+		},
+		{
+			name:       "no etag",
+			part:       other[:1000],
+			serverETag: "abc-2",
+			ranges:     []string{""},
+			ifRanges:   []string{""},
+		},
+		{
+			name:       "part complete",
+			part:       data,
+			etag:       md5Hex(data),
+			serverETag: md5Hex(data),
+		},
+		{
+			// The part may be complete, but without an MD5 ETag only the
+			// server can tell.
+			name:       "part complete multipart etag",
+			part:       data,
+			etag:       "abc-2",
+			serverETag: "abc-2",
+			ranges:     []string{"bytes=2999-"},
+			ifRanges:   []string{`"abc-2"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &contentServer{data: data, etag: tt.serverETag, noRanges: tt.noRanges}
+			c, link := newContentServer(t, s)
+			path := filepath.Join(t.TempDir(), "data.bin")
+			if tt.part != nil {
+				if err := os.WriteFile(path+partSuffix, tt.part, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := c.downloadLinkToFile(context.Background(), link, int64(len(data)), tt.etag, path); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("downloaded %d bytes which differ from the content", len(got))
+			}
+			if _, err := os.Stat(path + partSuffix); !os.IsNotExist(err) {
+				t.Errorf("partial file still exists: %v", err)
+			}
+			if !equalStrings(s.ranges, tt.ranges) {
+				t.Errorf("Range = %q, want %q", s.ranges, tt.ranges)
+			}
+			if !equalStrings(s.ifRanges, tt.ifRanges) {
+				t.Errorf("If-Range = %q, want %q", s.ifRanges, tt.ifRanges)
+			}
+		})
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDownloadLinkToFileETagMismatch(t *testing.T) {
+	data := content(3000)
+	tests := []struct {
+		name string
+		part []byte
+		etag string
+	}{
+		// The partial file does not hold the start of the content.
+		{"corrupt part", content(1100)[100:], md5Hex(data)},
+		// The content does not match the ETag recorded for the artifact.
+		{"wrong etag", nil, md5Hex(data[1:])},
+		{"wrong etag after resume", data[:1000], md5Hex(data[1:])},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, link := newContentServer(t, &contentServer{data: data, etag: tt.etag})
+			path := filepath.Join(t.TempDir(), "data.bin")
+			if tt.part != nil {
+				if err := os.WriteFile(path+partSuffix, tt.part, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := c.downloadLinkToFile(context.Background(), link, int64(len(data)), tt.etag, path)
+			var ierr *IntegrityError
+			if !errors.As(err, &ierr) {
+				t.Fatalf("err = %v, want *IntegrityError", err)
+			}
+			if ierr.Field != "etag" || ierr.Expected != tt.etag {
+				t.Errorf("err = %+v", ierr)
+			}
+			// The next attempt must not resume from the corrupted content.
+			for _, p := range []string{path, path + partSuffix} {
+				if _, err := os.Stat(p); !os.IsNotExist(err) {
+					t.Errorf("%s exists: %v", filepath.Base(p), err)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenLinkETagMismatch(t *testing.T) {
+	data := content(3000)
+	c, link := newContentServer(t, &contentServer{data: data})
+	r, err := c.OpenLink(context.Background(), link, int64(len(data)), md5Hex(data[1:]), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	var ierr *IntegrityError
+	if _, err := io.ReadAll(r); !errors.As(err, &ierr) || ierr.Field != "etag" {
+		t.Errorf("err = %v, want an etag *IntegrityError", err)
+	}
+}
+
+func TestDownloadErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		kind   *ivcaperr.Kind
+	}{
+		{http.StatusNotFound, ivcaperr.ErrNotFound},
+		{http.StatusUnauthorized, ivcaperr.ErrUnauthorized},
+		{http.StatusServiceUnavailable, ivcaperr.ErrUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			c, link := newContentServer(t, &contentServer{status: tt.status})
+			_, err := c.OpenLink(context.Background(), link, -1, "", nil)
+			if !errors.Is(err, tt.kind) {
+				t.Errorf("OpenLink: err = %v, want %v", err, tt.kind)
+			}
+			var e *ivcaperr.Error
+			if !errors.As(err, &e) || e.Service != "artifact" || e.Method != "download" || e.ResourceID != link {
+				t.Errorf("OpenLink: err = %#v, want an artifact download error for %s", err, link)
+			}
+			err = c.downloadLinkToFile(context.Background(), link, -1, "", filepath.Join(t.TempDir(), "data.bin"))
+			if !errors.Is(err, tt.kind) {
+				t.Errorf("downloadLinkToFile: err = %v, want %v", err, tt.kind)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/reinventingscience/ivcap-core-api/ivcaperr"
+
+	goa "goa.design/goa/v3/pkg"
+)
+
+// wrapErrors returns an endpoint calling e which returns its errors as
+// *ivcaperr.Error, adding the service and method names and the ID of the
+// resource in the payload. Requests aborted because ctx is done fail with
+// the error of ctx, which the generated clients only keep as text.
+func wrapErrors(service, method string, e goa.Endpoint) goa.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		res, err := e(ctx, req)
+		if err != nil {
+			if cerr := ctx.Err(); cerr != nil {
+				return res, cerr
+			}
+			return res, ivcaperr.Wrap(service, method, payloadID(req), err)
+		}
+		return res, nil
+	}
+}
+
+// payloadID returns the value of the ID field of payload p, if any.
+func payloadID(p interface{}) string {
+	v := reflect.ValueOf(p)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	f := v.FieldByName("ID")
+	if f.Kind() == reflect.Pointer && !f.IsNil() {
+		f = f.Elem()
+	}
+	if f.Kind() == reflect.String {
+		return f.String()
+	}
+	return ""
+}
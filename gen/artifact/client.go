@@ -0,0 +1,93 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// $ goa gen github.com/reinventingscience/ivcap-core-api/design
+
+package artifact
+
+import (
+	"context"
+	"io"
+
+	goa "goa.design/goa/v3/pkg"
+)
+
+// Client is the "artifact" service client.
+type Client struct {
+	ListEndpoint   goa.Endpoint
+	ReadEndpoint   goa.Endpoint
+	UploadEndpoint goa.Endpoint
+}
+
+// NewClient initializes a "artifact" service client given the endpoints.
+func NewClient(list, read, upload goa.Endpoint) *Client {
+	return &Client{
+		ListEndpoint:   list,
+		ReadEndpoint:   read,
+		UploadEndpoint: upload,
+	}
+}
+
+// List calls the "list" endpoint of the "artifact" service.
+// List may return the following errors:
+//   - "bad-request" (type *BadRequestT)
+//   - "invalid-credential" (type *InvalidCredentialsT)
+//   - "invalid-parameter" (type *InvalidParameterValue)
+//   - "invalid-scopes" (type *InvalidScopesT)
+//   - "not-implemented" (type *NotImplementedT)
+//   - "not-authorized" (type *UnauthorizedT)
+//   - error: internal error
+func (c *Client) List(ctx context.Context, p *ListPayload) (res *ArtifactListRT, err error) {
+	var ires interface{}
+	ires, err = c.ListEndpoint(ctx, p)
+	if err != nil {
+		return
+	}
+	return ires.(*ArtifactListRT), nil
+}
+
+// Read calls the "read" endpoint of the "artifact" service.
+// Read may return the following errors:
+//   - "bad-request" (type *BadRequestT)
+//   - "invalid-credential" (type *InvalidCredentialsT)
+//   - "invalid-scopes" (type *InvalidScopesT)
+//   - "not-implemented" (type *NotImplementedT)
+//   - "not-found" (type *ResourceNotFoundT)
+//   - "not-authorized" (type *UnauthorizedT)
+//   - error: internal error
+func (c *Client) Read(ctx context.Context, p *ReadPayload) (res *ArtifactStatusRT, err error) {
+	var ires interface{}
+	ires, err = c.ReadEndpoint(ctx, p)
+	if err != nil {
+		return
+	}
+	return ires.(*ArtifactStatusRT), nil
+}
+
+// Upload calls the "upload" endpoint of the "artifact" service.
+// Upload may return the following errors:
+//   - "bad-request" (type *BadRequestT)
+//   - "invalid-credential" (type *InvalidCredentialsT)
+//   - "invalid-scopes" (type *InvalidScopesT)
+//   - "not-implemented" (type *NotImplementedT)
+//   - "not-authorized" (type *UnauthorizedT)
+//   - error: internal error
+func (c *Client) Upload(ctx context.Context, p *UploadPayload, req io.ReadCloser) (res *ArtifactStatusRT, err error) {
+	var ires interface{}
+	ires, err = c.UploadEndpoint(ctx, &UploadRequestData{Payload: p, Body: req})
+	if err != nil {
+		return
+	}
+	return ires.(*ArtifactStatusRT), nil
+}
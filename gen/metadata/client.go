@@ -0,0 +1,149 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// $ goa gen github.com/reinventingscience/ivcap-core-api/design
+
+package metadata
+
+import (
+	"context"
+
+	goa "goa.design/goa/v3/pkg"
+)
+
+// Client is the "metadata" service client.
+type Client struct {
+	ReadEndpoint         goa.Endpoint
+	ListEndpoint         goa.Endpoint
+	AddEndpoint          goa.Endpoint
+	UpdateOneEndpoint    goa.Endpoint
+	UpdateRecordEndpoint goa.Endpoint
+	RevokeEndpoint       goa.Endpoint
+}
+
+// NewClient initializes a "metadata" service client given the endpoints.
+func NewClient(read, list, add, updateOne, updateRecord, revoke goa.Endpoint) *Client {
+	return &Client{
+		ReadEndpoint:         read,
+		ListEndpoint:         list,
+		AddEndpoint:          add,
+		UpdateOneEndpoint:    updateOne,
+		UpdateRecordEndpoint: updateRecord,
+		RevokeEndpoint:       revoke,
+	}
+}
+
+// Read calls the "read" endpoint of the "metadata" service.
+// Read may return the following errors:
+//   - "bad-request" (type *BadRequestT)
+//   - "invalid-credential" (type *InvalidCredentialsT)
+//   - "invalid-scopes" (type *InvalidScopesT)
+//   - "not-implemented" (type *NotImplementedT)
+//   - "not-found" (type *ResourceNotFoundT)
+//   - "not-authorized" (type *UnauthorizedT)
+//   - error: internal error
+func (c *Client) Read(ctx context.Context, p *ReadPayload) (res *MetadataRecordRT, err error) {
+	var ires interface{}
+	ires, err = c.ReadEndpoint(ctx, p)
+	if err != nil {
+		return
+	}
+	return ires.(*MetadataRecordRT), nil
+}
+
+// List calls the "list" endpoint of the "metadata" service.
+// List may return the following errors:
+//   - "bad-request" (type *BadRequestT)
+//   - "invalid-credential" (type *InvalidCredentialsT)
+//   - "invalid-parameter" (type *InvalidParameterValue)
+//   - "invalid-scopes" (type *InvalidScopesT)
+//   - "not-implemented" (type *NotImplementedT)
+//   - "not-authorized" (type *UnauthorizedT)
+//   - error: internal error
+func (c *Client) List(ctx context.Context, p *ListPayload) (res *ListMetaRT, err error) {
+	var ires interface{}
+	ires, err = c.ListEndpoint(ctx, p)
+	if err != nil {
+		return
+	}
+	return ires.(*ListMetaRT), nil
+}
+
+// Add calls the "add" endpoint of the "metadata" service.
+// Add may return the following errors:
+//   - "bad-request" (type *BadRequestT)
+//   - "invalid-credential" (type *InvalidCredentialsT)
+//   - "invalid-parameter" (type *InvalidParameterValue)
+//   - "invalid-scopes" (type *InvalidScopesT)
+//   - "not-implemented" (type *NotImplementedT)
+//   - "not-authorized" (type *UnauthorizedT)
+//   - error: internal error
+func (c *Client) Add(ctx context.Context, p *AddPayload) (res *AddMetaRT, err error) {
+	var ires interface{}
+	ires, err = c.AddEndpoint(ctx, p)
+	if err != nil {
+		return
+	}
+	return ires.(*AddMetaRT), nil
+}
+
+// UpdateOne calls the "update_one" endpoint of the "metadata" service.
+// UpdateOne may return the following errors:
+//   - "bad-request" (type *BadRequestT)
+//   - "invalid-credential" (type *InvalidCredentialsT)
+//   - "invalid-parameter" (type *InvalidParameterValue)
+//   - "invalid-scopes" (type *InvalidScopesT)
+//   - "not-implemented" (type *NotImplementedT)
+//   - "not-authorized" (type *UnauthorizedT)
+//   - error: internal error
+func (c *Client) UpdateOne(ctx context.Context, p *UpdateOnePayload) (res *AddMetaRT, err error) {
+	var ires interface{}
+	ires, err = c.UpdateOneEndpoint(ctx, p)
+	if err != nil {
+		return
+	}
+	return ires.(*AddMetaRT), nil
+}
+
+// UpdateRecord calls the "update_record" endpoint of the "metadata" service.
+// UpdateRecord may return the following errors:
+//   - "bad-request" (type *BadRequestT)
+//   - "invalid-credential" (type *InvalidCredentialsT)
+//   - "invalid-parameter" (type *InvalidParameterValue)
+//   - "invalid-scopes" (type *InvalidScopesT)
+//   - "not-implemented" (type *NotImplementedT)
+//   - "not-authorized" (type *UnauthorizedT)
+//   - error: internal error
+func (c *Client) UpdateRecord(ctx context.Context, p *UpdateRecordPayload) (res *AddMetaRT, err error) {
+	var ires interface{}
+	ires, err = c.UpdateRecordEndpoint(ctx, p)
+	if err != nil {
+		return
+	}
+	return ires.(*AddMetaRT), nil
+}
+
+// Revoke calls the "revoke" endpoint of the "metadata" service.
+// Revoke may return the following errors:
+//   - "bad-request" (type *BadRequestT)
+//   - "invalid-credential" (type *InvalidCredentialsT)
+//   - "invalid-parameter" (type *InvalidParameterValue)
+//   - "invalid-scopes" (type *InvalidScopesT)
+//   - "not-implemented" (type *NotImplementedT)
+//   - "not-authorized" (type *UnauthorizedT)
+//   - error: internal error
+func (c *Client) Revoke(ctx context.Context, p *RevokePayload) (err error) {
+	_, err = c.RevokeEndpoint(ctx, p)
+	return
+}
@@ -0,0 +1,139 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// $ goa gen github.com/reinventingscience/ivcap-core-api/design
+
+package order
+
+import (
+	"context"
+	"io"
+
+	goa "goa.design/goa/v3/pkg"
+)
+
+// Client is the "order" service client.
+type Client struct {
+	ReadEndpoint   goa.Endpoint
+	ListEndpoint   goa.Endpoint
+	CreateEndpoint goa.Endpoint
+	LogsEndpoint   goa.Endpoint
+	TopEndpoint    goa.Endpoint
+}
+
+// NewClient initializes a "order" service client given the endpoints.
+func NewClient(read, list, create, logs, top goa.Endpoint) *Client {
+	return &Client{
+		ReadEndpoint:   read,
+		ListEndpoint:   list,
+		CreateEndpoint: create,
+		LogsEndpoint:   logs,
+		TopEndpoint:    top,
+	}
+}
+
+// Read calls the "read" endpoint of the "order" service.
+// Read may return the following errors:
+//   - "bad-request" (type *BadRequestT)
+//   - "invalid-credential" (type *InvalidCredentialsT)
+//   - "invalid-scopes" (type *InvalidScopesT)
+//   - "not-implemented" (type *NotImplementedT)
+//   - "not-found" (type *ResourceNotFoundT)
+//   - "not-authorized" (type *UnauthorizedT)
+//   - error: internal error
+func (c *Client) Read(ctx context.Context, p *ReadPayload) (res *OrderStatusRT, err error) {
+	var ires interface{}
+	ires, err = c.ReadEndpoint(ctx, p)
+	if err != nil {
+		return
+	}
+	return ires.(*OrderStatusRT), nil
+}
+
+// List calls the "list" endpoint of the "order" service.
+// List may return the following errors:
+//   - "bad-request" (type *BadRequestT)
+//   - "invalid-credential" (type *InvalidCredentialsT)
+//   - "invalid-parameter" (type *InvalidParameterValue)
+//   - "invalid-scopes" (type *InvalidScopesT)
+//   - "not-implemented" (type *NotImplementedT)
+//   - "not-authorized" (type *UnauthorizedT)
+//   - error: internal error
+func (c *Client) List(ctx context.Context, p *ListPayload) (res *OrderListRT, err error) {
+	var ires interface{}
+	ires, err = c.ListEndpoint(ctx, p)
+	if err != nil {
+		return
+	}
+	return ires.(*OrderListRT), nil
+}
+
+// Create calls the "create" endpoint of the "order" service.
+// Create may return the following errors:
+//   - "bad-request" (type *BadRequestT)
+//   - "invalid-credential" (type *InvalidCredentialsT)
+//   - "invalid-parameter" (type *InvalidParameterValue)
+//   - "invalid-scopes" (type *InvalidScopesT)
+//   - "not-implemented" (type *NotImplementedT)
+//   - "not-found" (type *ResourceNotFoundT)
+//   - "not-available" (type *ServiceNotAvailableT)
+//   - "not-authorized" (type *UnauthorizedT)
+//   - error: internal error
+func (c *Client) Create(ctx context.Context, p *CreatePayload) (res *OrderStatusRT, err error) {
+	var ires interface{}
+	ires, err = c.CreateEndpoint(ctx, p)
+	if err != nil {
+		return
+	}
+	return ires.(*OrderStatusRT), nil
+}
+
+// Logs calls the "logs" endpoint of the "order" service.
+// Logs may return the following errors:
+//   - "bad-request" (type *BadRequestT)
+//   - "invalid-credential" (type *InvalidCredentialsT)
+//   - "invalid-parameter" (type *InvalidParameterValue)
+//   - "invalid-scopes" (type *InvalidScopesT)
+//   - "not-implemented" (type *NotImplementedT)
+//   - "not-found" (type *ResourceNotFoundT)
+//   - "not-authorized" (type *UnauthorizedT)
+//   - error: internal error
+func (c *Client) Logs(ctx context.Context, p *LogsPayload) (resp io.ReadCloser, err error) {
+	var ires interface{}
+	ires, err = c.LogsEndpoint(ctx, p)
+	if err != nil {
+		return
+	}
+	o := ires.(*LogsResponseData)
+	return o.Body, nil
+}
+
+// Top calls the "top" endpoint of the "order" service.
+// Top may return the following errors:
+//   - "bad-request" (type *BadRequestT)
+//   - "invalid-credential" (type *InvalidCredentialsT)
+//   - "invalid-parameter" (type *InvalidParameterValue)
+//   - "invalid-scopes" (type *InvalidScopesT)
+//   - "not-implemented" (type *NotImplementedT)
+//   - "not-found" (type *ResourceNotFoundT)
+//   - "not-authorized" (type *UnauthorizedT)
+//   - error: internal error
+func (c *Client) Top(ctx context.Context, p *TopPayload) (res OrderTopResultItemCollection, err error) {
+	var ires interface{}
+	ires, err = c.TopEndpoint(ctx, p)
+	if err != nil {
+		return
+	}
+	return ires.(OrderTopResultItemCollection), nil
+}
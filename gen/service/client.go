@@ -0,0 +1,131 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// $ goa gen github.com/reinventingscience/ivcap-core-api/design
+
+package service
+
+import (
+	"context"
+
+	goa "goa.design/goa/v3/pkg"
+)
+
+// Client is the "service" service client.
+type Client struct {
+	ListEndpoint          goa.Endpoint
+	CreateServiceEndpoint goa.Endpoint
+	ReadEndpoint          goa.Endpoint
+	UpdateEndpoint        goa.Endpoint
+	DeleteEndpoint        goa.Endpoint
+}
+
+// NewClient initializes a "service" service client given the endpoints.
+func NewClient(list, createService, read, update, delete goa.Endpoint) *Client {
+	return &Client{
+		ListEndpoint:          list,
+		CreateServiceEndpoint: createService,
+		ReadEndpoint:          read,
+		UpdateEndpoint:        update,
+		DeleteEndpoint:        delete,
+	}
+}
+
+// List calls the "list" endpoint of the "service" service.
+// List may return the following errors:
+//   - "bad-request" (type *BadRequestT)
+//   - "invalid-credential" (type *InvalidCredentialsT)
+//   - "invalid-parameter" (type *InvalidParameterValue)
+//   - "invalid-scopes" (type *InvalidScopesT)
+//   - "not-implemented" (type *NotImplementedT)
+//   - "not-authorized" (type *UnauthorizedT)
+//   - error: internal error
+func (c *Client) List(ctx context.Context, p *ListPayload) (res *ServiceListRT, err error) {
+	var ires interface{}
+	ires, err = c.ListEndpoint(ctx, p)
+	if err != nil {
+		return
+	}
+	return ires.(*ServiceListRT), nil
+}
+
+// CreateService calls the "create_service" endpoint of the "service" service.
+// CreateService may return the following errors:
+//   - "bad-request" (type *BadRequestT)
+//   - "invalid-credential" (type *InvalidCredentialsT)
+//   - "invalid-parameter" (type *InvalidParameterValue)
+//   - "invalid-scopes" (type *InvalidScopesT)
+//   - "not-implemented" (type *NotImplementedT)
+//   - "already-created" (type *ResourceAlreadyCreatedT)
+//   - "not-found" (type *ResourceNotFoundT)
+//   - "not-authorized" (type *UnauthorizedT)
+//   - error: internal error
+func (c *Client) CreateService(ctx context.Context, p *CreateServicePayload) (res *ServiceStatusRT, err error) {
+	var ires interface{}
+	ires, err = c.CreateServiceEndpoint(ctx, p)
+	if err != nil {
+		return
+	}
+	return ires.(*ServiceStatusRT), nil
+}
+
+// Read calls the "read" endpoint of the "service" service.
+// Read may return the following errors:
+//   - "bad-request" (type *BadRequestT)
+//   - "invalid-credential" (type *InvalidCredentialsT)
+//   - "invalid-scopes" (type *InvalidScopesT)
+//   - "not-implemented" (type *NotImplementedT)
+//   - "not-found" (type *ResourceNotFoundT)
+//   - "not-authorized" (type *UnauthorizedT)
+//   - error: internal error
+func (c *Client) Read(ctx context.Context, p *ReadPayload) (res *ServiceStatusRT, err error) {
+	var ires interface{}
+	ires, err = c.ReadEndpoint(ctx, p)
+	if err != nil {
+		return
+	}
+	return ires.(*ServiceStatusRT), nil
+}
+
+// Update calls the "update" endpoint of the "service" service.
+// Update may return the following errors:
+//   - "bad-request" (type *BadRequestT)
+//   - "invalid-credential" (type *InvalidCredentialsT)
+//   - "invalid-parameter" (type *InvalidParameterValue)
+//   - "invalid-scopes" (type *InvalidScopesT)
+//   - "not-implemented" (type *NotImplementedT)
+//   - "not-found" (type *ResourceNotFoundT)
+//   - "not-authorized" (type *UnauthorizedT)
+//   - error: internal error
+func (c *Client) Update(ctx context.Context, p *UpdatePayload) (res *ServiceStatusRT, err error) {
+	var ires interface{}
+	ires, err = c.UpdateEndpoint(ctx, p)
+	if err != nil {
+		return
+	}
+	return ires.(*ServiceStatusRT), nil
+}
+
+// Delete calls the "delete" endpoint of the "service" service.
+// Delete may return the following errors:
+//   - "bad-request" (type *BadRequestT)
+//   - "invalid-credential" (type *InvalidCredentialsT)
+//   - "invalid-scopes" (type *InvalidScopesT)
+//   - "not-implemented" (type *NotImplementedT)
+//   - "not-authorized" (type *UnauthorizedT)
+//   - error: internal error
+func (c *Client) Delete(ctx context.Context, p *DeletePayload) (err error) {
+	_, err = c.DeleteEndpoint(ctx, p)
+	return
+}
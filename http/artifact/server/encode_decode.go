@@ -0,0 +1,582 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	artifact "github.com/reinventingscience/ivcap-core-api/gen/artifact"
+	artifactviews "github.com/reinventingscience/ivcap-core-api/gen/artifact/views"
+	goahttp "goa.design/goa/v3/http"
+	goa "goa.design/goa/v3/pkg"
+)
+
+// EncodeListResponse returns an encoder for responses returned by the artifact
+// list endpoint.
+func EncodeListResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, interface{}) error {
+	return func(ctx context.Context, w http.ResponseWriter, v interface{}) error {
+		res := v.(*artifactviews.ArtifactListRT)
+		enc := encoder(ctx, w)
+		body := NewListResponseBody(res.Projected)
+		w.WriteHeader(http.StatusOK)
+		return enc.Encode(body)
+	}
+}
+
+// DecodeListRequest returns a decoder for requests sent to the artifact list
+// endpoint.
+func DecodeListRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		var (
+			limit     int
+			page      *string
+			filter    *string
+			orderBy   *string
+			orderDesc bool
+			atTime    *string
+			jwt       string
+			err       error
+		)
+		{
+			limitRaw := r.URL.Query().Get("limit")
+			if limitRaw == "" {
+				limit = 10
+			} else {
+				v, err2 := strconv.ParseInt(limitRaw, 10, strconv.IntSize)
+				if err2 != nil {
+					err = goa.MergeErrors(err, goa.InvalidFieldTypeError("limit", limitRaw, "integer"))
+				}
+				limit = int(v)
+			}
+		}
+		if limit < 1 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("limit", limit, 1, true))
+		}
+		if limit > 50 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("limit", limit, 50, false))
+		}
+		pageRaw := r.URL.Query().Get("page")
+		if pageRaw != "" {
+			page = &pageRaw
+		}
+		filterRaw := r.URL.Query().Get("filter")
+		if filterRaw != "" {
+			filter = &filterRaw
+		}
+		orderByRaw := r.URL.Query().Get("order-by")
+		if orderByRaw != "" {
+			orderBy = &orderByRaw
+		}
+		{
+			orderDescRaw := r.URL.Query().Get("order-desc")
+			if orderDescRaw != "" {
+				v, err2 := strconv.ParseBool(orderDescRaw)
+				if err2 != nil {
+					err = goa.MergeErrors(err, goa.InvalidFieldTypeError("orderDesc", orderDescRaw, "boolean"))
+				}
+				orderDesc = v
+			}
+		}
+		atTimeRaw := r.URL.Query().Get("at-time")
+		if atTimeRaw != "" {
+			atTime = &atTimeRaw
+		}
+		if atTime != nil {
+			err = goa.MergeErrors(err, goa.ValidateFormat("atTime", *atTime, goa.FormatDateTime))
+		}
+		jwt = r.Header.Get("Authorization")
+		if jwt == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("Authorization", "header"))
+		}
+		if err != nil {
+			return nil, err
+		}
+		payload := NewListPayload(limit, page, filter, orderBy, orderDesc, atTime, jwt)
+		if strings.Contains(payload.JWT, " ") {
+			// Remove authorization scheme prefix (e.g. "Bearer")
+			cred := strings.SplitN(payload.JWT, " ", 2)[1]
+			payload.JWT = cred
+		}
+
+		return payload, nil
+	}
+}
+
+// EncodeListError returns an encoder for errors returned by the list artifact
+// endpoint.
+func EncodeListError(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder, formatter func(ctx context.Context, err error) goahttp.Statuser) func(context.Context, http.ResponseWriter, error) error {
+	encodeError := goahttp.ErrorEncoder(encoder, formatter)
+	return func(ctx context.Context, w http.ResponseWriter, v error) error {
+		var en goa.GoaErrorNamer
+		if !errors.As(v, &en) {
+			return encodeError(ctx, w, v)
+		}
+		switch en.GoaErrorName() {
+		case "bad-request":
+			var res *artifact.BadRequestT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewListBadRequestResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return enc.Encode(body)
+		case "invalid-credential":
+			var res *artifact.InvalidCredentialsT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return nil
+		case "invalid-parameter":
+			var res *artifact.InvalidParameterValue
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewListInvalidParameterResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return enc.Encode(body)
+		case "invalid-scopes":
+			var res *artifact.InvalidScopesT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewListInvalidScopesResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusForbidden)
+			return enc.Encode(body)
+		case "not-implemented":
+			var res *artifact.NotImplementedT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewListNotImplementedResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotImplemented)
+			return enc.Encode(body)
+		case "not-authorized":
+			var res *artifact.UnauthorizedT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnauthorized)
+			return nil
+		default:
+			return encodeError(ctx, w, v)
+		}
+	}
+}
+
+// EncodeReadResponse returns an encoder for responses returned by the artifact
+// read endpoint.
+func EncodeReadResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, interface{}) error {
+	return func(ctx context.Context, w http.ResponseWriter, v interface{}) error {
+		res := v.(*artifactviews.ArtifactStatusRT)
+		enc := encoder(ctx, w)
+		body := NewReadResponseBody(res.Projected)
+		w.WriteHeader(http.StatusOK)
+		return enc.Encode(body)
+	}
+}
+
+// DecodeReadRequest returns a decoder for requests sent to the artifact read
+// endpoint.
+func DecodeReadRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		var (
+			id  string
+			jwt string
+			err error
+
+			params = mux.Vars(r)
+		)
+		id = params["id"]
+		jwt = r.Header.Get("Authorization")
+		if jwt == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("Authorization", "header"))
+		}
+		if err != nil {
+			return nil, err
+		}
+		payload := NewReadPayload(id, jwt)
+		if strings.Contains(payload.JWT, " ") {
+			// Remove authorization scheme prefix (e.g. "Bearer")
+			cred := strings.SplitN(payload.JWT, " ", 2)[1]
+			payload.JWT = cred
+		}
+
+		return payload, nil
+	}
+}
+
+// EncodeReadError returns an encoder for errors returned by the read artifact
+// endpoint.
+func EncodeReadError(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder, formatter func(ctx context.Context, err error) goahttp.Statuser) func(context.Context, http.ResponseWriter, error) error {
+	encodeError := goahttp.ErrorEncoder(encoder, formatter)
+	return func(ctx context.Context, w http.ResponseWriter, v error) error {
+		var en goa.GoaErrorNamer
+		if !errors.As(v, &en) {
+			return encodeError(ctx, w, v)
+		}
+		switch en.GoaErrorName() {
+		case "bad-request":
+			var res *artifact.BadRequestT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewReadBadRequestResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return enc.Encode(body)
+		case "invalid-credential":
+			var res *artifact.InvalidCredentialsT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return nil
+		case "invalid-scopes":
+			var res *artifact.InvalidScopesT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewReadInvalidScopesResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusForbidden)
+			return enc.Encode(body)
+		case "not-implemented":
+			var res *artifact.NotImplementedT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewReadNotImplementedResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotImplemented)
+			return enc.Encode(body)
+		case "not-found":
+			var res *artifact.ResourceNotFoundT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewReadNotFoundResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotFound)
+			return enc.Encode(body)
+		case "not-authorized":
+			var res *artifact.UnauthorizedT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnauthorized)
+			return nil
+		default:
+			return encodeError(ctx, w, v)
+		}
+	}
+}
+
+// EncodeUploadResponse returns an encoder for responses returned by the
+// artifact upload endpoint.
+func EncodeUploadResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, interface{}) error {
+	return func(ctx context.Context, w http.ResponseWriter, v interface{}) error {
+		res := v.(*artifactviews.ArtifactStatusRT)
+		enc := encoder(ctx, w)
+		body := NewUploadResponseBody(res.Projected)
+		if res.Projected.Location != nil {
+			w.Header().Set("Location", *res.Projected.Location)
+		}
+		if res.Projected.TusResumable != nil {
+			w.Header().Set("Tus-Resumable", *res.Projected.TusResumable)
+		}
+		if res.Projected.TusOffset != nil {
+			val := res.Projected.TusOffset
+			tusOffsets := strconv.FormatInt(*val, 10)
+			w.Header().Set("Upload-Offset", tusOffsets)
+		}
+		w.WriteHeader(http.StatusCreated)
+		return enc.Encode(body)
+	}
+}
+
+// DecodeUploadRequest returns a decoder for requests sent to the artifact
+// upload endpoint.
+func DecodeUploadRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		var (
+			contentType     *string
+			contentEncoding *string
+			contentLength   *int
+			name            *string
+			collection      *string
+			policy          *string
+			xContentType    *string
+			xContentLength  *int
+			uploadLength    *int
+			tusResumable    *string
+			jwt             string
+			err             error
+		)
+		contentTypeRaw := r.Header.Get("Content-Type")
+		if contentTypeRaw != "" {
+			contentType = &contentTypeRaw
+		}
+		contentEncodingRaw := r.Header.Get("Content-Encoding")
+		if contentEncodingRaw != "" {
+			contentEncoding = &contentEncodingRaw
+		}
+		{
+			contentLengthRaw := r.Header.Get("Content-Length")
+			if contentLengthRaw != "" {
+				v, err2 := strconv.ParseInt(contentLengthRaw, 10, strconv.IntSize)
+				if err2 != nil {
+					err = goa.MergeErrors(err, goa.InvalidFieldTypeError("contentLength", contentLengthRaw, "integer"))
+				}
+				pv := int(v)
+				contentLength = &pv
+			}
+		}
+		nameRaw := r.Header.Get("X-Name")
+		if nameRaw != "" {
+			name = &nameRaw
+		}
+		collectionRaw := r.Header.Get("X-Collection")
+		if collectionRaw != "" {
+			collection = &collectionRaw
+		}
+		policyRaw := r.Header.Get("X-Policy")
+		if policyRaw != "" {
+			policy = &policyRaw
+		}
+		xContentTypeRaw := r.Header.Get("X-Content-Type")
+		if xContentTypeRaw != "" {
+			xContentType = &xContentTypeRaw
+		}
+		{
+			xContentLengthRaw := r.Header.Get("X-Content-Length")
+			if xContentLengthRaw != "" {
+				v, err2 := strconv.ParseInt(xContentLengthRaw, 10, strconv.IntSize)
+				if err2 != nil {
+					err = goa.MergeErrors(err, goa.InvalidFieldTypeError("xContentLength", xContentLengthRaw, "integer"))
+				}
+				pv := int(v)
+				xContentLength = &pv
+			}
+		}
+		{
+			uploadLengthRaw := r.Header.Get("Upload-Length")
+			if uploadLengthRaw != "" {
+				v, err2 := strconv.ParseInt(uploadLengthRaw, 10, strconv.IntSize)
+				if err2 != nil {
+					err = goa.MergeErrors(err, goa.InvalidFieldTypeError("uploadLength", uploadLengthRaw, "integer"))
+				}
+				pv := int(v)
+				uploadLength = &pv
+			}
+		}
+		tusResumableRaw := r.Header.Get("Tus-Resumable")
+		if tusResumableRaw != "" {
+			tusResumable = &tusResumableRaw
+		}
+		jwt = r.Header.Get("Authorization")
+		if jwt == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("Authorization", "header"))
+		}
+		if err != nil {
+			return nil, err
+		}
+		payload := NewUploadPayload(contentType, contentEncoding, contentLength, name, collection, policy, xContentType, xContentLength, uploadLength, tusResumable, jwt)
+		if strings.Contains(payload.JWT, " ") {
+			// Remove authorization scheme prefix (e.g. "Bearer")
+			cred := strings.SplitN(payload.JWT, " ", 2)[1]
+			payload.JWT = cred
+		}
+
+		return payload, nil
+	}
+}
+
+// EncodeUploadError returns an encoder for errors returned by the upload
+// artifact endpoint.
+func EncodeUploadError(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder, formatter func(ctx context.Context, err error) goahttp.Statuser) func(context.Context, http.ResponseWriter, error) error {
+	encodeError := goahttp.ErrorEncoder(encoder, formatter)
+	return func(ctx context.Context, w http.ResponseWriter, v error) error {
+		var en goa.GoaErrorNamer
+		if !errors.As(v, &en) {
+			return encodeError(ctx, w, v)
+		}
+		switch en.GoaErrorName() {
+		case "bad-request":
+			var res *artifact.BadRequestT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewUploadBadRequestResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return enc.Encode(body)
+		case "invalid-credential":
+			var res *artifact.InvalidCredentialsT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return nil
+		case "invalid-scopes":
+			var res *artifact.InvalidScopesT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewUploadInvalidScopesResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusForbidden)
+			return enc.Encode(body)
+		case "not-implemented":
+			var res *artifact.NotImplementedT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewUploadNotImplementedResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotImplemented)
+			return enc.Encode(body)
+		case "not-authorized":
+			var res *artifact.UnauthorizedT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnauthorized)
+			return nil
+		default:
+			return encodeError(ctx, w, v)
+		}
+	}
+}
+
+// marshalArtifactviewsArtifactListItemViewToArtifactListItemResponseBody
+// builds a value of type *ArtifactListItemResponseBody from a value of type
+// *artifactviews.ArtifactListItemView.
+func marshalArtifactviewsArtifactListItemViewToArtifactListItemResponseBody(v *artifactviews.ArtifactListItemView) *ArtifactListItemResponseBody {
+	res := &ArtifactListItemResponseBody{
+		ID:       v.ID,
+		Name:     v.Name,
+		Status:   v.Status,
+		Size:     v.Size,
+		MimeType: v.MimeType,
+	}
+	if v.Links != nil {
+		res.Links = marshalArtifactviewsSelfTViewToSelfTResponseBody(v.Links)
+	}
+
+	return res
+}
+
+// marshalArtifactviewsSelfTViewToSelfTResponseBody builds a value of type
+// *SelfTResponseBody from a value of type *artifactviews.SelfTView.
+func marshalArtifactviewsSelfTViewToSelfTResponseBody(v *artifactviews.SelfTView) *SelfTResponseBody {
+	res := &SelfTResponseBody{
+		Self: v.Self,
+	}
+	if v.DescribedBy != nil {
+		res.DescribedBy = marshalArtifactviewsDescribedByTViewToDescribedByTResponseBody(v.DescribedBy)
+	}
+
+	return res
+}
+
+// marshalArtifactviewsDescribedByTViewToDescribedByTResponseBody builds a
+// value of type *DescribedByTResponseBody from a value of type
+// *artifactviews.DescribedByTView.
+func marshalArtifactviewsDescribedByTViewToDescribedByTResponseBody(v *artifactviews.DescribedByTView) *DescribedByTResponseBody {
+	if v == nil {
+		return nil
+	}
+	res := &DescribedByTResponseBody{
+		Href: v.Href,
+		Type: v.Type,
+	}
+
+	return res
+}
+
+// marshalArtifactviewsNavTViewToNavTResponseBody builds a value of type
+// *NavTResponseBody from a value of type *artifactviews.NavTView.
+func marshalArtifactviewsNavTViewToNavTResponseBody(v *artifactviews.NavTView) *NavTResponseBody {
+	res := &NavTResponseBody{
+		Self:  v.Self,
+		First: v.First,
+		Next:  v.Next,
+	}
+
+	return res
+}
+
+// marshalArtifactviewsRefTViewToRefTResponseBody builds a value of type
+// *RefTResponseBody from a value of type *artifactviews.RefTView.
+func marshalArtifactviewsRefTViewToRefTResponseBody(v *artifactviews.RefTView) *RefTResponseBody {
+	if v == nil {
+		return nil
+	}
+	res := &RefTResponseBody{
+		ID: v.ID,
+	}
+	if v.Links != nil {
+		res.Links = marshalArtifactviewsSelfTViewToSelfTResponseBody(v.Links)
+	}
+
+	return res
+}
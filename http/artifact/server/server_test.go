@@ -0,0 +1,217 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	artifact "github.com/reinventingscience/ivcap-core-api/gen/artifact"
+	client "github.com/reinventingscience/ivcap-core-api/http/artifact"
+	goahttp "goa.design/goa/v3/http"
+	"goa.design/goa/v3/security"
+)
+
+// stubService records the payloads and upload body it receives and answers
+// with result or err.
+type stubService struct {
+	payload interface{}
+	body    string
+	result  interface{}
+	err     error
+}
+
+func (s *stubService) JWTAuth(ctx context.Context, token string, _ *security.JWTScheme) (context.Context, error) {
+	return ctx, nil
+}
+
+func (s *stubService) List(_ context.Context, p *artifact.ListPayload) (*artifact.ArtifactListRT, error) {
+	s.payload = p
+	res, _ := s.result.(*artifact.ArtifactListRT)
+	return res, s.err
+}
+
+func (s *stubService) Read(_ context.Context, p *artifact.ReadPayload) (*artifact.ArtifactStatusRT, error) {
+	s.payload = p
+	res, _ := s.result.(*artifact.ArtifactStatusRT)
+	return res, s.err
+}
+
+func (s *stubService) Upload(_ context.Context, p *artifact.UploadPayload, body io.ReadCloser) (*artifact.ArtifactStatusRT, error) {
+	s.payload = p
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	s.body = string(b)
+	res, _ := s.result.(*artifact.ArtifactStatusRT)
+	return res, s.err
+}
+
+// newTestClient serves s and returns the generated client for it.
+func newTestClient(t *testing.T, s *stubService) *artifact.Client {
+	mux := goahttp.NewMuxer()
+	Mount(mux, New(artifact.NewEndpoints(s), mux, goahttp.RequestDecoder, goahttp.ResponseEncoder, nil, nil))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	u, _ := url.Parse(srv.URL)
+	c := client.NewClient(u.Scheme, u.Host, srv.Client(), goahttp.RequestEncoder, goahttp.ResponseDecoder, false)
+	return artifact.NewClient(c.List(), c.Read(), c.Upload())
+}
+
+func str(s string) *string { return &s }
+
+func TestRoundTrip(t *testing.T) {
+	s := &stubService{}
+	c := newTestClient(t, s)
+	ctx := context.Background()
+	id := "urn:ivcap:artifact:0d6ce3f6-5b1b-4bcd-95a5-2a7ca4b0e3ab"
+	size, offset := int64(5), int64(5)
+	status := &artifact.ArtifactStatusRT{
+		ID:             id,
+		Name:           str("hello.txt"),
+		Status:         "ready",
+		MimeType:       str("text/plain"),
+		Size:           &size,
+		CacheOf:        str("https://example.com/hello.txt"),
+		Etag:           str("5d41402abc4b2a76b9719d911017c592"),
+		CreatedAt:      str("2023-01-01T00:00:00Z"),
+		LastModifiedAt: str("2023-01-01T00:01:00Z"),
+		Policy:         &artifact.RefT{ID: str("urn:ivcap:policy:1")},
+		Account:        &artifact.RefT{ID: str("urn:ivcap:account:1"), Links: &artifact.SelfT{Self: str("https://ivcap.test/1/accounts/1")}},
+		Data:           &artifact.SelfT{Self: str("https://ivcap.test/1/artifacts/1/blob")},
+		Links:          &artifact.SelfT{Self: str("https://ivcap.test/1/artifacts/1"), DescribedBy: &artifact.DescribedByT{Href: str("https://ivcap.test/schema"), Type: str("application/json")}},
+		Location:       str("https://ivcap.test/1/artifacts/1/blob"),
+		TusResumable:   str("1.0.0"),
+		TusOffset:      &offset,
+	}
+
+	for _, tc := range []struct {
+		name    string
+		call    func() (interface{}, error)
+		payload interface{}
+		result  interface{}
+	}{
+		{
+			"list",
+			func() (interface{}, error) {
+				return c.List(ctx, &artifact.ListPayload{Limit: 5, Filter: str("status eq 'ready'"), OrderBy: str("name"), OrderDesc: true, AtTime: str("2023-01-01T00:00:00Z"), Page: str("p2"), JWT: "token"})
+			},
+			&artifact.ListPayload{Limit: 5, Filter: str("status eq 'ready'"), OrderBy: str("name"), OrderDesc: true, AtTime: str("2023-01-01T00:00:00Z"), Page: str("p2"), JWT: "token"},
+			&artifact.ArtifactListRT{
+				Artifacts: []*artifact.ArtifactListItem{{ID: &id, Name: str("hello.txt"), Status: str("ready"), Size: &size, MimeType: str("text/plain"), Links: &artifact.SelfT{Self: str("https://ivcap.test/1/artifacts/1")}}},
+				AtTime:    str("2023-01-01T00:00:00Z"),
+				Links:     &artifact.NavT{Self: str("https://ivcap.test/1/artifacts"), First: str("https://ivcap.test/1/artifacts?page=p1")},
+			},
+		},
+		{
+			"read",
+			func() (interface{}, error) { return c.Read(ctx, &artifact.ReadPayload{ID: id, JWT: "token"}) },
+			&artifact.ReadPayload{ID: id, JWT: "token"},
+			status,
+		},
+	} {
+		s.payload, s.result, s.err = nil, tc.result, nil
+		res, err := tc.call()
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(s.payload, tc.payload) {
+			t.Errorf("%s: server received %+v, want %+v", tc.name, s.payload, tc.payload)
+		}
+		if !reflect.DeepEqual(res, tc.result) {
+			t.Errorf("%s: client received %+v, want %+v", tc.name, res, tc.result)
+		}
+	}
+}
+
+func TestRoundTripUpload(t *testing.T) {
+	s := &stubService{}
+	c := newTestClient(t, s)
+	length := 5
+	p := &artifact.UploadPayload{
+		ContentType:     str("text/plain"),
+		ContentEncoding: str("identity"),
+		Name:            str("hello.txt"),
+		Collection:      str("urn:ivcap:collection:1"),
+		Policy:          str("urn:ivcap:policy:1"),
+		XContentType:    str("text/plain"),
+		XContentLength:  &length,
+		UploadLength:    &length,
+		TusResumable:    str("1.0.0"),
+		JWT:             "token",
+	}
+	s.result = &artifact.ArtifactStatusRT{
+		ID:       "urn:ivcap:artifact:1",
+		Status:   "pending",
+		Data:     &artifact.SelfT{Self: str("https://ivcap.test/1/artifacts/1/blob")},
+		Links:    &artifact.SelfT{Self: str("https://ivcap.test/1/artifacts/1")},
+		Location: str("https://ivcap.test/1/artifacts/1/blob"),
+	}
+	res, err := c.Upload(context.Background(), p, io.NopCloser(strings.NewReader("hello")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s.payload, p) {
+		t.Errorf("server received %+v, want %+v", s.payload, p)
+	}
+	if s.body != "hello" {
+		t.Errorf("server received body %q", s.body)
+	}
+	if !reflect.DeepEqual(res, s.result) {
+		t.Errorf("client received %+v, want %+v", res, s.result)
+	}
+}
+
+func TestRoundTripErrors(t *testing.T) {
+	s := &stubService{}
+	c := newTestClient(t, s)
+	ctx := context.Background()
+	read := func() error {
+		_, err := c.Read(ctx, &artifact.ReadPayload{ID: "urn:ivcap:artifact:1", JWT: "token"})
+		return err
+	}
+	list := func() error {
+		_, err := c.List(ctx, &artifact.ListPayload{Limit: 10, JWT: "token"})
+		return err
+	}
+	for _, tc := range []struct {
+		err  error
+		call func() error
+	}{
+		{&artifact.BadRequestT{Message: "bad"}, read},
+		{&artifact.InvalidCredentialsT{}, read},
+		{&artifact.ResourceNotFoundT{ID: "urn:ivcap:artifact:1", Message: "not found"}, read},
+		{&artifact.NotImplementedT{Message: "later"}, read},
+		{&artifact.UnauthorizedT{}, read},
+		{&artifact.InvalidParameterValue{Name: "limit", Message: "too large", Value: str("1000")}, list},
+		// The design names the invalid scopes error after its message.
+		{&artifact.InvalidScopesT{ID: str("0d6ce3f6-5b1b-4bcd-95a5-2a7ca4b0e3ab"), Message: "invalid-scopes"}, list},
+	} {
+		s.err = tc.err
+		err := tc.call()
+		got := reflect.New(reflect.TypeOf(tc.err))
+		if !errors.As(err, got.Interface()) || !reflect.DeepEqual(got.Elem().Interface(), tc.err) {
+			t.Errorf("returned %#v, client received %#v", tc.err, err)
+		}
+	}
+}
@@ -0,0 +1,449 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	artifact "github.com/reinventingscience/ivcap-core-api/gen/artifact"
+	artifactviews "github.com/reinventingscience/ivcap-core-api/gen/artifact/views"
+)
+
+// ListResponseBody is the type of the "artifact" service "list" endpoint HTTP
+// response body.
+type ListResponseBody struct {
+	// Artifacts
+	Artifacts []*ArtifactListItemResponseBody `form:"artifacts" json:"artifacts" xml:"artifacts"`
+	// Time at which this list was valid
+	AtTime *string `form:"at-time,omitempty" json:"at-time,omitempty" xml:"at-time,omitempty"`
+	// Navigation links
+	Links *NavTResponseBody `form:"links" json:"links" xml:"links"`
+}
+
+// ReadResponseBody is the type of the "artifact" service "read" endpoint HTTP
+// response body.
+type ReadResponseBody struct {
+	// Artifact ID
+	ID string `form:"id" json:"id" xml:"id"`
+	// Optional name
+	Name *string `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	// Artifact status
+	Status string `form:"status" json:"status" xml:"status"`
+	// Mime-type of data
+	MimeType *string `form:"mime-type,omitempty" json:"mime-type,omitempty" xml:"mime-type,omitempty"`
+	// Size of data
+	Size *int64 `form:"size,omitempty" json:"size,omitempty" xml:"size,omitempty"`
+	// URL of object this artifact is caching
+	CacheOf *string `form:"cache-of,omitempty" json:"cache-of,omitempty" xml:"cache-of,omitempty"`
+	// ETAG of artifact
+	Etag *string `json:"etag,omitempty"`
+	// DateTime artifact was created
+	CreatedAt *string `form:"created-at,omitempty" json:"created-at,omitempty" xml:"created-at,omitempty"`
+	// DateTime artifact was last modified
+	LastModifiedAt *string `form:"last-modified-at,omitempty" json:"last-modified-at,omitempty" xml:"last-modified-at,omitempty"`
+	// Reference to policy controlling access
+	Policy *RefTResponseBody `form:"policy,omitempty" json:"policy,omitempty" xml:"policy,omitempty"`
+	// Reference to policy controlling access
+	Account *RefTResponseBody  `form:"account,omitempty" json:"account,omitempty" xml:"account,omitempty"`
+	Data    *SelfTResponseBody `form:"data,omitempty" json:"data,omitempty" xml:"data,omitempty"`
+	Links   *SelfTResponseBody `form:"links" json:"links" xml:"links"`
+	// link back to record
+	Location *string `form:"location,omitempty" json:"location,omitempty" xml:"location,omitempty"`
+	// indicate version of TUS supported
+	TusResumable *string `form:"tus-resumable,omitempty" json:"tus-resumable,omitempty" xml:"tus-resumable,omitempty"`
+	// TUS offset for partially uploaded content
+	TusOffset *int64 `form:"tus-offset,omitempty" json:"tus-offset,omitempty" xml:"tus-offset,omitempty"`
+}
+
+// UploadResponseBody is the type of the "artifact" service "upload" endpoint
+// HTTP response body.
+type UploadResponseBody struct {
+	// Artifact ID
+	ID string `form:"id" json:"id" xml:"id"`
+	// Optional name
+	Name *string `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	// Artifact status
+	Status string `form:"status" json:"status" xml:"status"`
+	// Mime-type of data
+	MimeType *string `form:"mime-type,omitempty" json:"mime-type,omitempty" xml:"mime-type,omitempty"`
+	// Size of data
+	Size *int64 `form:"size,omitempty" json:"size,omitempty" xml:"size,omitempty"`
+	// URL of object this artifact is caching
+	CacheOf *string `form:"cache-of,omitempty" json:"cache-of,omitempty" xml:"cache-of,omitempty"`
+	// ETAG of artifact
+	Etag *string `json:"etag,omitempty"`
+	// DateTime artifact was created
+	CreatedAt *string `form:"created-at,omitempty" json:"created-at,omitempty" xml:"created-at,omitempty"`
+	// DateTime artifact was last modified
+	LastModifiedAt *string `form:"last-modified-at,omitempty" json:"last-modified-at,omitempty" xml:"last-modified-at,omitempty"`
+	// Reference to policy controlling access
+	Policy *RefTResponseBody `form:"policy,omitempty" json:"policy,omitempty" xml:"policy,omitempty"`
+	// Reference to policy controlling access
+	Account *RefTResponseBody  `form:"account,omitempty" json:"account,omitempty" xml:"account,omitempty"`
+	Data    *SelfTResponseBody `form:"data,omitempty" json:"data,omitempty" xml:"data,omitempty"`
+	Links   *SelfTResponseBody `form:"links" json:"links" xml:"links"`
+}
+
+// ListBadRequestResponseBody is the type of the "artifact" service "list"
+// endpoint HTTP response body for the "bad-request" error.
+type ListBadRequestResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ListInvalidParameterResponseBody is the type of the "artifact" service
+// "list" endpoint HTTP response body for the "invalid-parameter" error.
+type ListInvalidParameterResponseBody struct {
+	// message describing expected type or pattern.
+	Message string `form:"message" json:"message" xml:"message"`
+	// name of parameter.
+	Name string `form:"name" json:"name" xml:"name"`
+	// provided parameter value.
+	Value *string `form:"value,omitempty" json:"value,omitempty" xml:"value,omitempty"`
+}
+
+// ListInvalidScopesResponseBody is the type of the "artifact" service "list"
+// endpoint HTTP response body for the "invalid-scopes" error.
+type ListInvalidScopesResponseBody struct {
+	// ID of involved resource
+	ID *string `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ListNotImplementedResponseBody is the type of the "artifact" service "list"
+// endpoint HTTP response body for the "not-implemented" error.
+type ListNotImplementedResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ReadBadRequestResponseBody is the type of the "artifact" service "read"
+// endpoint HTTP response body for the "bad-request" error.
+type ReadBadRequestResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ReadInvalidScopesResponseBody is the type of the "artifact" service "read"
+// endpoint HTTP response body for the "invalid-scopes" error.
+type ReadInvalidScopesResponseBody struct {
+	// ID of involved resource
+	ID *string `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ReadNotImplementedResponseBody is the type of the "artifact" service "read"
+// endpoint HTTP response body for the "not-implemented" error.
+type ReadNotImplementedResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ReadNotFoundResponseBody is the type of the "artifact" service "read"
+// endpoint HTTP response body for the "not-found" error.
+type ReadNotFoundResponseBody struct {
+	// ID of missing resource
+	ID string `form:"id" json:"id" xml:"id"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// UploadBadRequestResponseBody is the type of the "artifact" service "upload"
+// endpoint HTTP response body for the "bad-request" error.
+type UploadBadRequestResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// UploadInvalidScopesResponseBody is the type of the "artifact" service
+// "upload" endpoint HTTP response body for the "invalid-scopes" error.
+type UploadInvalidScopesResponseBody struct {
+	// ID of involved resource
+	ID *string `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// UploadNotImplementedResponseBody is the type of the "artifact" service
+// "upload" endpoint HTTP response body for the "not-implemented" error.
+type UploadNotImplementedResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ArtifactListItemResponseBody is used to define fields on response body types.
+type ArtifactListItemResponseBody struct {
+	// Artifact ID
+	ID *string `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	// Optional name
+	Name *string `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	// Artifact status
+	Status *string `form:"status,omitempty" json:"status,omitempty" xml:"status,omitempty"`
+	// Size of aritfact in bytes
+	Size *int64 `form:"size,omitempty" json:"size,omitempty" xml:"size,omitempty"`
+	// Mime (content) type of artifact
+	MimeType *string            `form:"mime-type,omitempty" json:"mime-type,omitempty" xml:"mime-type,omitempty"`
+	Links    *SelfTResponseBody `form:"links" json:"links" xml:"links"`
+}
+
+// SelfTResponseBody is used to define fields on response body types.
+type SelfTResponseBody struct {
+	Self        *string                   `form:"self,omitempty" json:"self,omitempty" xml:"self,omitempty"`
+	DescribedBy *DescribedByTResponseBody `form:"describedBy,omitempty" json:"describedBy,omitempty" xml:"describedBy,omitempty"`
+}
+
+// DescribedByTResponseBody is used to define fields on response body types.
+type DescribedByTResponseBody struct {
+	Href *string `form:"href,omitempty" json:"href,omitempty" xml:"href,omitempty"`
+	Type *string `form:"type,omitempty" json:"type,omitempty" xml:"type,omitempty"`
+}
+
+// NavTResponseBody is used to define fields on response body types.
+type NavTResponseBody struct {
+	Self  *string `form:"self,omitempty" json:"self,omitempty" xml:"self,omitempty"`
+	First *string `form:"first,omitempty" json:"first,omitempty" xml:"first,omitempty"`
+	Next  *string `form:"next,omitempty" json:"next,omitempty" xml:"next,omitempty"`
+}
+
+// RefTResponseBody is used to define fields on response body types.
+type RefTResponseBody struct {
+	ID    *string            `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	Links *SelfTResponseBody `form:"links,omitempty" json:"links,omitempty" xml:"links,omitempty"`
+}
+
+// NewListResponseBody builds the HTTP response body from the result of the
+// "list" endpoint of the "artifact" service.
+func NewListResponseBody(res *artifactviews.ArtifactListRTView) *ListResponseBody {
+	body := &ListResponseBody{
+		AtTime: res.AtTime,
+	}
+	if res.Artifacts != nil {
+		body.Artifacts = make([]*ArtifactListItemResponseBody, len(res.Artifacts))
+		for i, val := range res.Artifacts {
+			body.Artifacts[i] = marshalArtifactviewsArtifactListItemViewToArtifactListItemResponseBody(val)
+		}
+	}
+	if res.Links != nil {
+		body.Links = marshalArtifactviewsNavTViewToNavTResponseBody(res.Links)
+	}
+	return body
+}
+
+// NewReadResponseBody builds the HTTP response body from the result of the
+// "read" endpoint of the "artifact" service.
+func NewReadResponseBody(res *artifactviews.ArtifactStatusRTView) *ReadResponseBody {
+	body := &ReadResponseBody{
+		ID:             *res.ID,
+		Name:           res.Name,
+		Status:         *res.Status,
+		MimeType:       res.MimeType,
+		Size:           res.Size,
+		CacheOf:        res.CacheOf,
+		Etag:           res.Etag,
+		CreatedAt:      res.CreatedAt,
+		LastModifiedAt: res.LastModifiedAt,
+		Location:       res.Location,
+		TusResumable:   res.TusResumable,
+		TusOffset:      res.TusOffset,
+	}
+	if res.Policy != nil {
+		body.Policy = marshalArtifactviewsRefTViewToRefTResponseBody(res.Policy)
+	}
+	if res.Account != nil {
+		body.Account = marshalArtifactviewsRefTViewToRefTResponseBody(res.Account)
+	}
+	if res.Data != nil {
+		body.Data = marshalArtifactviewsSelfTViewToSelfTResponseBody(res.Data)
+	}
+	if res.Links != nil {
+		body.Links = marshalArtifactviewsSelfTViewToSelfTResponseBody(res.Links)
+	}
+	return body
+}
+
+// NewUploadResponseBody builds the HTTP response body from the result of the
+// "upload" endpoint of the "artifact" service.
+func NewUploadResponseBody(res *artifactviews.ArtifactStatusRTView) *UploadResponseBody {
+	body := &UploadResponseBody{
+		ID:             *res.ID,
+		Name:           res.Name,
+		Status:         *res.Status,
+		MimeType:       res.MimeType,
+		Size:           res.Size,
+		CacheOf:        res.CacheOf,
+		Etag:           res.Etag,
+		CreatedAt:      res.CreatedAt,
+		LastModifiedAt: res.LastModifiedAt,
+	}
+	if res.Policy != nil {
+		body.Policy = marshalArtifactviewsRefTViewToRefTResponseBody(res.Policy)
+	}
+	if res.Account != nil {
+		body.Account = marshalArtifactviewsRefTViewToRefTResponseBody(res.Account)
+	}
+	if res.Data != nil {
+		body.Data = marshalArtifactviewsSelfTViewToSelfTResponseBody(res.Data)
+	}
+	if res.Links != nil {
+		body.Links = marshalArtifactviewsSelfTViewToSelfTResponseBody(res.Links)
+	}
+	return body
+}
+
+// NewListBadRequestResponseBody builds the HTTP response body from the result
+// of the "list" endpoint of the "artifact" service.
+func NewListBadRequestResponseBody(res *artifact.BadRequestT) *ListBadRequestResponseBody {
+	body := &ListBadRequestResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewListInvalidParameterResponseBody builds the HTTP response body from the
+// result of the "list" endpoint of the "artifact" service.
+func NewListInvalidParameterResponseBody(res *artifact.InvalidParameterValue) *ListInvalidParameterResponseBody {
+	body := &ListInvalidParameterResponseBody{
+		Message: res.Message,
+		Name:    res.Name,
+		Value:   res.Value,
+	}
+	return body
+}
+
+// NewListInvalidScopesResponseBody builds the HTTP response body from the
+// result of the "list" endpoint of the "artifact" service.
+func NewListInvalidScopesResponseBody(res *artifact.InvalidScopesT) *ListInvalidScopesResponseBody {
+	body := &ListInvalidScopesResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewListNotImplementedResponseBody builds the HTTP response body from the
+// result of the "list" endpoint of the "artifact" service.
+func NewListNotImplementedResponseBody(res *artifact.NotImplementedT) *ListNotImplementedResponseBody {
+	body := &ListNotImplementedResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewReadBadRequestResponseBody builds the HTTP response body from the result
+// of the "read" endpoint of the "artifact" service.
+func NewReadBadRequestResponseBody(res *artifact.BadRequestT) *ReadBadRequestResponseBody {
+	body := &ReadBadRequestResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewReadInvalidScopesResponseBody builds the HTTP response body from the
+// result of the "read" endpoint of the "artifact" service.
+func NewReadInvalidScopesResponseBody(res *artifact.InvalidScopesT) *ReadInvalidScopesResponseBody {
+	body := &ReadInvalidScopesResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewReadNotImplementedResponseBody builds the HTTP response body from the
+// result of the "read" endpoint of the "artifact" service.
+func NewReadNotImplementedResponseBody(res *artifact.NotImplementedT) *ReadNotImplementedResponseBody {
+	body := &ReadNotImplementedResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewReadNotFoundResponseBody builds the HTTP response body from the result of
+// the "read" endpoint of the "artifact" service.
+func NewReadNotFoundResponseBody(res *artifact.ResourceNotFoundT) *ReadNotFoundResponseBody {
+	body := &ReadNotFoundResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewUploadBadRequestResponseBody builds the HTTP response body from the
+// result of the "upload" endpoint of the "artifact" service.
+func NewUploadBadRequestResponseBody(res *artifact.BadRequestT) *UploadBadRequestResponseBody {
+	body := &UploadBadRequestResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewUploadInvalidScopesResponseBody builds the HTTP response body from the
+// result of the "upload" endpoint of the "artifact" service.
+func NewUploadInvalidScopesResponseBody(res *artifact.InvalidScopesT) *UploadInvalidScopesResponseBody {
+	body := &UploadInvalidScopesResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewUploadNotImplementedResponseBody builds the HTTP response body from the
+// result of the "upload" endpoint of the "artifact" service.
+func NewUploadNotImplementedResponseBody(res *artifact.NotImplementedT) *UploadNotImplementedResponseBody {
+	body := &UploadNotImplementedResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewListPayload builds a artifact service list endpoint payload.
+func NewListPayload(limit int, page *string, filter *string, orderBy *string, orderDesc bool, atTime *string, jwt string) *artifact.ListPayload {
+	v := &artifact.ListPayload{}
+	v.Limit = limit
+	v.Page = page
+	v.Filter = filter
+	v.OrderBy = orderBy
+	v.OrderDesc = orderDesc
+	v.AtTime = atTime
+	v.JWT = jwt
+
+	return v
+}
+
+// NewReadPayload builds a artifact service read endpoint payload.
+func NewReadPayload(id string, jwt string) *artifact.ReadPayload {
+	v := &artifact.ReadPayload{}
+	v.ID = id
+	v.JWT = jwt
+
+	return v
+}
+
+// NewUploadPayload builds a artifact service upload endpoint payload.
+func NewUploadPayload(contentType *string, contentEncoding *string, contentLength *int, name *string, collection *string, policy *string, xContentType *string, xContentLength *int, uploadLength *int, tusResumable *string, jwt string) *artifact.UploadPayload {
+	v := &artifact.UploadPayload{}
+	v.ContentType = contentType
+	v.ContentEncoding = contentEncoding
+	v.ContentLength = contentLength
+	v.Name = name
+	v.Collection = collection
+	v.Policy = policy
+	v.XContentType = xContentType
+	v.XContentLength = xContentLength
+	v.UploadLength = uploadLength
+	v.TusResumable = tusResumable
+	v.JWT = jwt
+
+	return v
+}
@@ -0,0 +1,958 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	metadata "github.com/reinventingscience/ivcap-core-api/gen/metadata"
+	metadataviews "github.com/reinventingscience/ivcap-core-api/gen/metadata/views"
+	goahttp "goa.design/goa/v3/http"
+	goa "goa.design/goa/v3/pkg"
+)
+
+// EncodeReadResponse returns an encoder for responses returned by the metadata
+// read endpoint.
+func EncodeReadResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, interface{}) error {
+	return func(ctx context.Context, w http.ResponseWriter, v interface{}) error {
+		res := v.(*metadataviews.MetadataRecordRT)
+		enc := encoder(ctx, w)
+		body := NewReadResponseBody(res.Projected)
+		w.WriteHeader(http.StatusOK)
+		return enc.Encode(body)
+	}
+}
+
+// DecodeReadRequest returns a decoder for requests sent to the metadata read
+// endpoint.
+func DecodeReadRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		var (
+			id  string
+			jwt string
+			err error
+
+			params = mux.Vars(r)
+		)
+		id = params["id"]
+		jwt = r.Header.Get("Authorization")
+		if jwt == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("Authorization", "header"))
+		}
+		if err != nil {
+			return nil, err
+		}
+		payload := NewReadPayload(id, jwt)
+		if strings.Contains(payload.JWT, " ") {
+			// Remove authorization scheme prefix (e.g. "Bearer")
+			cred := strings.SplitN(payload.JWT, " ", 2)[1]
+			payload.JWT = cred
+		}
+
+		return payload, nil
+	}
+}
+
+// EncodeReadError returns an encoder for errors returned by the read metadata
+// endpoint.
+func EncodeReadError(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder, formatter func(ctx context.Context, err error) goahttp.Statuser) func(context.Context, http.ResponseWriter, error) error {
+	encodeError := goahttp.ErrorEncoder(encoder, formatter)
+	return func(ctx context.Context, w http.ResponseWriter, v error) error {
+		var en goa.GoaErrorNamer
+		if !errors.As(v, &en) {
+			return encodeError(ctx, w, v)
+		}
+		switch en.GoaErrorName() {
+		case "bad-request":
+			var res *metadata.BadRequestT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewReadBadRequestResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return enc.Encode(body)
+		case "invalid-credential":
+			var res *metadata.InvalidCredentialsT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return nil
+		case "invalid-scopes":
+			var res *metadata.InvalidScopesT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewReadInvalidScopesResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusForbidden)
+			return enc.Encode(body)
+		case "not-implemented":
+			var res *metadata.NotImplementedT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewReadNotImplementedResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotImplemented)
+			return enc.Encode(body)
+		case "not-found":
+			var res *metadata.ResourceNotFoundT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewReadNotFoundResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotFound)
+			return enc.Encode(body)
+		case "not-authorized":
+			var res *metadata.UnauthorizedT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnauthorized)
+			return nil
+		default:
+			return encodeError(ctx, w, v)
+		}
+	}
+}
+
+// EncodeListResponse returns an encoder for responses returned by the metadata
+// list endpoint.
+func EncodeListResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, interface{}) error {
+	return func(ctx context.Context, w http.ResponseWriter, v interface{}) error {
+		res := v.(*metadataviews.ListMetaRT)
+		enc := encoder(ctx, w)
+		body := NewListResponseBody(res.Projected)
+		w.WriteHeader(http.StatusOK)
+		return enc.Encode(body)
+	}
+}
+
+// DecodeListRequest returns a decoder for requests sent to the metadata list
+// endpoint.
+func DecodeListRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		var (
+			entityID   *string
+			schema     *string
+			aspectPath *string
+			atTime     *string
+			limit      int
+			filter     string
+			orderBy    string
+			orderDesc  *bool
+			page       *string
+			jwt        string
+			err        error
+		)
+		entityIDRaw := r.URL.Query().Get("entity-id")
+		if entityIDRaw != "" {
+			entityID = &entityIDRaw
+		}
+		if entityID != nil {
+			err = goa.MergeErrors(err, goa.ValidateFormat("entityID", *entityID, goa.FormatURI))
+		}
+		schemaRaw := r.URL.Query().Get("schema")
+		if schemaRaw != "" {
+			schema = &schemaRaw
+		}
+		aspectPathRaw := r.URL.Query().Get("aspect-path")
+		if aspectPathRaw != "" {
+			aspectPath = &aspectPathRaw
+		}
+		atTimeRaw := r.URL.Query().Get("at-time")
+		if atTimeRaw != "" {
+			atTime = &atTimeRaw
+		}
+		if atTime != nil {
+			err = goa.MergeErrors(err, goa.ValidateFormat("atTime", *atTime, goa.FormatDateTime))
+		}
+		{
+			limitRaw := r.URL.Query().Get("limit")
+			if limitRaw == "" {
+				limit = 10
+			} else {
+				v, err2 := strconv.ParseInt(limitRaw, 10, strconv.IntSize)
+				if err2 != nil {
+					err = goa.MergeErrors(err, goa.InvalidFieldTypeError("limit", limitRaw, "integer"))
+				}
+				limit = int(v)
+			}
+		}
+		if limit < 1 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("limit", limit, 1, true))
+		}
+		if limit > 50 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("limit", limit, 50, false))
+		}
+		filterRaw := r.URL.Query().Get("filter")
+		if filterRaw != "" {
+			filter = filterRaw
+		}
+		orderByRaw := r.URL.Query().Get("order-by")
+		if orderByRaw != "" {
+			orderBy = orderByRaw
+		}
+		{
+			orderDescRaw := r.URL.Query().Get("order-desc")
+			if orderDescRaw != "" {
+				v, err2 := strconv.ParseBool(orderDescRaw)
+				if err2 != nil {
+					err = goa.MergeErrors(err, goa.InvalidFieldTypeError("orderDesc", orderDescRaw, "boolean"))
+				}
+				orderDesc = &v
+			}
+		}
+		pageRaw := r.URL.Query().Get("page")
+		if pageRaw != "" {
+			page = &pageRaw
+		}
+		jwt = r.Header.Get("Authorization")
+		if jwt == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("Authorization", "header"))
+		}
+		if err != nil {
+			return nil, err
+		}
+		payload := NewListPayload(entityID, schema, aspectPath, atTime, limit, filter, orderBy, orderDesc, page, jwt)
+		if strings.Contains(payload.JWT, " ") {
+			// Remove authorization scheme prefix (e.g. "Bearer")
+			cred := strings.SplitN(payload.JWT, " ", 2)[1]
+			payload.JWT = cred
+		}
+
+		return payload, nil
+	}
+}
+
+// EncodeListError returns an encoder for errors returned by the list metadata
+// endpoint.
+func EncodeListError(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder, formatter func(ctx context.Context, err error) goahttp.Statuser) func(context.Context, http.ResponseWriter, error) error {
+	encodeError := goahttp.ErrorEncoder(encoder, formatter)
+	return func(ctx context.Context, w http.ResponseWriter, v error) error {
+		var en goa.GoaErrorNamer
+		if !errors.As(v, &en) {
+			return encodeError(ctx, w, v)
+		}
+		switch en.GoaErrorName() {
+		case "bad-request":
+			var res *metadata.BadRequestT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewListBadRequestResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return enc.Encode(body)
+		case "invalid-credential":
+			var res *metadata.InvalidCredentialsT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return nil
+		case "invalid-parameter":
+			var res *metadata.InvalidParameterValue
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewListInvalidParameterResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return enc.Encode(body)
+		case "invalid-scopes":
+			var res *metadata.InvalidScopesT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewListInvalidScopesResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusForbidden)
+			return enc.Encode(body)
+		case "not-implemented":
+			var res *metadata.NotImplementedT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewListNotImplementedResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotImplemented)
+			return enc.Encode(body)
+		case "not-authorized":
+			var res *metadata.UnauthorizedT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnauthorized)
+			return nil
+		default:
+			return encodeError(ctx, w, v)
+		}
+	}
+}
+
+// EncodeAddResponse returns an encoder for responses returned by the metadata
+// add endpoint.
+func EncodeAddResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, interface{}) error {
+	return func(ctx context.Context, w http.ResponseWriter, v interface{}) error {
+		res := v.(*metadataviews.AddMetaRT)
+		enc := encoder(ctx, w)
+		body := NewAddResponseBody(res.Projected)
+		w.WriteHeader(http.StatusOK)
+		return enc.Encode(body)
+	}
+}
+
+// DecodeAddRequest returns a decoder for requests sent to the metadata add
+// endpoint.
+func DecodeAddRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		var (
+			body interface{}
+			err  error
+		)
+		err = decoder(r).Decode(&body)
+		if err != nil {
+			if err == io.EOF {
+				return nil, goa.MissingPayloadError()
+			}
+			return nil, goa.DecodePayloadError(err.Error())
+		}
+
+		var (
+			entityID    string
+			schema      string
+			policyID    *string
+			contentType string
+			jwt         string
+		)
+		entityID = r.URL.Query().Get("entity-id")
+		if entityID == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("entity-id", "query string"))
+		}
+		err = goa.MergeErrors(err, goa.ValidateFormat("entityID", entityID, goa.FormatURI))
+		schema = r.URL.Query().Get("schema")
+		if schema == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("schema", "query string"))
+		}
+		err = goa.MergeErrors(err, goa.ValidateFormat("schema", schema, goa.FormatURI))
+		policyIDRaw := r.URL.Query().Get("policy-id")
+		if policyIDRaw != "" {
+			policyID = &policyIDRaw
+		}
+		if policyID != nil {
+			err = goa.MergeErrors(err, goa.ValidateFormat("policyID", *policyID, goa.FormatURI))
+		}
+		contentType = r.Header.Get("Content-Type")
+		if contentType == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("Content-Type", "header"))
+		}
+		jwt = r.Header.Get("Authorization")
+		if jwt == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("Authorization", "header"))
+		}
+		if err != nil {
+			return nil, err
+		}
+		payload := NewAddPayload(body, entityID, schema, policyID, contentType, jwt)
+		if strings.Contains(payload.JWT, " ") {
+			// Remove authorization scheme prefix (e.g. "Bearer")
+			cred := strings.SplitN(payload.JWT, " ", 2)[1]
+			payload.JWT = cred
+		}
+
+		return payload, nil
+	}
+}
+
+// EncodeAddError returns an encoder for errors returned by the add metadata
+// endpoint.
+func EncodeAddError(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder, formatter func(ctx context.Context, err error) goahttp.Statuser) func(context.Context, http.ResponseWriter, error) error {
+	encodeError := goahttp.ErrorEncoder(encoder, formatter)
+	return func(ctx context.Context, w http.ResponseWriter, v error) error {
+		var en goa.GoaErrorNamer
+		if !errors.As(v, &en) {
+			return encodeError(ctx, w, v)
+		}
+		switch en.GoaErrorName() {
+		case "bad-request":
+			var res *metadata.BadRequestT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewAddBadRequestResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return enc.Encode(body)
+		case "invalid-credential":
+			var res *metadata.InvalidCredentialsT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return nil
+		case "invalid-parameter":
+			var res *metadata.InvalidParameterValue
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewAddInvalidParameterResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return enc.Encode(body)
+		case "invalid-scopes":
+			var res *metadata.InvalidScopesT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewAddInvalidScopesResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusForbidden)
+			return enc.Encode(body)
+		case "not-implemented":
+			var res *metadata.NotImplementedT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewAddNotImplementedResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotImplemented)
+			return enc.Encode(body)
+		case "not-authorized":
+			var res *metadata.UnauthorizedT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnauthorized)
+			return nil
+		default:
+			return encodeError(ctx, w, v)
+		}
+	}
+}
+
+// EncodeUpdateOneResponse returns an encoder for responses returned by the
+// metadata update_one endpoint.
+func EncodeUpdateOneResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, interface{}) error {
+	return func(ctx context.Context, w http.ResponseWriter, v interface{}) error {
+		res := v.(*metadataviews.AddMetaRT)
+		enc := encoder(ctx, w)
+		body := NewUpdateOneResponseBody(res.Projected)
+		w.WriteHeader(http.StatusOK)
+		return enc.Encode(body)
+	}
+}
+
+// DecodeUpdateOneRequest returns a decoder for requests sent to the metadata
+// update_one endpoint.
+func DecodeUpdateOneRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		var (
+			body interface{}
+			err  error
+		)
+		err = decoder(r).Decode(&body)
+		if err != nil {
+			if err == io.EOF {
+				return nil, goa.MissingPayloadError()
+			}
+			return nil, goa.DecodePayloadError(err.Error())
+		}
+
+		var (
+			entityID    string
+			schema      string
+			policyID    *string
+			contentType *string
+			jwt         string
+		)
+		entityID = r.URL.Query().Get("entity-id")
+		if entityID == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("entity-id", "query string"))
+		}
+		err = goa.MergeErrors(err, goa.ValidateFormat("entityID", entityID, goa.FormatURI))
+		schema = r.URL.Query().Get("schema")
+		if schema == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("schema", "query string"))
+		}
+		err = goa.MergeErrors(err, goa.ValidateFormat("schema", schema, goa.FormatURI))
+		policyIDRaw := r.URL.Query().Get("policy-id")
+		if policyIDRaw != "" {
+			policyID = &policyIDRaw
+		}
+		if policyID != nil {
+			err = goa.MergeErrors(err, goa.ValidateFormat("policyID", *policyID, goa.FormatURI))
+		}
+		contentTypeRaw := r.Header.Get("Content-Type")
+		if contentTypeRaw != "" {
+			contentType = &contentTypeRaw
+		}
+		jwt = r.Header.Get("Authorization")
+		if jwt == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("Authorization", "header"))
+		}
+		if err != nil {
+			return nil, err
+		}
+		payload := NewUpdateOnePayload(body, entityID, schema, policyID, contentType, jwt)
+		if strings.Contains(payload.JWT, " ") {
+			// Remove authorization scheme prefix (e.g. "Bearer")
+			cred := strings.SplitN(payload.JWT, " ", 2)[1]
+			payload.JWT = cred
+		}
+
+		return payload, nil
+	}
+}
+
+// EncodeUpdateOneError returns an encoder for errors returned by the
+// update_one metadata endpoint.
+func EncodeUpdateOneError(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder, formatter func(ctx context.Context, err error) goahttp.Statuser) func(context.Context, http.ResponseWriter, error) error {
+	encodeError := goahttp.ErrorEncoder(encoder, formatter)
+	return func(ctx context.Context, w http.ResponseWriter, v error) error {
+		var en goa.GoaErrorNamer
+		if !errors.As(v, &en) {
+			return encodeError(ctx, w, v)
+		}
+		switch en.GoaErrorName() {
+		case "bad-request":
+			var res *metadata.BadRequestT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewUpdateOneBadRequestResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return enc.Encode(body)
+		case "invalid-credential":
+			var res *metadata.InvalidCredentialsT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return nil
+		case "invalid-parameter":
+			var res *metadata.InvalidParameterValue
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewUpdateOneInvalidParameterResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return enc.Encode(body)
+		case "invalid-scopes":
+			var res *metadata.InvalidScopesT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewUpdateOneInvalidScopesResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusForbidden)
+			return enc.Encode(body)
+		case "not-implemented":
+			var res *metadata.NotImplementedT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewUpdateOneNotImplementedResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotImplemented)
+			return enc.Encode(body)
+		case "not-authorized":
+			var res *metadata.UnauthorizedT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnauthorized)
+			return nil
+		default:
+			return encodeError(ctx, w, v)
+		}
+	}
+}
+
+// EncodeUpdateRecordResponse returns an encoder for responses returned by the
+// metadata update_record endpoint.
+func EncodeUpdateRecordResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, interface{}) error {
+	return func(ctx context.Context, w http.ResponseWriter, v interface{}) error {
+		res := v.(*metadataviews.AddMetaRT)
+		enc := encoder(ctx, w)
+		body := NewUpdateRecordResponseBody(res.Projected)
+		w.WriteHeader(http.StatusOK)
+		return enc.Encode(body)
+	}
+}
+
+// DecodeUpdateRecordRequest returns a decoder for requests sent to the
+// metadata update_record endpoint.
+func DecodeUpdateRecordRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		var (
+			body interface{}
+			err  error
+		)
+		err = decoder(r).Decode(&body)
+		if err != nil {
+			if err == io.EOF {
+				return nil, goa.MissingPayloadError()
+			}
+			return nil, goa.DecodePayloadError(err.Error())
+		}
+
+		var (
+			id          string
+			entityID    *string
+			schema      *string
+			policyID    *string
+			contentType *string
+			jwt         string
+
+			params = mux.Vars(r)
+		)
+		id = params["id"]
+		err = goa.MergeErrors(err, goa.ValidateFormat("id", id, goa.FormatURI))
+		entityIDRaw := r.URL.Query().Get("entity-id")
+		if entityIDRaw != "" {
+			entityID = &entityIDRaw
+		}
+		if entityID != nil {
+			err = goa.MergeErrors(err, goa.ValidateFormat("entityID", *entityID, goa.FormatURI))
+		}
+		schemaRaw := r.URL.Query().Get("schema")
+		if schemaRaw != "" {
+			schema = &schemaRaw
+		}
+		if schema != nil {
+			err = goa.MergeErrors(err, goa.ValidateFormat("schema", *schema, goa.FormatURI))
+		}
+		policyIDRaw := r.URL.Query().Get("policy-id")
+		if policyIDRaw != "" {
+			policyID = &policyIDRaw
+		}
+		if policyID != nil {
+			err = goa.MergeErrors(err, goa.ValidateFormat("policyID", *policyID, goa.FormatURI))
+		}
+		contentTypeRaw := r.Header.Get("Content-Type")
+		if contentTypeRaw != "" {
+			contentType = &contentTypeRaw
+		}
+		jwt = r.Header.Get("Authorization")
+		if jwt == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("Authorization", "header"))
+		}
+		if err != nil {
+			return nil, err
+		}
+		payload := NewUpdateRecordPayload(body, id, entityID, schema, policyID, contentType, jwt)
+		if strings.Contains(payload.JWT, " ") {
+			// Remove authorization scheme prefix (e.g. "Bearer")
+			cred := strings.SplitN(payload.JWT, " ", 2)[1]
+			payload.JWT = cred
+		}
+
+		return payload, nil
+	}
+}
+
+// EncodeUpdateRecordError returns an encoder for errors returned by the
+// update_record metadata endpoint.
+func EncodeUpdateRecordError(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder, formatter func(ctx context.Context, err error) goahttp.Statuser) func(context.Context, http.ResponseWriter, error) error {
+	encodeError := goahttp.ErrorEncoder(encoder, formatter)
+	return func(ctx context.Context, w http.ResponseWriter, v error) error {
+		var en goa.GoaErrorNamer
+		if !errors.As(v, &en) {
+			return encodeError(ctx, w, v)
+		}
+		switch en.GoaErrorName() {
+		case "bad-request":
+			var res *metadata.BadRequestT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewUpdateRecordBadRequestResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return enc.Encode(body)
+		case "invalid-credential":
+			var res *metadata.InvalidCredentialsT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return nil
+		case "invalid-parameter":
+			var res *metadata.InvalidParameterValue
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewUpdateRecordInvalidParameterResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return enc.Encode(body)
+		case "invalid-scopes":
+			var res *metadata.InvalidScopesT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewUpdateRecordInvalidScopesResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusForbidden)
+			return enc.Encode(body)
+		case "not-implemented":
+			var res *metadata.NotImplementedT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewUpdateRecordNotImplementedResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotImplemented)
+			return enc.Encode(body)
+		case "not-authorized":
+			var res *metadata.UnauthorizedT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnauthorized)
+			return nil
+		default:
+			return encodeError(ctx, w, v)
+		}
+	}
+}
+
+// EncodeRevokeResponse returns an encoder for responses returned by the
+// metadata revoke endpoint.
+func EncodeRevokeResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, interface{}) error {
+	return func(ctx context.Context, w http.ResponseWriter, v interface{}) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+}
+
+// DecodeRevokeRequest returns a decoder for requests sent to the metadata
+// revoke endpoint.
+func DecodeRevokeRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		var (
+			id  string
+			jwt string
+			err error
+
+			params = mux.Vars(r)
+		)
+		id = params["id"]
+		err = goa.MergeErrors(err, goa.ValidateFormat("id", id, goa.FormatURI))
+		jwt = r.Header.Get("Authorization")
+		if jwt == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("Authorization", "header"))
+		}
+		if err != nil {
+			return nil, err
+		}
+		payload := NewRevokePayload(id, jwt)
+		if strings.Contains(payload.JWT, " ") {
+			// Remove authorization scheme prefix (e.g. "Bearer")
+			cred := strings.SplitN(payload.JWT, " ", 2)[1]
+			payload.JWT = cred
+		}
+
+		return payload, nil
+	}
+}
+
+// EncodeRevokeError returns an encoder for errors returned by the revoke
+// metadata endpoint.
+func EncodeRevokeError(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder, formatter func(ctx context.Context, err error) goahttp.Statuser) func(context.Context, http.ResponseWriter, error) error {
+	encodeError := goahttp.ErrorEncoder(encoder, formatter)
+	return func(ctx context.Context, w http.ResponseWriter, v error) error {
+		var en goa.GoaErrorNamer
+		if !errors.As(v, &en) {
+			return encodeError(ctx, w, v)
+		}
+		switch en.GoaErrorName() {
+		case "bad-request":
+			var res *metadata.BadRequestT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewRevokeBadRequestResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return enc.Encode(body)
+		case "invalid-credential":
+			var res *metadata.InvalidCredentialsT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return nil
+		case "invalid-parameter":
+			var res *metadata.InvalidParameterValue
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewRevokeInvalidParameterResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return enc.Encode(body)
+		case "invalid-scopes":
+			var res *metadata.InvalidScopesT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewRevokeInvalidScopesResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusForbidden)
+			return enc.Encode(body)
+		case "not-implemented":
+			var res *metadata.NotImplementedT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewRevokeNotImplementedResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotImplemented)
+			return enc.Encode(body)
+		case "not-authorized":
+			var res *metadata.UnauthorizedT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnauthorized)
+			return nil
+		default:
+			return encodeError(ctx, w, v)
+		}
+	}
+}
+
+// marshalMetadataviewsMetadataListItemRTViewToMetadataListItemRTResponseBody
+// builds a value of type *MetadataListItemRTResponseBody from a value of type
+// *metadataviews.MetadataListItemRTView.
+func marshalMetadataviewsMetadataListItemRTViewToMetadataListItemRTResponseBody(v *metadataviews.MetadataListItemRTView) *MetadataListItemRTResponseBody {
+	res := &MetadataListItemRTResponseBody{
+		RecordID:      v.RecordID,
+		Entity:        v.Entity,
+		Schema:        v.Schema,
+		Aspect:        v.Aspect,
+		AspectContext: v.AspectContext,
+	}
+
+	return res
+}
+
+// marshalMetadataviewsNavTViewToNavTResponseBody builds a value of type
+// *NavTResponseBody from a value of type *metadataviews.NavTView.
+func marshalMetadataviewsNavTViewToNavTResponseBody(v *metadataviews.NavTView) *NavTResponseBody {
+	res := &NavTResponseBody{
+		Self:  v.Self,
+		First: v.First,
+		Next:  v.Next,
+	}
+
+	return res
+}
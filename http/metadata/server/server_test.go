@@ -0,0 +1,208 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	metadata "github.com/reinventingscience/ivcap-core-api/gen/metadata"
+	client "github.com/reinventingscience/ivcap-core-api/http/metadata"
+	goahttp "goa.design/goa/v3/http"
+	"goa.design/goa/v3/security"
+)
+
+// stubService records the payloads it receives and answers with result or
+// err.
+type stubService struct {
+	payload interface{}
+	result  interface{}
+	err     error
+}
+
+func (s *stubService) JWTAuth(ctx context.Context, token string, _ *security.JWTScheme) (context.Context, error) {
+	return ctx, nil
+}
+
+func (s *stubService) Read(_ context.Context, p *metadata.ReadPayload) (*metadata.MetadataRecordRT, error) {
+	s.payload = p
+	res, _ := s.result.(*metadata.MetadataRecordRT)
+	return res, s.err
+}
+
+func (s *stubService) List(_ context.Context, p *metadata.ListPayload) (*metadata.ListMetaRT, error) {
+	s.payload = p
+	res, _ := s.result.(*metadata.ListMetaRT)
+	return res, s.err
+}
+
+func (s *stubService) Add(_ context.Context, p *metadata.AddPayload) (*metadata.AddMetaRT, error) {
+	s.payload = p
+	res, _ := s.result.(*metadata.AddMetaRT)
+	return res, s.err
+}
+
+func (s *stubService) UpdateOne(_ context.Context, p *metadata.UpdateOnePayload) (*metadata.AddMetaRT, error) {
+	s.payload = p
+	res, _ := s.result.(*metadata.AddMetaRT)
+	return res, s.err
+}
+
+func (s *stubService) UpdateRecord(_ context.Context, p *metadata.UpdateRecordPayload) (*metadata.AddMetaRT, error) {
+	s.payload = p
+	res, _ := s.result.(*metadata.AddMetaRT)
+	return res, s.err
+}
+
+func (s *stubService) Revoke(_ context.Context, p *metadata.RevokePayload) error {
+	s.payload = p
+	return s.err
+}
+
+// newTestClient serves s and returns the generated client for it.
+func newTestClient(t *testing.T, s *stubService) *metadata.Client {
+	mux := goahttp.NewMuxer()
+	Mount(mux, New(metadata.NewEndpoints(s), mux, goahttp.RequestDecoder, goahttp.ResponseEncoder, nil, nil))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	u, _ := url.Parse(srv.URL)
+	c := client.NewClient(u.Scheme, u.Host, srv.Client(), goahttp.RequestEncoder, goahttp.ResponseDecoder, false)
+	return metadata.NewClient(c.Read(), c.List(), c.Add(), c.UpdateOne(), c.UpdateRecord(), c.Revoke())
+}
+
+func str(s string) *string { return &s }
+
+func TestRoundTrip(t *testing.T) {
+	s := &stubService{}
+	c := newTestClient(t, s)
+	ctx := context.Background()
+	id := "urn:ivcap:record:0d6ce3f6-5b1b-4bcd-95a5-2a7ca4b0e3ab"
+	entity := "urn:ivcap:artifact:1"
+	schema := "urn:example:schema:test.1"
+	// Aspects travel as JSON, so they come back with JSON's Go types.
+	aspect := map[string]interface{}{"name": "test", "size": 2.0, "tags": []interface{}{"a", "b"}}
+	added := &metadata.AddMetaRT{RecordID: id}
+	desc := true
+
+	for _, tc := range []struct {
+		name    string
+		call    func() (interface{}, error)
+		payload interface{}
+		result  interface{}
+	}{
+		{
+			"read",
+			func() (interface{}, error) { return c.Read(ctx, &metadata.ReadPayload{ID: id, JWT: "token"}) },
+			&metadata.ReadPayload{ID: id, JWT: "token"},
+			&metadata.MetadataRecordRT{RecordID: &id, Entity: &entity, Schema: &schema, Aspect: aspect, ValidFrom: str("2023-01-01T00:00:00Z"), ValidTo: str("2023-02-01T00:00:00Z"), Asserter: str("urn:ivcap:account:1"), Revoker: str("2023-02-01T00:00:00Z")},
+		},
+		{
+			"list",
+			func() (interface{}, error) {
+				return c.List(ctx, &metadata.ListPayload{EntityID: &entity, Schema: str("urn:example:%"), AspectPath: str("$.name"), AtTime: str("2023-01-01T00:00:00Z"), Limit: 5, Filter: "name = 'test'", OrderBy: "valid_from", OrderDesc: &desc, Page: str("p2"), JWT: "token"})
+			},
+			&metadata.ListPayload{EntityID: &entity, Schema: str("urn:example:%"), AspectPath: str("$.name"), AtTime: str("2023-01-01T00:00:00Z"), Limit: 5, Filter: "name = 'test'", OrderBy: "valid_from", OrderDesc: &desc, Page: str("p2"), JWT: "token"},
+			&metadata.ListMetaRT{
+				Records:    []*metadata.MetadataListItemRT{{RecordID: &id, Entity: &entity, Schema: &schema, Aspect: aspect, AspectContext: str("test")}},
+				EntityID:   &entity,
+				Schema:     str("urn:example:%"),
+				AspectPath: str("$.name"),
+				AtTime:     str("2023-01-01T00:00:00Z"),
+				Links:      &metadata.NavT{Self: str("https://ivcap.test/1/metadata"), Next: str("https://ivcap.test/1/metadata?page=p3")},
+			},
+		},
+		{
+			"add",
+			func() (interface{}, error) {
+				return c.Add(ctx, &metadata.AddPayload{EntityID: entity, Schema: schema, Aspect: aspect, ContentType: "application/json", PolicyID: str("urn:ivcap:policy:1"), JWT: "token"})
+			},
+			&metadata.AddPayload{EntityID: entity, Schema: schema, Aspect: aspect, ContentType: "application/json", PolicyID: str("urn:ivcap:policy:1"), JWT: "token"},
+			added,
+		},
+		{
+			"update one",
+			func() (interface{}, error) {
+				return c.UpdateOne(ctx, &metadata.UpdateOnePayload{EntityID: entity, Schema: schema, Aspect: aspect, ContentType: str("application/json"), PolicyID: str("urn:ivcap:policy:1"), JWT: "token"})
+			},
+			&metadata.UpdateOnePayload{EntityID: entity, Schema: schema, Aspect: aspect, ContentType: str("application/json"), PolicyID: str("urn:ivcap:policy:1"), JWT: "token"},
+			added,
+		},
+		{
+			"update record",
+			func() (interface{}, error) {
+				return c.UpdateRecord(ctx, &metadata.UpdateRecordPayload{ID: id, EntityID: &entity, Schema: &schema, Aspect: aspect, ContentType: str("application/json"), PolicyID: str("urn:ivcap:policy:1"), JWT: "token"})
+			},
+			&metadata.UpdateRecordPayload{ID: id, EntityID: &entity, Schema: &schema, Aspect: aspect, ContentType: str("application/json"), PolicyID: str("urn:ivcap:policy:1"), JWT: "token"},
+			added,
+		},
+		{
+			"revoke",
+			func() (interface{}, error) { return nil, c.Revoke(ctx, &metadata.RevokePayload{ID: &id, JWT: "token"}) },
+			&metadata.RevokePayload{ID: &id, JWT: "token"},
+			nil,
+		},
+	} {
+		s.payload, s.result, s.err = nil, tc.result, nil
+		res, err := tc.call()
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(s.payload, tc.payload) {
+			t.Errorf("%s: server received %+v, want %+v", tc.name, s.payload, tc.payload)
+		}
+		if !reflect.DeepEqual(res, tc.result) {
+			t.Errorf("%s: client received %+v, want %+v", tc.name, res, tc.result)
+		}
+	}
+}
+
+func TestRoundTripErrors(t *testing.T) {
+	s := &stubService{}
+	c := newTestClient(t, s)
+	ctx := context.Background()
+	read := func() error {
+		_, err := c.Read(ctx, &metadata.ReadPayload{ID: "urn:ivcap:record:1", JWT: "token"})
+		return err
+	}
+	add := func() error {
+		_, err := c.Add(ctx, &metadata.AddPayload{EntityID: "urn:ivcap:artifact:1", Schema: "urn:example:schema:test.1", Aspect: map[string]interface{}{}, ContentType: "application/json", JWT: "token"})
+		return err
+	}
+	for _, tc := range []struct {
+		err  error
+		call func() error
+	}{
+		{&metadata.BadRequestT{Message: "bad"}, read},
+		{&metadata.InvalidCredentialsT{}, read},
+		{&metadata.ResourceNotFoundT{ID: "urn:ivcap:record:1", Message: "not found"}, read},
+		{&metadata.NotImplementedT{Message: "later"}, read},
+		{&metadata.UnauthorizedT{}, read},
+		{&metadata.InvalidParameterValue{Name: "schema", Message: "unknown", Value: str("urn:example:schema:test.1")}, add},
+		// The design names the invalid scopes error after its message.
+		{&metadata.InvalidScopesT{ID: str("0d6ce3f6-5b1b-4bcd-95a5-2a7ca4b0e3ab"), Message: "invalid-scopes"}, add},
+	} {
+		s.err = tc.err
+		err := tc.call()
+		got := reflect.New(reflect.TypeOf(tc.err))
+		if !errors.As(err, got.Interface()) || !reflect.DeepEqual(got.Elem().Interface(), tc.err) {
+			t.Errorf("returned %#v, client received %#v", tc.err, err)
+		}
+	}
+}
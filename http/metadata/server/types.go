@@ -0,0 +1,683 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	metadata "github.com/reinventingscience/ivcap-core-api/gen/metadata"
+	metadataviews "github.com/reinventingscience/ivcap-core-api/gen/metadata/views"
+)
+
+// ReadResponseBody is the type of the "metadata" service "read" endpoint HTTP
+// response body.
+type ReadResponseBody struct {
+	// Record ID
+	RecordID *string `form:"record-id,omitempty" json:"record-id,omitempty" xml:"record-id,omitempty"`
+	// Entity ID
+	Entity *string `form:"entity,omitempty" json:"entity,omitempty" xml:"entity,omitempty"`
+	// Schema ID
+	Schema *string `form:"schema,omitempty" json:"schema,omitempty" xml:"schema,omitempty"`
+	// Attached metadata aspect
+	Aspect interface{} `form:"aspect,omitempty" json:"aspect,omitempty" xml:"aspect,omitempty"`
+	// Time this record was asserted
+	ValidFrom *string `form:"valid-from,omitempty" json:"valid-from,omitempty" xml:"valid-from,omitempty"`
+	// Time this record was revoked
+	ValidTo *string `form:"valid-to,omitempty" json:"valid-to,omitempty" xml:"valid-to,omitempty"`
+	// Entity asserting this metadata record at 'valid-from'
+	Asserter *string `form:"asserter,omitempty" json:"asserter,omitempty" xml:"asserter,omitempty"`
+	// Entity revoking this record at 'valid-to'
+	Revoker *string `form:"revoker,omitempty" json:"revoker,omitempty" xml:"revoker,omitempty"`
+}
+
+// ListResponseBody is the type of the "metadata" service "list" endpoint HTTP
+// response body.
+type ListResponseBody struct {
+	// List of metadata records
+	Records []*MetadataListItemRTResponseBody `form:"records" json:"records" xml:"records"`
+	// Entity for which to request metadata
+	EntityID *string `form:"entity-id,omitempty" json:"entity-id,omitempty" xml:"entity-id,omitempty"`
+	// Optional schema to filter on
+	Schema *string `form:"schema,omitempty" json:"schema,omitempty" xml:"schema,omitempty"`
+	// Optional json path to further filter on returned list
+	AspectPath *string `form:"aspect-path,omitempty" json:"aspect-path,omitempty" xml:"aspect-path,omitempty"`
+	// Time at which this list was valid
+	AtTime *string `form:"at-time,omitempty" json:"at-time,omitempty" xml:"at-time,omitempty"`
+	// Navigation links
+	Links *NavTResponseBody `form:"links" json:"links" xml:"links"`
+}
+
+// AddResponseBody is the type of the "metadata" service "add" endpoint HTTP
+// response body.
+type AddResponseBody struct {
+	// Reference to record created
+	RecordID string `form:"record-id" json:"record-id" xml:"record-id"`
+}
+
+// UpdateOneResponseBody is the type of the "metadata" service "update_one"
+// endpoint HTTP response body.
+type UpdateOneResponseBody struct {
+	// Reference to record created
+	RecordID string `form:"record-id" json:"record-id" xml:"record-id"`
+}
+
+// UpdateRecordResponseBody is the type of the "metadata" service
+// "update_record" endpoint HTTP response body.
+type UpdateRecordResponseBody struct {
+	// Reference to record created
+	RecordID string `form:"record-id" json:"record-id" xml:"record-id"`
+}
+
+// ReadBadRequestResponseBody is the type of the "metadata" service "read"
+// endpoint HTTP response body for the "bad-request" error.
+type ReadBadRequestResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ReadInvalidScopesResponseBody is the type of the "metadata" service "read"
+// endpoint HTTP response body for the "invalid-scopes" error.
+type ReadInvalidScopesResponseBody struct {
+	// ID of involved resource
+	ID *string `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ReadNotImplementedResponseBody is the type of the "metadata" service "read"
+// endpoint HTTP response body for the "not-implemented" error.
+type ReadNotImplementedResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ReadNotFoundResponseBody is the type of the "metadata" service "read"
+// endpoint HTTP response body for the "not-found" error.
+type ReadNotFoundResponseBody struct {
+	// ID of missing resource
+	ID string `form:"id" json:"id" xml:"id"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ListBadRequestResponseBody is the type of the "metadata" service "list"
+// endpoint HTTP response body for the "bad-request" error.
+type ListBadRequestResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ListInvalidParameterResponseBody is the type of the "metadata" service
+// "list" endpoint HTTP response body for the "invalid-parameter" error.
+type ListInvalidParameterResponseBody struct {
+	// message describing expected type or pattern.
+	Message string `form:"message" json:"message" xml:"message"`
+	// name of parameter.
+	Name string `form:"name" json:"name" xml:"name"`
+	// provided parameter value.
+	Value *string `form:"value,omitempty" json:"value,omitempty" xml:"value,omitempty"`
+}
+
+// ListInvalidScopesResponseBody is the type of the "metadata" service "list"
+// endpoint HTTP response body for the "invalid-scopes" error.
+type ListInvalidScopesResponseBody struct {
+	// ID of involved resource
+	ID *string `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ListNotImplementedResponseBody is the type of the "metadata" service "list"
+// endpoint HTTP response body for the "not-implemented" error.
+type ListNotImplementedResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// AddBadRequestResponseBody is the type of the "metadata" service "add"
+// endpoint HTTP response body for the "bad-request" error.
+type AddBadRequestResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// AddInvalidParameterResponseBody is the type of the "metadata" service "add"
+// endpoint HTTP response body for the "invalid-parameter" error.
+type AddInvalidParameterResponseBody struct {
+	// message describing expected type or pattern.
+	Message string `form:"message" json:"message" xml:"message"`
+	// name of parameter.
+	Name string `form:"name" json:"name" xml:"name"`
+	// provided parameter value.
+	Value *string `form:"value,omitempty" json:"value,omitempty" xml:"value,omitempty"`
+}
+
+// AddInvalidScopesResponseBody is the type of the "metadata" service "add"
+// endpoint HTTP response body for the "invalid-scopes" error.
+type AddInvalidScopesResponseBody struct {
+	// ID of involved resource
+	ID *string `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// AddNotImplementedResponseBody is the type of the "metadata" service "add"
+// endpoint HTTP response body for the "not-implemented" error.
+type AddNotImplementedResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// UpdateOneBadRequestResponseBody is the type of the "metadata" service
+// "update_one" endpoint HTTP response body for the "bad-request" error.
+type UpdateOneBadRequestResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// UpdateOneInvalidParameterResponseBody is the type of the "metadata" service
+// "update_one" endpoint HTTP response body for the "invalid-parameter" error.
+type UpdateOneInvalidParameterResponseBody struct {
+	// message describing expected type or pattern.
+	Message string `form:"message" json:"message" xml:"message"`
+	// name of parameter.
+	Name string `form:"name" json:"name" xml:"name"`
+	// provided parameter value.
+	Value *string `form:"value,omitempty" json:"value,omitempty" xml:"value,omitempty"`
+}
+
+// UpdateOneInvalidScopesResponseBody is the type of the "metadata" service
+// "update_one" endpoint HTTP response body for the "invalid-scopes" error.
+type UpdateOneInvalidScopesResponseBody struct {
+	// ID of involved resource
+	ID *string `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// UpdateOneNotImplementedResponseBody is the type of the "metadata" service
+// "update_one" endpoint HTTP response body for the "not-implemented" error.
+type UpdateOneNotImplementedResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// UpdateRecordBadRequestResponseBody is the type of the "metadata" service
+// "update_record" endpoint HTTP response body for the "bad-request" error.
+type UpdateRecordBadRequestResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// UpdateRecordInvalidParameterResponseBody is the type of the "metadata"
+// service "update_record" endpoint HTTP response body for the
+// "invalid-parameter" error.
+type UpdateRecordInvalidParameterResponseBody struct {
+	// message describing expected type or pattern.
+	Message string `form:"message" json:"message" xml:"message"`
+	// name of parameter.
+	Name string `form:"name" json:"name" xml:"name"`
+	// provided parameter value.
+	Value *string `form:"value,omitempty" json:"value,omitempty" xml:"value,omitempty"`
+}
+
+// UpdateRecordInvalidScopesResponseBody is the type of the "metadata" service
+// "update_record" endpoint HTTP response body for the "invalid-scopes" error.
+type UpdateRecordInvalidScopesResponseBody struct {
+	// ID of involved resource
+	ID *string `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// UpdateRecordNotImplementedResponseBody is the type of the "metadata" service
+// "update_record" endpoint HTTP response body for the "not-implemented" error.
+type UpdateRecordNotImplementedResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// RevokeBadRequestResponseBody is the type of the "metadata" service "revoke"
+// endpoint HTTP response body for the "bad-request" error.
+type RevokeBadRequestResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// RevokeInvalidParameterResponseBody is the type of the "metadata" service
+// "revoke" endpoint HTTP response body for the "invalid-parameter" error.
+type RevokeInvalidParameterResponseBody struct {
+	// message describing expected type or pattern.
+	Message string `form:"message" json:"message" xml:"message"`
+	// name of parameter.
+	Name string `form:"name" json:"name" xml:"name"`
+	// provided parameter value.
+	Value *string `form:"value,omitempty" json:"value,omitempty" xml:"value,omitempty"`
+}
+
+// RevokeInvalidScopesResponseBody is the type of the "metadata" service
+// "revoke" endpoint HTTP response body for the "invalid-scopes" error.
+type RevokeInvalidScopesResponseBody struct {
+	// ID of involved resource
+	ID *string `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// RevokeNotImplementedResponseBody is the type of the "metadata" service
+// "revoke" endpoint HTTP response body for the "not-implemented" error.
+type RevokeNotImplementedResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// MetadataListItemRTResponseBody is used to define fields on response body
+// types.
+type MetadataListItemRTResponseBody struct {
+	// Record ID
+	RecordID *string `form:"record-id,omitempty" json:"record-id,omitempty" xml:"record-id,omitempty"`
+	// Entity ID
+	Entity *string `form:"entity,omitempty" json:"entity,omitempty" xml:"entity,omitempty"`
+	// Schema ID
+	Schema *string `form:"schema,omitempty" json:"schema,omitempty" xml:"schema,omitempty"`
+	// Attached metadata aspect
+	Aspect interface{} `form:"aspect,omitempty" json:"aspect,omitempty" xml:"aspect,omitempty"`
+	// If aspectPath was defined, this is what matched the query
+	AspectContext *string `form:"aspectContext,omitempty" json:"aspectContext,omitempty" xml:"aspectContext,omitempty"`
+}
+
+// NavTResponseBody is used to define fields on response body types.
+type NavTResponseBody struct {
+	Self  *string `form:"self,omitempty" json:"self,omitempty" xml:"self,omitempty"`
+	First *string `form:"first,omitempty" json:"first,omitempty" xml:"first,omitempty"`
+	Next  *string `form:"next,omitempty" json:"next,omitempty" xml:"next,omitempty"`
+}
+
+// NewReadResponseBody builds the HTTP response body from the result of the
+// "read" endpoint of the "metadata" service.
+func NewReadResponseBody(res *metadataviews.MetadataRecordRTView) *ReadResponseBody {
+	body := &ReadResponseBody{
+		RecordID:  res.RecordID,
+		Entity:    res.Entity,
+		Schema:    res.Schema,
+		Aspect:    res.Aspect,
+		ValidFrom: res.ValidFrom,
+		ValidTo:   res.ValidTo,
+		Asserter:  res.Asserter,
+		Revoker:   res.Revoker,
+	}
+	return body
+}
+
+// NewListResponseBody builds the HTTP response body from the result of the
+// "list" endpoint of the "metadata" service.
+func NewListResponseBody(res *metadataviews.ListMetaRTView) *ListResponseBody {
+	body := &ListResponseBody{
+		EntityID:   res.EntityID,
+		Schema:     res.Schema,
+		AspectPath: res.AspectPath,
+		AtTime:     res.AtTime,
+	}
+	if res.Records != nil {
+		body.Records = make([]*MetadataListItemRTResponseBody, len(res.Records))
+		for i, val := range res.Records {
+			body.Records[i] = marshalMetadataviewsMetadataListItemRTViewToMetadataListItemRTResponseBody(val)
+		}
+	}
+	if res.Links != nil {
+		body.Links = marshalMetadataviewsNavTViewToNavTResponseBody(res.Links)
+	}
+	return body
+}
+
+// NewAddResponseBody builds the HTTP response body from the result of the
+// "add" endpoint of the "metadata" service.
+func NewAddResponseBody(res *metadataviews.AddMetaRTView) *AddResponseBody {
+	body := &AddResponseBody{
+		RecordID: *res.RecordID,
+	}
+	return body
+}
+
+// NewUpdateOneResponseBody builds the HTTP response body from the result of
+// the "update_one" endpoint of the "metadata" service.
+func NewUpdateOneResponseBody(res *metadataviews.AddMetaRTView) *UpdateOneResponseBody {
+	body := &UpdateOneResponseBody{
+		RecordID: *res.RecordID,
+	}
+	return body
+}
+
+// NewUpdateRecordResponseBody builds the HTTP response body from the result of
+// the "update_record" endpoint of the "metadata" service.
+func NewUpdateRecordResponseBody(res *metadataviews.AddMetaRTView) *UpdateRecordResponseBody {
+	body := &UpdateRecordResponseBody{
+		RecordID: *res.RecordID,
+	}
+	return body
+}
+
+// NewReadBadRequestResponseBody builds the HTTP response body from the result
+// of the "read" endpoint of the "metadata" service.
+func NewReadBadRequestResponseBody(res *metadata.BadRequestT) *ReadBadRequestResponseBody {
+	body := &ReadBadRequestResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewReadInvalidScopesResponseBody builds the HTTP response body from the
+// result of the "read" endpoint of the "metadata" service.
+func NewReadInvalidScopesResponseBody(res *metadata.InvalidScopesT) *ReadInvalidScopesResponseBody {
+	body := &ReadInvalidScopesResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewReadNotImplementedResponseBody builds the HTTP response body from the
+// result of the "read" endpoint of the "metadata" service.
+func NewReadNotImplementedResponseBody(res *metadata.NotImplementedT) *ReadNotImplementedResponseBody {
+	body := &ReadNotImplementedResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewReadNotFoundResponseBody builds the HTTP response body from the result of
+// the "read" endpoint of the "metadata" service.
+func NewReadNotFoundResponseBody(res *metadata.ResourceNotFoundT) *ReadNotFoundResponseBody {
+	body := &ReadNotFoundResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewListBadRequestResponseBody builds the HTTP response body from the result
+// of the "list" endpoint of the "metadata" service.
+func NewListBadRequestResponseBody(res *metadata.BadRequestT) *ListBadRequestResponseBody {
+	body := &ListBadRequestResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewListInvalidParameterResponseBody builds the HTTP response body from the
+// result of the "list" endpoint of the "metadata" service.
+func NewListInvalidParameterResponseBody(res *metadata.InvalidParameterValue) *ListInvalidParameterResponseBody {
+	body := &ListInvalidParameterResponseBody{
+		Message: res.Message,
+		Name:    res.Name,
+		Value:   res.Value,
+	}
+	return body
+}
+
+// NewListInvalidScopesResponseBody builds the HTTP response body from the
+// result of the "list" endpoint of the "metadata" service.
+func NewListInvalidScopesResponseBody(res *metadata.InvalidScopesT) *ListInvalidScopesResponseBody {
+	body := &ListInvalidScopesResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewListNotImplementedResponseBody builds the HTTP response body from the
+// result of the "list" endpoint of the "metadata" service.
+func NewListNotImplementedResponseBody(res *metadata.NotImplementedT) *ListNotImplementedResponseBody {
+	body := &ListNotImplementedResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewAddBadRequestResponseBody builds the HTTP response body from the result
+// of the "add" endpoint of the "metadata" service.
+func NewAddBadRequestResponseBody(res *metadata.BadRequestT) *AddBadRequestResponseBody {
+	body := &AddBadRequestResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewAddInvalidParameterResponseBody builds the HTTP response body from the
+// result of the "add" endpoint of the "metadata" service.
+func NewAddInvalidParameterResponseBody(res *metadata.InvalidParameterValue) *AddInvalidParameterResponseBody {
+	body := &AddInvalidParameterResponseBody{
+		Message: res.Message,
+		Name:    res.Name,
+		Value:   res.Value,
+	}
+	return body
+}
+
+// NewAddInvalidScopesResponseBody builds the HTTP response body from the
+// result of the "add" endpoint of the "metadata" service.
+func NewAddInvalidScopesResponseBody(res *metadata.InvalidScopesT) *AddInvalidScopesResponseBody {
+	body := &AddInvalidScopesResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewAddNotImplementedResponseBody builds the HTTP response body from the
+// result of the "add" endpoint of the "metadata" service.
+func NewAddNotImplementedResponseBody(res *metadata.NotImplementedT) *AddNotImplementedResponseBody {
+	body := &AddNotImplementedResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewUpdateOneBadRequestResponseBody builds the HTTP response body from the
+// result of the "update_one" endpoint of the "metadata" service.
+func NewUpdateOneBadRequestResponseBody(res *metadata.BadRequestT) *UpdateOneBadRequestResponseBody {
+	body := &UpdateOneBadRequestResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewUpdateOneInvalidParameterResponseBody builds the HTTP response body from
+// the result of the "update_one" endpoint of the "metadata" service.
+func NewUpdateOneInvalidParameterResponseBody(res *metadata.InvalidParameterValue) *UpdateOneInvalidParameterResponseBody {
+	body := &UpdateOneInvalidParameterResponseBody{
+		Message: res.Message,
+		Name:    res.Name,
+		Value:   res.Value,
+	}
+	return body
+}
+
+// NewUpdateOneInvalidScopesResponseBody builds the HTTP response body from the
+// result of the "update_one" endpoint of the "metadata" service.
+func NewUpdateOneInvalidScopesResponseBody(res *metadata.InvalidScopesT) *UpdateOneInvalidScopesResponseBody {
+	body := &UpdateOneInvalidScopesResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewUpdateOneNotImplementedResponseBody builds the HTTP response body from
+// the result of the "update_one" endpoint of the "metadata" service.
+func NewUpdateOneNotImplementedResponseBody(res *metadata.NotImplementedT) *UpdateOneNotImplementedResponseBody {
+	body := &UpdateOneNotImplementedResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewUpdateRecordBadRequestResponseBody builds the HTTP response body from the
+// result of the "update_record" endpoint of the "metadata" service.
+func NewUpdateRecordBadRequestResponseBody(res *metadata.BadRequestT) *UpdateRecordBadRequestResponseBody {
+	body := &UpdateRecordBadRequestResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewUpdateRecordInvalidParameterResponseBody builds the HTTP response body
+// from the result of the "update_record" endpoint of the "metadata" service.
+func NewUpdateRecordInvalidParameterResponseBody(res *metadata.InvalidParameterValue) *UpdateRecordInvalidParameterResponseBody {
+	body := &UpdateRecordInvalidParameterResponseBody{
+		Message: res.Message,
+		Name:    res.Name,
+		Value:   res.Value,
+	}
+	return body
+}
+
+// NewUpdateRecordInvalidScopesResponseBody builds the HTTP response body from
+// the result of the "update_record" endpoint of the "metadata" service.
+func NewUpdateRecordInvalidScopesResponseBody(res *metadata.InvalidScopesT) *UpdateRecordInvalidScopesResponseBody {
+	body := &UpdateRecordInvalidScopesResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewUpdateRecordNotImplementedResponseBody builds the HTTP response body from
+// the result of the "update_record" endpoint of the "metadata" service.
+func NewUpdateRecordNotImplementedResponseBody(res *metadata.NotImplementedT) *UpdateRecordNotImplementedResponseBody {
+	body := &UpdateRecordNotImplementedResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewRevokeBadRequestResponseBody builds the HTTP response body from the
+// result of the "revoke" endpoint of the "metadata" service.
+func NewRevokeBadRequestResponseBody(res *metadata.BadRequestT) *RevokeBadRequestResponseBody {
+	body := &RevokeBadRequestResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewRevokeInvalidParameterResponseBody builds the HTTP response body from the
+// result of the "revoke" endpoint of the "metadata" service.
+func NewRevokeInvalidParameterResponseBody(res *metadata.InvalidParameterValue) *RevokeInvalidParameterResponseBody {
+	body := &RevokeInvalidParameterResponseBody{
+		Message: res.Message,
+		Name:    res.Name,
+		Value:   res.Value,
+	}
+	return body
+}
+
+// NewRevokeInvalidScopesResponseBody builds the HTTP response body from the
+// result of the "revoke" endpoint of the "metadata" service.
+func NewRevokeInvalidScopesResponseBody(res *metadata.InvalidScopesT) *RevokeInvalidScopesResponseBody {
+	body := &RevokeInvalidScopesResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewRevokeNotImplementedResponseBody builds the HTTP response body from the
+// result of the "revoke" endpoint of the "metadata" service.
+func NewRevokeNotImplementedResponseBody(res *metadata.NotImplementedT) *RevokeNotImplementedResponseBody {
+	body := &RevokeNotImplementedResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewReadPayload builds a metadata service read endpoint payload.
+func NewReadPayload(id string, jwt string) *metadata.ReadPayload {
+	v := &metadata.ReadPayload{}
+	v.ID = id
+	v.JWT = jwt
+
+	return v
+}
+
+// NewListPayload builds a metadata service list endpoint payload.
+func NewListPayload(entityID *string, schema *string, aspectPath *string, atTime *string, limit int, filter string, orderBy string, orderDesc *bool, page *string, jwt string) *metadata.ListPayload {
+	v := &metadata.ListPayload{}
+	v.EntityID = entityID
+	v.Schema = schema
+	v.AspectPath = aspectPath
+	v.AtTime = atTime
+	v.Limit = limit
+	v.Filter = filter
+	v.OrderBy = orderBy
+	v.OrderDesc = orderDesc
+	v.Page = page
+	v.JWT = jwt
+
+	return v
+}
+
+// NewAddPayload builds a metadata service add endpoint payload.
+func NewAddPayload(body interface{}, entityID string, schema string, policyID *string, contentType string, jwt string) *metadata.AddPayload {
+	v := body
+	res := &metadata.AddPayload{
+		Aspect: v,
+	}
+	res.EntityID = entityID
+	res.Schema = schema
+	res.PolicyID = policyID
+	res.ContentType = contentType
+	res.JWT = jwt
+
+	return res
+}
+
+// NewUpdateOnePayload builds a metadata service update_one endpoint payload.
+func NewUpdateOnePayload(body interface{}, entityID string, schema string, policyID *string, contentType *string, jwt string) *metadata.UpdateOnePayload {
+	v := body
+	res := &metadata.UpdateOnePayload{
+		Aspect: v,
+	}
+	res.EntityID = entityID
+	res.Schema = schema
+	res.PolicyID = policyID
+	res.ContentType = contentType
+	res.JWT = jwt
+
+	return res
+}
+
+// NewUpdateRecordPayload builds a metadata service update_record endpoint
+// payload.
+func NewUpdateRecordPayload(body interface{}, id string, entityID *string, schema *string, policyID *string, contentType *string, jwt string) *metadata.UpdateRecordPayload {
+	v := body
+	res := &metadata.UpdateRecordPayload{
+		Aspect: v,
+	}
+	res.ID = id
+	res.EntityID = entityID
+	res.Schema = schema
+	res.PolicyID = policyID
+	res.ContentType = contentType
+	res.JWT = jwt
+
+	return res
+}
+
+// NewRevokePayload builds a metadata service revoke endpoint payload.
+func NewRevokePayload(id string, jwt string) *metadata.RevokePayload {
+	v := &metadata.RevokePayload{}
+	v.ID = &id
+	v.JWT = jwt
+
+	return v
+}
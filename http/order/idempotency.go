@@ -0,0 +1,71 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"net/http"
+
+	goahttp "goa.design/goa/v3/http"
+)
+
+// IdempotencyKeyHeader is the request header carrying an idempotency key.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey returns a copy of ctx making the order create requests
+// sent with it carry key in the Idempotency-Key header, see
+// SendIdempotencyKeys. The service performs requests with the same key at
+// most once. On the server side, the key of a request is available in the
+// context passed to the service if the handler was wrapped with
+// DecodeIdempotencyKey.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// IdempotencyKey returns the key set with WithIdempotencyKey, or "".
+func IdempotencyKey(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKey{}).(string)
+	return key
+}
+
+// SendIdempotencyKeys makes the create requests of c carry the idempotency
+// key of their context, if any.
+func SendIdempotencyKeys(c *Client) {
+	c.CreateDoer = idempotencyDoer{c.CreateDoer}
+}
+
+type idempotencyDoer struct {
+	doer goahttp.Doer
+}
+
+func (d idempotencyDoer) Do(req *http.Request) (*http.Response, error) {
+	if key := IdempotencyKey(req.Context()); key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	return d.doer.Do(req)
+}
+
+// DecodeIdempotencyKey returns a handler passing the Idempotency-Key header
+// of requests to h in their context, see IdempotencyKey.
+func DecodeIdempotencyKey(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key := r.Header.Get(IdempotencyKeyHeader); key != "" {
+			r = r.WithContext(WithIdempotencyKey(r.Context(), key))
+		}
+		h.ServeHTTP(w, r)
+	})
+}
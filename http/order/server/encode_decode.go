@@ -0,0 +1,940 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+	orderviews "github.com/reinventingscience/ivcap-core-api/gen/order/views"
+	goahttp "goa.design/goa/v3/http"
+	goa "goa.design/goa/v3/pkg"
+)
+
+// EncodeReadResponse returns an encoder for responses returned by the order
+// read endpoint.
+func EncodeReadResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, interface{}) error {
+	return func(ctx context.Context, w http.ResponseWriter, v interface{}) error {
+		res := v.(*orderviews.OrderStatusRT)
+		w.Header().Set("goa-view", res.View)
+		enc := encoder(ctx, w)
+		var body interface{}
+		switch res.View {
+		case "default", "":
+			body = NewReadResponseBody(res.Projected)
+		case "tiny":
+			body = NewReadResponseBodyTiny(res.Projected)
+		}
+		w.WriteHeader(http.StatusOK)
+		return enc.Encode(body)
+	}
+}
+
+// DecodeReadRequest returns a decoder for requests sent to the order read
+// endpoint.
+func DecodeReadRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		var (
+			id  string
+			jwt string
+			err error
+
+			params = mux.Vars(r)
+		)
+		id = params["id"]
+		jwt = r.Header.Get("Authorization")
+		if jwt == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("Authorization", "header"))
+		}
+		if err != nil {
+			return nil, err
+		}
+		payload := NewReadPayload(id, jwt)
+		if strings.Contains(payload.JWT, " ") {
+			// Remove authorization scheme prefix (e.g. "Bearer")
+			cred := strings.SplitN(payload.JWT, " ", 2)[1]
+			payload.JWT = cred
+		}
+
+		return payload, nil
+	}
+}
+
+// EncodeReadError returns an encoder for errors returned by the read order
+// endpoint.
+func EncodeReadError(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder, formatter func(ctx context.Context, err error) goahttp.Statuser) func(context.Context, http.ResponseWriter, error) error {
+	encodeError := goahttp.ErrorEncoder(encoder, formatter)
+	return func(ctx context.Context, w http.ResponseWriter, v error) error {
+		var en goa.GoaErrorNamer
+		if !errors.As(v, &en) {
+			return encodeError(ctx, w, v)
+		}
+		switch en.GoaErrorName() {
+		case "bad-request":
+			var res *order.BadRequestT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewReadBadRequestResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return enc.Encode(body)
+		case "invalid-credential":
+			var res *order.InvalidCredentialsT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return nil
+		case "invalid-scopes":
+			var res *order.InvalidScopesT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewReadInvalidScopesResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusForbidden)
+			return enc.Encode(body)
+		case "not-implemented":
+			var res *order.NotImplementedT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewReadNotImplementedResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotImplemented)
+			return enc.Encode(body)
+		case "not-found":
+			var res *order.ResourceNotFoundT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewReadNotFoundResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotFound)
+			return enc.Encode(body)
+		case "not-authorized":
+			var res *order.UnauthorizedT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnauthorized)
+			return nil
+		default:
+			return encodeError(ctx, w, v)
+		}
+	}
+}
+
+// EncodeListResponse returns an encoder for responses returned by the order
+// list endpoint.
+func EncodeListResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, interface{}) error {
+	return func(ctx context.Context, w http.ResponseWriter, v interface{}) error {
+		res := v.(*orderviews.OrderListRT)
+		enc := encoder(ctx, w)
+		body := NewListResponseBody(res.Projected)
+		w.WriteHeader(http.StatusOK)
+		return enc.Encode(body)
+	}
+}
+
+// DecodeListRequest returns a decoder for requests sent to the order list
+// endpoint.
+func DecodeListRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		var (
+			limit     int
+			page      *string
+			filter    *string
+			orderBy   *string
+			orderDesc bool
+			atTime    *string
+			jwt       string
+			err       error
+		)
+		{
+			limitRaw := r.URL.Query().Get("limit")
+			if limitRaw == "" {
+				limit = 10
+			} else {
+				v, err2 := strconv.ParseInt(limitRaw, 10, strconv.IntSize)
+				if err2 != nil {
+					err = goa.MergeErrors(err, goa.InvalidFieldTypeError("limit", limitRaw, "integer"))
+				}
+				limit = int(v)
+			}
+		}
+		if limit < 1 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("limit", limit, 1, true))
+		}
+		if limit > 50 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("limit", limit, 50, false))
+		}
+		pageRaw := r.URL.Query().Get("page")
+		if pageRaw != "" {
+			page = &pageRaw
+		}
+		filterRaw := r.URL.Query().Get("filter")
+		if filterRaw != "" {
+			filter = &filterRaw
+		}
+		orderByRaw := r.URL.Query().Get("order-by")
+		if orderByRaw != "" {
+			orderBy = &orderByRaw
+		}
+		{
+			orderDescRaw := r.URL.Query().Get("order-desc")
+			if orderDescRaw != "" {
+				v, err2 := strconv.ParseBool(orderDescRaw)
+				if err2 != nil {
+					err = goa.MergeErrors(err, goa.InvalidFieldTypeError("orderDesc", orderDescRaw, "boolean"))
+				}
+				orderDesc = v
+			}
+		}
+		atTimeRaw := r.URL.Query().Get("at-time")
+		if atTimeRaw != "" {
+			atTime = &atTimeRaw
+		}
+		if atTime != nil {
+			err = goa.MergeErrors(err, goa.ValidateFormat("atTime", *atTime, goa.FormatDateTime))
+		}
+		jwt = r.Header.Get("Authorization")
+		if jwt == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("Authorization", "header"))
+		}
+		if err != nil {
+			return nil, err
+		}
+		payload := NewListPayload(limit, page, filter, orderBy, orderDesc, atTime, jwt)
+		if strings.Contains(payload.JWT, " ") {
+			// Remove authorization scheme prefix (e.g. "Bearer")
+			cred := strings.SplitN(payload.JWT, " ", 2)[1]
+			payload.JWT = cred
+		}
+
+		return payload, nil
+	}
+}
+
+// EncodeListError returns an encoder for errors returned by the list order
+// endpoint.
+func EncodeListError(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder, formatter func(ctx context.Context, err error) goahttp.Statuser) func(context.Context, http.ResponseWriter, error) error {
+	encodeError := goahttp.ErrorEncoder(encoder, formatter)
+	return func(ctx context.Context, w http.ResponseWriter, v error) error {
+		var en goa.GoaErrorNamer
+		if !errors.As(v, &en) {
+			return encodeError(ctx, w, v)
+		}
+		switch en.GoaErrorName() {
+		case "bad-request":
+			var res *order.BadRequestT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewListBadRequestResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return enc.Encode(body)
+		case "invalid-credential":
+			var res *order.InvalidCredentialsT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return nil
+		case "invalid-parameter":
+			var res *order.InvalidParameterValue
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewListInvalidParameterResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return enc.Encode(body)
+		case "invalid-scopes":
+			var res *order.InvalidScopesT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewListInvalidScopesResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusForbidden)
+			return enc.Encode(body)
+		case "not-implemented":
+			var res *order.NotImplementedT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewListNotImplementedResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotImplemented)
+			return enc.Encode(body)
+		case "not-authorized":
+			var res *order.UnauthorizedT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnauthorized)
+			return nil
+		default:
+			return encodeError(ctx, w, v)
+		}
+	}
+}
+
+// EncodeCreateResponse returns an encoder for responses returned by the order
+// create endpoint.
+func EncodeCreateResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, interface{}) error {
+	return func(ctx context.Context, w http.ResponseWriter, v interface{}) error {
+		res := v.(*orderviews.OrderStatusRT)
+		w.Header().Set("goa-view", res.View)
+		enc := encoder(ctx, w)
+		var body interface{}
+		switch res.View {
+		case "default", "":
+			body = NewCreateResponseBody(res.Projected)
+		case "tiny":
+			body = NewCreateResponseBodyTiny(res.Projected)
+		}
+		w.WriteHeader(http.StatusOK)
+		return enc.Encode(body)
+	}
+}
+
+// DecodeCreateRequest returns a decoder for requests sent to the order create
+// endpoint.
+func DecodeCreateRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		var (
+			body CreateRequestBody
+			err  error
+		)
+		err = decoder(r).Decode(&body)
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			} else {
+				return nil, goa.DecodePayloadError(err.Error())
+			}
+		}
+		err = ValidateCreateRequestBody(&body)
+		if err != nil {
+			return nil, err
+		}
+
+		var (
+			jwt string
+		)
+		jwt = r.Header.Get("Authorization")
+		if jwt == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("Authorization", "header"))
+		}
+		if err != nil {
+			return nil, err
+		}
+		payload := NewCreatePayload(&body, jwt)
+		if strings.Contains(payload.JWT, " ") {
+			// Remove authorization scheme prefix (e.g. "Bearer")
+			cred := strings.SplitN(payload.JWT, " ", 2)[1]
+			payload.JWT = cred
+		}
+
+		return payload, nil
+	}
+}
+
+// EncodeCreateError returns an encoder for errors returned by the create order
+// endpoint.
+func EncodeCreateError(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder, formatter func(ctx context.Context, err error) goahttp.Statuser) func(context.Context, http.ResponseWriter, error) error {
+	encodeError := goahttp.ErrorEncoder(encoder, formatter)
+	return func(ctx context.Context, w http.ResponseWriter, v error) error {
+		var en goa.GoaErrorNamer
+		if !errors.As(v, &en) {
+			return encodeError(ctx, w, v)
+		}
+		switch en.GoaErrorName() {
+		case "bad-request":
+			var res *order.BadRequestT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewCreateBadRequestResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return enc.Encode(body)
+		case "invalid-credential":
+			var res *order.InvalidCredentialsT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return nil
+		case "invalid-parameter":
+			var res *order.InvalidParameterValue
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewCreateInvalidParameterResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return enc.Encode(body)
+		case "invalid-scopes":
+			var res *order.InvalidScopesT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewCreateInvalidScopesResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusForbidden)
+			return enc.Encode(body)
+		case "not-implemented":
+			var res *order.NotImplementedT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewCreateNotImplementedResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotImplemented)
+			return enc.Encode(body)
+		case "not-found":
+			var res *order.ResourceNotFoundT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewCreateNotFoundResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotFound)
+			return enc.Encode(body)
+		case "not-available":
+			var res *order.ServiceNotAvailableT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return nil
+		case "not-authorized":
+			var res *order.UnauthorizedT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnauthorized)
+			return nil
+		default:
+			return encodeError(ctx, w, v)
+		}
+	}
+}
+
+// EncodeLogsResponse returns an encoder for responses returned by the order
+// logs endpoint.
+func EncodeLogsResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, interface{}) error {
+	return func(ctx context.Context, w http.ResponseWriter, v interface{}) error {
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}
+}
+
+// DecodeLogsRequest returns a decoder for requests sent to the order logs
+// endpoint.
+func DecodeLogsRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		var (
+			body LogsRequestBody
+			err  error
+		)
+		err = decoder(r).Decode(&body)
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			} else {
+				return nil, goa.DecodePayloadError(err.Error())
+			}
+		}
+		err = ValidateLogsRequestBody(&body)
+		if err != nil {
+			return nil, err
+		}
+
+		var (
+			jwt string
+		)
+		jwt = r.Header.Get("Authorization")
+		if jwt == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("Authorization", "header"))
+		}
+		if err != nil {
+			return nil, err
+		}
+		payload := NewLogsPayload(&body, jwt)
+		if strings.Contains(payload.JWT, " ") {
+			// Remove authorization scheme prefix (e.g. "Bearer")
+			cred := strings.SplitN(payload.JWT, " ", 2)[1]
+			payload.JWT = cred
+		}
+
+		return payload, nil
+	}
+}
+
+// EncodeLogsError returns an encoder for errors returned by the logs order
+// endpoint.
+func EncodeLogsError(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder, formatter func(ctx context.Context, err error) goahttp.Statuser) func(context.Context, http.ResponseWriter, error) error {
+	encodeError := goahttp.ErrorEncoder(encoder, formatter)
+	return func(ctx context.Context, w http.ResponseWriter, v error) error {
+		var en goa.GoaErrorNamer
+		if !errors.As(v, &en) {
+			return encodeError(ctx, w, v)
+		}
+		switch en.GoaErrorName() {
+		case "bad-request":
+			var res *order.BadRequestT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewLogsBadRequestResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return enc.Encode(body)
+		case "invalid-credential":
+			var res *order.InvalidCredentialsT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return nil
+		case "invalid-parameter":
+			var res *order.InvalidParameterValue
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewLogsInvalidParameterResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return enc.Encode(body)
+		case "invalid-scopes":
+			var res *order.InvalidScopesT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewLogsInvalidScopesResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusForbidden)
+			return enc.Encode(body)
+		case "not-implemented":
+			var res *order.NotImplementedT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewLogsNotImplementedResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotImplemented)
+			return enc.Encode(body)
+		case "not-found":
+			var res *order.ResourceNotFoundT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewLogsNotFoundResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotFound)
+			return enc.Encode(body)
+		case "not-authorized":
+			var res *order.UnauthorizedT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnauthorized)
+			return nil
+		default:
+			return encodeError(ctx, w, v)
+		}
+	}
+}
+
+// EncodeTopResponse returns an encoder for responses returned by the order top
+// endpoint.
+func EncodeTopResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, interface{}) error {
+	return func(ctx context.Context, w http.ResponseWriter, v interface{}) error {
+		res := v.(orderviews.OrderTopResultItemCollection)
+		enc := encoder(ctx, w)
+		body := NewOrderTopResultItemResponseCollection(res.Projected)
+		w.WriteHeader(http.StatusOK)
+		return enc.Encode(body)
+	}
+}
+
+// DecodeTopRequest returns a decoder for requests sent to the order top
+// endpoint.
+func DecodeTopRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		var (
+			body TopRequestBody
+			err  error
+		)
+		err = decoder(r).Decode(&body)
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			} else {
+				return nil, goa.DecodePayloadError(err.Error())
+			}
+		}
+		err = ValidateTopRequestBody(&body)
+		if err != nil {
+			return nil, err
+		}
+
+		var (
+			jwt string
+		)
+		jwt = r.Header.Get("Authorization")
+		if jwt == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("Authorization", "header"))
+		}
+		if err != nil {
+			return nil, err
+		}
+		payload := NewTopPayload(&body, jwt)
+		if strings.Contains(payload.JWT, " ") {
+			// Remove authorization scheme prefix (e.g. "Bearer")
+			cred := strings.SplitN(payload.JWT, " ", 2)[1]
+			payload.JWT = cred
+		}
+
+		return payload, nil
+	}
+}
+
+// EncodeTopError returns an encoder for errors returned by the top order
+// endpoint.
+func EncodeTopError(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder, formatter func(ctx context.Context, err error) goahttp.Statuser) func(context.Context, http.ResponseWriter, error) error {
+	encodeError := goahttp.ErrorEncoder(encoder, formatter)
+	return func(ctx context.Context, w http.ResponseWriter, v error) error {
+		var en goa.GoaErrorNamer
+		if !errors.As(v, &en) {
+			return encodeError(ctx, w, v)
+		}
+		switch en.GoaErrorName() {
+		case "bad-request":
+			var res *order.BadRequestT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewTopBadRequestResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return enc.Encode(body)
+		case "invalid-credential":
+			var res *order.InvalidCredentialsT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return nil
+		case "invalid-parameter":
+			var res *order.InvalidParameterValue
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewTopInvalidParameterResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return enc.Encode(body)
+		case "invalid-scopes":
+			var res *order.InvalidScopesT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewTopInvalidScopesResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusForbidden)
+			return enc.Encode(body)
+		case "not-implemented":
+			var res *order.NotImplementedT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewTopNotImplementedResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotImplemented)
+			return enc.Encode(body)
+		case "not-found":
+			var res *order.ResourceNotFoundT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewTopNotFoundResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotFound)
+			return enc.Encode(body)
+		case "not-authorized":
+			var res *order.UnauthorizedT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnauthorized)
+			return nil
+		default:
+			return encodeError(ctx, w, v)
+		}
+	}
+}
+
+// marshalOrderviewsProductTViewToProductTResponseBody builds a value of type
+// *ProductTResponseBody from a value of type *orderviews.ProductTView.
+func marshalOrderviewsProductTViewToProductTResponseBody(v *orderviews.ProductTView) *ProductTResponseBody {
+	if v == nil {
+		return nil
+	}
+	res := &ProductTResponseBody{
+		ID:       v.ID,
+		Name:     v.Name,
+		Status:   v.Status,
+		MimeType: v.MimeType,
+		Size:     v.Size,
+		Etag:     v.Etag,
+	}
+	if v.Links != nil {
+		res.Links = marshalOrderviewsSelfWithDataTViewToSelfWithDataTResponseBody(v.Links)
+	}
+
+	return res
+}
+
+// marshalOrderviewsSelfWithDataTViewToSelfWithDataTResponseBody builds a value
+// of type *SelfWithDataTResponseBody from a value of type
+// *orderviews.SelfWithDataTView.
+func marshalOrderviewsSelfWithDataTViewToSelfWithDataTResponseBody(v *orderviews.SelfWithDataTView) *SelfWithDataTResponseBody {
+	if v == nil {
+		return nil
+	}
+	res := &SelfWithDataTResponseBody{
+		Self: v.Self,
+		Data: v.Data,
+	}
+	if v.DescribedBy != nil {
+		res.DescribedBy = marshalOrderviewsDescribedByTViewToDescribedByTResponseBody(v.DescribedBy)
+	}
+
+	return res
+}
+
+// marshalOrderviewsDescribedByTViewToDescribedByTResponseBody builds a value
+// of type *DescribedByTResponseBody from a value of type
+// *orderviews.DescribedByTView.
+func marshalOrderviewsDescribedByTViewToDescribedByTResponseBody(v *orderviews.DescribedByTView) *DescribedByTResponseBody {
+	if v == nil {
+		return nil
+	}
+	res := &DescribedByTResponseBody{
+		Href: v.Href,
+		Type: v.Type,
+	}
+
+	return res
+}
+
+// marshalOrderviewsRefTViewToRefTResponseBody builds a value of type
+// *RefTResponseBody from a value of type *orderviews.RefTView.
+func marshalOrderviewsRefTViewToRefTResponseBody(v *orderviews.RefTView) *RefTResponseBody {
+	if v == nil {
+		return nil
+	}
+	res := &RefTResponseBody{
+		ID: v.ID,
+	}
+	if v.Links != nil {
+		res.Links = marshalOrderviewsSelfTViewToSelfTResponseBody(v.Links)
+	}
+
+	return res
+}
+
+// marshalOrderviewsSelfTViewToSelfTResponseBody builds a value of type
+// *SelfTResponseBody from a value of type *orderviews.SelfTView.
+func marshalOrderviewsSelfTViewToSelfTResponseBody(v *orderviews.SelfTView) *SelfTResponseBody {
+	if v == nil {
+		return nil
+	}
+	res := &SelfTResponseBody{
+		Self: v.Self,
+	}
+	if v.DescribedBy != nil {
+		res.DescribedBy = marshalOrderviewsDescribedByTViewToDescribedByTResponseBody(v.DescribedBy)
+	}
+
+	return res
+}
+
+// marshalOrderviewsNavTViewToNavTResponseBody builds a value of type
+// *NavTResponseBody from a value of type *orderviews.NavTView.
+func marshalOrderviewsNavTViewToNavTResponseBody(v *orderviews.NavTView) *NavTResponseBody {
+	if v == nil {
+		return nil
+	}
+	res := &NavTResponseBody{
+		Self:  v.Self,
+		First: v.First,
+		Next:  v.Next,
+	}
+
+	return res
+}
+
+// marshalOrderviewsParameterTViewToParameterTResponseBody builds a value of
+// type *ParameterTResponseBody from a value of type *orderviews.ParameterTView.
+func marshalOrderviewsParameterTViewToParameterTResponseBody(v *orderviews.ParameterTView) *ParameterTResponseBody {
+	res := &ParameterTResponseBody{
+		Name:  v.Name,
+		Value: v.Value,
+	}
+
+	return res
+}
+
+// marshalOrderviewsOrderListItemViewToOrderListItemResponseBody builds a value
+// of type *OrderListItemResponseBody from a value of type
+// *orderviews.OrderListItemView.
+func marshalOrderviewsOrderListItemViewToOrderListItemResponseBody(v *orderviews.OrderListItemView) *OrderListItemResponseBody {
+	res := &OrderListItemResponseBody{
+		ID:         v.ID,
+		Name:       v.Name,
+		Status:     v.Status,
+		OrderedAt:  v.OrderedAt,
+		StartedAt:  v.StartedAt,
+		FinishedAt: v.FinishedAt,
+		ServiceID:  v.ServiceID,
+		AccountID:  v.AccountID,
+	}
+	if v.Links != nil {
+		res.Links = marshalOrderviewsSelfTViewToSelfTResponseBody(v.Links)
+	}
+
+	return res
+}
+
+// unmarshalParameterTRequestBodyRequestBodyToOrderParameterT builds a value of
+// type *order.ParameterT from a value of type
+// *ParameterTRequestBodyRequestBody.
+func unmarshalParameterTRequestBodyRequestBodyToOrderParameterT(v *ParameterTRequestBodyRequestBody) *order.ParameterT {
+	res := &order.ParameterT{
+		Name:  v.Name,
+		Value: v.Value,
+	}
+
+	return res
+}
+
+// marshalOrderviewsOrderTopResultItemViewToOrderTopResultItemResponse builds a
+// value of type *OrderTopResultItemResponse from a value of type
+// *orderviews.OrderTopResultItemView.
+func marshalOrderviewsOrderTopResultItemViewToOrderTopResultItemResponse(v *orderviews.OrderTopResultItemView) *OrderTopResultItemResponse {
+	res := &OrderTopResultItemResponse{
+		Container:        *v.Container,
+		CPU:              *v.CPU,
+		Memory:           *v.Memory,
+		Storage:          *v.Storage,
+		EphemeralStorage: *v.EphemeralStorage,
+	}
+
+	return res
+}
@@ -0,0 +1,220 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+	client "github.com/reinventingscience/ivcap-core-api/http/order"
+	goahttp "goa.design/goa/v3/http"
+	"goa.design/goa/v3/security"
+)
+
+// stubService records the payloads it receives and answers with result or
+// err.
+type stubService struct {
+	payload interface{}
+	result  interface{}
+	err     error
+}
+
+func (s *stubService) JWTAuth(ctx context.Context, token string, _ *security.JWTScheme) (context.Context, error) {
+	return ctx, nil
+}
+
+func (s *stubService) Read(_ context.Context, p *order.ReadPayload) (*order.OrderStatusRT, string, error) {
+	s.payload = p
+	res, _ := s.result.(*order.OrderStatusRT)
+	return res, "default", s.err
+}
+
+func (s *stubService) List(_ context.Context, p *order.ListPayload) (*order.OrderListRT, error) {
+	s.payload = p
+	res, _ := s.result.(*order.OrderListRT)
+	return res, s.err
+}
+
+func (s *stubService) Create(_ context.Context, p *order.CreatePayload) (*order.OrderStatusRT, string, error) {
+	s.payload = p
+	res, _ := s.result.(*order.OrderStatusRT)
+	return res, "default", s.err
+}
+
+func (s *stubService) Logs(_ context.Context, p *order.LogsPayload) (io.ReadCloser, error) {
+	s.payload = p
+	if s.err != nil {
+		return nil, s.err
+	}
+	return io.NopCloser(strings.NewReader(s.result.(string))), nil
+}
+
+func (s *stubService) Top(_ context.Context, p *order.TopPayload) (order.OrderTopResultItemCollection, error) {
+	s.payload = p
+	res, _ := s.result.(order.OrderTopResultItemCollection)
+	return res, s.err
+}
+
+// newTestClient serves s and returns the generated client for it.
+func newTestClient(t *testing.T, s *stubService) *order.Client {
+	mux := goahttp.NewMuxer()
+	Mount(mux, New(order.NewEndpoints(s), mux, goahttp.RequestDecoder, goahttp.ResponseEncoder, nil, nil))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	u, _ := url.Parse(srv.URL)
+	c := client.NewClient(u.Scheme, u.Host, srv.Client(), goahttp.RequestEncoder, goahttp.ResponseDecoder, false)
+	return order.NewClient(c.Read(), c.List(), c.Create(), c.Logs(), c.Top())
+}
+
+func str(s string) *string { return &s }
+
+func TestRoundTrip(t *testing.T) {
+	s := &stubService{}
+	c := newTestClient(t, s)
+	ctx := context.Background()
+	id := "0d6ce3f6-5b1b-4bcd-95a5-2a7ca4b0e3ab"
+	size := int64(12)
+	status := &order.OrderStatusRT{
+		ID:         id,
+		Status:     str("succeeded"),
+		OrderedAt:  str("2023-01-01T00:00:00Z"),
+		FinishedAt: str("2023-01-01T00:01:00Z"),
+		Products: []*order.ProductT{{
+			ID: str("urn:ivcap:artifact:1"), Name: str("out.txt"), Status: str("ready"), MimeType: str("text/plain"), Size: &size,
+			Links: &order.SelfWithDataT{Self: str("https://ivcap.test/1/artifacts/1"), Data: str("https://ivcap.test/1/artifacts/1/blob")},
+		}},
+		Service:      &order.RefT{ID: str("urn:ivcap:service:1"), Links: &order.SelfT{Self: str("https://ivcap.test/1/services/1")}},
+		Account:      &order.RefT{ID: str("urn:ivcap:account:1")},
+		Links:        &order.SelfT{Self: str("https://ivcap.test/1/orders/1")},
+		ProductLinks: &order.NavT{Self: str("https://ivcap.test/1/orders/1"), Next: str("https://ivcap.test/1/orders/1?page=2")},
+		Name:         str("test"),
+		Parameters:   []*order.ParameterT{{Name: str("size"), Value: str("1")}},
+	}
+
+	for _, tc := range []struct {
+		name    string
+		call    func() (interface{}, error)
+		payload interface{}
+		result  interface{}
+	}{
+		{
+			"read",
+			func() (interface{}, error) { return c.Read(ctx, &order.ReadPayload{ID: id, JWT: "token"}) },
+			&order.ReadPayload{ID: id, JWT: "token"},
+			status,
+		},
+		{
+			"list",
+			func() (interface{}, error) {
+				return c.List(ctx, &order.ListPayload{Limit: 5, Filter: str("status eq 'succeeded'"), OrderBy: str("name"), OrderDesc: true, AtTime: str("2023-01-01T00:00:00Z"), Page: str("p2"), JWT: "token"})
+			},
+			&order.ListPayload{Limit: 5, Filter: str("status eq 'succeeded'"), OrderBy: str("name"), OrderDesc: true, AtTime: str("2023-01-01T00:00:00Z"), Page: str("p2"), JWT: "token"},
+			&order.OrderListRT{
+				Orders: []*order.OrderListItem{{ID: &id, Name: str("test"), Status: str("pending"), OrderedAt: str("2023-01-01T00:00:00Z"), ServiceID: str("urn:ivcap:service:1"), AccountID: str("urn:ivcap:account:1"), Links: &order.SelfT{Self: str("https://ivcap.test/1/orders/1")}}},
+				AtTime: "2023-01-01T00:00:00Z",
+				Links:  &order.NavT{Self: str("https://ivcap.test/1/orders"), First: str("https://ivcap.test/1/orders?page=1")},
+			},
+		},
+		{
+			"create",
+			func() (interface{}, error) {
+				return c.Create(ctx, &order.CreatePayload{Orders: &order.OrderRequestT{ServiceID: "urn:ivcap:service:1", PolicyID: str("urn:ivcap:policy:1"), Name: str("test"), Tags: []string{"a"}, Parameters: []*order.ParameterT{{Name: str("size"), Value: str("1")}}}, JWT: "token"})
+			},
+			&order.CreatePayload{Orders: &order.OrderRequestT{ServiceID: "urn:ivcap:service:1", PolicyID: str("urn:ivcap:policy:1"), Name: str("test"), Tags: []string{"a"}, Parameters: []*order.ParameterT{{Name: str("size"), Value: str("1")}}}, JWT: "token"},
+			status,
+		},
+		{
+			"top",
+			func() (interface{}, error) {
+				return c.Top(ctx, &order.TopPayload{OrderTopRequest: &order.OrderTopRequestT{OrderID: "urn:ivcap:order:" + id, NamespaceName: str("ns")}, JWT: "token"})
+			},
+			&order.TopPayload{OrderTopRequest: &order.OrderTopRequestT{OrderID: "urn:ivcap:order:" + id, NamespaceName: str("ns")}, JWT: "token"},
+			order.OrderTopResultItemCollection{{Container: "main", CPU: "100m", Memory: "1Mi", Storage: "0", EphemeralStorage: "2Ki"}},
+		},
+	} {
+		s.payload, s.result, s.err = nil, tc.result, nil
+		res, err := tc.call()
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(s.payload, tc.payload) {
+			t.Errorf("%s: server received %+v, want %+v", tc.name, s.payload, tc.payload)
+		}
+		if !reflect.DeepEqual(res, tc.result) {
+			t.Errorf("%s: client received %+v, want %+v", tc.name, res, tc.result)
+		}
+	}
+}
+
+func TestRoundTripLogs(t *testing.T) {
+	s := &stubService{result: "line 1\nline 2\n"}
+	c := newTestClient(t, s)
+	from := int64(1)
+	p := &order.LogsPayload{DownloadLogRequest: &order.DownloadLogRequestT{OrderID: "urn:ivcap:order:1", From: &from, ContainerName: str("main")}, JWT: "token"}
+	body, err := c.Logs(context.Background(), p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	b, _ := io.ReadAll(body)
+	if string(b) != "line 1\nline 2\n" {
+		t.Errorf("client received %q", b)
+	}
+	if !reflect.DeepEqual(s.payload, p) {
+		t.Errorf("server received %+v, want %+v", s.payload, p)
+	}
+}
+
+func TestRoundTripErrors(t *testing.T) {
+	s := &stubService{}
+	c := newTestClient(t, s)
+	ctx := context.Background()
+	read := func() error {
+		_, err := c.Read(ctx, &order.ReadPayload{ID: "urn:ivcap:order:1", JWT: "token"})
+		return err
+	}
+	list := func() error {
+		_, err := c.List(ctx, &order.ListPayload{Limit: 10, JWT: "token"})
+		return err
+	}
+	for _, tc := range []struct {
+		err  error
+		call func() error
+	}{
+		{&order.BadRequestT{Message: "bad"}, read},
+		{&order.InvalidCredentialsT{}, read},
+		{&order.ResourceNotFoundT{ID: "urn:ivcap:order:1", Message: "not found"}, read},
+		{&order.NotImplementedT{Message: "later"}, read},
+		{&order.UnauthorizedT{}, read},
+		{&order.InvalidParameterValue{Name: "limit", Message: "too large", Value: str("1000")}, list},
+		// The design names the invalid scopes error after its message.
+		{&order.InvalidScopesT{ID: str("0d6ce3f6-5b1b-4bcd-95a5-2a7ca4b0e3ab"), Message: "invalid-scopes"}, list},
+	} {
+		s.err = tc.err
+		err := tc.call()
+		got := reflect.New(reflect.TypeOf(tc.err))
+		if !errors.As(err, got.Interface()) || !reflect.DeepEqual(got.Elem().Interface(), tc.err) {
+			t.Errorf("returned %#v, client received %#v", tc.err, err)
+		}
+	}
+}
@@ -0,0 +1,937 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+	orderviews "github.com/reinventingscience/ivcap-core-api/gen/order/views"
+	goa "goa.design/goa/v3/pkg"
+)
+
+// CreateRequestBody is the type of the "order" service "create" endpoint HTTP
+// request body.
+type CreateRequestBody struct {
+	// Reference to service requested
+	ServiceID *string `json:"service-id,omitempty"`
+	// Policy to control access to record an all generated artifacts
+	PolicyID *string `json:"policy-id,omitempty"`
+	// Optional customer provided name
+	Name *string `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	// Optional customer provided tags
+	Tags []string `form:"tags,omitempty" json:"tags,omitempty" xml:"tags,omitempty"`
+	// Service parameters
+	Parameters []*ParameterTRequestBodyRequestBody `form:"parameters,omitempty" json:"parameters,omitempty" xml:"parameters,omitempty"`
+}
+
+// LogsRequestBody is the type of the "order" service "logs" endpoint HTTP
+// request body.
+type LogsRequestBody struct {
+	// From unix time, seconds since 1970-01-01
+	From *int64 `form:"from,omitempty" json:"from,omitempty" xml:"from,omitempty"`
+	// To unix time, seconds since 1970-01-01
+	To *int64 `form:"to,omitempty" json:"to,omitempty" xml:"to,omitempty"`
+	// Reference to namespace name
+	NamespaceName *string `json:"namespace-name,omitempty"`
+	// Reference to container name
+	ContainerName *string `json:"container-name,omitempty"`
+	// Reference to order requested
+	OrderID *string `json:"order-id,omitempty"`
+	// Policy to control access to record an all generated artifacts
+	PolicyID *string `json:"policy-id,omitempty"`
+}
+
+// TopRequestBody is the type of the "order" service "top" endpoint HTTP
+// request body.
+type TopRequestBody struct {
+	// Reference to order requested
+	OrderID *string `json:"order-id,omitempty"`
+	// Reference to namespace name
+	NamespaceName *string `json:"namespace-name,omitempty"`
+	// Policy to control access to record an all generated artifacts
+	PolicyID *string `json:"policy-id,omitempty"`
+}
+
+// ReadResponseBody is the type of the "order" service "read" endpoint HTTP
+// response body.
+type ReadResponseBody struct {
+	// Order ID
+	ID string `form:"id" json:"id" xml:"id"`
+	// Optional customer provided name
+	Name *string `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	// Order status
+	Status *string `form:"status,omitempty" json:"status,omitempty" xml:"status,omitempty"`
+	// DateTime order was placed
+	OrderedAt *string `form:"ordered-at,omitempty" json:"ordered-at,omitempty" xml:"ordered-at,omitempty"`
+	// DateTime order processing started
+	StartedAt *string `form:"started-at,omitempty" json:"started-at,omitempty" xml:"started-at,omitempty"`
+	// DateTime order processing finished
+	FinishedAt *string `form:"finished-at,omitempty" json:"finished-at,omitempty" xml:"finished-at,omitempty"`
+	// Service parameters
+	Parameters []*ParameterTResponseBody `form:"parameters" json:"parameters" xml:"parameters"`
+	// Products delivered for this order
+	Products []*ProductTResponseBody `form:"products,omitempty" json:"products,omitempty" xml:"products,omitempty"`
+	// Reference to service requested
+	Service *RefTResponseBody `form:"service,omitempty" json:"service,omitempty" xml:"service,omitempty"`
+	// Reference to service requested
+	Account *RefTResponseBody  `form:"account,omitempty" json:"account,omitempty" xml:"account,omitempty"`
+	Links   *SelfTResponseBody `form:"links,omitempty" json:"links,omitempty" xml:"links,omitempty"`
+	// Product metadata links
+	ProductLinks *NavTResponseBody `form:"product-links,omitempty" json:"product-links,omitempty" xml:"product-links,omitempty"`
+	// Optional customer provided tags
+	Tags []string `form:"tags,omitempty" json:"tags,omitempty" xml:"tags,omitempty"`
+}
+
+// ReadResponseBodyTiny is the type of the "order" service "read" endpoint HTTP
+// response body.
+type ReadResponseBodyTiny struct {
+	// Optional customer provided name
+	Name *string `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	// Order status
+	Status *string            `form:"status,omitempty" json:"status,omitempty" xml:"status,omitempty"`
+	Links  *SelfTResponseBody `form:"links,omitempty" json:"links,omitempty" xml:"links,omitempty"`
+}
+
+// ListResponseBody is the type of the "order" service "list" endpoint HTTP
+// response body.
+type ListResponseBody struct {
+	// Orders
+	Orders []*OrderListItemResponseBody `form:"orders" json:"orders" xml:"orders"`
+	// Time at which this list was valid
+	AtTime string `form:"at-time" json:"at-time" xml:"at-time"`
+	// Navigation links
+	Links *NavTResponseBody `form:"links" json:"links" xml:"links"`
+}
+
+// CreateResponseBody is the type of the "order" service "create" endpoint HTTP
+// response body.
+type CreateResponseBody struct {
+	// Order ID
+	ID string `form:"id" json:"id" xml:"id"`
+	// Optional customer provided name
+	Name *string `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	// Order status
+	Status *string `form:"status,omitempty" json:"status,omitempty" xml:"status,omitempty"`
+	// DateTime order was placed
+	OrderedAt *string `form:"ordered-at,omitempty" json:"ordered-at,omitempty" xml:"ordered-at,omitempty"`
+	// DateTime order processing started
+	StartedAt *string `form:"started-at,omitempty" json:"started-at,omitempty" xml:"started-at,omitempty"`
+	// DateTime order processing finished
+	FinishedAt *string `form:"finished-at,omitempty" json:"finished-at,omitempty" xml:"finished-at,omitempty"`
+	// Service parameters
+	Parameters []*ParameterTResponseBody `form:"parameters" json:"parameters" xml:"parameters"`
+	// Products delivered for this order
+	Products []*ProductTResponseBody `form:"products,omitempty" json:"products,omitempty" xml:"products,omitempty"`
+	// Reference to service requested
+	Service *RefTResponseBody `form:"service,omitempty" json:"service,omitempty" xml:"service,omitempty"`
+	// Reference to service requested
+	Account *RefTResponseBody  `form:"account,omitempty" json:"account,omitempty" xml:"account,omitempty"`
+	Links   *SelfTResponseBody `form:"links,omitempty" json:"links,omitempty" xml:"links,omitempty"`
+	// Product metadata links
+	ProductLinks *NavTResponseBody `form:"product-links,omitempty" json:"product-links,omitempty" xml:"product-links,omitempty"`
+	// Optional customer provided tags
+	Tags []string `form:"tags,omitempty" json:"tags,omitempty" xml:"tags,omitempty"`
+}
+
+// CreateResponseBodyTiny is the type of the "order" service "create" endpoint
+// HTTP response body.
+type CreateResponseBodyTiny struct {
+	// Optional customer provided name
+	Name *string `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	// Order status
+	Status *string            `form:"status,omitempty" json:"status,omitempty" xml:"status,omitempty"`
+	Links  *SelfTResponseBody `form:"links,omitempty" json:"links,omitempty" xml:"links,omitempty"`
+}
+
+// OrderTopResultItemResponseCollection is the type of the "order" service
+// "top" endpoint HTTP response body.
+type OrderTopResultItemResponseCollection []*OrderTopResultItemResponse
+
+// ReadBadRequestResponseBody is the type of the "order" service "read"
+// endpoint HTTP response body for the "bad-request" error.
+type ReadBadRequestResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ReadInvalidScopesResponseBody is the type of the "order" service "read"
+// endpoint HTTP response body for the "invalid-scopes" error.
+type ReadInvalidScopesResponseBody struct {
+	// ID of involved resource
+	ID *string `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ReadNotImplementedResponseBody is the type of the "order" service "read"
+// endpoint HTTP response body for the "not-implemented" error.
+type ReadNotImplementedResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ReadNotFoundResponseBody is the type of the "order" service "read" endpoint
+// HTTP response body for the "not-found" error.
+type ReadNotFoundResponseBody struct {
+	// ID of missing resource
+	ID string `form:"id" json:"id" xml:"id"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ListBadRequestResponseBody is the type of the "order" service "list"
+// endpoint HTTP response body for the "bad-request" error.
+type ListBadRequestResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ListInvalidParameterResponseBody is the type of the "order" service "list"
+// endpoint HTTP response body for the "invalid-parameter" error.
+type ListInvalidParameterResponseBody struct {
+	// message describing expected type or pattern.
+	Message string `form:"message" json:"message" xml:"message"`
+	// name of parameter.
+	Name string `form:"name" json:"name" xml:"name"`
+	// provided parameter value.
+	Value *string `form:"value,omitempty" json:"value,omitempty" xml:"value,omitempty"`
+}
+
+// ListInvalidScopesResponseBody is the type of the "order" service "list"
+// endpoint HTTP response body for the "invalid-scopes" error.
+type ListInvalidScopesResponseBody struct {
+	// ID of involved resource
+	ID *string `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ListNotImplementedResponseBody is the type of the "order" service "list"
+// endpoint HTTP response body for the "not-implemented" error.
+type ListNotImplementedResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// CreateBadRequestResponseBody is the type of the "order" service "create"
+// endpoint HTTP response body for the "bad-request" error.
+type CreateBadRequestResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// CreateInvalidParameterResponseBody is the type of the "order" service
+// "create" endpoint HTTP response body for the "invalid-parameter" error.
+type CreateInvalidParameterResponseBody struct {
+	// message describing expected type or pattern.
+	Message string `form:"message" json:"message" xml:"message"`
+	// name of parameter.
+	Name string `form:"name" json:"name" xml:"name"`
+	// provided parameter value.
+	Value *string `form:"value,omitempty" json:"value,omitempty" xml:"value,omitempty"`
+}
+
+// CreateInvalidScopesResponseBody is the type of the "order" service "create"
+// endpoint HTTP response body for the "invalid-scopes" error.
+type CreateInvalidScopesResponseBody struct {
+	// ID of involved resource
+	ID *string `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// CreateNotImplementedResponseBody is the type of the "order" service "create"
+// endpoint HTTP response body for the "not-implemented" error.
+type CreateNotImplementedResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// CreateNotFoundResponseBody is the type of the "order" service "create"
+// endpoint HTTP response body for the "not-found" error.
+type CreateNotFoundResponseBody struct {
+	// ID of missing resource
+	ID string `form:"id" json:"id" xml:"id"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// LogsBadRequestResponseBody is the type of the "order" service "logs"
+// endpoint HTTP response body for the "bad-request" error.
+type LogsBadRequestResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// LogsInvalidParameterResponseBody is the type of the "order" service "logs"
+// endpoint HTTP response body for the "invalid-parameter" error.
+type LogsInvalidParameterResponseBody struct {
+	// message describing expected type or pattern.
+	Message string `form:"message" json:"message" xml:"message"`
+	// name of parameter.
+	Name string `form:"name" json:"name" xml:"name"`
+	// provided parameter value.
+	Value *string `form:"value,omitempty" json:"value,omitempty" xml:"value,omitempty"`
+}
+
+// LogsInvalidScopesResponseBody is the type of the "order" service "logs"
+// endpoint HTTP response body for the "invalid-scopes" error.
+type LogsInvalidScopesResponseBody struct {
+	// ID of involved resource
+	ID *string `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// LogsNotImplementedResponseBody is the type of the "order" service "logs"
+// endpoint HTTP response body for the "not-implemented" error.
+type LogsNotImplementedResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// LogsNotFoundResponseBody is the type of the "order" service "logs" endpoint
+// HTTP response body for the "not-found" error.
+type LogsNotFoundResponseBody struct {
+	// ID of missing resource
+	ID string `form:"id" json:"id" xml:"id"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// TopBadRequestResponseBody is the type of the "order" service "top" endpoint
+// HTTP response body for the "bad-request" error.
+type TopBadRequestResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// TopInvalidParameterResponseBody is the type of the "order" service "top"
+// endpoint HTTP response body for the "invalid-parameter" error.
+type TopInvalidParameterResponseBody struct {
+	// message describing expected type or pattern.
+	Message string `form:"message" json:"message" xml:"message"`
+	// name of parameter.
+	Name string `form:"name" json:"name" xml:"name"`
+	// provided parameter value.
+	Value *string `form:"value,omitempty" json:"value,omitempty" xml:"value,omitempty"`
+}
+
+// TopInvalidScopesResponseBody is the type of the "order" service "top"
+// endpoint HTTP response body for the "invalid-scopes" error.
+type TopInvalidScopesResponseBody struct {
+	// ID of involved resource
+	ID *string `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// TopNotImplementedResponseBody is the type of the "order" service "top"
+// endpoint HTTP response body for the "not-implemented" error.
+type TopNotImplementedResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// TopNotFoundResponseBody is the type of the "order" service "top" endpoint
+// HTTP response body for the "not-found" error.
+type TopNotFoundResponseBody struct {
+	// ID of missing resource
+	ID string `form:"id" json:"id" xml:"id"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ParameterTResponseBody is used to define fields on response body types.
+type ParameterTResponseBody struct {
+	Name  *string `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	Value *string `form:"value,omitempty" json:"value,omitempty" xml:"value,omitempty"`
+}
+
+// ProductTResponseBody is used to define fields on response body types.
+type ProductTResponseBody struct {
+	ID       *string                    `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	Name     *string                    `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	Status   *string                    `form:"status,omitempty" json:"status,omitempty" xml:"status,omitempty"`
+	MimeType *string                    `json:"mime-type,omitempty"`
+	Size     *int64                     `form:"size,omitempty" json:"size,omitempty" xml:"size,omitempty"`
+	Links    *SelfWithDataTResponseBody `form:"links,omitempty" json:"links,omitempty" xml:"links,omitempty"`
+	Etag     *string                    `json:"etag,omitempty"`
+}
+
+// SelfWithDataTResponseBody is used to define fields on response body types.
+type SelfWithDataTResponseBody struct {
+	Self        *string                   `form:"self,omitempty" json:"self,omitempty" xml:"self,omitempty"`
+	DescribedBy *DescribedByTResponseBody `form:"describedBy,omitempty" json:"describedBy,omitempty" xml:"describedBy,omitempty"`
+	Data        *string                   `form:"data,omitempty" json:"data,omitempty" xml:"data,omitempty"`
+}
+
+// DescribedByTResponseBody is used to define fields on response body types.
+type DescribedByTResponseBody struct {
+	Href *string `form:"href,omitempty" json:"href,omitempty" xml:"href,omitempty"`
+	Type *string `form:"type,omitempty" json:"type,omitempty" xml:"type,omitempty"`
+}
+
+// RefTResponseBody is used to define fields on response body types.
+type RefTResponseBody struct {
+	ID    *string            `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	Links *SelfTResponseBody `form:"links,omitempty" json:"links,omitempty" xml:"links,omitempty"`
+}
+
+// SelfTResponseBody is used to define fields on response body types.
+type SelfTResponseBody struct {
+	Self        *string                   `form:"self,omitempty" json:"self,omitempty" xml:"self,omitempty"`
+	DescribedBy *DescribedByTResponseBody `form:"describedBy,omitempty" json:"describedBy,omitempty" xml:"describedBy,omitempty"`
+}
+
+// NavTResponseBody is used to define fields on response body types.
+type NavTResponseBody struct {
+	Self  *string `form:"self,omitempty" json:"self,omitempty" xml:"self,omitempty"`
+	First *string `form:"first,omitempty" json:"first,omitempty" xml:"first,omitempty"`
+	Next  *string `form:"next,omitempty" json:"next,omitempty" xml:"next,omitempty"`
+}
+
+// OrderListItemResponseBody is used to define fields on response body types.
+type OrderListItemResponseBody struct {
+	// Order ID
+	ID *string `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	// Optional customer provided name
+	Name *string `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	// Order status
+	Status *string `form:"status,omitempty" json:"status,omitempty" xml:"status,omitempty"`
+	// DateTime order was placed
+	OrderedAt *string `form:"ordered-at,omitempty" json:"ordered-at,omitempty" xml:"ordered-at,omitempty"`
+	// DateTime processing of order started
+	StartedAt *string `form:"started-at,omitempty" json:"started-at,omitempty" xml:"started-at,omitempty"`
+	// DateTime order was finished
+	FinishedAt *string `form:"finished-at,omitempty" json:"finished-at,omitempty" xml:"finished-at,omitempty"`
+	// ID of ordered service
+	ServiceID *string `form:"service-id,omitempty" json:"service-id,omitempty" xml:"service-id,omitempty"`
+	// ID of ordered service
+	AccountID *string            `form:"account-id,omitempty" json:"account-id,omitempty" xml:"account-id,omitempty"`
+	Links     *SelfTResponseBody `form:"links" json:"links" xml:"links"`
+}
+
+// OrderTopResultItemResponse is used to define fields on response body types.
+type OrderTopResultItemResponse struct {
+	// container
+	Container string `form:"container" json:"container" xml:"container"`
+	// cpu
+	CPU string `form:"cpu" json:"cpu" xml:"cpu"`
+	// memory
+	Memory string `form:"memory" json:"memory" xml:"memory"`
+	// storage
+	Storage string `form:"storage" json:"storage" xml:"storage"`
+	// ephemeral-storage
+	EphemeralStorage string `form:"ephemeral-storage" json:"ephemeral-storage" xml:"ephemeral-storage"`
+}
+
+// ParameterTRequestBodyRequestBody is used to define fields on request body
+// types.
+type ParameterTRequestBodyRequestBody struct {
+	Name  *string `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	Value *string `form:"value,omitempty" json:"value,omitempty" xml:"value,omitempty"`
+}
+
+// NewReadResponseBody builds the HTTP response body from the result of the
+// "read" endpoint of the "order" service.
+func NewReadResponseBody(res *orderviews.OrderStatusRTView) *ReadResponseBody {
+	body := &ReadResponseBody{
+		ID:         *res.ID,
+		Status:     res.Status,
+		OrderedAt:  res.OrderedAt,
+		StartedAt:  res.StartedAt,
+		FinishedAt: res.FinishedAt,
+		Name:       res.Name,
+	}
+	if res.Products != nil {
+		body.Products = make([]*ProductTResponseBody, len(res.Products))
+		for i, val := range res.Products {
+			body.Products[i] = marshalOrderviewsProductTViewToProductTResponseBody(val)
+		}
+	}
+	if res.Service != nil {
+		body.Service = marshalOrderviewsRefTViewToRefTResponseBody(res.Service)
+	}
+	if res.Account != nil {
+		body.Account = marshalOrderviewsRefTViewToRefTResponseBody(res.Account)
+	}
+	if res.Links != nil {
+		body.Links = marshalOrderviewsSelfTViewToSelfTResponseBody(res.Links)
+	}
+	if res.ProductLinks != nil {
+		body.ProductLinks = marshalOrderviewsNavTViewToNavTResponseBody(res.ProductLinks)
+	}
+	if res.Tags != nil {
+		body.Tags = make([]string, len(res.Tags))
+		for i, val := range res.Tags {
+			body.Tags[i] = val
+		}
+	}
+	if res.Parameters != nil {
+		body.Parameters = make([]*ParameterTResponseBody, len(res.Parameters))
+		for i, val := range res.Parameters {
+			body.Parameters[i] = marshalOrderviewsParameterTViewToParameterTResponseBody(val)
+		}
+	}
+	return body
+}
+
+// NewReadResponseBodyTiny builds the HTTP response body from the result of the
+// "read" endpoint of the "order" service.
+func NewReadResponseBodyTiny(res *orderviews.OrderStatusRTView) *ReadResponseBodyTiny {
+	body := &ReadResponseBodyTiny{
+		Status: res.Status,
+		Name:   res.Name,
+	}
+	if res.Links != nil {
+		body.Links = marshalOrderviewsSelfTViewToSelfTResponseBody(res.Links)
+	}
+	return body
+}
+
+// NewListResponseBody builds the HTTP response body from the result of the
+// "list" endpoint of the "order" service.
+func NewListResponseBody(res *orderviews.OrderListRTView) *ListResponseBody {
+	body := &ListResponseBody{
+		AtTime: *res.AtTime,
+	}
+	if res.Orders != nil {
+		body.Orders = make([]*OrderListItemResponseBody, len(res.Orders))
+		for i, val := range res.Orders {
+			body.Orders[i] = marshalOrderviewsOrderListItemViewToOrderListItemResponseBody(val)
+		}
+	}
+	if res.Links != nil {
+		body.Links = marshalOrderviewsNavTViewToNavTResponseBody(res.Links)
+	}
+	return body
+}
+
+// NewCreateResponseBody builds the HTTP response body from the result of the
+// "create" endpoint of the "order" service.
+func NewCreateResponseBody(res *orderviews.OrderStatusRTView) *CreateResponseBody {
+	body := &CreateResponseBody{
+		ID:         *res.ID,
+		Status:     res.Status,
+		OrderedAt:  res.OrderedAt,
+		StartedAt:  res.StartedAt,
+		FinishedAt: res.FinishedAt,
+		Name:       res.Name,
+	}
+	if res.Products != nil {
+		body.Products = make([]*ProductTResponseBody, len(res.Products))
+		for i, val := range res.Products {
+			body.Products[i] = marshalOrderviewsProductTViewToProductTResponseBody(val)
+		}
+	}
+	if res.Service != nil {
+		body.Service = marshalOrderviewsRefTViewToRefTResponseBody(res.Service)
+	}
+	if res.Account != nil {
+		body.Account = marshalOrderviewsRefTViewToRefTResponseBody(res.Account)
+	}
+	if res.Links != nil {
+		body.Links = marshalOrderviewsSelfTViewToSelfTResponseBody(res.Links)
+	}
+	if res.ProductLinks != nil {
+		body.ProductLinks = marshalOrderviewsNavTViewToNavTResponseBody(res.ProductLinks)
+	}
+	if res.Tags != nil {
+		body.Tags = make([]string, len(res.Tags))
+		for i, val := range res.Tags {
+			body.Tags[i] = val
+		}
+	}
+	if res.Parameters != nil {
+		body.Parameters = make([]*ParameterTResponseBody, len(res.Parameters))
+		for i, val := range res.Parameters {
+			body.Parameters[i] = marshalOrderviewsParameterTViewToParameterTResponseBody(val)
+		}
+	}
+	return body
+}
+
+// NewCreateResponseBodyTiny builds the HTTP response body from the result of
+// the "create" endpoint of the "order" service.
+func NewCreateResponseBodyTiny(res *orderviews.OrderStatusRTView) *CreateResponseBodyTiny {
+	body := &CreateResponseBodyTiny{
+		Status: res.Status,
+		Name:   res.Name,
+	}
+	if res.Links != nil {
+		body.Links = marshalOrderviewsSelfTViewToSelfTResponseBody(res.Links)
+	}
+	return body
+}
+
+// NewOrderTopResultItemResponseCollection builds the HTTP response body from
+// the result of the "top" endpoint of the "order" service.
+func NewOrderTopResultItemResponseCollection(res orderviews.OrderTopResultItemCollectionView) OrderTopResultItemResponseCollection {
+	body := make([]*OrderTopResultItemResponse, len(res))
+	for i, val := range res {
+		body[i] = marshalOrderviewsOrderTopResultItemViewToOrderTopResultItemResponse(val)
+	}
+	return body
+}
+
+// NewReadBadRequestResponseBody builds the HTTP response body from the result
+// of the "read" endpoint of the "order" service.
+func NewReadBadRequestResponseBody(res *order.BadRequestT) *ReadBadRequestResponseBody {
+	body := &ReadBadRequestResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewReadInvalidScopesResponseBody builds the HTTP response body from the
+// result of the "read" endpoint of the "order" service.
+func NewReadInvalidScopesResponseBody(res *order.InvalidScopesT) *ReadInvalidScopesResponseBody {
+	body := &ReadInvalidScopesResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewReadNotImplementedResponseBody builds the HTTP response body from the
+// result of the "read" endpoint of the "order" service.
+func NewReadNotImplementedResponseBody(res *order.NotImplementedT) *ReadNotImplementedResponseBody {
+	body := &ReadNotImplementedResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewReadNotFoundResponseBody builds the HTTP response body from the result of
+// the "read" endpoint of the "order" service.
+func NewReadNotFoundResponseBody(res *order.ResourceNotFoundT) *ReadNotFoundResponseBody {
+	body := &ReadNotFoundResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewListBadRequestResponseBody builds the HTTP response body from the result
+// of the "list" endpoint of the "order" service.
+func NewListBadRequestResponseBody(res *order.BadRequestT) *ListBadRequestResponseBody {
+	body := &ListBadRequestResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewListInvalidParameterResponseBody builds the HTTP response body from the
+// result of the "list" endpoint of the "order" service.
+func NewListInvalidParameterResponseBody(res *order.InvalidParameterValue) *ListInvalidParameterResponseBody {
+	body := &ListInvalidParameterResponseBody{
+		Message: res.Message,
+		Name:    res.Name,
+		Value:   res.Value,
+	}
+	return body
+}
+
+// NewListInvalidScopesResponseBody builds the HTTP response body from the
+// result of the "list" endpoint of the "order" service.
+func NewListInvalidScopesResponseBody(res *order.InvalidScopesT) *ListInvalidScopesResponseBody {
+	body := &ListInvalidScopesResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewListNotImplementedResponseBody builds the HTTP response body from the
+// result of the "list" endpoint of the "order" service.
+func NewListNotImplementedResponseBody(res *order.NotImplementedT) *ListNotImplementedResponseBody {
+	body := &ListNotImplementedResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewCreateBadRequestResponseBody builds the HTTP response body from the
+// result of the "create" endpoint of the "order" service.
+func NewCreateBadRequestResponseBody(res *order.BadRequestT) *CreateBadRequestResponseBody {
+	body := &CreateBadRequestResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewCreateInvalidParameterResponseBody builds the HTTP response body from the
+// result of the "create" endpoint of the "order" service.
+func NewCreateInvalidParameterResponseBody(res *order.InvalidParameterValue) *CreateInvalidParameterResponseBody {
+	body := &CreateInvalidParameterResponseBody{
+		Message: res.Message,
+		Name:    res.Name,
+		Value:   res.Value,
+	}
+	return body
+}
+
+// NewCreateInvalidScopesResponseBody builds the HTTP response body from the
+// result of the "create" endpoint of the "order" service.
+func NewCreateInvalidScopesResponseBody(res *order.InvalidScopesT) *CreateInvalidScopesResponseBody {
+	body := &CreateInvalidScopesResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewCreateNotImplementedResponseBody builds the HTTP response body from the
+// result of the "create" endpoint of the "order" service.
+func NewCreateNotImplementedResponseBody(res *order.NotImplementedT) *CreateNotImplementedResponseBody {
+	body := &CreateNotImplementedResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewCreateNotFoundResponseBody builds the HTTP response body from the result
+// of the "create" endpoint of the "order" service.
+func NewCreateNotFoundResponseBody(res *order.ResourceNotFoundT) *CreateNotFoundResponseBody {
+	body := &CreateNotFoundResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewLogsBadRequestResponseBody builds the HTTP response body from the result
+// of the "logs" endpoint of the "order" service.
+func NewLogsBadRequestResponseBody(res *order.BadRequestT) *LogsBadRequestResponseBody {
+	body := &LogsBadRequestResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewLogsInvalidParameterResponseBody builds the HTTP response body from the
+// result of the "logs" endpoint of the "order" service.
+func NewLogsInvalidParameterResponseBody(res *order.InvalidParameterValue) *LogsInvalidParameterResponseBody {
+	body := &LogsInvalidParameterResponseBody{
+		Message: res.Message,
+		Name:    res.Name,
+		Value:   res.Value,
+	}
+	return body
+}
+
+// NewLogsInvalidScopesResponseBody builds the HTTP response body from the
+// result of the "logs" endpoint of the "order" service.
+func NewLogsInvalidScopesResponseBody(res *order.InvalidScopesT) *LogsInvalidScopesResponseBody {
+	body := &LogsInvalidScopesResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewLogsNotImplementedResponseBody builds the HTTP response body from the
+// result of the "logs" endpoint of the "order" service.
+func NewLogsNotImplementedResponseBody(res *order.NotImplementedT) *LogsNotImplementedResponseBody {
+	body := &LogsNotImplementedResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewLogsNotFoundResponseBody builds the HTTP response body from the result of
+// the "logs" endpoint of the "order" service.
+func NewLogsNotFoundResponseBody(res *order.ResourceNotFoundT) *LogsNotFoundResponseBody {
+	body := &LogsNotFoundResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewTopBadRequestResponseBody builds the HTTP response body from the result
+// of the "top" endpoint of the "order" service.
+func NewTopBadRequestResponseBody(res *order.BadRequestT) *TopBadRequestResponseBody {
+	body := &TopBadRequestResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewTopInvalidParameterResponseBody builds the HTTP response body from the
+// result of the "top" endpoint of the "order" service.
+func NewTopInvalidParameterResponseBody(res *order.InvalidParameterValue) *TopInvalidParameterResponseBody {
+	body := &TopInvalidParameterResponseBody{
+		Message: res.Message,
+		Name:    res.Name,
+		Value:   res.Value,
+	}
+	return body
+}
+
+// NewTopInvalidScopesResponseBody builds the HTTP response body from the
+// result of the "top" endpoint of the "order" service.
+func NewTopInvalidScopesResponseBody(res *order.InvalidScopesT) *TopInvalidScopesResponseBody {
+	body := &TopInvalidScopesResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewTopNotImplementedResponseBody builds the HTTP response body from the
+// result of the "top" endpoint of the "order" service.
+func NewTopNotImplementedResponseBody(res *order.NotImplementedT) *TopNotImplementedResponseBody {
+	body := &TopNotImplementedResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewTopNotFoundResponseBody builds the HTTP response body from the result of
+// the "top" endpoint of the "order" service.
+func NewTopNotFoundResponseBody(res *order.ResourceNotFoundT) *TopNotFoundResponseBody {
+	body := &TopNotFoundResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewReadPayload builds a order service read endpoint payload.
+func NewReadPayload(id string, jwt string) *order.ReadPayload {
+	v := &order.ReadPayload{}
+	v.ID = id
+	v.JWT = jwt
+
+	return v
+}
+
+// NewListPayload builds a order service list endpoint payload.
+func NewListPayload(limit int, page *string, filter *string, orderBy *string, orderDesc bool, atTime *string, jwt string) *order.ListPayload {
+	v := &order.ListPayload{}
+	v.Limit = limit
+	v.Page = page
+	v.Filter = filter
+	v.OrderBy = orderBy
+	v.OrderDesc = orderDesc
+	v.AtTime = atTime
+	v.JWT = jwt
+
+	return v
+}
+
+// NewCreatePayload builds a order service create endpoint payload.
+func NewCreatePayload(body *CreateRequestBody, jwt string) *order.CreatePayload {
+	v := &order.OrderRequestT{
+		ServiceID: *body.ServiceID,
+		PolicyID:  body.PolicyID,
+		Name:      body.Name,
+	}
+	if body.Tags != nil {
+		v.Tags = make([]string, len(body.Tags))
+		for i, val := range body.Tags {
+			v.Tags[i] = val
+		}
+	}
+	v.Parameters = make([]*order.ParameterT, len(body.Parameters))
+	for i, val := range body.Parameters {
+		v.Parameters[i] = unmarshalParameterTRequestBodyRequestBodyToOrderParameterT(val)
+	}
+	res := &order.CreatePayload{
+		Orders: v,
+	}
+	res.JWT = jwt
+
+	return res
+}
+
+// NewLogsPayload builds a order service logs endpoint payload.
+func NewLogsPayload(body *LogsRequestBody, jwt string) *order.LogsPayload {
+	v := &order.DownloadLogRequestT{
+		From:          body.From,
+		To:            body.To,
+		NamespaceName: body.NamespaceName,
+		ContainerName: body.ContainerName,
+		OrderID:       *body.OrderID,
+		PolicyID:      body.PolicyID,
+	}
+	res := &order.LogsPayload{
+		DownloadLogRequest: v,
+	}
+	res.JWT = jwt
+
+	return res
+}
+
+// NewTopPayload builds a order service top endpoint payload.
+func NewTopPayload(body *TopRequestBody, jwt string) *order.TopPayload {
+	v := &order.OrderTopRequestT{
+		OrderID:       *body.OrderID,
+		NamespaceName: body.NamespaceName,
+		PolicyID:      body.PolicyID,
+	}
+	res := &order.TopPayload{
+		OrderTopRequest: v,
+	}
+	res.JWT = jwt
+
+	return res
+}
+
+// ValidateCreateRequestBody runs the validations defined on CreateRequestBody
+func ValidateCreateRequestBody(body *CreateRequestBody) (err error) {
+	if body.ServiceID == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("service-id", "body"))
+	}
+	if body.Parameters == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("parameters", "body"))
+	}
+	if body.ServiceID != nil {
+		err = goa.MergeErrors(err, goa.ValidateFormat("body.service-id", *body.ServiceID, goa.FormatURI))
+	}
+	if body.PolicyID != nil {
+		err = goa.MergeErrors(err, goa.ValidateFormat("body.policy-id", *body.PolicyID, goa.FormatURI))
+	}
+	return
+}
+
+// ValidateLogsRequestBody runs the validations defined on LogsRequestBody
+func ValidateLogsRequestBody(body *LogsRequestBody) (err error) {
+	if body.OrderID == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("order-id", "body"))
+	}
+	if body.OrderID != nil {
+		err = goa.MergeErrors(err, goa.ValidateFormat("body.order-id", *body.OrderID, goa.FormatURI))
+	}
+	if body.PolicyID != nil {
+		err = goa.MergeErrors(err, goa.ValidateFormat("body.policy-id", *body.PolicyID, goa.FormatURI))
+	}
+	return
+}
+
+// ValidateTopRequestBody runs the validations defined on TopRequestBody
+func ValidateTopRequestBody(body *TopRequestBody) (err error) {
+	if body.OrderID == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("order-id", "body"))
+	}
+	if body.OrderID != nil {
+		err = goa.MergeErrors(err, goa.ValidateFormat("body.order-id", *body.OrderID, goa.FormatURI))
+	}
+	if body.PolicyID != nil {
+		err = goa.MergeErrors(err, goa.ValidateFormat("body.policy-id", *body.PolicyID, goa.FormatURI))
+	}
+	return
+}
@@ -0,0 +1,1032 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+	serviceviews "github.com/reinventingscience/ivcap-core-api/gen/service/views"
+	goahttp "goa.design/goa/v3/http"
+	goa "goa.design/goa/v3/pkg"
+)
+
+// EncodeListResponse returns an encoder for responses returned by the service
+// list endpoint.
+func EncodeListResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, interface{}) error {
+	return func(ctx context.Context, w http.ResponseWriter, v interface{}) error {
+		res := v.(*serviceviews.ServiceListRT)
+		enc := encoder(ctx, w)
+		body := NewListResponseBody(res.Projected)
+		w.WriteHeader(http.StatusOK)
+		return enc.Encode(body)
+	}
+}
+
+// DecodeListRequest returns a decoder for requests sent to the service list
+// endpoint.
+func DecodeListRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		var (
+			limit     int
+			page      *string
+			filter    *string
+			orderBy   *string
+			orderDesc bool
+			atTime    *string
+			jwt       string
+			err       error
+		)
+		{
+			limitRaw := r.URL.Query().Get("limit")
+			if limitRaw == "" {
+				limit = 10
+			} else {
+				v, err2 := strconv.ParseInt(limitRaw, 10, strconv.IntSize)
+				if err2 != nil {
+					err = goa.MergeErrors(err, goa.InvalidFieldTypeError("limit", limitRaw, "integer"))
+				}
+				limit = int(v)
+			}
+		}
+		if limit < 1 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("limit", limit, 1, true))
+		}
+		if limit > 50 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError("limit", limit, 50, false))
+		}
+		pageRaw := r.URL.Query().Get("page")
+		if pageRaw != "" {
+			page = &pageRaw
+		}
+		filterRaw := r.URL.Query().Get("filter")
+		if filterRaw != "" {
+			filter = &filterRaw
+		}
+		orderByRaw := r.URL.Query().Get("order-by")
+		if orderByRaw != "" {
+			orderBy = &orderByRaw
+		}
+		{
+			orderDescRaw := r.URL.Query().Get("order-desc")
+			if orderDescRaw != "" {
+				v, err2 := strconv.ParseBool(orderDescRaw)
+				if err2 != nil {
+					err = goa.MergeErrors(err, goa.InvalidFieldTypeError("orderDesc", orderDescRaw, "boolean"))
+				}
+				orderDesc = v
+			}
+		}
+		atTimeRaw := r.URL.Query().Get("at-time")
+		if atTimeRaw != "" {
+			atTime = &atTimeRaw
+		}
+		if atTime != nil {
+			err = goa.MergeErrors(err, goa.ValidateFormat("atTime", *atTime, goa.FormatDateTime))
+		}
+		jwt = r.Header.Get("Authorization")
+		if jwt == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("Authorization", "header"))
+		}
+		if err != nil {
+			return nil, err
+		}
+		payload := NewListPayload(limit, page, filter, orderBy, orderDesc, atTime, jwt)
+		if strings.Contains(payload.JWT, " ") {
+			// Remove authorization scheme prefix (e.g. "Bearer")
+			cred := strings.SplitN(payload.JWT, " ", 2)[1]
+			payload.JWT = cred
+		}
+
+		return payload, nil
+	}
+}
+
+// EncodeListError returns an encoder for errors returned by the list service
+// endpoint.
+func EncodeListError(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder, formatter func(ctx context.Context, err error) goahttp.Statuser) func(context.Context, http.ResponseWriter, error) error {
+	encodeError := goahttp.ErrorEncoder(encoder, formatter)
+	return func(ctx context.Context, w http.ResponseWriter, v error) error {
+		var en goa.GoaErrorNamer
+		if !errors.As(v, &en) {
+			return encodeError(ctx, w, v)
+		}
+		switch en.GoaErrorName() {
+		case "bad-request":
+			var res *service.BadRequestT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewListBadRequestResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return enc.Encode(body)
+		case "invalid-credential":
+			var res *service.InvalidCredentialsT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return nil
+		case "invalid-parameter":
+			var res *service.InvalidParameterValue
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewListInvalidParameterResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return enc.Encode(body)
+		case "invalid-scopes":
+			var res *service.InvalidScopesT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewListInvalidScopesResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusForbidden)
+			return enc.Encode(body)
+		case "not-implemented":
+			var res *service.NotImplementedT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewListNotImplementedResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotImplemented)
+			return enc.Encode(body)
+		case "not-authorized":
+			var res *service.UnauthorizedT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnauthorized)
+			return nil
+		default:
+			return encodeError(ctx, w, v)
+		}
+	}
+}
+
+// EncodeCreateServiceResponse returns an encoder for responses returned by the
+// service create_service endpoint.
+func EncodeCreateServiceResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, interface{}) error {
+	return func(ctx context.Context, w http.ResponseWriter, v interface{}) error {
+		res := v.(*serviceviews.ServiceStatusRT)
+		w.Header().Set("goa-view", res.View)
+		enc := encoder(ctx, w)
+		var body interface{}
+		switch res.View {
+		case "default", "":
+			body = NewCreateServiceResponseBody(res.Projected)
+		case "tiny":
+			body = NewCreateServiceResponseBodyTiny(res.Projected)
+		}
+		w.WriteHeader(http.StatusCreated)
+		return enc.Encode(body)
+	}
+}
+
+// DecodeCreateServiceRequest returns a decoder for requests sent to the
+// service create_service endpoint.
+func DecodeCreateServiceRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		var (
+			body CreateServiceRequestBody
+			err  error
+		)
+		err = decoder(r).Decode(&body)
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			} else {
+				return nil, goa.DecodePayloadError(err.Error())
+			}
+		}
+		err = ValidateCreateServiceRequestBody(&body)
+		if err != nil {
+			return nil, err
+		}
+
+		var (
+			jwt string
+		)
+		jwt = r.Header.Get("Authorization")
+		if jwt == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("Authorization", "header"))
+		}
+		if err != nil {
+			return nil, err
+		}
+		payload := NewCreateServicePayload(&body, jwt)
+		if strings.Contains(payload.JWT, " ") {
+			// Remove authorization scheme prefix (e.g. "Bearer")
+			cred := strings.SplitN(payload.JWT, " ", 2)[1]
+			payload.JWT = cred
+		}
+
+		return payload, nil
+	}
+}
+
+// EncodeCreateServiceError returns an encoder for errors returned by the
+// create_service service endpoint.
+func EncodeCreateServiceError(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder, formatter func(ctx context.Context, err error) goahttp.Statuser) func(context.Context, http.ResponseWriter, error) error {
+	encodeError := goahttp.ErrorEncoder(encoder, formatter)
+	return func(ctx context.Context, w http.ResponseWriter, v error) error {
+		var en goa.GoaErrorNamer
+		if !errors.As(v, &en) {
+			return encodeError(ctx, w, v)
+		}
+		switch en.GoaErrorName() {
+		case "bad-request":
+			var res *service.BadRequestT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewCreateServiceBadRequestResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return enc.Encode(body)
+		case "invalid-credential":
+			var res *service.InvalidCredentialsT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return nil
+		case "invalid-parameter":
+			var res *service.InvalidParameterValue
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewCreateServiceInvalidParameterResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return enc.Encode(body)
+		case "invalid-scopes":
+			var res *service.InvalidScopesT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewCreateServiceInvalidScopesResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusForbidden)
+			return enc.Encode(body)
+		case "not-implemented":
+			var res *service.NotImplementedT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewCreateServiceNotImplementedResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotImplemented)
+			return enc.Encode(body)
+		case "already-created":
+			var res *service.ResourceAlreadyCreatedT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewCreateServiceAlreadyCreatedResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusConflict)
+			return enc.Encode(body)
+		case "not-found":
+			var res *service.ResourceNotFoundT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewCreateServiceNotFoundResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotFound)
+			return enc.Encode(body)
+		case "not-authorized":
+			var res *service.UnauthorizedT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnauthorized)
+			return nil
+		default:
+			return encodeError(ctx, w, v)
+		}
+	}
+}
+
+// EncodeReadResponse returns an encoder for responses returned by the service
+// read endpoint.
+func EncodeReadResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, interface{}) error {
+	return func(ctx context.Context, w http.ResponseWriter, v interface{}) error {
+		res := v.(*serviceviews.ServiceStatusRT)
+		w.Header().Set("goa-view", res.View)
+		enc := encoder(ctx, w)
+		var body interface{}
+		switch res.View {
+		case "default", "":
+			body = NewReadResponseBody(res.Projected)
+		case "tiny":
+			body = NewReadResponseBodyTiny(res.Projected)
+		}
+		w.WriteHeader(http.StatusOK)
+		return enc.Encode(body)
+	}
+}
+
+// DecodeReadRequest returns a decoder for requests sent to the service read
+// endpoint.
+func DecodeReadRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		var (
+			id  string
+			jwt string
+			err error
+
+			params = mux.Vars(r)
+		)
+		id = params["id"]
+		jwt = r.Header.Get("Authorization")
+		if jwt == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("Authorization", "header"))
+		}
+		if err != nil {
+			return nil, err
+		}
+		payload := NewReadPayload(id, jwt)
+		if strings.Contains(payload.JWT, " ") {
+			// Remove authorization scheme prefix (e.g. "Bearer")
+			cred := strings.SplitN(payload.JWT, " ", 2)[1]
+			payload.JWT = cred
+		}
+
+		return payload, nil
+	}
+}
+
+// EncodeReadError returns an encoder for errors returned by the read service
+// endpoint.
+func EncodeReadError(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder, formatter func(ctx context.Context, err error) goahttp.Statuser) func(context.Context, http.ResponseWriter, error) error {
+	encodeError := goahttp.ErrorEncoder(encoder, formatter)
+	return func(ctx context.Context, w http.ResponseWriter, v error) error {
+		var en goa.GoaErrorNamer
+		if !errors.As(v, &en) {
+			return encodeError(ctx, w, v)
+		}
+		switch en.GoaErrorName() {
+		case "bad-request":
+			var res *service.BadRequestT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewReadBadRequestResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return enc.Encode(body)
+		case "invalid-credential":
+			var res *service.InvalidCredentialsT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return nil
+		case "invalid-scopes":
+			var res *service.InvalidScopesT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewReadInvalidScopesResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusForbidden)
+			return enc.Encode(body)
+		case "not-implemented":
+			var res *service.NotImplementedT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewReadNotImplementedResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotImplemented)
+			return enc.Encode(body)
+		case "not-found":
+			var res *service.ResourceNotFoundT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewReadNotFoundResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotFound)
+			return enc.Encode(body)
+		case "not-authorized":
+			var res *service.UnauthorizedT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnauthorized)
+			return nil
+		default:
+			return encodeError(ctx, w, v)
+		}
+	}
+}
+
+// EncodeUpdateResponse returns an encoder for responses returned by the
+// service update endpoint.
+func EncodeUpdateResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, interface{}) error {
+	return func(ctx context.Context, w http.ResponseWriter, v interface{}) error {
+		res := v.(*serviceviews.ServiceStatusRT)
+		w.Header().Set("goa-view", res.View)
+		enc := encoder(ctx, w)
+		var body interface{}
+		switch res.View {
+		case "default", "":
+			body = NewUpdateResponseBody(res.Projected)
+		case "tiny":
+			body = NewUpdateResponseBodyTiny(res.Projected)
+		}
+		w.WriteHeader(http.StatusOK)
+		return enc.Encode(body)
+	}
+}
+
+// DecodeUpdateRequest returns a decoder for requests sent to the service
+// update endpoint.
+func DecodeUpdateRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		var (
+			body UpdateRequestBody
+			err  error
+		)
+		err = decoder(r).Decode(&body)
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			} else {
+				return nil, goa.DecodePayloadError(err.Error())
+			}
+		}
+		err = ValidateUpdateRequestBody(&body)
+		if err != nil {
+			return nil, err
+		}
+
+		var (
+			id          string
+			forceCreate *bool
+			jwt         string
+
+			params = mux.Vars(r)
+		)
+		id = params["id"]
+		{
+			forceCreateRaw := r.URL.Query().Get("force-create")
+			if forceCreateRaw != "" {
+				v, err2 := strconv.ParseBool(forceCreateRaw)
+				if err2 != nil {
+					err = goa.MergeErrors(err, goa.InvalidFieldTypeError("forceCreate", forceCreateRaw, "boolean"))
+				}
+				forceCreate = &v
+			}
+		}
+		jwt = r.Header.Get("Authorization")
+		if jwt == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("Authorization", "header"))
+		}
+		if err != nil {
+			return nil, err
+		}
+		payload := NewUpdatePayload(&body, id, forceCreate, jwt)
+		if strings.Contains(payload.JWT, " ") {
+			// Remove authorization scheme prefix (e.g. "Bearer")
+			cred := strings.SplitN(payload.JWT, " ", 2)[1]
+			payload.JWT = cred
+		}
+
+		return payload, nil
+	}
+}
+
+// EncodeUpdateError returns an encoder for errors returned by the update
+// service endpoint.
+func EncodeUpdateError(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder, formatter func(ctx context.Context, err error) goahttp.Statuser) func(context.Context, http.ResponseWriter, error) error {
+	encodeError := goahttp.ErrorEncoder(encoder, formatter)
+	return func(ctx context.Context, w http.ResponseWriter, v error) error {
+		var en goa.GoaErrorNamer
+		if !errors.As(v, &en) {
+			return encodeError(ctx, w, v)
+		}
+		switch en.GoaErrorName() {
+		case "bad-request":
+			var res *service.BadRequestT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewUpdateBadRequestResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return enc.Encode(body)
+		case "invalid-credential":
+			var res *service.InvalidCredentialsT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return nil
+		case "invalid-parameter":
+			var res *service.InvalidParameterValue
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewUpdateInvalidParameterResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return enc.Encode(body)
+		case "invalid-scopes":
+			var res *service.InvalidScopesT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewUpdateInvalidScopesResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusForbidden)
+			return enc.Encode(body)
+		case "not-implemented":
+			var res *service.NotImplementedT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewUpdateNotImplementedResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotImplemented)
+			return enc.Encode(body)
+		case "not-found":
+			var res *service.ResourceNotFoundT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewUpdateNotFoundResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotFound)
+			return enc.Encode(body)
+		case "not-authorized":
+			var res *service.UnauthorizedT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnauthorized)
+			return nil
+		default:
+			return encodeError(ctx, w, v)
+		}
+	}
+}
+
+// EncodeDeleteResponse returns an encoder for responses returned by the
+// service delete endpoint.
+func EncodeDeleteResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, interface{}) error {
+	return func(ctx context.Context, w http.ResponseWriter, v interface{}) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+}
+
+// DecodeDeleteRequest returns a decoder for requests sent to the service
+// delete endpoint.
+func DecodeDeleteRequest(mux goahttp.Muxer, decoder func(*http.Request) goahttp.Decoder) func(*http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		var (
+			id  string
+			jwt string
+			err error
+
+			params = mux.Vars(r)
+		)
+		id = params["id"]
+		jwt = r.Header.Get("Authorization")
+		if jwt == "" {
+			err = goa.MergeErrors(err, goa.MissingFieldError("Authorization", "header"))
+		}
+		if err != nil {
+			return nil, err
+		}
+		payload := NewDeletePayload(id, jwt)
+		if strings.Contains(payload.JWT, " ") {
+			// Remove authorization scheme prefix (e.g. "Bearer")
+			cred := strings.SplitN(payload.JWT, " ", 2)[1]
+			payload.JWT = cred
+		}
+
+		return payload, nil
+	}
+}
+
+// EncodeDeleteError returns an encoder for errors returned by the delete
+// service endpoint.
+func EncodeDeleteError(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder, formatter func(ctx context.Context, err error) goahttp.Statuser) func(context.Context, http.ResponseWriter, error) error {
+	encodeError := goahttp.ErrorEncoder(encoder, formatter)
+	return func(ctx context.Context, w http.ResponseWriter, v error) error {
+		var en goa.GoaErrorNamer
+		if !errors.As(v, &en) {
+			return encodeError(ctx, w, v)
+		}
+		switch en.GoaErrorName() {
+		case "bad-request":
+			var res *service.BadRequestT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewDeleteBadRequestResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return enc.Encode(body)
+		case "invalid-credential":
+			var res *service.InvalidCredentialsT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusBadRequest)
+			return nil
+		case "invalid-scopes":
+			var res *service.InvalidScopesT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewDeleteInvalidScopesResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusForbidden)
+			return enc.Encode(body)
+		case "not-implemented":
+			var res *service.NotImplementedT
+			errors.As(v, &res)
+			enc := encoder(ctx, w)
+			var body interface{}
+			if formatter != nil {
+				body = formatter(ctx, res)
+			} else {
+				body = NewDeleteNotImplementedResponseBody(res)
+			}
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusNotImplemented)
+			return enc.Encode(body)
+		case "not-authorized":
+			var res *service.UnauthorizedT
+			errors.As(v, &res)
+			w.Header().Set("goa-error", res.GoaErrorName())
+			w.WriteHeader(http.StatusUnauthorized)
+			return nil
+		default:
+			return encodeError(ctx, w, v)
+		}
+	}
+}
+
+// marshalServiceviewsServiceListItemViewToServiceListItemResponseBody builds a
+// value of type *ServiceListItemResponseBody from a value of type
+// *serviceviews.ServiceListItemView.
+func marshalServiceviewsServiceListItemViewToServiceListItemResponseBody(v *serviceviews.ServiceListItemView) *ServiceListItemResponseBody {
+	res := &ServiceListItemResponseBody{
+		ID:          v.ID,
+		Name:        v.Name,
+		Description: v.Description,
+	}
+	if v.Provider != nil {
+		res.Provider = marshalServiceviewsRefTViewToRefTResponseBody(v.Provider)
+	}
+	if v.Links != nil {
+		res.Links = marshalServiceviewsSelfTViewToSelfTResponseBody(v.Links)
+	}
+
+	return res
+}
+
+// marshalServiceviewsRefTViewToRefTResponseBody builds a value of type
+// *RefTResponseBody from a value of type *serviceviews.RefTView.
+func marshalServiceviewsRefTViewToRefTResponseBody(v *serviceviews.RefTView) *RefTResponseBody {
+	if v == nil {
+		return nil
+	}
+	res := &RefTResponseBody{
+		ID: v.ID,
+	}
+	if v.Links != nil {
+		res.Links = marshalServiceviewsSelfTViewToSelfTResponseBody(v.Links)
+	}
+
+	return res
+}
+
+// marshalServiceviewsSelfTViewToSelfTResponseBody builds a value of type
+// *SelfTResponseBody from a value of type *serviceviews.SelfTView.
+func marshalServiceviewsSelfTViewToSelfTResponseBody(v *serviceviews.SelfTView) *SelfTResponseBody {
+	if v == nil {
+		return nil
+	}
+	res := &SelfTResponseBody{
+		Self: v.Self,
+	}
+	if v.DescribedBy != nil {
+		res.DescribedBy = marshalServiceviewsDescribedByTViewToDescribedByTResponseBody(v.DescribedBy)
+	}
+
+	return res
+}
+
+// marshalServiceviewsDescribedByTViewToDescribedByTResponseBody builds a value
+// of type *DescribedByTResponseBody from a value of type
+// *serviceviews.DescribedByTView.
+func marshalServiceviewsDescribedByTViewToDescribedByTResponseBody(v *serviceviews.DescribedByTView) *DescribedByTResponseBody {
+	if v == nil {
+		return nil
+	}
+	res := &DescribedByTResponseBody{
+		Href: v.Href,
+		Type: v.Type,
+	}
+
+	return res
+}
+
+// marshalServiceviewsNavTViewToNavTResponseBody builds a value of type
+// *NavTResponseBody from a value of type *serviceviews.NavTView.
+func marshalServiceviewsNavTViewToNavTResponseBody(v *serviceviews.NavTView) *NavTResponseBody {
+	res := &NavTResponseBody{
+		Self:  v.Self,
+		First: v.First,
+		Next:  v.Next,
+	}
+
+	return res
+}
+
+// unmarshalParameterTRequestBodyRequestBodyToServiceParameterT builds a value
+// of type *service.ParameterT from a value of type
+// *ParameterTRequestBodyRequestBody.
+func unmarshalParameterTRequestBodyRequestBodyToServiceParameterT(v *ParameterTRequestBodyRequestBody) *service.ParameterT {
+	if v == nil {
+		return nil
+	}
+	res := &service.ParameterT{
+		Name:  v.Name,
+		Value: v.Value,
+	}
+
+	return res
+}
+
+// unmarshalReferenceTRequestBodyRequestBodyToServiceReferenceT builds a value
+// of type *service.ReferenceT from a value of type
+// *ReferenceTRequestBodyRequestBody.
+func unmarshalReferenceTRequestBodyRequestBodyToServiceReferenceT(v *ReferenceTRequestBodyRequestBody) *service.ReferenceT {
+	if v == nil {
+		return nil
+	}
+	res := &service.ReferenceT{
+		Title: v.Title,
+		URI:   v.URI,
+	}
+
+	return res
+}
+
+// unmarshalWorkflowTRequestBodyRequestBodyToServiceWorkflowT builds a value of
+// type *service.WorkflowT from a value of type
+// *WorkflowTRequestBodyRequestBody.
+func unmarshalWorkflowTRequestBodyRequestBodyToServiceWorkflowT(v *WorkflowTRequestBodyRequestBody) *service.WorkflowT {
+	res := &service.WorkflowT{
+		Type: v.Type,
+		Argo: v.Argo,
+		Opts: v.Opts,
+	}
+	if v.Basic != nil {
+		res.Basic = unmarshalBasicWorkflowOptsTRequestBodyRequestBodyToServiceBasicWorkflowOptsT(v.Basic)
+	}
+
+	return res
+}
+
+// unmarshalBasicWorkflowOptsTRequestBodyRequestBodyToServiceBasicWorkflowOptsT
+// builds a value of type *service.BasicWorkflowOptsT from a value of type
+// *BasicWorkflowOptsTRequestBodyRequestBody.
+func unmarshalBasicWorkflowOptsTRequestBodyRequestBodyToServiceBasicWorkflowOptsT(v *BasicWorkflowOptsTRequestBodyRequestBody) *service.BasicWorkflowOptsT {
+	if v == nil {
+		return nil
+	}
+	res := &service.BasicWorkflowOptsT{
+		Image: *v.Image,
+	}
+	res.Command = make([]string, len(v.Command))
+	for i, val := range v.Command {
+		res.Command[i] = val
+	}
+	if v.Memory != nil {
+		res.Memory = unmarshalResourceMemoryTRequestBodyRequestBodyToServiceResourceMemoryT(v.Memory)
+	}
+	if v.CPU != nil {
+		res.CPU = unmarshalResourceMemoryTRequestBodyRequestBodyToServiceResourceMemoryT(v.CPU)
+	}
+	if v.EphemeralStorage != nil {
+		res.EphemeralStorage = unmarshalResourceMemoryTRequestBodyRequestBodyToServiceResourceMemoryT(v.EphemeralStorage)
+	}
+
+	return res
+}
+
+// unmarshalResourceMemoryTRequestBodyRequestBodyToServiceResourceMemoryT
+// builds a value of type *service.ResourceMemoryT from a value of type
+// *ResourceMemoryTRequestBodyRequestBody.
+func unmarshalResourceMemoryTRequestBodyRequestBodyToServiceResourceMemoryT(v *ResourceMemoryTRequestBodyRequestBody) *service.ResourceMemoryT {
+	if v == nil {
+		return nil
+	}
+	res := &service.ResourceMemoryT{
+		Request: v.Request,
+		Limit:   v.Limit,
+	}
+
+	return res
+}
+
+// unmarshalParameterDefTRequestBodyRequestBodyToServiceParameterDefT builds a
+// value of type *service.ParameterDefT from a value of type
+// *ParameterDefTRequestBodyRequestBody.
+func unmarshalParameterDefTRequestBodyRequestBodyToServiceParameterDefT(v *ParameterDefTRequestBodyRequestBody) *service.ParameterDefT {
+	res := &service.ParameterDefT{
+		Name:        v.Name,
+		Label:       v.Label,
+		Type:        v.Type,
+		Description: v.Description,
+		Unit:        v.Unit,
+		Constant:    v.Constant,
+		Optional:    v.Optional,
+		Default:     v.Default,
+		Unary:       v.Unary,
+	}
+	if v.Options != nil {
+		res.Options = make([]*service.ParameterOptT, len(v.Options))
+		for i, val := range v.Options {
+			res.Options[i] = unmarshalParameterOptTRequestBodyRequestBodyToServiceParameterOptT(val)
+		}
+	}
+
+	return res
+}
+
+// unmarshalParameterOptTRequestBodyRequestBodyToServiceParameterOptT builds a
+// value of type *service.ParameterOptT from a value of type
+// *ParameterOptTRequestBodyRequestBody.
+func unmarshalParameterOptTRequestBodyRequestBodyToServiceParameterOptT(v *ParameterOptTRequestBodyRequestBody) *service.ParameterOptT {
+	if v == nil {
+		return nil
+	}
+	res := &service.ParameterOptT{
+		Value:       v.Value,
+		Description: v.Description,
+	}
+
+	return res
+}
+
+// marshalServiceviewsParameterTViewToParameterTResponseBody builds a value of
+// type *ParameterTResponseBody from a value of type
+// *serviceviews.ParameterTView.
+func marshalServiceviewsParameterTViewToParameterTResponseBody(v *serviceviews.ParameterTView) *ParameterTResponseBody {
+	if v == nil {
+		return nil
+	}
+	res := &ParameterTResponseBody{
+		Name:  v.Name,
+		Value: v.Value,
+	}
+
+	return res
+}
+
+// marshalServiceviewsParameterDefTViewToParameterDefTResponseBody builds a
+// value of type *ParameterDefTResponseBody from a value of type
+// *serviceviews.ParameterDefTView.
+func marshalServiceviewsParameterDefTViewToParameterDefTResponseBody(v *serviceviews.ParameterDefTView) *ParameterDefTResponseBody {
+	res := &ParameterDefTResponseBody{
+		Name:        v.Name,
+		Label:       v.Label,
+		Type:        v.Type,
+		Description: v.Description,
+		Unit:        v.Unit,
+		Constant:    v.Constant,
+		Optional:    v.Optional,
+		Default:     v.Default,
+		Unary:       v.Unary,
+	}
+	if v.Options != nil {
+		res.Options = make([]*ParameterOptTResponseBody, len(v.Options))
+		for i, val := range v.Options {
+			res.Options[i] = marshalServiceviewsParameterOptTViewToParameterOptTResponseBody(val)
+		}
+	}
+
+	return res
+}
+
+// marshalServiceviewsParameterOptTViewToParameterOptTResponseBody builds a
+// value of type *ParameterOptTResponseBody from a value of type
+// *serviceviews.ParameterOptTView.
+func marshalServiceviewsParameterOptTViewToParameterOptTResponseBody(v *serviceviews.ParameterOptTView) *ParameterOptTResponseBody {
+	if v == nil {
+		return nil
+	}
+	res := &ParameterOptTResponseBody{
+		Value:       v.Value,
+		Description: v.Description,
+	}
+
+	return res
+}
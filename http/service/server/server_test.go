@@ -0,0 +1,220 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+	client "github.com/reinventingscience/ivcap-core-api/http/service"
+	goahttp "goa.design/goa/v3/http"
+	"goa.design/goa/v3/security"
+)
+
+// stubService records the payloads it receives and answers with result or
+// err.
+type stubService struct {
+	payload interface{}
+	result  interface{}
+	err     error
+}
+
+func (s *stubService) JWTAuth(ctx context.Context, token string, _ *security.JWTScheme) (context.Context, error) {
+	return ctx, nil
+}
+
+func (s *stubService) List(_ context.Context, p *service.ListPayload) (*service.ServiceListRT, error) {
+	s.payload = p
+	res, _ := s.result.(*service.ServiceListRT)
+	return res, s.err
+}
+
+func (s *stubService) CreateService(_ context.Context, p *service.CreateServicePayload) (*service.ServiceStatusRT, string, error) {
+	s.payload = p
+	res, _ := s.result.(*service.ServiceStatusRT)
+	return res, "default", s.err
+}
+
+func (s *stubService) Read(_ context.Context, p *service.ReadPayload) (*service.ServiceStatusRT, string, error) {
+	s.payload = p
+	res, _ := s.result.(*service.ServiceStatusRT)
+	return res, "default", s.err
+}
+
+func (s *stubService) Update(_ context.Context, p *service.UpdatePayload) (*service.ServiceStatusRT, string, error) {
+	s.payload = p
+	res, _ := s.result.(*service.ServiceStatusRT)
+	return res, "default", s.err
+}
+
+func (s *stubService) Delete(_ context.Context, p *service.DeletePayload) error {
+	s.payload = p
+	return s.err
+}
+
+// newTestClient serves s and returns the generated client for it.
+func newTestClient(t *testing.T, s *stubService) *service.Client {
+	mux := goahttp.NewMuxer()
+	Mount(mux, New(service.NewEndpoints(s), mux, goahttp.RequestDecoder, goahttp.ResponseEncoder, nil, nil))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	u, _ := url.Parse(srv.URL)
+	c := client.NewClient(u.Scheme, u.Host, srv.Client(), goahttp.RequestEncoder, goahttp.ResponseDecoder, false)
+	return service.NewClient(c.List(), c.CreateService(), c.Read(), c.Update(), c.Delete())
+}
+
+func str(s string) *string { return &s }
+
+func flag(b bool) *bool { return &b }
+
+func TestRoundTrip(t *testing.T) {
+	s := &stubService{}
+	c := newTestClient(t, s)
+	ctx := context.Background()
+	id := "0d6ce3f6-5b1b-4bcd-95a5-2a7ca4b0e3ab"
+	params := []*service.ParameterDefT{
+		{Name: str("size"), Label: str("Size"), Type: str("int"), Description: str("edge length"), Unit: str("px"), Optional: flag(true), Default: str("10")},
+		{Name: str("mode"), Type: str("option"), Constant: flag(false), Unary: flag(false), Options: []*service.ParameterOptT{{Value: str("fast"), Description: str("be quick")}}},
+	}
+	desc := &service.ServiceDescriptionT{
+		ProviderRef: str("provider/test"),
+		ProviderID:  "urn:ivcap:provider:1",
+		Description: "a test service",
+		Metadata:    []*service.ParameterT{{Name: str("k"), Value: str("v")}},
+		References:  []*service.ReferenceT{{Title: str("docs"), URI: str("https://ivcap.test/docs")}},
+		Banner:      str("https://ivcap.test/banner.png"),
+		Workflow: &service.WorkflowT{
+			Type: str("basic"),
+			Basic: &service.BasicWorkflowOptsT{
+				Image:            "alpine",
+				Command:          []string{"echo", "hi"},
+				Memory:           &service.ResourceMemoryT{Request: str("10Mi"), Limit: str("20Mi")},
+				CPU:              &service.ResourceMemoryT{Request: str("100m")},
+				EphemeralStorage: &service.ResourceMemoryT{Limit: str("1Gi")},
+			},
+			Opts: map[string]interface{}{"retries": 2.0},
+		},
+		PolicyID:   str("urn:ivcap:policy:1"),
+		Name:       str("test"),
+		Tags:       []string{"a", "b"},
+		Parameters: params,
+	}
+	status := &service.ServiceStatusRT{
+		ID:          id,
+		Description: str("a test service"),
+		Metadata:    []*service.ParameterT{{Name: str("k"), Value: str("v")}},
+		Provider:    &service.RefT{ID: str("urn:ivcap:provider:1"), Links: &service.SelfT{Self: str("https://ivcap.test/1/providers/1")}},
+		Account:     &service.RefT{ID: str("urn:ivcap:account:1")},
+		Links:       &service.SelfT{Self: str("https://ivcap.test/1/services/1"), DescribedBy: &service.DescribedByT{Href: str("https://ivcap.test/schema"), Type: str("application/json")}},
+		Name:        str("test"),
+		Tags:        []string{"a", "b"},
+		Parameters:  params,
+	}
+
+	for _, tc := range []struct {
+		name    string
+		call    func() (interface{}, error)
+		payload interface{}
+		result  interface{}
+	}{
+		{
+			"list",
+			func() (interface{}, error) {
+				return c.List(ctx, &service.ListPayload{Limit: 5, Filter: str("name ~= 'test'"), OrderBy: str("name"), OrderDesc: true, AtTime: str("2023-01-01T00:00:00Z"), Page: str("p2"), JWT: "token"})
+			},
+			&service.ListPayload{Limit: 5, Filter: str("name ~= 'test'"), OrderBy: str("name"), OrderDesc: true, AtTime: str("2023-01-01T00:00:00Z"), Page: str("p2"), JWT: "token"},
+			&service.ServiceListRT{
+				Services: []*service.ServiceListItem{{ID: &id, Name: str("test"), Description: str("a test service"), Provider: &service.RefT{ID: str("urn:ivcap:provider:1")}, Links: &service.SelfT{Self: str("https://ivcap.test/1/services/1")}}},
+				AtTime:   "2023-01-01T00:00:00Z",
+				Links:    &service.NavT{Self: str("https://ivcap.test/1/services"), Next: str("https://ivcap.test/1/services?page=p3")},
+			},
+		},
+		{
+			"create",
+			func() (interface{}, error) {
+				return c.CreateService(ctx, &service.CreateServicePayload{Services: desc, JWT: "token"})
+			},
+			&service.CreateServicePayload{Services: desc, JWT: "token"},
+			status,
+		},
+		{
+			"read",
+			func() (interface{}, error) { return c.Read(ctx, &service.ReadPayload{ID: id, JWT: "token"}) },
+			&service.ReadPayload{ID: id, JWT: "token"},
+			status,
+		},
+		{
+			"update",
+			func() (interface{}, error) {
+				return c.Update(ctx, &service.UpdatePayload{ID: &id, ForceCreate: flag(true), Services: desc, JWT: "token"})
+			},
+			&service.UpdatePayload{ID: &id, ForceCreate: flag(true), Services: desc, JWT: "token"},
+			status,
+		},
+		{
+			"delete",
+			func() (interface{}, error) { return nil, c.Delete(ctx, &service.DeletePayload{ID: id, JWT: "token"}) },
+			&service.DeletePayload{ID: id, JWT: "token"},
+			nil,
+		},
+	} {
+		s.payload, s.result, s.err = nil, tc.result, nil
+		res, err := tc.call()
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(s.payload, tc.payload) {
+			t.Errorf("%s: server received %+v, want %+v", tc.name, s.payload, tc.payload)
+		}
+		if !reflect.DeepEqual(res, tc.result) {
+			t.Errorf("%s: client received %+v, want %+v", tc.name, res, tc.result)
+		}
+	}
+}
+
+func TestRoundTripErrors(t *testing.T) {
+	s := &stubService{}
+	c := newTestClient(t, s)
+	ctx := context.Background()
+	id := "0d6ce3f6-5b1b-4bcd-95a5-2a7ca4b0e3ab"
+	create := func() error {
+		_, err := c.CreateService(ctx, &service.CreateServicePayload{Services: &service.ServiceDescriptionT{ProviderID: "urn:ivcap:provider:1", Workflow: &service.WorkflowT{Type: str("basic")}, Parameters: []*service.ParameterDefT{}}, JWT: "token"})
+		return err
+	}
+	for _, want := range []error{
+		&service.BadRequestT{Message: "bad"},
+		&service.InvalidCredentialsT{},
+		&service.InvalidParameterValue{Name: "parameters", Message: "missing", Value: str("[]")},
+		// The design names the invalid scopes error after its message.
+		&service.InvalidScopesT{ID: &id, Message: "invalid-scopes"},
+		&service.NotImplementedT{Message: "later"},
+		&service.ResourceAlreadyCreatedT{ID: "urn:ivcap:service:" + id, Message: "exists"},
+		&service.ResourceNotFoundT{ID: "urn:ivcap:provider:1", Message: "not found"},
+		&service.UnauthorizedT{},
+	} {
+		s.err = want
+		err := create()
+		got := reflect.New(reflect.TypeOf(want))
+		if !errors.As(err, got.Interface()) || !reflect.DeepEqual(got.Elem().Interface(), want) {
+			t.Errorf("returned %#v, client received %#v", want, err)
+		}
+	}
+}
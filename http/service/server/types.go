@@ -0,0 +1,1108 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+	serviceviews "github.com/reinventingscience/ivcap-core-api/gen/service/views"
+	goa "goa.design/goa/v3/pkg"
+)
+
+// CreateServiceRequestBody is the type of the "service" service
+// "create_service" endpoint HTTP request body.
+type CreateServiceRequestBody struct {
+	// Provider provided reference. Should to be a single string with punctuations
+	// allowed. Might be changed, so please check result
+	ProviderRef *string `json:"provider-ref,omitempty"`
+	// Reference to service provider
+	ProviderID *string `json:"provider-id,omitempty"`
+	// More detailed description of the service
+	Description *string `form:"description,omitempty" json:"description,omitempty" xml:"description,omitempty"`
+	// Optional provider provided meta tags
+	Metadata []*ParameterTRequestBodyRequestBody `form:"metadata,omitempty" json:"metadata,omitempty" xml:"metadata,omitempty"`
+	// Reference to account revenues for this service should be credited to
+	References []*ReferenceTRequestBodyRequestBody `form:"references,omitempty" json:"references,omitempty" xml:"references,omitempty"`
+	// Link to banner image oprionally used for this service
+	Banner *string `form:"banner,omitempty" json:"banner,omitempty" xml:"banner,omitempty"`
+	// Definition of the workflow to use for executing this service
+	Workflow *WorkflowTRequestBodyRequestBody `form:"workflow,omitempty" json:"workflow,omitempty" xml:"workflow,omitempty"`
+	// Reference to policy controlling access
+	PolicyID *string `json:"policy-id,omitempty"`
+	// Optional provider provided name
+	Name *string `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	// Optional provider provided tags
+	Tags []string `form:"tags,omitempty" json:"tags,omitempty" xml:"tags,omitempty"`
+	// Service parameter definitions
+	Parameters []*ParameterDefTRequestBodyRequestBody `form:"parameters,omitempty" json:"parameters,omitempty" xml:"parameters,omitempty"`
+}
+
+// UpdateRequestBody is the type of the "service" service "update" endpoint
+// HTTP request body.
+type UpdateRequestBody struct {
+	// Provider provided reference. Should to be a single string with punctuations
+	// allowed. Might be changed, so please check result
+	ProviderRef *string `json:"provider-ref,omitempty"`
+	// Reference to service provider
+	ProviderID *string `json:"provider-id,omitempty"`
+	// More detailed description of the service
+	Description *string `form:"description,omitempty" json:"description,omitempty" xml:"description,omitempty"`
+	// Optional provider provided meta tags
+	Metadata []*ParameterTRequestBodyRequestBody `form:"metadata,omitempty" json:"metadata,omitempty" xml:"metadata,omitempty"`
+	// Reference to account revenues for this service should be credited to
+	References []*ReferenceTRequestBodyRequestBody `form:"references,omitempty" json:"references,omitempty" xml:"references,omitempty"`
+	// Link to banner image oprionally used for this service
+	Banner *string `form:"banner,omitempty" json:"banner,omitempty" xml:"banner,omitempty"`
+	// Definition of the workflow to use for executing this service
+	Workflow *WorkflowTRequestBodyRequestBody `form:"workflow,omitempty" json:"workflow,omitempty" xml:"workflow,omitempty"`
+	// Reference to policy controlling access
+	PolicyID *string `json:"policy-id,omitempty"`
+	// Optional provider provided name
+	Name *string `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	// Optional provider provided tags
+	Tags []string `form:"tags,omitempty" json:"tags,omitempty" xml:"tags,omitempty"`
+	// Service parameter definitions
+	Parameters []*ParameterDefTRequestBodyRequestBody `form:"parameters,omitempty" json:"parameters,omitempty" xml:"parameters,omitempty"`
+}
+
+// ListResponseBody is the type of the "service" service "list" endpoint HTTP
+// response body.
+type ListResponseBody struct {
+	// Services
+	Services []*ServiceListItemResponseBody `form:"services" json:"services" xml:"services"`
+	// Time at which this list was valid
+	AtTime string `form:"at-time" json:"at-time" xml:"at-time"`
+	// Navigation links
+	Links *NavTResponseBody `form:"links" json:"links" xml:"links"`
+}
+
+// CreateServiceResponseBody is the type of the "service" service
+// "create_service" endpoint HTTP response body.
+type CreateServiceResponseBody struct {
+	// Service ID
+	ID string `form:"id" json:"id" xml:"id"`
+	// Optional provider provided name
+	Name *string `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	// More detailed description of the service
+	Description *string `form:"description,omitempty" json:"description,omitempty" xml:"description,omitempty"`
+	// Optional provider provided tags
+	Tags []string `form:"tags,omitempty" json:"tags,omitempty" xml:"tags,omitempty"`
+	// Optional provider provided meta tags
+	Metadata []*ParameterTResponseBody `form:"metadata,omitempty" json:"metadata,omitempty" xml:"metadata,omitempty"`
+	// Service parameter definitions
+	Parameters []*ParameterDefTResponseBody `form:"parameters" json:"parameters" xml:"parameters"`
+	// Reference to service provider
+	Provider *RefTResponseBody `form:"provider,omitempty" json:"provider,omitempty" xml:"provider,omitempty"`
+	// Reference to service provider
+	Account *RefTResponseBody  `form:"account,omitempty" json:"account,omitempty" xml:"account,omitempty"`
+	Links   *SelfTResponseBody `form:"links" json:"links" xml:"links"`
+}
+
+// CreateServiceResponseBodyTiny is the type of the "service" service
+// "create_service" endpoint HTTP response body.
+type CreateServiceResponseBodyTiny struct {
+	// Optional provider provided name
+	Name  *string            `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	Links *SelfTResponseBody `form:"links" json:"links" xml:"links"`
+}
+
+// ReadResponseBody is the type of the "service" service "read" endpoint HTTP
+// response body.
+type ReadResponseBody struct {
+	// Service ID
+	ID string `form:"id" json:"id" xml:"id"`
+	// Optional provider provided name
+	Name *string `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	// More detailed description of the service
+	Description *string `form:"description,omitempty" json:"description,omitempty" xml:"description,omitempty"`
+	// Optional provider provided tags
+	Tags []string `form:"tags,omitempty" json:"tags,omitempty" xml:"tags,omitempty"`
+	// Optional provider provided meta tags
+	Metadata []*ParameterTResponseBody `form:"metadata,omitempty" json:"metadata,omitempty" xml:"metadata,omitempty"`
+	// Service parameter definitions
+	Parameters []*ParameterDefTResponseBody `form:"parameters" json:"parameters" xml:"parameters"`
+	// Reference to service provider
+	Provider *RefTResponseBody `form:"provider,omitempty" json:"provider,omitempty" xml:"provider,omitempty"`
+	// Reference to service provider
+	Account *RefTResponseBody  `form:"account,omitempty" json:"account,omitempty" xml:"account,omitempty"`
+	Links   *SelfTResponseBody `form:"links" json:"links" xml:"links"`
+}
+
+// ReadResponseBodyTiny is the type of the "service" service "read" endpoint
+// HTTP response body.
+type ReadResponseBodyTiny struct {
+	// Optional provider provided name
+	Name  *string            `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	Links *SelfTResponseBody `form:"links" json:"links" xml:"links"`
+}
+
+// UpdateResponseBody is the type of the "service" service "update" endpoint
+// HTTP response body.
+type UpdateResponseBody struct {
+	// Service ID
+	ID string `form:"id" json:"id" xml:"id"`
+	// Optional provider provided name
+	Name *string `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	// More detailed description of the service
+	Description *string `form:"description,omitempty" json:"description,omitempty" xml:"description,omitempty"`
+	// Optional provider provided tags
+	Tags []string `form:"tags,omitempty" json:"tags,omitempty" xml:"tags,omitempty"`
+	// Optional provider provided meta tags
+	Metadata []*ParameterTResponseBody `form:"metadata,omitempty" json:"metadata,omitempty" xml:"metadata,omitempty"`
+	// Service parameter definitions
+	Parameters []*ParameterDefTResponseBody `form:"parameters" json:"parameters" xml:"parameters"`
+	// Reference to service provider
+	Provider *RefTResponseBody `form:"provider,omitempty" json:"provider,omitempty" xml:"provider,omitempty"`
+	// Reference to service provider
+	Account *RefTResponseBody  `form:"account,omitempty" json:"account,omitempty" xml:"account,omitempty"`
+	Links   *SelfTResponseBody `form:"links" json:"links" xml:"links"`
+}
+
+// UpdateResponseBodyTiny is the type of the "service" service "update"
+// endpoint HTTP response body.
+type UpdateResponseBodyTiny struct {
+	// Optional provider provided name
+	Name  *string            `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	Links *SelfTResponseBody `form:"links" json:"links" xml:"links"`
+}
+
+// ListBadRequestResponseBody is the type of the "service" service "list"
+// endpoint HTTP response body for the "bad-request" error.
+type ListBadRequestResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ListInvalidParameterResponseBody is the type of the "service" service "list"
+// endpoint HTTP response body for the "invalid-parameter" error.
+type ListInvalidParameterResponseBody struct {
+	// message describing expected type or pattern.
+	Message string `form:"message" json:"message" xml:"message"`
+	// name of parameter.
+	Name string `form:"name" json:"name" xml:"name"`
+	// provided parameter value.
+	Value *string `form:"value,omitempty" json:"value,omitempty" xml:"value,omitempty"`
+}
+
+// ListInvalidScopesResponseBody is the type of the "service" service "list"
+// endpoint HTTP response body for the "invalid-scopes" error.
+type ListInvalidScopesResponseBody struct {
+	// ID of involved resource
+	ID *string `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ListNotImplementedResponseBody is the type of the "service" service "list"
+// endpoint HTTP response body for the "not-implemented" error.
+type ListNotImplementedResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// CreateServiceBadRequestResponseBody is the type of the "service" service
+// "create_service" endpoint HTTP response body for the "bad-request" error.
+type CreateServiceBadRequestResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// CreateServiceInvalidParameterResponseBody is the type of the "service"
+// service "create_service" endpoint HTTP response body for the
+// "invalid-parameter" error.
+type CreateServiceInvalidParameterResponseBody struct {
+	// message describing expected type or pattern.
+	Message string `form:"message" json:"message" xml:"message"`
+	// name of parameter.
+	Name string `form:"name" json:"name" xml:"name"`
+	// provided parameter value.
+	Value *string `form:"value,omitempty" json:"value,omitempty" xml:"value,omitempty"`
+}
+
+// CreateServiceInvalidScopesResponseBody is the type of the "service" service
+// "create_service" endpoint HTTP response body for the "invalid-scopes" error.
+type CreateServiceInvalidScopesResponseBody struct {
+	// ID of involved resource
+	ID *string `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// CreateServiceNotImplementedResponseBody is the type of the "service" service
+// "create_service" endpoint HTTP response body for the "not-implemented" error.
+type CreateServiceNotImplementedResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// CreateServiceAlreadyCreatedResponseBody is the type of the "service" service
+// "create_service" endpoint HTTP response body for the "already-created" error.
+type CreateServiceAlreadyCreatedResponseBody struct {
+	// ID of already existing resource
+	ID string `form:"id" json:"id" xml:"id"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// CreateServiceNotFoundResponseBody is the type of the "service" service
+// "create_service" endpoint HTTP response body for the "not-found" error.
+type CreateServiceNotFoundResponseBody struct {
+	// ID of missing resource
+	ID string `form:"id" json:"id" xml:"id"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ReadBadRequestResponseBody is the type of the "service" service "read"
+// endpoint HTTP response body for the "bad-request" error.
+type ReadBadRequestResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ReadInvalidScopesResponseBody is the type of the "service" service "read"
+// endpoint HTTP response body for the "invalid-scopes" error.
+type ReadInvalidScopesResponseBody struct {
+	// ID of involved resource
+	ID *string `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ReadNotImplementedResponseBody is the type of the "service" service "read"
+// endpoint HTTP response body for the "not-implemented" error.
+type ReadNotImplementedResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ReadNotFoundResponseBody is the type of the "service" service "read"
+// endpoint HTTP response body for the "not-found" error.
+type ReadNotFoundResponseBody struct {
+	// ID of missing resource
+	ID string `form:"id" json:"id" xml:"id"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// UpdateBadRequestResponseBody is the type of the "service" service "update"
+// endpoint HTTP response body for the "bad-request" error.
+type UpdateBadRequestResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// UpdateInvalidParameterResponseBody is the type of the "service" service
+// "update" endpoint HTTP response body for the "invalid-parameter" error.
+type UpdateInvalidParameterResponseBody struct {
+	// message describing expected type or pattern.
+	Message string `form:"message" json:"message" xml:"message"`
+	// name of parameter.
+	Name string `form:"name" json:"name" xml:"name"`
+	// provided parameter value.
+	Value *string `form:"value,omitempty" json:"value,omitempty" xml:"value,omitempty"`
+}
+
+// UpdateInvalidScopesResponseBody is the type of the "service" service
+// "update" endpoint HTTP response body for the "invalid-scopes" error.
+type UpdateInvalidScopesResponseBody struct {
+	// ID of involved resource
+	ID *string `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// UpdateNotImplementedResponseBody is the type of the "service" service
+// "update" endpoint HTTP response body for the "not-implemented" error.
+type UpdateNotImplementedResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// UpdateNotFoundResponseBody is the type of the "service" service "update"
+// endpoint HTTP response body for the "not-found" error.
+type UpdateNotFoundResponseBody struct {
+	// ID of missing resource
+	ID string `form:"id" json:"id" xml:"id"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// DeleteBadRequestResponseBody is the type of the "service" service "delete"
+// endpoint HTTP response body for the "bad-request" error.
+type DeleteBadRequestResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// DeleteInvalidScopesResponseBody is the type of the "service" service
+// "delete" endpoint HTTP response body for the "invalid-scopes" error.
+type DeleteInvalidScopesResponseBody struct {
+	// ID of involved resource
+	ID *string `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	// Message of error
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// DeleteNotImplementedResponseBody is the type of the "service" service
+// "delete" endpoint HTTP response body for the "not-implemented" error.
+type DeleteNotImplementedResponseBody struct {
+	// Information message
+	Message string `form:"message" json:"message" xml:"message"`
+}
+
+// ServiceListItemResponseBody is used to define fields on response body types.
+type ServiceListItemResponseBody struct {
+	// Service ID
+	ID *string `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	// Optional customer provided name
+	Name *string `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	// Optional description of the service
+	Description *string `form:"description,omitempty" json:"description,omitempty" xml:"description,omitempty"`
+	// Optional provider link
+	Provider *RefTResponseBody  `form:"provider,omitempty" json:"provider,omitempty" xml:"provider,omitempty"`
+	Links    *SelfTResponseBody `form:"links" json:"links" xml:"links"`
+}
+
+// RefTResponseBody is used to define fields on response body types.
+type RefTResponseBody struct {
+	ID    *string            `form:"id,omitempty" json:"id,omitempty" xml:"id,omitempty"`
+	Links *SelfTResponseBody `form:"links,omitempty" json:"links,omitempty" xml:"links,omitempty"`
+}
+
+// SelfTResponseBody is used to define fields on response body types.
+type SelfTResponseBody struct {
+	Self        *string                   `form:"self,omitempty" json:"self,omitempty" xml:"self,omitempty"`
+	DescribedBy *DescribedByTResponseBody `form:"describedBy,omitempty" json:"describedBy,omitempty" xml:"describedBy,omitempty"`
+}
+
+// DescribedByTResponseBody is used to define fields on response body types.
+type DescribedByTResponseBody struct {
+	Href *string `form:"href,omitempty" json:"href,omitempty" xml:"href,omitempty"`
+	Type *string `form:"type,omitempty" json:"type,omitempty" xml:"type,omitempty"`
+}
+
+// NavTResponseBody is used to define fields on response body types.
+type NavTResponseBody struct {
+	Self  *string `form:"self,omitempty" json:"self,omitempty" xml:"self,omitempty"`
+	First *string `form:"first,omitempty" json:"first,omitempty" xml:"first,omitempty"`
+	Next  *string `form:"next,omitempty" json:"next,omitempty" xml:"next,omitempty"`
+}
+
+// ParameterTResponseBody is used to define fields on response body types.
+type ParameterTResponseBody struct {
+	Name  *string `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	Value *string `form:"value,omitempty" json:"value,omitempty" xml:"value,omitempty"`
+}
+
+// ParameterDefTResponseBody is used to define fields on response body types.
+type ParameterDefTResponseBody struct {
+	Name        *string                      `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	Label       *string                      `form:"label,omitempty" json:"label,omitempty" xml:"label,omitempty"`
+	Type        *string                      `form:"type,omitempty" json:"type,omitempty" xml:"type,omitempty"`
+	Description *string                      `form:"description,omitempty" json:"description,omitempty" xml:"description,omitempty"`
+	Unit        *string                      `form:"unit,omitempty" json:"unit,omitempty" xml:"unit,omitempty"`
+	Constant    *bool                        `form:"constant,omitempty" json:"constant,omitempty" xml:"constant,omitempty"`
+	Optional    *bool                        `form:"optional,omitempty" json:"optional,omitempty" xml:"optional,omitempty"`
+	Default     *string                      `form:"default,omitempty" json:"default,omitempty" xml:"default,omitempty"`
+	Options     []*ParameterOptTResponseBody `form:"options,omitempty" json:"options,omitempty" xml:"options,omitempty"`
+	Unary       *bool                        `form:"unary,omitempty" json:"unary,omitempty" xml:"unary,omitempty"`
+}
+
+// ParameterOptTResponseBody is used to define fields on response body types.
+type ParameterOptTResponseBody struct {
+	Value       *string `form:"value,omitempty" json:"value,omitempty" xml:"value,omitempty"`
+	Description *string `form:"description,omitempty" json:"description,omitempty" xml:"description,omitempty"`
+}
+
+// ParameterTRequestBodyRequestBody is used to define fields on request body
+// types.
+type ParameterTRequestBodyRequestBody struct {
+	Name  *string `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	Value *string `form:"value,omitempty" json:"value,omitempty" xml:"value,omitempty"`
+}
+
+// ReferenceTRequestBodyRequestBody is used to define fields on request body
+// types.
+type ReferenceTRequestBodyRequestBody struct {
+	// Title of reference document
+	Title *string `form:"title,omitempty" json:"title,omitempty" xml:"title,omitempty"`
+	// Link to document
+	URI *string `form:"uri,omitempty" json:"uri,omitempty" xml:"uri,omitempty"`
+}
+
+// WorkflowTRequestBodyRequestBody is used to define fields on request body
+// types.
+type WorkflowTRequestBodyRequestBody struct {
+	// Type of workflow
+	Type *string `form:"type,omitempty" json:"type,omitempty" xml:"type,omitempty"`
+	// Type of workflow
+	Basic *BasicWorkflowOptsTRequestBodyRequestBody `form:"basic,omitempty" json:"basic,omitempty" xml:"basic,omitempty"`
+	// Defines the workflow using argo's WF schema
+	Argo interface{} `form:"argo,omitempty" json:"argo,omitempty" xml:"argo,omitempty"`
+	// Type specific options - left for backward compatibility, if possible use
+	// type specific elements
+	Opts interface{} `form:"opts,omitempty" json:"opts,omitempty" xml:"opts,omitempty"`
+}
+
+// BasicWorkflowOptsTRequestBodyRequestBody is used to define fields on request
+// body types.
+type BasicWorkflowOptsTRequestBodyRequestBody struct {
+	// container image name
+	Image *string `form:"image,omitempty" json:"image,omitempty" xml:"image,omitempty"`
+	// Command to start the container - needed for some container runtimes
+	Command []string `form:"command,omitempty" json:"command,omitempty" xml:"command,omitempty"`
+	// Defines memory resource requests and limits
+	Memory *ResourceMemoryTRequestBodyRequestBody `form:"memory,omitempty" json:"memory,omitempty" xml:"memory,omitempty"`
+	// Defines cpu resource requests and limits
+	// (see
+	// https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/#meaning-of-cpu)
+	CPU *ResourceMemoryTRequestBodyRequestBody `form:"cpu,omitempty" json:"cpu,omitempty" xml:"cpu,omitempty"`
+	// Defines ephemeral storage resource requests and limits
+	// (see
+	// https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/#local-ephemeral-storage)
+	EphemeralStorage *ResourceMemoryTRequestBodyRequestBody `json:"ephemeral-storage,omitempty"`
+}
+
+// ResourceMemoryTRequestBodyRequestBody is used to define fields on request
+// body types.
+type ResourceMemoryTRequestBodyRequestBody struct {
+	// minimal requirements [0]
+	Request *string `form:"request,omitempty" json:"request,omitempty" xml:"request,omitempty"`
+	// minimal requirements [system limit]
+	Limit *string `form:"limit,omitempty" json:"limit,omitempty" xml:"limit,omitempty"`
+}
+
+// ParameterDefTRequestBodyRequestBody is used to define fields on request body
+// types.
+type ParameterDefTRequestBodyRequestBody struct {
+	Name        *string                                `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+	Label       *string                                `form:"label,omitempty" json:"label,omitempty" xml:"label,omitempty"`
+	Type        *string                                `form:"type,omitempty" json:"type,omitempty" xml:"type,omitempty"`
+	Description *string                                `form:"description,omitempty" json:"description,omitempty" xml:"description,omitempty"`
+	Unit        *string                                `form:"unit,omitempty" json:"unit,omitempty" xml:"unit,omitempty"`
+	Constant    *bool                                  `form:"constant,omitempty" json:"constant,omitempty" xml:"constant,omitempty"`
+	Optional    *bool                                  `form:"optional,omitempty" json:"optional,omitempty" xml:"optional,omitempty"`
+	Default     *string                                `form:"default,omitempty" json:"default,omitempty" xml:"default,omitempty"`
+	Options     []*ParameterOptTRequestBodyRequestBody `form:"options,omitempty" json:"options,omitempty" xml:"options,omitempty"`
+	Unary       *bool                                  `form:"unary,omitempty" json:"unary,omitempty" xml:"unary,omitempty"`
+}
+
+// ParameterOptTRequestBodyRequestBody is used to define fields on request body
+// types.
+type ParameterOptTRequestBodyRequestBody struct {
+	Value       *string `form:"value,omitempty" json:"value,omitempty" xml:"value,omitempty"`
+	Description *string `form:"description,omitempty" json:"description,omitempty" xml:"description,omitempty"`
+}
+
+// NewListResponseBody builds the HTTP response body from the result of the
+// "list" endpoint of the "service" service.
+func NewListResponseBody(res *serviceviews.ServiceListRTView) *ListResponseBody {
+	body := &ListResponseBody{
+		AtTime: *res.AtTime,
+	}
+	if res.Services != nil {
+		body.Services = make([]*ServiceListItemResponseBody, len(res.Services))
+		for i, val := range res.Services {
+			body.Services[i] = marshalServiceviewsServiceListItemViewToServiceListItemResponseBody(val)
+		}
+	}
+	if res.Links != nil {
+		body.Links = marshalServiceviewsNavTViewToNavTResponseBody(res.Links)
+	}
+	return body
+}
+
+// NewCreateServiceResponseBody builds the HTTP response body from the result
+// of the "create_service" endpoint of the "service" service.
+func NewCreateServiceResponseBody(res *serviceviews.ServiceStatusRTView) *CreateServiceResponseBody {
+	body := &CreateServiceResponseBody{
+		ID:          *res.ID,
+		Description: res.Description,
+		Name:        res.Name,
+	}
+	if res.Metadata != nil {
+		body.Metadata = make([]*ParameterTResponseBody, len(res.Metadata))
+		for i, val := range res.Metadata {
+			body.Metadata[i] = marshalServiceviewsParameterTViewToParameterTResponseBody(val)
+		}
+	}
+	if res.Provider != nil {
+		body.Provider = marshalServiceviewsRefTViewToRefTResponseBody(res.Provider)
+	}
+	if res.Account != nil {
+		body.Account = marshalServiceviewsRefTViewToRefTResponseBody(res.Account)
+	}
+	if res.Links != nil {
+		body.Links = marshalServiceviewsSelfTViewToSelfTResponseBody(res.Links)
+	}
+	if res.Tags != nil {
+		body.Tags = make([]string, len(res.Tags))
+		for i, val := range res.Tags {
+			body.Tags[i] = val
+		}
+	}
+	if res.Parameters != nil {
+		body.Parameters = make([]*ParameterDefTResponseBody, len(res.Parameters))
+		for i, val := range res.Parameters {
+			body.Parameters[i] = marshalServiceviewsParameterDefTViewToParameterDefTResponseBody(val)
+		}
+	}
+	return body
+}
+
+// NewCreateServiceResponseBodyTiny builds the HTTP response body from the
+// result of the "create_service" endpoint of the "service" service.
+func NewCreateServiceResponseBodyTiny(res *serviceviews.ServiceStatusRTView) *CreateServiceResponseBodyTiny {
+	body := &CreateServiceResponseBodyTiny{
+		Name: res.Name,
+	}
+	if res.Links != nil {
+		body.Links = marshalServiceviewsSelfTViewToSelfTResponseBody(res.Links)
+	}
+	return body
+}
+
+// NewReadResponseBody builds the HTTP response body from the result of the
+// "read" endpoint of the "service" service.
+func NewReadResponseBody(res *serviceviews.ServiceStatusRTView) *ReadResponseBody {
+	body := &ReadResponseBody{
+		ID:          *res.ID,
+		Description: res.Description,
+		Name:        res.Name,
+	}
+	if res.Metadata != nil {
+		body.Metadata = make([]*ParameterTResponseBody, len(res.Metadata))
+		for i, val := range res.Metadata {
+			body.Metadata[i] = marshalServiceviewsParameterTViewToParameterTResponseBody(val)
+		}
+	}
+	if res.Provider != nil {
+		body.Provider = marshalServiceviewsRefTViewToRefTResponseBody(res.Provider)
+	}
+	if res.Account != nil {
+		body.Account = marshalServiceviewsRefTViewToRefTResponseBody(res.Account)
+	}
+	if res.Links != nil {
+		body.Links = marshalServiceviewsSelfTViewToSelfTResponseBody(res.Links)
+	}
+	if res.Tags != nil {
+		body.Tags = make([]string, len(res.Tags))
+		for i, val := range res.Tags {
+			body.Tags[i] = val
+		}
+	}
+	if res.Parameters != nil {
+		body.Parameters = make([]*ParameterDefTResponseBody, len(res.Parameters))
+		for i, val := range res.Parameters {
+			body.Parameters[i] = marshalServiceviewsParameterDefTViewToParameterDefTResponseBody(val)
+		}
+	}
+	return body
+}
+
+// NewReadResponseBodyTiny builds the HTTP response body from the result of the
+// "read" endpoint of the "service" service.
+func NewReadResponseBodyTiny(res *serviceviews.ServiceStatusRTView) *ReadResponseBodyTiny {
+	body := &ReadResponseBodyTiny{
+		Name: res.Name,
+	}
+	if res.Links != nil {
+		body.Links = marshalServiceviewsSelfTViewToSelfTResponseBody(res.Links)
+	}
+	return body
+}
+
+// NewUpdateResponseBody builds the HTTP response body from the result of the
+// "update" endpoint of the "service" service.
+func NewUpdateResponseBody(res *serviceviews.ServiceStatusRTView) *UpdateResponseBody {
+	body := &UpdateResponseBody{
+		ID:          *res.ID,
+		Description: res.Description,
+		Name:        res.Name,
+	}
+	if res.Metadata != nil {
+		body.Metadata = make([]*ParameterTResponseBody, len(res.Metadata))
+		for i, val := range res.Metadata {
+			body.Metadata[i] = marshalServiceviewsParameterTViewToParameterTResponseBody(val)
+		}
+	}
+	if res.Provider != nil {
+		body.Provider = marshalServiceviewsRefTViewToRefTResponseBody(res.Provider)
+	}
+	if res.Account != nil {
+		body.Account = marshalServiceviewsRefTViewToRefTResponseBody(res.Account)
+	}
+	if res.Links != nil {
+		body.Links = marshalServiceviewsSelfTViewToSelfTResponseBody(res.Links)
+	}
+	if res.Tags != nil {
+		body.Tags = make([]string, len(res.Tags))
+		for i, val := range res.Tags {
+			body.Tags[i] = val
+		}
+	}
+	if res.Parameters != nil {
+		body.Parameters = make([]*ParameterDefTResponseBody, len(res.Parameters))
+		for i, val := range res.Parameters {
+			body.Parameters[i] = marshalServiceviewsParameterDefTViewToParameterDefTResponseBody(val)
+		}
+	}
+	return body
+}
+
+// NewUpdateResponseBodyTiny builds the HTTP response body from the result of
+// the "update" endpoint of the "service" service.
+func NewUpdateResponseBodyTiny(res *serviceviews.ServiceStatusRTView) *UpdateResponseBodyTiny {
+	body := &UpdateResponseBodyTiny{
+		Name: res.Name,
+	}
+	if res.Links != nil {
+		body.Links = marshalServiceviewsSelfTViewToSelfTResponseBody(res.Links)
+	}
+	return body
+}
+
+// NewListBadRequestResponseBody builds the HTTP response body from the result
+// of the "list" endpoint of the "service" service.
+func NewListBadRequestResponseBody(res *service.BadRequestT) *ListBadRequestResponseBody {
+	body := &ListBadRequestResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewListInvalidParameterResponseBody builds the HTTP response body from the
+// result of the "list" endpoint of the "service" service.
+func NewListInvalidParameterResponseBody(res *service.InvalidParameterValue) *ListInvalidParameterResponseBody {
+	body := &ListInvalidParameterResponseBody{
+		Message: res.Message,
+		Name:    res.Name,
+		Value:   res.Value,
+	}
+	return body
+}
+
+// NewListInvalidScopesResponseBody builds the HTTP response body from the
+// result of the "list" endpoint of the "service" service.
+func NewListInvalidScopesResponseBody(res *service.InvalidScopesT) *ListInvalidScopesResponseBody {
+	body := &ListInvalidScopesResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewListNotImplementedResponseBody builds the HTTP response body from the
+// result of the "list" endpoint of the "service" service.
+func NewListNotImplementedResponseBody(res *service.NotImplementedT) *ListNotImplementedResponseBody {
+	body := &ListNotImplementedResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewCreateServiceBadRequestResponseBody builds the HTTP response body from
+// the result of the "create_service" endpoint of the "service" service.
+func NewCreateServiceBadRequestResponseBody(res *service.BadRequestT) *CreateServiceBadRequestResponseBody {
+	body := &CreateServiceBadRequestResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewCreateServiceInvalidParameterResponseBody builds the HTTP response body
+// from the result of the "create_service" endpoint of the "service" service.
+func NewCreateServiceInvalidParameterResponseBody(res *service.InvalidParameterValue) *CreateServiceInvalidParameterResponseBody {
+	body := &CreateServiceInvalidParameterResponseBody{
+		Message: res.Message,
+		Name:    res.Name,
+		Value:   res.Value,
+	}
+	return body
+}
+
+// NewCreateServiceInvalidScopesResponseBody builds the HTTP response body from
+// the result of the "create_service" endpoint of the "service" service.
+func NewCreateServiceInvalidScopesResponseBody(res *service.InvalidScopesT) *CreateServiceInvalidScopesResponseBody {
+	body := &CreateServiceInvalidScopesResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewCreateServiceNotImplementedResponseBody builds the HTTP response body
+// from the result of the "create_service" endpoint of the "service" service.
+func NewCreateServiceNotImplementedResponseBody(res *service.NotImplementedT) *CreateServiceNotImplementedResponseBody {
+	body := &CreateServiceNotImplementedResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewCreateServiceAlreadyCreatedResponseBody builds the HTTP response body
+// from the result of the "create_service" endpoint of the "service" service.
+func NewCreateServiceAlreadyCreatedResponseBody(res *service.ResourceAlreadyCreatedT) *CreateServiceAlreadyCreatedResponseBody {
+	body := &CreateServiceAlreadyCreatedResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewCreateServiceNotFoundResponseBody builds the HTTP response body from the
+// result of the "create_service" endpoint of the "service" service.
+func NewCreateServiceNotFoundResponseBody(res *service.ResourceNotFoundT) *CreateServiceNotFoundResponseBody {
+	body := &CreateServiceNotFoundResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewReadBadRequestResponseBody builds the HTTP response body from the result
+// of the "read" endpoint of the "service" service.
+func NewReadBadRequestResponseBody(res *service.BadRequestT) *ReadBadRequestResponseBody {
+	body := &ReadBadRequestResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewReadInvalidScopesResponseBody builds the HTTP response body from the
+// result of the "read" endpoint of the "service" service.
+func NewReadInvalidScopesResponseBody(res *service.InvalidScopesT) *ReadInvalidScopesResponseBody {
+	body := &ReadInvalidScopesResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewReadNotImplementedResponseBody builds the HTTP response body from the
+// result of the "read" endpoint of the "service" service.
+func NewReadNotImplementedResponseBody(res *service.NotImplementedT) *ReadNotImplementedResponseBody {
+	body := &ReadNotImplementedResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewReadNotFoundResponseBody builds the HTTP response body from the result of
+// the "read" endpoint of the "service" service.
+func NewReadNotFoundResponseBody(res *service.ResourceNotFoundT) *ReadNotFoundResponseBody {
+	body := &ReadNotFoundResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewUpdateBadRequestResponseBody builds the HTTP response body from the
+// result of the "update" endpoint of the "service" service.
+func NewUpdateBadRequestResponseBody(res *service.BadRequestT) *UpdateBadRequestResponseBody {
+	body := &UpdateBadRequestResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewUpdateInvalidParameterResponseBody builds the HTTP response body from the
+// result of the "update" endpoint of the "service" service.
+func NewUpdateInvalidParameterResponseBody(res *service.InvalidParameterValue) *UpdateInvalidParameterResponseBody {
+	body := &UpdateInvalidParameterResponseBody{
+		Message: res.Message,
+		Name:    res.Name,
+		Value:   res.Value,
+	}
+	return body
+}
+
+// NewUpdateInvalidScopesResponseBody builds the HTTP response body from the
+// result of the "update" endpoint of the "service" service.
+func NewUpdateInvalidScopesResponseBody(res *service.InvalidScopesT) *UpdateInvalidScopesResponseBody {
+	body := &UpdateInvalidScopesResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewUpdateNotImplementedResponseBody builds the HTTP response body from the
+// result of the "update" endpoint of the "service" service.
+func NewUpdateNotImplementedResponseBody(res *service.NotImplementedT) *UpdateNotImplementedResponseBody {
+	body := &UpdateNotImplementedResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewUpdateNotFoundResponseBody builds the HTTP response body from the result
+// of the "update" endpoint of the "service" service.
+func NewUpdateNotFoundResponseBody(res *service.ResourceNotFoundT) *UpdateNotFoundResponseBody {
+	body := &UpdateNotFoundResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewDeleteBadRequestResponseBody builds the HTTP response body from the
+// result of the "delete" endpoint of the "service" service.
+func NewDeleteBadRequestResponseBody(res *service.BadRequestT) *DeleteBadRequestResponseBody {
+	body := &DeleteBadRequestResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewDeleteInvalidScopesResponseBody builds the HTTP response body from the
+// result of the "delete" endpoint of the "service" service.
+func NewDeleteInvalidScopesResponseBody(res *service.InvalidScopesT) *DeleteInvalidScopesResponseBody {
+	body := &DeleteInvalidScopesResponseBody{
+		ID:      res.ID,
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewDeleteNotImplementedResponseBody builds the HTTP response body from the
+// result of the "delete" endpoint of the "service" service.
+func NewDeleteNotImplementedResponseBody(res *service.NotImplementedT) *DeleteNotImplementedResponseBody {
+	body := &DeleteNotImplementedResponseBody{
+		Message: res.Message,
+	}
+	return body
+}
+
+// NewListPayload builds a service service list endpoint payload.
+func NewListPayload(limit int, page *string, filter *string, orderBy *string, orderDesc bool, atTime *string, jwt string) *service.ListPayload {
+	v := &service.ListPayload{}
+	v.Limit = limit
+	v.Page = page
+	v.Filter = filter
+	v.OrderBy = orderBy
+	v.OrderDesc = orderDesc
+	v.AtTime = atTime
+	v.JWT = jwt
+
+	return v
+}
+
+// NewCreateServicePayload builds a service service create_service endpoint
+// payload.
+func NewCreateServicePayload(body *CreateServiceRequestBody, jwt string) *service.CreateServicePayload {
+	v := &service.ServiceDescriptionT{
+		ProviderRef: body.ProviderRef,
+		ProviderID:  *body.ProviderID,
+		Description: *body.Description,
+		Banner:      body.Banner,
+		PolicyID:    body.PolicyID,
+		Name:        body.Name,
+	}
+	if body.Metadata != nil {
+		v.Metadata = make([]*service.ParameterT, len(body.Metadata))
+		for i, val := range body.Metadata {
+			v.Metadata[i] = unmarshalParameterTRequestBodyRequestBodyToServiceParameterT(val)
+		}
+	}
+	if body.References != nil {
+		v.References = make([]*service.ReferenceT, len(body.References))
+		for i, val := range body.References {
+			v.References[i] = unmarshalReferenceTRequestBodyRequestBodyToServiceReferenceT(val)
+		}
+	}
+	v.Workflow = unmarshalWorkflowTRequestBodyRequestBodyToServiceWorkflowT(body.Workflow)
+	if body.Tags != nil {
+		v.Tags = make([]string, len(body.Tags))
+		for i, val := range body.Tags {
+			v.Tags[i] = val
+		}
+	}
+	v.Parameters = make([]*service.ParameterDefT, len(body.Parameters))
+	for i, val := range body.Parameters {
+		v.Parameters[i] = unmarshalParameterDefTRequestBodyRequestBodyToServiceParameterDefT(val)
+	}
+	res := &service.CreateServicePayload{
+		Services: v,
+	}
+	res.JWT = jwt
+
+	return res
+}
+
+// NewReadPayload builds a service service read endpoint payload.
+func NewReadPayload(id string, jwt string) *service.ReadPayload {
+	v := &service.ReadPayload{}
+	v.ID = id
+	v.JWT = jwt
+
+	return v
+}
+
+// NewUpdatePayload builds a service service update endpoint payload.
+func NewUpdatePayload(body *UpdateRequestBody, id string, forceCreate *bool, jwt string) *service.UpdatePayload {
+	v := &service.ServiceDescriptionT{
+		ProviderRef: body.ProviderRef,
+		ProviderID:  *body.ProviderID,
+		Description: *body.Description,
+		Banner:      body.Banner,
+		PolicyID:    body.PolicyID,
+		Name:        body.Name,
+	}
+	if body.Metadata != nil {
+		v.Metadata = make([]*service.ParameterT, len(body.Metadata))
+		for i, val := range body.Metadata {
+			v.Metadata[i] = unmarshalParameterTRequestBodyRequestBodyToServiceParameterT(val)
+		}
+	}
+	if body.References != nil {
+		v.References = make([]*service.ReferenceT, len(body.References))
+		for i, val := range body.References {
+			v.References[i] = unmarshalReferenceTRequestBodyRequestBodyToServiceReferenceT(val)
+		}
+	}
+	v.Workflow = unmarshalWorkflowTRequestBodyRequestBodyToServiceWorkflowT(body.Workflow)
+	if body.Tags != nil {
+		v.Tags = make([]string, len(body.Tags))
+		for i, val := range body.Tags {
+			v.Tags[i] = val
+		}
+	}
+	v.Parameters = make([]*service.ParameterDefT, len(body.Parameters))
+	for i, val := range body.Parameters {
+		v.Parameters[i] = unmarshalParameterDefTRequestBodyRequestBodyToServiceParameterDefT(val)
+	}
+	res := &service.UpdatePayload{
+		Services: v,
+	}
+	res.ID = &id
+	res.ForceCreate = forceCreate
+	res.JWT = jwt
+
+	return res
+}
+
+// NewDeletePayload builds a service service delete endpoint payload.
+func NewDeletePayload(id string, jwt string) *service.DeletePayload {
+	v := &service.DeletePayload{}
+	v.ID = id
+	v.JWT = jwt
+
+	return v
+}
+
+// ValidateCreateServiceRequestBody runs the validations defined on
+// create_service_request_body
+func ValidateCreateServiceRequestBody(body *CreateServiceRequestBody) (err error) {
+	if body.Description == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("description", "body"))
+	}
+	if body.ProviderID == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("provider-id", "body"))
+	}
+	if body.Workflow == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("workflow", "body"))
+	}
+	if body.Parameters == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("parameters", "body"))
+	}
+	if body.ProviderID != nil {
+		err = goa.MergeErrors(err, goa.ValidateFormat("body.provider-id", *body.ProviderID, goa.FormatURI))
+	}
+	for _, e := range body.References {
+		if e != nil {
+			if err2 := ValidateReferenceTRequestBodyRequestBody(e); err2 != nil {
+				err = goa.MergeErrors(err, err2)
+			}
+		}
+	}
+	if body.Banner != nil {
+		err = goa.MergeErrors(err, goa.ValidateFormat("body.banner", *body.Banner, goa.FormatURI))
+	}
+	if body.Workflow != nil {
+		if err2 := ValidateWorkflowTRequestBodyRequestBody(body.Workflow); err2 != nil {
+			err = goa.MergeErrors(err, err2)
+		}
+	}
+	return
+}
+
+// ValidateUpdateRequestBody runs the validations defined on UpdateRequestBody
+func ValidateUpdateRequestBody(body *UpdateRequestBody) (err error) {
+	if body.Description == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("description", "body"))
+	}
+	if body.ProviderID == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("provider-id", "body"))
+	}
+	if body.Workflow == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("workflow", "body"))
+	}
+	if body.Parameters == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("parameters", "body"))
+	}
+	if body.ProviderID != nil {
+		err = goa.MergeErrors(err, goa.ValidateFormat("body.provider-id", *body.ProviderID, goa.FormatURI))
+	}
+	for _, e := range body.References {
+		if e != nil {
+			if err2 := ValidateReferenceTRequestBodyRequestBody(e); err2 != nil {
+				err = goa.MergeErrors(err, err2)
+			}
+		}
+	}
+	if body.Banner != nil {
+		err = goa.MergeErrors(err, goa.ValidateFormat("body.banner", *body.Banner, goa.FormatURI))
+	}
+	if body.Workflow != nil {
+		if err2 := ValidateWorkflowTRequestBodyRequestBody(body.Workflow); err2 != nil {
+			err = goa.MergeErrors(err, err2)
+		}
+	}
+	return
+}
+
+// ValidateReferenceTRequestBodyRequestBody runs the validations defined on
+// ReferenceTRequestBodyRequestBody
+func ValidateReferenceTRequestBodyRequestBody(body *ReferenceTRequestBodyRequestBody) (err error) {
+	if body.URI != nil {
+		err = goa.MergeErrors(err, goa.ValidateFormat("body.uri", *body.URI, goa.FormatURI))
+	}
+	return
+}
+
+// ValidateWorkflowTRequestBodyRequestBody runs the validations defined on
+// WorkflowTRequestBodyRequestBody
+func ValidateWorkflowTRequestBodyRequestBody(body *WorkflowTRequestBodyRequestBody) (err error) {
+	if body.Basic != nil {
+		if err2 := ValidateBasicWorkflowOptsTRequestBodyRequestBody(body.Basic); err2 != nil {
+			err = goa.MergeErrors(err, err2)
+		}
+	}
+	return
+}
+
+// ValidateBasicWorkflowOptsTRequestBodyRequestBody runs the validations
+// defined on BasicWorkflowOptsTRequestBodyRequestBody
+func ValidateBasicWorkflowOptsTRequestBodyRequestBody(body *BasicWorkflowOptsTRequestBodyRequestBody) (err error) {
+	if body.Image == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("image", "body"))
+	}
+	if body.Command == nil {
+		err = goa.MergeErrors(err, goa.MissingFieldError("command", "body"))
+	}
+	return
+}
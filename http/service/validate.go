@@ -0,0 +1,79 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"github.com/reinventingscience/ivcap-core-api/argo"
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+	"github.com/reinventingscience/ivcap-core-api/resource"
+	goa "goa.design/goa/v3/pkg"
+)
+
+// BuildCheckedCreateServicePayload is like BuildCreateServicePayload but also
+// rejects service descriptions failing ValidateDescription.
+func BuildCheckedCreateServicePayload(serviceCreateServiceBody string, serviceCreateServiceJWT string) (*service.CreateServicePayload, error) {
+	res, err := BuildCreateServicePayload(serviceCreateServiceBody, serviceCreateServiceJWT)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateDescription("body", res.Services); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// BuildCheckedUpdatePayload is like BuildUpdatePayload but also rejects
+// service descriptions failing ValidateDescription.
+func BuildCheckedUpdatePayload(serviceUpdateBody string, serviceUpdateID string, serviceUpdateForceCreate string, serviceUpdateJWT string) (*service.UpdatePayload, error) {
+	res, err := BuildUpdatePayload(serviceUpdateBody, serviceUpdateID, serviceUpdateForceCreate, serviceUpdateJWT)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateDescription("body", res.Services); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ValidateDescription checks what the generated validation of a service
+// description cannot: the definition of an Argo workflow, see
+// argo.ValidateService, and the resource requests and limits of a basic
+// workflow, see resource.ValidateRequirements. name is the path of desc in
+// errors, e.g. "body", or empty. Errors are reported as goa validation
+// errors.
+func ValidateDescription(name string, desc *service.ServiceDescriptionT) error {
+	err := argo.ValidateService(name, desc)
+	if desc == nil || desc.Workflow == nil || desc.Workflow.Basic == nil {
+		return err
+	}
+	path := "workflow.basic"
+	if name != "" {
+		path = name + "." + path
+	}
+	b := desc.Workflow.Basic
+	for _, r := range []struct {
+		name string
+		res  *service.ResourceMemoryT
+	}{
+		{"memory", b.Memory},
+		{"cpu", b.CPU},
+		{"ephemeral-storage", b.EphemeralStorage},
+	} {
+		if r.res != nil {
+			err = goa.MergeErrors(err, resource.ValidateRequirements(path+"."+r.name, r.res.Request, r.res.Limit))
+		}
+	}
+	return err
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildCheckedCreateServicePayload(t *testing.T) {
+	for _, tc := range []struct {
+		name, workflow, want string
+	}{
+		{"basic", `{"type": "basic", "basic": {"image": "alpine", "command": ["true"], "cpu": {"request": "10m", "limit": "100m"}}}`, ""},
+		{"request above limit", `{"type": "basic", "basic": {"image": "alpine", "command": ["true"], "memory": {"request": "1Gi", "limit": "100Mi"}}}`,
+			"body.workflow.basic.memory.request must be lesser or equal than body.workflow.basic.memory.limit"},
+		{"malformed limit", `{"type": "basic", "basic": {"image": "alpine", "command": ["true"], "cpu": {"limit": "lots"}}}`,
+			"body.workflow.basic.cpu.limit"},
+		{"argo", `{"type": "argo", "argo": {"entrypoint": "main", "templates": [{"name": "main", "container": {"image": "alpine"}}]}}`, ""},
+		{"invalid argo", `{"type": "argo", "argo": {"entrypoint": "main", "templates": [{"name": "other", "container": {"image": "alpine"}}]}}`,
+			`body.workflow.argo.entrypoint refers to undefined template "main"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			body := `{"name": "test", "provider-id": "urn:ivcap:provider:1", "parameters": [], "workflow": ` + tc.workflow + `}`
+			res, err := BuildCheckedCreateServicePayload(body, "token")
+			if tc.want == "" {
+				if err != nil || res.Services == nil {
+					t.Fatalf("got %v, %v", res, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("error = %v, want one containing %q", err, tc.want)
+			}
+			if _, err := BuildUpdatePayload(body, "urn:ivcap:service:1", "false", "token"); err != nil {
+				t.Errorf("generated builder rejects the body: %v", err)
+			}
+			if _, err := BuildCheckedUpdatePayload(body, "urn:ivcap:service:1", "false", "token"); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("update error = %v, want one containing %q", err, tc.want)
+			}
+		})
+	}
+}
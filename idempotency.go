@@ -0,0 +1,100 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+	orderc "github.com/reinventingscience/ivcap-core-api/http/order"
+	"github.com/reinventingscience/ivcap-core-api/ivcaperr"
+	"github.com/reinventingscience/ivcap-core-api/query"
+
+	"github.com/google/uuid"
+)
+
+// NewIdempotencyKey returns a random key suitable for CreateWithKey or the
+// WithIdempotencyKey functions of the HTTP client packages.
+func NewIdempotencyKey() string {
+	return uuid.NewString()
+}
+
+// CreateWithKey places an order with idempotency key key, so retrying it
+// cannot place the order twice. If the outcome of the request is unknown,
+// for instance because it timed out or the connection was lost,
+// CreateWithKey looks for an order placed with key and returns it if found.
+// Otherwise the original error is returned and the call may be repeated with
+// the same key.
+func (c *OrdersClient) CreateWithKey(ctx context.Context, req *order.OrderRequestT, key string) (*order.OrderStatusRT, error) {
+	started := time.Now()
+	res, err := c.client.Create(orderc.WithIdempotencyKey(ctx, key), &order.CreatePayload{Orders: req})
+	if err == nil || !ambiguous(err) {
+		return res, err
+	}
+	// Look up the order even if ctx timed out, allowing for clock skew
+	// between client and service.
+	lctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Minute)
+	defer cancel()
+	if o, ferr := c.FindByIdempotencyKey(lctx, req.ServiceID, key, started.Add(-5*time.Minute)); ferr == nil {
+		return o, nil
+	}
+	return nil, err
+}
+
+// FindByIdempotencyKey returns the newest order for service serviceID placed
+// with idempotency key key since the given time. The service filters the
+// orders on their "idempotency-key" field, so only the order found is read.
+// If serviceID is empty, orders of any service match. If no such order
+// exists, an error matching ivcaperr.ErrNotFound is returned.
+//
+// The "idempotency-key" field is not part of the published order API and
+// only services which support it, such as ivcapfake, can look orders up.
+// Others reject the filter with an error matching ivcaperr.ErrBadRequest or
+// ivcaperr.ErrInvalidParameter, in which case CreateWithKey returns the
+// error of the create request.
+func (c *OrdersClient) FindByIdempotencyKey(ctx context.Context, serviceID, key string, since time.Time) (*order.OrderStatusRT, error) {
+	f := query.And(query.Eq("idempotency-key", key), query.Ge("ordered-at", since))
+	if serviceID != "" {
+		f = query.And(f, query.Eq("service-id", serviceID))
+	}
+	res, err := c.List(ctx, &order.ListPayload{
+		Limit:     1,
+		Filter:    query.Filter(f),
+		OrderBy:   query.OrderBy(query.Order{Field: "ordered-at"}),
+		OrderDesc: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Orders) > 0 && res.Orders[0].ID != nil {
+		o, err := c.Read(ctx, *res.Orders[0].ID)
+		if !errors.Is(err, ivcaperr.ErrNotFound) {
+			return o, err
+		}
+	}
+	return nil, ivcaperr.Wrap("order", "list", key, &order.ResourceNotFoundT{ID: key, Message: "no order with this idempotency key"})
+}
+
+// ambiguous returns true if a request failing with err may nevertheless
+// have been performed by the service.
+func ambiguous(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return true
+	}
+	k := ivcaperr.KindOf(err)
+	return k == ivcaperr.ErrTransport || k == ivcaperr.ErrUnavailable || k == ivcaperr.ErrInvalidResponse
+}
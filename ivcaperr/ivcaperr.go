@@ -0,0 +1,296 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ivcaperr classifies the errors returned by the IVCAP services.
+//
+// Every service package defines its own error types, such as
+// order.ResourceNotFoundT and artifact.ResourceNotFoundT. KindOf maps all of
+// them to the corresponding kind defined here. Errors returned by the ivcap
+// client are wrapped in an *Error carrying the kind together with the HTTP
+// status, service, method and resource ID, so callers can handle them
+// without a type switch per service:
+//
+//	if errors.Is(err, ivcaperr.ErrNotFound) {
+//		...
+//	}
+package ivcaperr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	goahttp "goa.design/goa/v3/http"
+)
+
+// Kind is a class of errors. Kinds are compared by identity, use errors.Is to
+// test whether an error is of a given kind.
+type Kind struct {
+	name      string
+	status    int
+	retryable bool
+}
+
+// Error returns the name of the kind.
+func (k *Kind) Error() string {
+	return k.name
+}
+
+// StatusCode returns the HTTP status code the service uses for errors of
+// this kind, or zero if there is none.
+func (k *Kind) StatusCode() int {
+	return k.status
+}
+
+// Retryable returns true if a request failing with an error of this kind may
+// succeed when it is retried unchanged.
+func (k *Kind) Retryable() bool {
+	return k.retryable
+}
+
+// Error kinds returned by the IVCAP services.
+var (
+	// ErrBadRequest is returned for malformed requests.
+	ErrBadRequest = &Kind{"bad request", http.StatusBadRequest, false}
+	// ErrInvalidCredentials is returned if the provided credentials are not
+	// valid.
+	ErrInvalidCredentials = &Kind{"invalid credentials", http.StatusBadRequest, false}
+	// ErrInvalidParameter is returned if a parameter has a semantically wrong
+	// value.
+	ErrInvalidParameter = &Kind{"invalid parameter", http.StatusUnprocessableEntity, false}
+	// ErrInvalidScopes is returned if the token lacks a required scope.
+	ErrInvalidScopes = &Kind{"invalid scopes", http.StatusForbidden, false}
+	// ErrUnauthorized is returned if the caller is not authorized.
+	ErrUnauthorized = &Kind{"unauthorized", http.StatusUnauthorized, false}
+	// ErrNotFound is returned if the resource does not exist.
+	ErrNotFound = &Kind{"not found", http.StatusNotFound, false}
+	// ErrAlreadyExists is returned if the resource to create already exists.
+	ErrAlreadyExists = &Kind{"already exists", http.StatusConflict, false}
+	// ErrNotImplemented is returned for methods the service does not
+	// implement yet.
+	ErrNotImplemented = &Kind{"not implemented", http.StatusNotImplemented, false}
+	// ErrUnavailable is returned if the service is temporarily unavailable.
+	ErrUnavailable = &Kind{"service unavailable", http.StatusServiceUnavailable, true}
+	// ErrTooManyRequests is returned if the caller is rate limited.
+	ErrTooManyRequests = &Kind{"too many requests", http.StatusTooManyRequests, true}
+	// ErrTransport is returned if the request could not be sent or no
+	// response was received.
+	ErrTransport = &Kind{"transport error", 0, true}
+	// ErrInvalidResponse is returned if the response could not be decoded or
+	// had an unexpected status.
+	ErrInvalidResponse = &Kind{"invalid response", 0, false}
+)
+
+// kinds lists all kinds, most specific first.
+var kinds = []*Kind{
+	ErrBadRequest, ErrInvalidCredentials, ErrInvalidParameter, ErrInvalidScopes,
+	ErrUnauthorized, ErrNotFound, ErrAlreadyExists, ErrNotImplemented,
+	ErrUnavailable, ErrTooManyRequests, ErrTransport, ErrInvalidResponse,
+}
+
+// Error is an error returned by a method of an IVCAP service.
+type Error struct {
+	// Kind classifies the error, nil if unknown
+	Kind *Kind
+	// StatusCode is the HTTP status of the response, zero if there was none
+	StatusCode int
+	// Service is the name of the service, e.g. "order"
+	Service string
+	// Method is the name of the method, e.g. "read"
+	Method string
+	// ResourceID is the ID of the resource concerned, if known
+	ResourceID string
+	// Err is the underlying error
+	Err error
+}
+
+// Error returns an error description.
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%s %s", e.Service, e.Method)
+	if e.ResourceID != "" {
+		msg += " " + e.ResourceID
+	}
+	return msg + ": " + describe(e.Err, e.Kind)
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is returns true if target is the kind of e.
+func (e *Error) Is(target error) bool {
+	return e.Kind != nil && target == e.Kind
+}
+
+// Retryable returns true if the request may succeed when retried.
+func (e *Error) Retryable() bool {
+	return Retryable(e.Err)
+}
+
+// Wrap returns err as an *Error for a call of method of service concerning
+// resource id, which may be empty. It returns nil if err is nil and err
+// itself if it already is an *Error or a context error.
+func Wrap(service, method, id string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	k := KindOf(err)
+	if se, ok := asServiceError(err); ok && id == "" {
+		id = se.id
+	}
+	return &Error{Kind: k, StatusCode: statusOf(err, k), Service: service, Method: method, ResourceID: id, Err: err}
+}
+
+// statusOf returns the HTTP status of the response err of kind k was decoded
+// from, or zero if there was none.
+func statusOf(err error, k *Kind) int {
+	var ce *goahttp.ClientError
+	if errors.As(err, &ce) {
+		return responseStatus(ce)
+	}
+	var ne net.Error
+	if k == nil || errors.As(err, &ne) {
+		return 0
+	}
+	// The error types of the services are decoded from responses with the
+	// status of their kind.
+	return k.status
+}
+
+// responseStatus returns the status of the unexpected response reported by
+// ce, or zero if ce is about something else.
+func responseStatus(ce *goahttp.ClientError) int {
+	var status int
+	if ce.Name != "invalid_response" {
+		return 0
+	}
+	if _, err := fmt.Sscanf(ce.Message, "invalid response code %d", &status); err != nil {
+		return 0
+	}
+	return status
+}
+
+// FromStatus returns an *Error for a response with an unexpected HTTP status
+// received outside of the generated clients, e.g. when downloading content.
+// body is an excerpt of the response body used in the message.
+func FromStatus(service, method, id string, status int, body string) error {
+	msg := fmt.Sprintf("unexpected response %d %s", status, http.StatusText(status))
+	if body != "" {
+		msg += ": " + body
+	}
+	return &Error{
+		Kind:       KindOfStatus(status),
+		StatusCode: status,
+		Service:    service,
+		Method:     method,
+		ResourceID: id,
+		Err:        errors.New(msg),
+	}
+}
+
+// KindOf returns the kind of err, or nil if it is not known.
+func KindOf(err error) *Kind {
+	for _, k := range kinds {
+		if errors.Is(err, k) {
+			return k
+		}
+	}
+	if se, ok := asServiceError(err); ok {
+		return se.kind
+	}
+	var ce *goahttp.ClientError
+	if errors.As(err, &ce) {
+		switch ce.Name {
+		case "request_error":
+			return ErrTransport
+		case "invalid_response":
+			// Classify the status as if it was received outside of the
+			// generated clients, see KindOfStatus.
+			if k := KindOfStatus(responseStatus(ce)); k != nil {
+				return k
+			}
+			if ce.Temporary || ce.Timeout {
+				return ErrUnavailable
+			}
+			return ErrInvalidResponse
+		case "decoding_error", "validation_error":
+			return ErrInvalidResponse
+		}
+		return nil
+	}
+	var ne net.Error
+	if errors.As(err, &ne) {
+		return ErrTransport
+	}
+	return nil
+}
+
+// KindOfStatus returns the kind of error a response with the given HTTP
+// status represents, or nil if the status does not indicate an error.
+func KindOfStatus(status int) *Kind {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrBadRequest
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrInvalidScopes
+	case http.StatusNotFound, http.StatusGone:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrAlreadyExists
+	case http.StatusUnprocessableEntity:
+		return ErrInvalidParameter
+	case http.StatusTooManyRequests:
+		return ErrTooManyRequests
+	case http.StatusNotImplemented:
+		return ErrNotImplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return ErrUnavailable
+	}
+	if status >= 400 {
+		return ErrInvalidResponse
+	}
+	return nil
+}
+
+// Retryable returns true if err is of a kind that may succeed when the
+// request is retried. Context errors are never retryable.
+func Retryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	k := KindOf(err)
+	return k != nil && k.retryable
+}
+
+// describe returns the most useful description of err. The generated error
+// types describe their type rather than the actual problem, so their message
+// is used where available.
+func describe(err error, k *Kind) string {
+	if se, ok := classify(err); ok && se.message != "" {
+		if k != nil {
+			return k.name + ": " + se.message
+		}
+		return se.message
+	}
+	return err.Error()
+}
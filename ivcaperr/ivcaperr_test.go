@@ -0,0 +1,195 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcaperr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	artifact "github.com/reinventingscience/ivcap-core-api/gen/artifact"
+	metadata "github.com/reinventingscience/ivcap-core-api/gen/metadata"
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+	goahttp "goa.design/goa/v3/http"
+)
+
+func TestServiceErrors(t *testing.T) {
+	id := "urn:ivcap:order:1"
+	for _, tc := range []struct {
+		err  error
+		kind *Kind
+		msg  string
+		id   string
+	}{
+		{&artifact.BadRequestT{Message: "bad"}, ErrBadRequest, "bad request: bad", ""},
+		{&metadata.BadRequestT{Message: "bad"}, ErrBadRequest, "bad request: bad", ""},
+		{&order.BadRequestT{Message: "bad"}, ErrBadRequest, "bad request: bad", ""},
+		{&service.BadRequestT{Message: "bad"}, ErrBadRequest, "bad request: bad", ""},
+
+		{&artifact.InvalidCredentialsT{}, ErrInvalidCredentials, "", ""},
+		{&metadata.InvalidCredentialsT{}, ErrInvalidCredentials, "", ""},
+		{&order.InvalidCredentialsT{}, ErrInvalidCredentials, "", ""},
+		{&service.InvalidCredentialsT{}, ErrInvalidCredentials, "", ""},
+
+		{&artifact.InvalidParameterValue{Name: "limit", Message: "too large"}, ErrInvalidParameter, "invalid parameter: limit: too large", ""},
+		{&metadata.InvalidParameterValue{Name: "limit", Message: "too large"}, ErrInvalidParameter, "invalid parameter: limit: too large", ""},
+		{&order.InvalidParameterValue{Name: "limit", Message: "too large"}, ErrInvalidParameter, "invalid parameter: limit: too large", ""},
+		{&service.InvalidParameterValue{Message: "too large"}, ErrInvalidParameter, "invalid parameter: too large", ""},
+
+		{&artifact.InvalidScopesT{ID: &id, Message: "missing"}, ErrInvalidScopes, "invalid scopes: missing", id},
+		{&metadata.InvalidScopesT{Message: "missing"}, ErrInvalidScopes, "invalid scopes: missing", ""},
+		{&order.InvalidScopesT{ID: &id, Message: "missing"}, ErrInvalidScopes, "invalid scopes: missing", id},
+		{&service.InvalidScopesT{ID: &id, Message: "missing"}, ErrInvalidScopes, "invalid scopes: missing", id},
+
+		{&artifact.NotImplementedT{Message: "later"}, ErrNotImplemented, "not implemented: later", ""},
+		{&metadata.NotImplementedT{Message: "later"}, ErrNotImplemented, "not implemented: later", ""},
+		{&order.NotImplementedT{Message: "later"}, ErrNotImplemented, "not implemented: later", ""},
+		{&service.NotImplementedT{Message: "later"}, ErrNotImplemented, "not implemented: later", ""},
+
+		{&artifact.ResourceNotFoundT{ID: id, Message: "gone"}, ErrNotFound, "not found: gone", id},
+		{&metadata.ResourceNotFoundT{ID: id, Message: "gone"}, ErrNotFound, "not found: gone", id},
+		{&order.ResourceNotFoundT{ID: id, Message: "gone"}, ErrNotFound, "not found: gone", id},
+		{&service.ResourceNotFoundT{ID: id, Message: "gone"}, ErrNotFound, "not found: gone", id},
+
+		{&service.ResourceAlreadyCreatedT{ID: id, Message: "exists"}, ErrAlreadyExists, "already exists: exists", id},
+
+		{&metadata.ServiceNotAvailableT{}, ErrUnavailable, "", ""},
+		{&order.ServiceNotAvailableT{}, ErrUnavailable, "", ""},
+
+		{&artifact.UnauthorizedT{}, ErrUnauthorized, "", ""},
+		{&metadata.UnauthorizedT{}, ErrUnauthorized, "", ""},
+		{&order.UnauthorizedT{}, ErrUnauthorized, "", ""},
+		{&service.UnauthorizedT{}, ErrUnauthorized, "", ""},
+	} {
+		name := fmt.Sprintf("%T", tc.err)
+		if k := KindOf(tc.err); k != tc.kind {
+			t.Errorf("KindOf(%s) = %v, want %v", name, k, tc.kind)
+		}
+		if k := KindOf(fmt.Errorf("context: %w", tc.err)); k != tc.kind {
+			t.Errorf("KindOf(wrapped %s) = %v, want %v", name, k, tc.kind)
+		}
+		err := Wrap("svc", "read", "", tc.err)
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Fatalf("Wrap(%s) = %T", name, err)
+		}
+		if !errors.Is(err, tc.kind) || e.Kind != tc.kind {
+			t.Errorf("Wrap(%s) is not %v", name, tc.kind)
+		}
+		for _, k := range kinds {
+			if k != tc.kind && errors.Is(err, k) {
+				t.Errorf("Wrap(%s) is also %v", name, k)
+			}
+		}
+		if !errors.Is(err, tc.err) {
+			t.Errorf("Wrap(%s) does not wrap the error", name)
+		}
+		if e.StatusCode != tc.kind.StatusCode() || e.ResourceID != tc.id {
+			t.Errorf("Wrap(%s) = status %d, id %q, want %d, %q", name, e.StatusCode, e.ResourceID, tc.kind.StatusCode(), tc.id)
+		}
+		want := "svc read"
+		if tc.id != "" {
+			want += " " + tc.id
+		}
+		if tc.msg != "" {
+			want += ": " + tc.msg
+		} else {
+			want += ": " + tc.err.Error()
+		}
+		if e.Error() != want {
+			t.Errorf("Wrap(%s) = %q, want %q", name, e.Error(), want)
+		}
+		if Retryable(err) != tc.kind.Retryable() {
+			t.Errorf("Retryable(%s) = %v", name, Retryable(err))
+		}
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+var _ net.Error = timeoutError{}
+
+func TestWrap(t *testing.T) {
+	if Wrap("order", "read", "", nil) != nil {
+		t.Error("Wrap(nil) != nil")
+	}
+	for _, err := range []error{context.Canceled, fmt.Errorf("x: %w", context.DeadlineExceeded)} {
+		if Wrap("order", "read", "", err) != err {
+			t.Errorf("Wrap(%v) wrapped a context error", err)
+		}
+		if Retryable(err) {
+			t.Errorf("Retryable(%v)", err)
+		}
+	}
+	inner := Wrap("order", "read", "id1", &order.ResourceNotFoundT{ID: "id2"})
+	if Wrap("order", "list", "", inner) != inner {
+		t.Error("Wrap wrapped an *Error twice")
+	}
+	if e := inner.(*Error); e.ResourceID != "id1" {
+		t.Errorf("ResourceID = %q, want the one passed to Wrap", e.ResourceID)
+	}
+
+	for _, tc := range []struct {
+		err    error
+		kind   *Kind
+		status int
+	}{
+		{goahttp.ErrInvalidResponse("order", "read", 503, "down"), ErrUnavailable, 503},
+		{goahttp.ErrInvalidResponse("order", "read", 418, "teapot"), ErrInvalidResponse, 418},
+		{goahttp.ErrRequestError("order", "read", timeoutError{}), ErrTransport, 0},
+		{goahttp.ErrDecodingError("order", "read", errors.New("bad json")), ErrInvalidResponse, 0},
+		{&net.OpError{Op: "dial", Err: timeoutError{}}, ErrTransport, 0},
+		{errors.New("other"), nil, 0},
+	} {
+		e := Wrap("order", "read", "", tc.err).(*Error)
+		if e.Kind != tc.kind || e.StatusCode != tc.status {
+			t.Errorf("Wrap(%v) = kind %v, status %d, want %v, %d", tc.err, e.Kind, e.StatusCode, tc.kind, tc.status)
+		}
+	}
+}
+
+func TestFromStatus(t *testing.T) {
+	for status, kind := range map[int]*Kind{
+		http.StatusBadRequest:          ErrBadRequest,
+		http.StatusUnauthorized:        ErrUnauthorized,
+		http.StatusForbidden:           ErrInvalidScopes,
+		http.StatusGone:                ErrNotFound,
+		http.StatusConflict:            ErrAlreadyExists,
+		http.StatusUnprocessableEntity: ErrInvalidParameter,
+		http.StatusTooManyRequests:     ErrTooManyRequests,
+		http.StatusGatewayTimeout:      ErrUnavailable,
+		http.StatusTeapot:              ErrInvalidResponse,
+	} {
+		err := FromStatus("artifact", "download", "a1", status, "body")
+		if !errors.Is(err, kind) || err.(*Error).StatusCode != status {
+			t.Errorf("FromStatus(%d) = %v, want %v", status, err, kind)
+		}
+	}
+	if k := KindOfStatus(http.StatusOK); k != nil {
+		t.Errorf("KindOfStatus(200) = %v", k)
+	}
+	want := "artifact download a1: unexpected response 404 Not Found: missing"
+	if err := FromStatus("artifact", "download", "a1", 404, "missing"); err.Error() != want {
+		t.Errorf("FromStatus = %q, want %q", err, want)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcaperr
+
+import (
+	"errors"
+
+	artifact "github.com/reinventingscience/ivcap-core-api/gen/artifact"
+	metadata "github.com/reinventingscience/ivcap-core-api/gen/metadata"
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+)
+
+// serviceError is what an error type of a service tells about the error.
+type serviceError struct {
+	kind *Kind
+	// message sent by the service, if any
+	message string
+	// id of the resource concerned, if any
+	id string
+}
+
+// asServiceError returns the first error in the chain of err which is of an
+// error type of a service.
+func asServiceError(err error) (serviceError, bool) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if se, ok := classify(err); ok {
+			return se, true
+		}
+	}
+	return serviceError{}, false
+}
+
+// classify maps the error types generated for the services to their kinds.
+func classify(err error) (serviceError, bool) {
+	switch e := err.(type) {
+	case *artifact.BadRequestT:
+		return serviceError{ErrBadRequest, e.Message, ""}, true
+	case *metadata.BadRequestT:
+		return serviceError{ErrBadRequest, e.Message, ""}, true
+	case *order.BadRequestT:
+		return serviceError{ErrBadRequest, e.Message, ""}, true
+	case *service.BadRequestT:
+		return serviceError{ErrBadRequest, e.Message, ""}, true
+
+	case *artifact.InvalidCredentialsT, *metadata.InvalidCredentialsT, *order.InvalidCredentialsT, *service.InvalidCredentialsT:
+		return serviceError{kind: ErrInvalidCredentials}, true
+
+	case *artifact.InvalidParameterValue:
+		return serviceError{ErrInvalidParameter, parameterMessage(e.Name, e.Message), ""}, true
+	case *metadata.InvalidParameterValue:
+		return serviceError{ErrInvalidParameter, parameterMessage(e.Name, e.Message), ""}, true
+	case *order.InvalidParameterValue:
+		return serviceError{ErrInvalidParameter, parameterMessage(e.Name, e.Message), ""}, true
+	case *service.InvalidParameterValue:
+		return serviceError{ErrInvalidParameter, parameterMessage(e.Name, e.Message), ""}, true
+
+	case *artifact.InvalidScopesT:
+		return serviceError{ErrInvalidScopes, e.Message, deref(e.ID)}, true
+	case *metadata.InvalidScopesT:
+		return serviceError{ErrInvalidScopes, e.Message, deref(e.ID)}, true
+	case *order.InvalidScopesT:
+		return serviceError{ErrInvalidScopes, e.Message, deref(e.ID)}, true
+	case *service.InvalidScopesT:
+		return serviceError{ErrInvalidScopes, e.Message, deref(e.ID)}, true
+
+	case *artifact.NotImplementedT:
+		return serviceError{ErrNotImplemented, e.Message, ""}, true
+	case *metadata.NotImplementedT:
+		return serviceError{ErrNotImplemented, e.Message, ""}, true
+	case *order.NotImplementedT:
+		return serviceError{ErrNotImplemented, e.Message, ""}, true
+	case *service.NotImplementedT:
+		return serviceError{ErrNotImplemented, e.Message, ""}, true
+
+	case *artifact.ResourceNotFoundT:
+		return serviceError{ErrNotFound, e.Message, e.ID}, true
+	case *metadata.ResourceNotFoundT:
+		return serviceError{ErrNotFound, e.Message, e.ID}, true
+	case *order.ResourceNotFoundT:
+		return serviceError{ErrNotFound, e.Message, e.ID}, true
+	case *service.ResourceNotFoundT:
+		return serviceError{ErrNotFound, e.Message, e.ID}, true
+
+	case *service.ResourceAlreadyCreatedT:
+		return serviceError{ErrAlreadyExists, e.Message, e.ID}, true
+
+	case *metadata.ServiceNotAvailableT, *order.ServiceNotAvailableT:
+		return serviceError{kind: ErrUnavailable}, true
+
+	case *artifact.UnauthorizedT, *metadata.UnauthorizedT, *order.UnauthorizedT, *service.UnauthorizedT:
+		return serviceError{kind: ErrUnauthorized}, true
+	}
+	return serviceError{}, false
+}
+
+// parameterMessage returns the name of an invalid parameter and what is
+// wrong with it.
+func parameterMessage(name, msg string) string {
+	if name == "" {
+		return msg
+	}
+	return name + ": " + msg
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,329 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcapfake
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	ivcap "github.com/reinventingscience/ivcap-core-api"
+	artifact "github.com/reinventingscience/ivcap-core-api/gen/artifact"
+
+	"goa.design/goa/v3/security"
+)
+
+// Artifact states.
+const (
+	ArtifactPending = "pending"
+	ArtifactPartial = "partial"
+	ArtifactReady   = "ready"
+)
+
+// Artifacts is an in-memory implementation of artifact.Service. Uploads
+// announced with a Tus-Resumable header only create the artifact; its
+// content is then added with Append, as a TUS server would on PATCH.
+type Artifacts struct {
+	env *env
+
+	mu    sync.RWMutex
+	items map[string]*artifactRec
+	order []string
+}
+
+var (
+	_ artifact.Service = (*Artifacts)(nil)
+	_ artifact.Auther  = (*Artifacts)(nil)
+)
+
+// artifactRec is an artifact. Its content is kept in the BlobStore.
+type artifactRec struct {
+	id       string
+	name     *string
+	mimeType *string
+	policy   *string
+	account  string
+	size     int64
+	received int64
+	etag     string
+	created  time.Time
+	modified time.Time
+}
+
+// artifactFields lists the fields artifact list filters may use.
+var artifactFields = []string{"id", "name", "status", "size", "mime-type", "created-at", "last-modified-at"}
+
+// JWTAuth implements artifact.Auther.
+func (s *Artifacts) JWTAuth(ctx context.Context, token string, scheme *security.JWTScheme) (context.Context, error) {
+	ctx, kind, _ := s.env.authenticate(ctx, token, scheme)
+	switch kind {
+	case authUnauthorized:
+		return ctx, &artifact.UnauthorizedT{}
+	case authInvalidScopes:
+		return ctx, &artifact.InvalidScopesT{Message: invalidScopes}
+	}
+	return ctx, nil
+}
+
+// List implements artifact.Service.
+func (s *Artifacts) List(ctx context.Context, p *artifact.ListPayload) (*artifact.ArtifactListRT, error) {
+	q := listQuery{limit: p.Limit, filter: stringOf(p.Filter), orderBy: stringOf(p.OrderBy), desc: p.OrderDesc, atTime: p.AtTime, page: p.Page}
+	pg, perr := list(s.env, "/1/artifacts", q, artifactFields, s.snapshot, func(st *artifact.ArtifactStatusRT) fields {
+		f := fields{
+			"id":               st.ID,
+			"name":             optString(st.Name),
+			"status":           st.Status,
+			"size":             nil,
+			"mime-type":        optString(st.MimeType),
+			"created-at":       parsedTime(st.CreatedAt),
+			"last-modified-at": parsedTime(st.LastModifiedAt),
+		}
+		if st.Size != nil {
+			f["size"] = float64(*st.Size)
+		}
+		return f
+	})
+	if perr != nil {
+		return nil, &artifact.InvalidParameterValue{Name: perr.name, Value: &perr.value, Message: perr.msg}
+	}
+	res := &artifact.ArtifactListRT{
+		Artifacts: []*artifact.ArtifactListItem{},
+		AtTime:    formatTime(pg.at),
+		Links:     &artifact.NavT{Self: &pg.self, First: &pg.first, Next: pg.next},
+	}
+	for _, st := range pg.items {
+		res.Artifacts = append(res.Artifacts, &artifact.ArtifactListItem{
+			ID:       &st.ID,
+			Name:     st.Name,
+			Status:   &st.Status,
+			Size:     st.Size,
+			MimeType: st.MimeType,
+			Links:    st.Links,
+		})
+	}
+	return res, nil
+}
+
+// snapshot returns the artifacts created until time at, oldest first.
+func (s *Artifacts) snapshot(at time.Time, _ map[string]string) ([]*artifact.ArtifactStatusRT, *paramError) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var res []*artifact.ArtifactStatusRT
+	for _, id := range s.order {
+		if r := s.items[id]; !r.created.After(at) {
+			res = append(res, r.status(s.env))
+		}
+	}
+	return res, nil
+}
+
+// Read implements artifact.Service.
+func (s *Artifacts) Read(ctx context.Context, p *artifact.ReadPayload) (*artifact.ArtifactStatusRT, error) {
+	st, ok := s.Status(p.ID)
+	if !ok {
+		return nil, &artifact.ResourceNotFoundT{ID: p.ID, Message: "artifact not found"}
+	}
+	return st, nil
+}
+
+// Status returns the current status of artifact id.
+func (s *Artifacts) Status(id string) (*artifact.ArtifactStatusRT, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r := s.items[id]
+	if r == nil {
+		return nil, false
+	}
+	return r.status(s.env), true
+}
+
+// Upload implements artifact.Service. The body is read completely. If p
+// announces a TUS upload, the body may be empty and the artifact stays
+// pending until UploadLength bytes were added with Append.
+func (s *Artifacts) Upload(ctx context.Context, p *artifact.UploadPayload, body io.ReadCloser) (*artifact.ArtifactStatusRT, error) {
+	defer body.Close()
+	now := s.env.now()
+	r := &artifactRec{
+		id:       newID("artifact"),
+		name:     p.Name,
+		mimeType: p.XContentType,
+		policy:   p.Policy,
+		account:  Account(ctx),
+		size:     -1,
+		created:  now,
+		modified: now,
+	}
+	if r.mimeType == nil {
+		r.mimeType = p.ContentType
+	}
+	tus := p.TusResumable != nil
+	if tus {
+		if p.UploadLength == nil || *p.UploadLength < 0 {
+			return nil, &artifact.InvalidParameterValue{Name: "upload-length", Message: "missing upload length for resumable upload"}
+		}
+		r.size = int64(*p.UploadLength)
+	}
+	h := md5.New()
+	buf := make([]byte, 1<<20)
+	for {
+		n, err := io.ReadFull(body, buf)
+		if n > 0 {
+			if r.size >= 0 && r.received+int64(n) > r.size {
+				return nil, &artifact.InvalidParameterValue{Name: "upload-length", Message: "content exceeds upload length"}
+			}
+			if err := s.env.opts.Blobs.Append(r.id, buf[:n]); err != nil {
+				return nil, err
+			}
+			h.Write(buf[:n])
+			r.received += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return nil, &artifact.BadRequestT{Message: fmt.Sprintf("reading content: %v", err)}
+		}
+	}
+	if r.size < 0 {
+		r.size = r.received
+	}
+	if r.received == r.size {
+		r.etag = hex.EncodeToString(h.Sum(nil))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.env.save(KindArtifact, r.id, r); err != nil {
+		return nil, err
+	}
+	s.items[r.id] = r
+	s.order = append(s.order, r.id)
+	st := r.status(s.env)
+	if tus {
+		st.Location = st.Data.Self
+		st.TusResumable = ptr(ivcap.TusVersion)
+		st.TusOffset = ptr(r.received)
+	}
+	return st, nil
+}
+
+// Append adds data at offset to the content of artifact id and returns the
+// new offset, as a TUS PATCH request does. offset must be the current size
+// of the content.
+func (s *Artifacts) Append(id string, offset int64, data []byte) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.items[id]
+	if r == nil {
+		return 0, &artifact.ResourceNotFoundT{ID: id, Message: "artifact not found"}
+	}
+	cur := r.received
+	if offset != cur {
+		return cur, &artifact.InvalidParameterValue{Name: "upload-offset", Value: ptr(fmt.Sprint(offset)), Message: fmt.Sprintf("offset must be %d", cur)}
+	}
+	if cur+int64(len(data)) > r.size {
+		return cur, &artifact.InvalidParameterValue{Name: "upload-offset", Message: "content exceeds upload length"}
+	}
+	if err := s.env.opts.Blobs.Append(id, data); err != nil {
+		return cur, err
+	}
+	r.received += int64(len(data))
+	r.modified = s.env.now()
+	if r.received == r.size {
+		etag, err := s.checksum(id)
+		if err != nil {
+			return r.received, err
+		}
+		r.etag = etag
+	}
+	return r.received, s.env.save(KindArtifact, id, r)
+}
+
+// checksum returns the MD5 sum of the content of artifact id.
+func (s *Artifacts) checksum(id string) (string, error) {
+	c, err := s.env.opts.Blobs.Open(id)
+	if err != nil {
+		return "", err
+	}
+	defer c.Close()
+	h := md5.New()
+	if _, err := io.Copy(h, c); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Content returns the content of artifact id uploaded so far together with
+// its status.
+func (s *Artifacts) Content(id string) ([]byte, *artifact.ArtifactStatusRT, bool) {
+	c, st, ok := s.Open(id)
+	if !ok {
+		return nil, nil, false
+	}
+	defer c.Close()
+	data, err := io.ReadAll(c)
+	if err != nil {
+		return nil, nil, false
+	}
+	return data, st, true
+}
+
+// Open returns a reader for the content of artifact id uploaded so far
+// together with its status. The caller must close the reader.
+func (s *Artifacts) Open(id string) (io.ReadSeekCloser, *artifact.ArtifactStatusRT, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r := s.items[id]
+	if r == nil {
+		return nil, nil, false
+	}
+	c, err := s.env.opts.Blobs.Open(id)
+	if err != nil {
+		return nil, nil, false
+	}
+	return c, r.status(s.env), true
+}
+
+// status returns the status of the artifact.
+func (r *artifactRec) status(e *env) *artifact.ArtifactStatusRT {
+	st := &artifact.ArtifactStatusRT{
+		ID:             r.id,
+		Name:           r.name,
+		Status:         ArtifactPending,
+		MimeType:       r.mimeType,
+		Size:           ptr(r.size),
+		CreatedAt:      formatTime(r.created),
+		LastModifiedAt: formatTime(r.modified),
+		Data:           &artifact.SelfT{Self: e.link("/1/artifacts/%s/blob", r.id)},
+		Links:          &artifact.SelfT{Self: e.link("/1/artifacts/%s", r.id)},
+	}
+	switch {
+	case r.received == r.size:
+		st.Status = ArtifactReady
+		st.Etag = ptr(r.etag)
+	case r.received > 0:
+		st.Status = ArtifactPartial
+	}
+	if r.policy != nil {
+		st.Policy = &artifact.RefT{ID: ptr(*r.policy)}
+	}
+	if r.account != "" {
+		st.Account = &artifact.RefT{ID: ptr(r.account)}
+	}
+	return st
+}
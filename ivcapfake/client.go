@@ -0,0 +1,96 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcapfake
+
+import (
+	"context"
+
+	artifact "github.com/reinventingscience/ivcap-core-api/gen/artifact"
+	artifactviews "github.com/reinventingscience/ivcap-core-api/gen/artifact/views"
+	metadata "github.com/reinventingscience/ivcap-core-api/gen/metadata"
+	metadataviews "github.com/reinventingscience/ivcap-core-api/gen/metadata/views"
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+	orderviews "github.com/reinventingscience/ivcap-core-api/gen/order/views"
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+	serviceviews "github.com/reinventingscience/ivcap-core-api/gen/service/views"
+
+	goa "goa.design/goa/v3/pkg"
+)
+
+// OrderClient returns a client calling the endpoints of f.Orders in
+// process.
+func (f *Fake) OrderClient() *order.Client {
+	e := order.NewEndpoints(f.Orders)
+	e.Use(unview)
+	return order.NewClient(e.Read, e.List, e.Create, e.Logs, e.Top)
+}
+
+// ArtifactClient returns a client calling the endpoints of f.Artifacts in
+// process.
+func (f *Fake) ArtifactClient() *artifact.Client {
+	e := artifact.NewEndpoints(f.Artifacts)
+	e.Use(unview)
+	return artifact.NewClient(e.List, e.Read, e.Upload)
+}
+
+// ServiceClient returns a client calling the endpoints of f.Services in
+// process.
+func (f *Fake) ServiceClient() *service.Client {
+	e := service.NewEndpoints(f.Services)
+	e.Use(unview)
+	return service.NewClient(e.List, e.CreateService, e.Read, e.Update, e.Delete)
+}
+
+// MetadataClient returns a client calling the endpoints of f.Metadata in
+// process.
+func (f *Fake) MetadataClient() *metadata.Client {
+	e := metadata.NewEndpoints(f.Metadata)
+	e.Use(unview)
+	return metadata.NewClient(e.Read, e.List, e.Add, e.UpdateOne, e.UpdateRecord, e.Revoke)
+}
+
+// unview converts the viewed results returned by server endpoints into the
+// result types the generated clients expect, as the HTTP transport would.
+func unview(e goa.Endpoint) goa.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		res, err := e(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		switch v := res.(type) {
+		case *orderviews.OrderStatusRT:
+			return order.NewOrderStatusRT(v), nil
+		case *orderviews.OrderListRT:
+			return order.NewOrderListRT(v), nil
+		case orderviews.OrderTopResultItemCollection:
+			return order.NewOrderTopResultItemCollection(v), nil
+		case *artifactviews.ArtifactStatusRT:
+			return artifact.NewArtifactStatusRT(v), nil
+		case *artifactviews.ArtifactListRT:
+			return artifact.NewArtifactListRT(v), nil
+		case *serviceviews.ServiceStatusRT:
+			return service.NewServiceStatusRT(v), nil
+		case *serviceviews.ServiceListRT:
+			return service.NewServiceListRT(v), nil
+		case *metadataviews.MetadataRecordRT:
+			return metadata.NewMetadataRecordRT(v), nil
+		case *metadataviews.ListMetaRT:
+			return metadata.NewListMetaRT(v), nil
+		case *metadataviews.AddMetaRT:
+			return metadata.NewAddMetaRT(v), nil
+		}
+		return res, nil
+	}
+}
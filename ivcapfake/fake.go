@@ -0,0 +1,331 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ivcapfake provides in-memory implementations of the order,
+// artifact, service and metadata services for tests. They implement the
+// Service and Auther interfaces of the generated service packages and can be
+// used directly through the generated endpoints:
+//
+//	f := ivcapfake.New(nil)
+//	e := order.NewEndpoints(f.Orders)
+//	token := ivcapfake.Token("urn:ivcap:account:1", "consumer:read", "consumer:write")
+//	res, err := e.List(ctx, &order.ListPayload{Limit: 10, JWT: token})
+//
+// OrderClient and friends return generated clients calling these endpoints
+// in process, while Handler serves them over HTTP for the real clients.
+// Records stay in memory unless Options.Store and Options.Blobs persist
+// them, as package local does for the ivcap-local server.
+//
+// The fakes check the scopes of the JWT against those required by each
+// method, page list results through "next" links, answer list requests for
+// a past AtTime with the state at that time and retain revoked metadata
+// records. All of them are safe for concurrent use.
+package ivcapfake
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"goa.design/goa/v3/security"
+)
+
+// DefaultBaseURL is the base URL of the links returned by the fakes unless
+// Options.BaseURL is set.
+const DefaultBaseURL = "http://localhost:8088"
+
+// Options configures the fakes.
+type Options struct {
+	// BaseURL is prepended to the paths of returned links. Defaults to
+	// DefaultBaseURL.
+	BaseURL string
+	// Tokens maps bearer tokens to the scopes they grant. Tokens not listed
+	// are decoded as JWTs, without checking their signature, and grant the
+	// scopes in their "scope" or "scopes" claim.
+	Tokens map[string][]string
+	// Now returns the current time. Defaults to time.Now. Setting it allows
+	// tests to control the timestamps used for AtTime queries.
+	Now func() time.Time
+	// Store persists the records of the fakes. The records in it are loaded
+	// when the fakes are opened and every change is saved to it. Defaults to
+	// keeping the records in memory only.
+	Store Store
+	// Blobs holds the content of artifacts. Defaults to memory.
+	Blobs BlobStore
+	// OrderCreated is called with the ID of every new order, e.g. to execute
+	// it. It must not block.
+	OrderCreated func(id string)
+}
+
+// Fake bundles the fakes of all four services, which share their state: an
+// order refers to a service of Services and its products are artifacts of
+// Artifacts.
+type Fake struct {
+	Orders    *Orders
+	Artifacts *Artifacts
+	Services  *Services
+	Metadata  *Metadata
+}
+
+// New returns a set of fakes. opts may be nil. New panics if the records in
+// opts.Store cannot be loaded, use Open to handle that error.
+func New(opts *Options) *Fake {
+	f, err := Open(opts)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// Open returns a set of fakes holding the records in opts.Store, if set.
+// opts may be nil.
+func Open(opts *Options) (*Fake, error) {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.BaseURL == "" {
+		o.BaseURL = DefaultBaseURL
+	}
+	o.BaseURL = strings.TrimSuffix(o.BaseURL, "/")
+	if o.Now == nil {
+		o.Now = time.Now
+	}
+	if o.Blobs == nil {
+		o.Blobs = &memBlobs{}
+	}
+	e := &env{opts: o}
+	f := &Fake{
+		Artifacts: &Artifacts{env: e, items: map[string]*artifactRec{}},
+		Services:  &Services{env: e, items: map[string]*serviceRec{}},
+		Metadata:  &Metadata{env: e, items: map[string]*metadataRec{}, keys: map[string]string{}},
+	}
+	f.Orders = &Orders{env: e, items: map[string]*orderRec{}, keys: map[string]string{}, services: f.Services, artifacts: f.Artifacts}
+	if o.Store != nil {
+		if err := f.load(o.Store); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// env is the configuration shared by the fakes.
+type env struct {
+	opts Options
+}
+
+func (e *env) now() time.Time {
+	return e.opts.Now().UTC()
+}
+
+func (e *env) link(format string, args ...interface{}) *string {
+	s := e.opts.BaseURL + fmt.Sprintf(format, args...)
+	return &s
+}
+
+// Token returns an unsigned JWT for account granting scopes, which the fakes
+// accept.
+func Token(account string, scopes ...string) string {
+	enc := base64.RawURLEncoding
+	header, _ := json.Marshal(map[string]string{"alg": "none", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"sub":   account,
+		"scope": strings.Join(scopes, " "),
+		"iat":   time.Now().Unix(),
+	})
+	return enc.EncodeToString(header) + "." + enc.EncodeToString(claims) + "."
+}
+
+// principalKey is the context key of the authenticated principal.
+type principalKey struct{}
+
+// principal is the caller of a method.
+type principal struct {
+	account string
+	scopes  []string
+}
+
+// WithAccount returns a copy of ctx acting on behalf of account, e.g. for
+// uploading the products of an order outside of a request.
+func WithAccount(ctx context.Context, account string) context.Context {
+	return context.WithValue(ctx, principalKey{}, &principal{account: account})
+}
+
+// Account returns the account of the caller authenticated by one of the
+// fakes, "" if none.
+func Account(ctx context.Context) string {
+	if p, ok := ctx.Value(principalKey{}).(*principal); ok {
+		return p.account
+	}
+	return ""
+}
+
+// authError classifies authentication failures, which each service reports
+// with its own error types.
+type authError int
+
+const (
+	authOK authError = iota
+	authUnauthorized
+	authInvalidScopes
+)
+
+// invalidScopes is the message of the InvalidScopesT errors returned by the
+// fakes. The generated types use the message as error name, which the HTTP
+// servers map to the 403 response.
+const invalidScopes = "invalid-scopes"
+
+// authenticate checks token against the scopes required by scheme.
+func (e *env) authenticate(ctx context.Context, token string, scheme *security.JWTScheme) (context.Context, authError, error) {
+	token = strings.TrimPrefix(token, "Bearer ")
+	if token == "" {
+		return ctx, authUnauthorized, fmt.Errorf("missing token")
+	}
+	p := &principal{}
+	if scopes, ok := e.opts.Tokens[token]; ok {
+		p.scopes = scopes
+	} else {
+		var err error
+		if p, err = decodeJWT(token); err != nil {
+			return ctx, authUnauthorized, err
+		}
+	}
+	if err := scheme.Validate(p.scopes); err != nil {
+		return ctx, authInvalidScopes, err
+	}
+	return context.WithValue(ctx, principalKey{}, p), authOK, nil
+}
+
+// decodeJWT returns the principal described by the claims of a JWT without
+// verifying its signature.
+func decodeJWT(token string) (*principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("malformed token: %w", err)
+	}
+	var claims struct {
+		Sub    string          `json:"sub"`
+		Scope  string          `json:"scope"`
+		Scopes json.RawMessage `json:"scopes"`
+		Exp    int64           `json:"exp"`
+	}
+	if err := json.Unmarshal(b, &claims); err != nil {
+		return nil, fmt.Errorf("malformed token: %w", err)
+	}
+	if claims.Exp != 0 && time.Unix(claims.Exp, 0).Before(time.Now()) {
+		return nil, fmt.Errorf("token expired")
+	}
+	p := &principal{account: claims.Sub, scopes: strings.Fields(claims.Scope)}
+	if len(claims.Scopes) > 0 {
+		var scopes []string
+		if json.Unmarshal(claims.Scopes, &scopes) != nil {
+			var s string
+			json.Unmarshal(claims.Scopes, &s)
+			scopes = strings.Fields(s)
+		}
+		p.scopes = append(p.scopes, scopes...)
+	}
+	return p, nil
+}
+
+// newID returns a new URN of the given kind, e.g. "urn:ivcap:artifact:<uuid>".
+func newID(kind string) string {
+	return fmt.Sprintf("urn:ivcap:%s:%s", kind, uuid.NewString())
+}
+
+// newOrderID returns a new order ID. The order status declares its ID a
+// UUID while the log and top requests expect a URI. The "urn:uuid:" form
+// satisfies both, so the IDs pass the validation of the HTTP clients.
+func newOrderID() string {
+	return "urn:uuid:" + uuid.NewString()
+}
+
+func formatTime(t time.Time) *string {
+	if t.IsZero() {
+		return nil
+	}
+	s := t.Format(time.RFC3339Nano)
+	return &s
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// clone returns a deep copy of v so that records do not share state with
+// payloads or results.
+func clone[T any](v *T) *T {
+	if v == nil {
+		return nil
+	}
+	return deepCopy(reflect.ValueOf(v)).Interface().(*T)
+}
+
+// deepCopy returns a copy of v sharing no pointers, slices or maps with it.
+// Unexported struct fields are copied shallowly.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for it := v.MapRange(); it.Next(); {
+			c.SetMapIndex(it.Key(), deepCopy(it.Value()))
+		}
+		return c
+	}
+	return v
+}
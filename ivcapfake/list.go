@@ -0,0 +1,378 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcapfake
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reinventingscience/ivcap-core-api/query"
+)
+
+// listQuery holds the parameters common to all list methods.
+type listQuery struct {
+	limit   int
+	filter  string
+	orderBy string
+	desc    bool
+	atTime  *string
+	page    *string
+	// extra holds service specific parameters, such as the entity of a
+	// metadata query
+	extra map[string]string
+}
+
+// paramError describes an invalid list parameter. Each service reports it
+// as its own InvalidParameterValue.
+type paramError struct {
+	name  string
+	value string
+	msg   string
+}
+
+// fields are the values of a record a filter or order-by may refer to.
+// Values are strings, float64, bool, time.Time or nil.
+type fields map[string]interface{}
+
+// pageToken is the content of the opaque "page" parameter. It captures the
+// complete query, so the parameters of requests for later pages are
+// ignored.
+type pageToken struct {
+	Offset  int               `json:"o"`
+	At      time.Time         `json:"t"`
+	Filter  string            `json:"f,omitempty"`
+	OrderBy string            `json:"b,omitempty"`
+	Desc    bool              `json:"d,omitempty"`
+	Extra   map[string]string `json:"x,omitempty"`
+}
+
+// page is a single page of a list result.
+type page[T any] struct {
+	items []T
+	at    time.Time
+	self  string
+	first string
+	next  *string
+}
+
+// list returns the page of items selected by q. snapshot returns the items
+// as of the at-time of the query, taking the service specific parameters
+// into account, and fieldsOf returns the fields of an item. path is the path
+// of the list endpoint for links, names the fields filters and order-by
+// clauses may use.
+func list[T any](e *env, path string, q listQuery, names []string, snapshot func(at time.Time, extra map[string]string) ([]T, *paramError), fieldsOf func(T) fields) (*page[T], *paramError) {
+	limit := q.limit
+	if limit == 0 {
+		limit = 10
+	}
+	if limit < 1 || limit > 50 {
+		return nil, &paramError{"limit", strconv.Itoa(limit), "limit must be between 1 and 50"}
+	}
+	at := e.now()
+	offset := 0
+	if q.page != nil && *q.page != "" {
+		tok, err := decodePage(*q.page)
+		if err != nil {
+			return nil, &paramError{"page", *q.page, "invalid page token"}
+		}
+		at, offset = tok.At, tok.Offset
+		q.filter, q.orderBy, q.desc, q.extra = tok.Filter, tok.OrderBy, tok.Desc, tok.Extra
+	} else if q.atTime != nil && *q.atTime != "" {
+		t, err := time.Parse(time.RFC3339Nano, *q.atTime)
+		if err != nil {
+			return nil, &paramError{"at-time", *q.atTime, "at-time must be an RFC 3339 date-time"}
+		}
+		at = t.UTC()
+	}
+	var filter query.Expr
+	if q.filter != "" {
+		if err := query.Validate(q.filter, names...); err != nil {
+			return nil, &paramError{"filter", q.filter, err.Error()}
+		}
+		filter, _ = query.Parse(q.filter)
+	}
+	var orders []query.Order
+	if q.orderBy != "" {
+		if err := query.ValidateOrderBy(q.orderBy, names...); err != nil {
+			return nil, &paramError{"order-by", q.orderBy, err.Error()}
+		}
+		orders, _ = query.ParseOrderBy(q.orderBy)
+	}
+
+	type entry struct {
+		item T
+		f    fields
+		pos  int
+	}
+	items, perr := snapshot(at, q.extra)
+	if perr != nil {
+		return nil, perr
+	}
+	var all []entry
+	for i, item := range items {
+		f := fieldsOf(item)
+		if filter == nil || eval(filter, f) {
+			all = append(all, entry{item, f, i})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		for _, o := range orders {
+			c := compare(all[i].f[o.Field], all[j].f[o.Field])
+			if c == 0 {
+				continue
+			}
+			desc := o.Direction == query.Descending || (o.Direction == "" && q.desc)
+			return (c < 0) != desc
+		}
+		if q.desc {
+			return all[i].pos > all[j].pos
+		}
+		return all[i].pos < all[j].pos
+	})
+
+	p := &page[T]{at: at}
+	for i := offset; i < len(all) && i < offset+limit; i++ {
+		p.items = append(p.items, all[i].item)
+	}
+	link := func(offset int) string {
+		v := url.Values{}
+		v.Set("limit", strconv.Itoa(limit))
+		v.Set("page", encodePage(pageToken{
+			Offset:  offset,
+			At:      at,
+			Filter:  q.filter,
+			OrderBy: q.orderBy,
+			Desc:    q.desc,
+			Extra:   q.extra,
+		}))
+		return e.opts.BaseURL + path + "?" + v.Encode()
+	}
+	p.self = link(offset)
+	p.first = link(0)
+	if offset+limit < len(all) {
+		next := link(offset + limit)
+		p.next = &next
+	}
+	return p, nil
+}
+
+func encodePage(t pageToken) string {
+	b, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodePage(s string) (pageToken, error) {
+	var t pageToken
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(b, &t)
+	}
+	if err == nil && t.Offset < 0 {
+		err = fmt.Errorf("negative offset")
+	}
+	return t, err
+}
+
+// eval returns true if the record with fields f matches e.
+func eval(e query.Expr, f fields) bool {
+	switch x := e.(type) {
+	case *query.Logical:
+		for _, sub := range x.Exprs {
+			m := eval(sub, f)
+			if x.Op == "or" && m {
+				return true
+			}
+			if x.Op == "and" && !m {
+				return false
+			}
+		}
+		return x.Op == "and"
+	case *query.Negation:
+		return !eval(x.Expr, f)
+	case *query.Call:
+		s, ok := f[x.Field].(string)
+		v, vok := literal(x.Value).(string)
+		if !ok || !vok {
+			return false
+		}
+		switch x.Func {
+		case query.FuncContains:
+			return strings.Contains(s, v)
+		case query.FuncStartsWith:
+			return strings.HasPrefix(s, v)
+		case query.FuncEndsWith:
+			return strings.HasSuffix(s, v)
+		}
+		return false
+	case *query.InList:
+		for _, v := range x.Values {
+			if c, ok := compareValue(f[x.Field], v); ok && c == 0 {
+				return true
+			}
+		}
+		return false
+	case *query.Comparison:
+		a := f[x.Field]
+		if x.Op == query.OpLike {
+			s, ok := a.(string)
+			p, pok := literal(x.Value).(string)
+			return ok && pok && like(s, p)
+		}
+		if a == nil || literal(x.Value) == nil {
+			eq := a == nil && literal(x.Value) == nil
+			switch x.Op {
+			case query.OpEq:
+				return eq
+			case query.OpNe:
+				return !eq
+			}
+			return false
+		}
+		c, ok := compareValue(a, x.Value)
+		if !ok {
+			return x.Op == query.OpNe
+		}
+		switch x.Op {
+		case query.OpEq:
+			return c == 0
+		case query.OpNe:
+			return c != 0
+		case query.OpGt:
+			return c > 0
+		case query.OpGe:
+			return c >= 0
+		case query.OpLt:
+			return c < 0
+		case query.OpLe:
+			return c <= 0
+		}
+	}
+	return false
+}
+
+// compareValue orders field value a and literal l, decoding dates and
+// date-times, quoted or not, for time fields.
+func compareValue(a interface{}, l query.Literal) (int, bool) {
+	b := literal(l)
+	if _, ok := a.(time.Time); ok {
+		if s, ok := b.(string); ok {
+			for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02T15:04Z07:00", "2006-01-02T15:04", "2006-01-02"} {
+				if t, err := time.Parse(layout, s); err == nil {
+					b = t
+					break
+				}
+			}
+		}
+	}
+	if a == nil || b == nil {
+		return 0, false
+	}
+	return compareSameType(a, b)
+}
+
+// literal decodes a literal of a filter expression.
+func literal(l query.Literal) interface{} {
+	s := string(l)
+	switch {
+	case s == "null":
+		return nil
+	case s == "true" || s == "false":
+		return s == "true"
+	case strings.HasPrefix(s, "'"):
+		return strings.ReplaceAll(strings.TrimSuffix(strings.TrimPrefix(s, "'"), "'"), "''", "'")
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return s
+}
+
+// compare orders two field values, nil first.
+func compare(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	if c, ok := compareSameType(a, b); ok {
+		return c
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func compareSameType(a, b interface{}) (int, bool) {
+	switch x := a.(type) {
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), true
+		}
+	case float64:
+		if y, ok := b.(float64); ok {
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			}
+			return 0, true
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0, true
+			case !x:
+				return -1, true
+			}
+			return 1, true
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y), true
+		}
+	}
+	return 0, false
+}
+
+// optString returns the value of s or nil for use in fields.
+func optString(s *string) interface{} {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
+
+// optTime returns t or nil if it is zero for use in fields.
+func optTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
+
+// alive returns true if something created at created and deleted at
+// deleted, zero if never, exists at time at.
+func alive(created, deleted, at time.Time) bool {
+	return !created.After(at) && (deleted.IsZero() || deleted.After(at))
+}
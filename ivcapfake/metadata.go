@@ -0,0 +1,537 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcapfake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	metadata "github.com/reinventingscience/ivcap-core-api/gen/metadata"
+	metadatac "github.com/reinventingscience/ivcap-core-api/http/metadata"
+
+	"goa.design/goa/v3/security"
+)
+
+// Metadata is an in-memory implementation of metadata.Service. Records are
+// never deleted: revoking a record ends its validity, so it is no longer
+// listed but can still be read and is listed for an earlier AtTime.
+type Metadata struct {
+	env *env
+
+	mu    sync.RWMutex
+	items map[string]*metadataRec
+	order []string
+	keys  map[string]string
+}
+
+var (
+	_ metadata.Service = (*Metadata)(nil)
+	_ metadata.Auther  = (*Metadata)(nil)
+)
+
+// metadataRec is a metadata record.
+type metadataRec struct {
+	id          string
+	entity      string
+	schema      string
+	aspect      interface{}
+	contentType string
+	policy      *string
+	asserter    string
+	revoker     string
+	key         string
+	validFrom   time.Time
+	validTo     time.Time
+}
+
+// metadataFields lists the fields metadata list filters may use.
+var metadataFields = []string{"record-id", "entity", "schema", "valid-from", "valid-to", "asserter", "revoker"}
+
+// JWTAuth implements metadata.Auther.
+func (s *Metadata) JWTAuth(ctx context.Context, token string, scheme *security.JWTScheme) (context.Context, error) {
+	ctx, kind, _ := s.env.authenticate(ctx, token, scheme)
+	switch kind {
+	case authUnauthorized:
+		return ctx, &metadata.UnauthorizedT{}
+	case authInvalidScopes:
+		return ctx, &metadata.InvalidScopesT{Message: invalidScopes}
+	}
+	return ctx, nil
+}
+
+// Read implements metadata.Service. Revoked records can still be read.
+func (s *Metadata) Read(ctx context.Context, p *metadata.ReadPayload) (*metadata.MetadataRecordRT, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r := s.items[p.ID]
+	if r == nil {
+		return nil, &metadata.ResourceNotFoundT{ID: p.ID, Message: "metadata record not found"}
+	}
+	return r.record(), nil
+}
+
+// metadataMatch is a record valid at the time of a list query.
+type metadataMatch struct {
+	rec     *metadataRec
+	context *string
+}
+
+// List implements metadata.Service. Schema may end in '%' to select all
+// schemas with that prefix. AspectPath supports the member, index and
+// wildcard accessors of JSON path, e.g. "$.images[*].name".
+func (s *Metadata) List(ctx context.Context, p *metadata.ListPayload) (*metadata.ListMetaRT, error) {
+	q := listQuery{limit: p.Limit, filter: p.Filter, orderBy: p.OrderBy, atTime: p.AtTime, page: p.Page, extra: map[string]string{}}
+	if p.OrderDesc != nil {
+		q.desc = *p.OrderDesc
+	}
+	for k, v := range map[string]*string{"entity-id": p.EntityID, "schema": p.Schema, "aspect-path": p.AspectPath} {
+		if v != nil && *v != "" {
+			q.extra[k] = *v
+		}
+	}
+	var extra map[string]string
+	pg, perr := list(s.env, "/1/metadata", q, metadataFields, func(at time.Time, x map[string]string) ([]metadataMatch, *paramError) {
+		extra = x
+		return s.snapshot(at, x)
+	}, func(m metadataMatch) fields {
+		r := m.rec
+		return fields{
+			"record-id":  r.id,
+			"entity":     r.entity,
+			"schema":     r.schema,
+			"valid-from": r.validFrom,
+			"valid-to":   optTime(r.validTo),
+			"asserter":   r.asserter,
+			"revoker":    r.revoker,
+		}
+	})
+	if perr != nil {
+		return nil, &metadata.InvalidParameterValue{Name: perr.name, Value: &perr.value, Message: perr.msg}
+	}
+	res := &metadata.ListMetaRT{
+		Records: []*metadata.MetadataListItemRT{},
+		AtTime:  formatTime(pg.at),
+		Links:   &metadata.NavT{Self: &pg.self, First: &pg.first, Next: pg.next},
+	}
+	for k, v := range extra {
+		switch v := v; k {
+		case "entity-id":
+			res.EntityID = &v
+		case "schema":
+			res.Schema = &v
+		case "aspect-path":
+			res.AspectPath = &v
+		}
+	}
+	for _, m := range pg.items {
+		r := m.rec
+		res.Records = append(res.Records, &metadata.MetadataListItemRT{
+			RecordID:      ptr(r.id),
+			Entity:        ptr(r.entity),
+			Schema:        ptr(r.schema),
+			Aspect:        cloneAny(r.aspect),
+			AspectContext: m.context,
+		})
+	}
+	return res, nil
+}
+
+// snapshot returns the records valid at time at which match the entity,
+// schema and aspect path in extra, oldest first.
+func (s *Metadata) snapshot(at time.Time, extra map[string]string) ([]metadataMatch, *paramError) {
+	var path []pathStep
+	if ap, ok := extra["aspect-path"]; ok {
+		var err error
+		if path, err = parsePath(ap); err != nil {
+			return nil, &paramError{"aspect-path", ap, err.Error()}
+		}
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var res []metadataMatch
+	for _, id := range s.order {
+		r := s.items[id]
+		if !alive(r.validFrom, r.validTo, at) {
+			continue
+		}
+		if e, ok := extra["entity-id"]; ok && r.entity != e {
+			continue
+		}
+		if sc, ok := extra["schema"]; ok && !like(r.schema, sc) {
+			continue
+		}
+		m := metadataMatch{rec: r}
+		if path != nil {
+			found := selectPath(r.aspect, path)
+			if len(found) == 0 {
+				continue
+			}
+			var v interface{} = found
+			if len(found) == 1 {
+				v = found[0]
+			}
+			b, _ := json.Marshal(v)
+			m.context = ptr(string(b))
+		}
+		res = append(res, m)
+	}
+	return res, nil
+}
+
+// Add implements metadata.Service. Adding a record with an idempotency key
+// already used by the same account returns the existing record. The key is
+// taken from ctx, see metadatac.WithIdempotencyKey.
+func (s *Metadata) Add(ctx context.Context, p *metadata.AddPayload) (*metadata.AddMetaRT, error) {
+	if err := checkAspect(p.EntityID, p.Schema, p.ContentType); err != nil {
+		return nil, err
+	}
+	account := Account(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var key string
+	if k := metadatac.IdempotencyKey(ctx); k != "" {
+		key = account + "|" + k
+		if id, ok := s.keys[key]; ok {
+			return &metadata.AddMetaRT{RecordID: id}, nil
+		}
+	}
+	r, err := s.add(account, p.EntityID, p.Schema, p.Aspect, p.ContentType, p.PolicyID, key)
+	if err != nil {
+		return nil, err
+	}
+	if key != "" {
+		s.keys[key] = r.id
+	}
+	return &metadata.AddMetaRT{RecordID: r.id}, nil
+}
+
+// add creates a record. It is called with s.mu held.
+func (s *Metadata) add(account, entity, schema string, aspect interface{}, contentType string, policy *string, key string) (*metadataRec, error) {
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	// Reported as an internal error, as payloads decoded from requests can
+	// always be encoded again.
+	aspect, err := jsonValue(aspect)
+	if err != nil {
+		return nil, fmt.Errorf("encoding aspect: %w", err)
+	}
+	r := &metadataRec{
+		id:          newID("record"),
+		entity:      entity,
+		schema:      schema,
+		aspect:      aspect,
+		contentType: contentType,
+		policy:      policy,
+		asserter:    account,
+		key:         key,
+		validFrom:   s.env.now(),
+	}
+	if err := s.env.save(KindMetadata, r.id, r); err != nil {
+		return nil, err
+	}
+	s.items[r.id] = r
+	s.order = append(s.order, r.id)
+	return r, nil
+}
+
+// UpdateOne implements metadata.Service. It fails with
+// *metadata.BadRequestT if more than one record is active for the
+// entity/schema pair.
+func (s *Metadata) UpdateOne(ctx context.Context, p *metadata.UpdateOnePayload) (*metadata.AddMetaRT, error) {
+	ct := "application/json"
+	if p.ContentType != nil {
+		ct = *p.ContentType
+	}
+	if err := checkAspect(p.EntityID, p.Schema, ct); err != nil {
+		return nil, err
+	}
+	account := Account(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.env.now()
+	var active []*metadataRec
+	for _, id := range s.order {
+		if r := s.items[id]; r.entity == p.EntityID && r.schema == p.Schema && alive(r.validFrom, r.validTo, now) {
+			active = append(active, r)
+		}
+	}
+	if len(active) > 1 {
+		return nil, &metadata.BadRequestT{Message: fmt.Sprintf("%d active records for entity %s and schema %s", len(active), p.EntityID, p.Schema)}
+	}
+	for _, r := range active {
+		if err := s.revoke(r, account, now); err != nil {
+			return nil, err
+		}
+	}
+	r, err := s.add(account, p.EntityID, p.Schema, p.Aspect, ct, p.PolicyID, "")
+	if err != nil {
+		return nil, err
+	}
+	return &metadata.AddMetaRT{RecordID: r.id}, nil
+}
+
+// UpdateRecord implements metadata.Service. The record must be active.
+func (s *Metadata) UpdateRecord(ctx context.Context, p *metadata.UpdateRecordPayload) (*metadata.AddMetaRT, error) {
+	account := Account(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.env.now()
+	old := s.items[p.ID]
+	if old == nil || !old.validTo.IsZero() {
+		return nil, &metadata.ResourceNotFoundT{ID: p.ID, Message: "active metadata record not found"}
+	}
+	entity, schema, aspect, ct, policy := old.entity, old.schema, old.aspect, old.contentType, old.policy
+	if p.EntityID != nil {
+		entity = *p.EntityID
+	}
+	if p.Schema != nil {
+		schema = *p.Schema
+	}
+	if p.Aspect != nil {
+		aspect = p.Aspect
+	}
+	if p.ContentType != nil {
+		ct = *p.ContentType
+	}
+	if p.PolicyID != nil {
+		policy = p.PolicyID
+	}
+	if err := checkAspect(entity, schema, ct); err != nil {
+		return nil, err
+	}
+	if err := s.revoke(old, account, now); err != nil {
+		return nil, err
+	}
+	r, err := s.add(account, entity, schema, aspect, ct, policy, "")
+	if err != nil {
+		return nil, err
+	}
+	return &metadata.AddMetaRT{RecordID: r.id}, nil
+}
+
+// Revoke implements metadata.Service. Revoking a revoked record is a no-op.
+func (s *Metadata) Revoke(ctx context.Context, p *metadata.RevokePayload) error {
+	if p.ID == nil {
+		return &metadata.InvalidParameterValue{Name: "id", Message: "missing record ID"}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.items[*p.ID]
+	if r == nil {
+		return &metadata.ResourceNotFoundT{ID: *p.ID, Message: "metadata record not found"}
+	}
+	if r.validTo.IsZero() {
+		return s.revoke(r, Account(ctx), s.env.now())
+	}
+	return nil
+}
+
+// revoke ends the validity of r. It is called with s.mu held.
+func (s *Metadata) revoke(r *metadataRec, account string, at time.Time) error {
+	r.validTo = at
+	r.revoker = account
+	return s.env.save(KindMetadata, r.id, r)
+}
+
+func (r *metadataRec) record() *metadata.MetadataRecordRT {
+	rec := &metadata.MetadataRecordRT{
+		RecordID:  ptr(r.id),
+		Entity:    ptr(r.entity),
+		Schema:    ptr(r.schema),
+		Aspect:    cloneAny(r.aspect),
+		ValidFrom: formatTime(r.validFrom),
+		ValidTo:   formatTime(r.validTo),
+	}
+	if r.asserter != "" {
+		rec.Asserter = ptr(r.asserter)
+	}
+	if r.revoker != "" {
+		rec.Revoker = ptr(r.revoker)
+	}
+	return rec
+}
+
+// checkAspect validates the parameters of a new record.
+func checkAspect(entity, schema, contentType string) error {
+	if entity == "" {
+		return &metadata.InvalidParameterValue{Name: "entity-id", Message: "missing entity"}
+	}
+	if schema == "" {
+		return &metadata.InvalidParameterValue{Name: "schema", Message: "missing schema"}
+	}
+	if mt, _, _ := strings.Cut(contentType, ";"); contentType != "" && strings.TrimSpace(mt) != "application/json" {
+		return &metadata.InvalidParameterValue{Name: "content-type", Value: &contentType, Message: "content type must be application/json"}
+	}
+	return nil
+}
+
+// like matches s against a pattern using '%' as wildcard.
+func like(s, pattern string) bool {
+	parts := strings.Split(pattern, "%")
+	if len(parts) == 1 {
+		return s == pattern
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, p := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, p)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(p):]
+	}
+	return strings.HasSuffix(s, last)
+}
+
+// pathStep is an accessor of a JSON path: a member name, an array index or,
+// if both are unset, a wildcard.
+type pathStep struct {
+	name  *string
+	index *int
+}
+
+// parsePath parses the member, index and wildcard accessors of a JSON path
+// such as "$.a.b[0]" or "$.a[*].b".
+func parsePath(p string) ([]pathStep, error) {
+	s := strings.TrimSpace(p)
+	if !strings.HasPrefix(s, "$") {
+		return nil, fmt.Errorf("path must start with '$'")
+	}
+	s = s[1:]
+	steps := []pathStep{}
+	for s != "" {
+		switch s[0] {
+		case '.':
+			s = s[1:]
+			if strings.HasPrefix(s, "*") {
+				steps, s = append(steps, pathStep{}), s[1:]
+				continue
+			}
+			if strings.HasPrefix(s, `"`) {
+				end := strings.IndexByte(s[1:], '"')
+				if end < 0 {
+					return nil, fmt.Errorf("unterminated member name in %q", p)
+				}
+				name := s[1 : end+1]
+				steps, s = append(steps, pathStep{name: &name}), s[end+2:]
+				continue
+			}
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("missing member name in %q", p)
+			}
+			name := s[:end]
+			steps, s = append(steps, pathStep{name: &name}), s[end:]
+		case '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated index in %q", p)
+			}
+			idx := strings.TrimSpace(s[1:end])
+			if idx == "*" {
+				steps = append(steps, pathStep{})
+			} else {
+				i, err := strconv.Atoi(idx)
+				if err != nil || i < 0 {
+					return nil, fmt.Errorf("unsupported index %q in %q", idx, p)
+				}
+				steps = append(steps, pathStep{index: &i})
+			}
+			s = s[end+1:]
+		default:
+			return nil, fmt.Errorf("unsupported path %q", p)
+		}
+	}
+	return steps, nil
+}
+
+// selectPath returns the values in v selected by path.
+func selectPath(v interface{}, path []pathStep) []interface{} {
+	if len(path) == 0 {
+		return []interface{}{v}
+	}
+	step, rest := path[0], path[1:]
+	var res []interface{}
+	switch x := v.(type) {
+	case map[string]interface{}:
+		switch {
+		case step.name != nil:
+			if c, ok := x[*step.name]; ok {
+				res = append(res, selectPath(c, rest)...)
+			}
+		case step.index == nil:
+			keys := make([]string, 0, len(x))
+			for k := range x {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				res = append(res, selectPath(x[k], rest)...)
+			}
+		}
+	case []interface{}:
+		switch {
+		case step.index != nil:
+			if *step.index < len(x) {
+				res = append(res, selectPath(x[*step.index], rest)...)
+			}
+		case step.name == nil:
+			for _, c := range x {
+				res = append(res, selectPath(c, rest)...)
+			}
+		}
+	}
+	return res
+}
+
+// cloneAny returns a deep copy of a value returned by jsonValue.
+func cloneAny(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return deepCopy(reflect.ValueOf(v)).Interface()
+}
+
+// jsonValue returns a copy of v in the generic representation of
+// encoding/json, which selectPath expects. It fails for values which cannot
+// be encoded as JSON.
+func jsonValue(v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var c interface{}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,436 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcapfake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	ivcap "github.com/reinventingscience/ivcap-core-api"
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+	orderc "github.com/reinventingscience/ivcap-core-api/http/order"
+
+	"goa.design/goa/v3/security"
+)
+
+// Orders is an in-memory implementation of order.Service. Orders stay
+// pending until their status is changed with SetStatus; their products,
+// logs and resource usage are set with AddProduct, AppendLog and SetTop.
+type Orders struct {
+	env       *env
+	services  *Services
+	artifacts *Artifacts
+
+	mu    sync.RWMutex
+	items map[string]*orderRec
+	order []string
+	keys  map[string]string
+}
+
+var (
+	_ order.Service = (*Orders)(nil)
+	_ order.Auther  = (*Orders)(nil)
+)
+
+// orderRec is an order together with its history.
+type orderRec struct {
+	id        string
+	req       *order.OrderRequestT
+	account   string
+	key       string
+	orderedAt time.Time
+	changes   []statusChange
+	products  []productRec
+	logs      []logRec
+	top       order.OrderTopResultItemCollection
+}
+
+// statusChange records the status of an order from a point in time on.
+type statusChange struct {
+	at     time.Time
+	status string
+}
+
+// productRec is a product and the time it was added.
+type productRec struct {
+	at      time.Time
+	product *order.ProductT
+}
+
+// logRec is a log line of an order.
+type logRec struct {
+	at        time.Time
+	container string
+	text      string
+}
+
+// orderFields lists the fields order list filters may use.
+var orderFields = []string{"id", "name", "status", "ordered-at", "started-at", "finished-at", "service-id", "account-id", "idempotency-key"}
+
+// JWTAuth implements order.Auther.
+func (s *Orders) JWTAuth(ctx context.Context, token string, scheme *security.JWTScheme) (context.Context, error) {
+	ctx, kind, _ := s.env.authenticate(ctx, token, scheme)
+	switch kind {
+	case authUnauthorized:
+		return ctx, &order.UnauthorizedT{}
+	case authInvalidScopes:
+		return ctx, &order.InvalidScopesT{Message: invalidScopes}
+	}
+	return ctx, nil
+}
+
+// Read implements order.Service.
+func (s *Orders) Read(ctx context.Context, p *order.ReadPayload) (*order.OrderStatusRT, string, error) {
+	st, ok := s.Status(p.ID)
+	if !ok {
+		return nil, "", &order.ResourceNotFoundT{ID: p.ID, Message: "order not found"}
+	}
+	return st, "default", nil
+}
+
+// Status returns the current status of order id.
+func (s *Orders) Status(id string) (*order.OrderStatusRT, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r := s.items[id]
+	if r == nil {
+		return nil, false
+	}
+	return r.statusAt(s.env, s.env.now()), true
+}
+
+// IDs returns the IDs of all orders, oldest first.
+func (s *Orders) IDs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string{}, s.order...)
+}
+
+// List implements order.Service.
+func (s *Orders) List(ctx context.Context, p *order.ListPayload) (*order.OrderListRT, error) {
+	q := listQuery{limit: p.Limit, filter: stringOf(p.Filter), orderBy: stringOf(p.OrderBy), desc: p.OrderDesc, atTime: p.AtTime, page: p.Page}
+	account := Account(ctx)
+	pg, perr := list(s.env, "/1/orders", q, orderFields, s.snapshot, func(it *order.OrderListItem) fields {
+		return fields{
+			"id":          optString(it.ID),
+			"name":        optString(it.Name),
+			"status":      optString(it.Status),
+			"ordered-at":  parsedTime(it.OrderedAt),
+			"started-at":  parsedTime(it.StartedAt),
+			"finished-at": parsedTime(it.FinishedAt),
+			"service-id":  optString(it.ServiceID),
+			"account-id":  optString(it.AccountID),
+			// Only the account which placed an order sees its key.
+			"idempotency-key": s.idempotencyKey(stringOf(it.ID), account),
+		}
+	})
+	if perr != nil {
+		return nil, &order.InvalidParameterValue{Name: perr.name, Value: &perr.value, Message: perr.msg}
+	}
+	return &order.OrderListRT{
+		Orders: append([]*order.OrderListItem{}, pg.items...),
+		AtTime: *formatTime(pg.at),
+		Links:  &order.NavT{Self: &pg.self, First: &pg.first, Next: pg.next},
+	}, nil
+}
+
+// idempotencyKey returns the idempotency key order id was placed with by
+// account, or nil if there is none.
+func (s *Orders) idempotencyKey(id, account string) interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r := s.items[id]
+	if r == nil || r.key == "" {
+		return nil
+	}
+	key, ok := strings.CutPrefix(r.key, account+"|")
+	if !ok {
+		return nil
+	}
+	return key
+}
+
+// snapshot returns the orders placed until time at, oldest first.
+func (s *Orders) snapshot(at time.Time, _ map[string]string) ([]*order.OrderListItem, *paramError) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var res []*order.OrderListItem
+	for _, id := range s.order {
+		r := s.items[id]
+		if r.orderedAt.After(at) {
+			continue
+		}
+		st := r.statusAt(s.env, at)
+		it := &order.OrderListItem{
+			ID:         &st.ID,
+			Name:       st.Name,
+			Status:     st.Status,
+			OrderedAt:  st.OrderedAt,
+			StartedAt:  st.StartedAt,
+			FinishedAt: st.FinishedAt,
+			ServiceID:  ptr(r.req.ServiceID),
+			Links:      st.Links,
+		}
+		if r.account != "" {
+			it.AccountID = ptr(r.account)
+		}
+		res = append(res, it)
+	}
+	return res, nil
+}
+
+// Create implements order.Service. The service must exist and the
+// parameters must match its definitions, see ivcap.ValidateParameters.
+// Orders created with an idempotency key already used by the same account
+// are not created again, the existing order is returned instead. The key is
+// taken from ctx, see orderc.WithIdempotencyKey.
+func (s *Orders) Create(ctx context.Context, p *order.CreatePayload) (*order.OrderStatusRT, string, error) {
+	if p.Orders == nil {
+		return nil, "", &order.InvalidParameterValue{Name: "orders", Message: "missing order request"}
+	}
+	svc, ok := s.services.Status(p.Orders.ServiceID)
+	if !ok {
+		return nil, "", &order.ResourceNotFoundT{ID: p.Orders.ServiceID, Message: "service not found"}
+	}
+	req := clone(p.Orders)
+	if err := ivcap.ValidateParameters(svc, req); err != nil {
+		var perr *order.InvalidParameterValue
+		if errors.As(err, &perr) {
+			return nil, "", perr
+		}
+		return nil, "", &order.InvalidParameterValue{Name: "parameters", Message: err.Error()}
+	}
+
+	account := Account(ctx)
+	var created string
+	defer func() {
+		// Called once the lock is released, so the hook may use s.
+		if created != "" && s.env.opts.OrderCreated != nil {
+			s.env.opts.OrderCreated(created)
+		}
+	}()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var key string
+	if k := orderc.IdempotencyKey(ctx); k != "" {
+		key = account + "|" + k
+		if id, ok := s.keys[key]; ok {
+			r := s.items[id]
+			return r.statusAt(s.env, s.env.now()), "default", nil
+		}
+	}
+	now := s.env.now()
+	r := &orderRec{
+		id:        newOrderID(),
+		req:       req,
+		account:   account,
+		key:       key,
+		orderedAt: now,
+		changes:   []statusChange{{now, ivcap.OrderStatusPending}},
+	}
+	if err := s.env.save(KindOrder, r.id, r); err != nil {
+		return nil, "", err
+	}
+	s.items[r.id] = r
+	s.order = append(s.order, r.id)
+	if key != "" {
+		s.keys[key] = r.id
+	}
+	created = r.id
+	return r.statusAt(s.env, now), "default", nil
+}
+
+// Logs implements order.Service. Lines are returned in the format of the
+// IVCAP deployments, see ivcap.ParseLogLine.
+func (s *Orders) Logs(ctx context.Context, p *order.LogsPayload) (io.ReadCloser, error) {
+	req := p.DownloadLogRequest
+	if req == nil {
+		return nil, &order.InvalidParameterValue{Name: "download-log-request", Message: "missing request"}
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r := s.items[req.OrderID]
+	if r == nil {
+		return nil, &order.ResourceNotFoundT{ID: req.OrderID, Message: "order not found"}
+	}
+	pod := r.id[strings.LastIndexByte(r.id, ':')+1:]
+	var b strings.Builder
+	for _, l := range r.logs {
+		if req.From != nil && l.at.Unix() < *req.From || req.To != nil && l.at.Unix() > *req.To {
+			continue
+		}
+		if req.ContainerName != nil && *req.ContainerName != "" && *req.ContainerName != l.container {
+			continue
+		}
+		fmt.Fprintf(&b, "[%s/%s] %s %s\n", pod, l.container, l.at.Format(time.RFC3339Nano), l.text)
+	}
+	return io.NopCloser(strings.NewReader(b.String())), nil
+}
+
+// Top implements order.Service.
+func (s *Orders) Top(ctx context.Context, p *order.TopPayload) (order.OrderTopResultItemCollection, error) {
+	if p.OrderTopRequest == nil {
+		return nil, &order.InvalidParameterValue{Name: "order-top-request", Message: "missing request"}
+	}
+	id := p.OrderTopRequest.OrderID
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r := s.items[id]
+	if r == nil {
+		return nil, &order.ResourceNotFoundT{ID: id, Message: "order not found"}
+	}
+	res := order.OrderTopResultItemCollection{}
+	for _, it := range r.top {
+		c := *it
+		res = append(res, &c)
+	}
+	return res, nil
+}
+
+// SetStatus changes the status of order id, e.g. to
+// ivcap.OrderStatusExecuting.
+func (s *Orders) SetStatus(id, status string) error {
+	return s.update(id, func(r *orderRec, now time.Time) error {
+		r.changes = append(r.changes, statusChange{now, status})
+		return nil
+	})
+}
+
+// AddProduct adds artifact artifactID of Artifacts to the products of order
+// id under name, which defaults to the name of the artifact.
+func (s *Orders) AddProduct(id, artifactID, name string) error {
+	a, ok := s.artifacts.Status(artifactID)
+	if !ok {
+		return &order.ResourceNotFoundT{ID: artifactID, Message: "artifact not found"}
+	}
+	if name == "" {
+		name = stringOf(a.Name)
+	}
+	p := &order.ProductT{
+		ID:       &a.ID,
+		Name:     &name,
+		Status:   &a.Status,
+		MimeType: a.MimeType,
+		Size:     a.Size,
+		Etag:     a.Etag,
+	}
+	return s.update(id, func(r *orderRec, now time.Time) error {
+		r.products = append(r.products, productRec{now, p})
+		return nil
+	})
+}
+
+// AppendLog adds a line logged by container to the logs of order id.
+func (s *Orders) AppendLog(id, container, line string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.items[id]
+	if r == nil {
+		return &order.ResourceNotFoundT{ID: id, Message: "order not found"}
+	}
+	l := logRec{s.env.now(), container, line}
+	lid := fmt.Sprintf("%s/%d", id, len(r.logs))
+	if err := s.env.save(KindOrderLog, lid, logJSON{id, l.at, l.container, l.text}); err != nil {
+		return err
+	}
+	r.logs = append(r.logs, l)
+	return nil
+}
+
+// SetTop sets the resource usage reported for the containers of order id.
+func (s *Orders) SetTop(id string, items order.OrderTopResultItemCollection) error {
+	return s.update(id, func(r *orderRec, now time.Time) error {
+		r.top = items
+		return nil
+	})
+}
+
+func (s *Orders) update(id string, f func(r *orderRec, now time.Time) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.items[id]
+	if r == nil {
+		return &order.ResourceNotFoundT{ID: id, Message: "order not found"}
+	}
+	if err := f(r, s.env.now()); err != nil {
+		return err
+	}
+	return s.env.save(KindOrder, id, r)
+}
+
+// statusAt returns the status of the order at time at.
+func (r *orderRec) statusAt(e *env, at time.Time) *order.OrderStatusRT {
+	var started, finished time.Time
+	status := ivcap.OrderStatusPending
+	for _, c := range r.changes {
+		if c.at.After(at) {
+			break
+		}
+		status = c.status
+		if started.IsZero() && c.status == ivcap.OrderStatusExecuting {
+			started = c.at
+		}
+		if ivcap.IsTerminalOrderStatus(c.status) {
+			finished = c.at
+		} else {
+			finished = time.Time{}
+		}
+	}
+	req := clone(r.req)
+	st := &order.OrderStatusRT{
+		ID:           r.id,
+		Status:       &status,
+		OrderedAt:    formatTime(r.orderedAt),
+		StartedAt:    formatTime(started),
+		FinishedAt:   formatTime(finished),
+		Products:     []*order.ProductT{},
+		Service:      &order.RefT{ID: ptr(req.ServiceID), Links: &order.SelfT{Self: e.link("/1/services/%s", req.ServiceID)}},
+		Links:        &order.SelfT{Self: e.link("/1/orders/%s", r.id)},
+		ProductLinks: &order.NavT{Self: e.link("/1/orders/%s", r.id)},
+		Name:         req.Name,
+		Tags:         req.Tags,
+		Parameters:   req.Parameters,
+	}
+	if r.account != "" {
+		st.Account = &order.RefT{ID: ptr(r.account)}
+	}
+	for _, p := range r.products {
+		if !p.at.After(at) {
+			// Links follow the current base URL, which may differ from the
+			// one the product was added under.
+			pr := clone(p.product)
+			pr.Links = &order.SelfWithDataT{Self: e.link("/1/artifacts/%s", *pr.ID), Data: e.link("/1/artifacts/%s/blob", *pr.ID)}
+			st.Products = append(st.Products, pr)
+		}
+	}
+	return st
+}
+
+// parsedTime returns the time in s or nil for use in fields.
+func parsedTime(s *string) interface{} {
+	if s == nil {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, *s)
+	if err != nil {
+		return *s
+	}
+	return t
+}
@@ -0,0 +1,149 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcapfake
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	ivcap "github.com/reinventingscience/ivcap-core-api"
+	artifact "github.com/reinventingscience/ivcap-core-api/gen/artifact"
+	metadata "github.com/reinventingscience/ivcap-core-api/gen/metadata"
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+	artifactc "github.com/reinventingscience/ivcap-core-api/http/artifact"
+	artifactsvr "github.com/reinventingscience/ivcap-core-api/http/artifact/server"
+	metadatac "github.com/reinventingscience/ivcap-core-api/http/metadata"
+	metadatasvr "github.com/reinventingscience/ivcap-core-api/http/metadata/server"
+	orderc "github.com/reinventingscience/ivcap-core-api/http/order"
+	ordersvr "github.com/reinventingscience/ivcap-core-api/http/order/server"
+	servicesvr "github.com/reinventingscience/ivcap-core-api/http/service/server"
+
+	goahttp "goa.design/goa/v3/http"
+	"goa.design/goa/v3/security"
+)
+
+// Handler returns an HTTP handler serving the fakes through the generated
+// HTTP servers, so that the real clients can be pointed at them:
+//
+//	srv := httptest.NewUnstartedServer(nil)
+//	f := ivcapfake.New(&ivcapfake.Options{BaseURL: "http://" + srv.Listener.Addr().String()})
+//	srv.Config.Handler = f.Handler()
+//	srv.Start()
+//
+// Besides the API it serves the artifact data links, supporting range
+// requests, and the TUS requests continuing a resumable upload. Options.BaseURL
+// should point at the server for the returned links to work.
+func (f *Fake) Handler() http.Handler {
+	var (
+		mux = goahttp.NewMuxer()
+		dec = goahttp.RequestDecoder
+		enc = goahttp.ResponseEncoder
+	)
+	errh := func(ctx context.Context, w http.ResponseWriter, err error) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+	ordersvr.Mount(mux, ordersvr.New(order.NewEndpoints(f.Orders), mux, dec, enc, errh, nil))
+	artifactsvr.Mount(mux, artifactsvr.New(artifact.NewEndpoints(f.Artifacts), mux, dec, enc, errh, nil))
+	servicesvr.Mount(mux, servicesvr.New(service.NewEndpoints(f.Services), mux, dec, enc, errh, nil))
+	metadatasvr.Mount(mux, metadatasvr.New(metadata.NewEndpoints(f.Metadata), mux, dec, enc, errh, nil))
+
+	blob := &blobHandler{artifacts: f.Artifacts, mux: mux}
+	mux.Handle("GET", "/1/artifacts/{id}/blob", blob.ServeHTTP)
+	mux.Handle("HEAD", "/1/artifacts/{id}/blob", blob.ServeHTTP)
+	mux.Handle("PATCH", "/1/artifacts/{id}/blob", blob.ServeHTTP)
+	return orderc.DecodeIdempotencyKey(artifactc.DecodeIdempotencyKey(metadatac.DecodeIdempotencyKey(mux)))
+}
+
+// blobHandler serves the content of artifacts and accepts TUS PATCH
+// requests adding to it.
+type blobHandler struct {
+	artifacts *Artifacts
+	mux       goahttp.Muxer
+}
+
+func (h *blobHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	scope := "consumer:read"
+	if r.Method == "PATCH" {
+		scope = "consumer:write"
+	}
+	scheme := &security.JWTScheme{Name: "jwt", RequiredScopes: []string{scope}}
+	if _, err := h.artifacts.JWTAuth(r.Context(), r.Header.Get("Authorization"), scheme); err != nil {
+		var unauth *artifact.UnauthorizedT
+		if errors.As(err, &unauth) {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		} else {
+			http.Error(w, err.Error(), http.StatusForbidden)
+		}
+		return
+	}
+
+	id := h.mux.Vars(r)["id"]
+	content, st, ok := h.artifacts.Open(id)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	defer content.Close()
+	switch {
+	case r.Method == "PATCH":
+		h.patch(w, r, id)
+	case r.Header.Get("Tus-Resumable") != "":
+		offset, err := content.Seek(0, io.SeekEnd)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Tus-Resumable", ivcap.TusVersion)
+		w.Header().Set("Upload-Offset", strconv.FormatInt(offset, 10))
+		w.Header().Set("Upload-Length", strconv.FormatInt(*st.Size, 10))
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+	default:
+		if st.Etag != nil {
+			w.Header().Set("ETag", `"`+*st.Etag+`"`)
+		}
+		if st.MimeType != nil {
+			w.Header().Set("Content-Type", *st.MimeType)
+		}
+		http.ServeContent(w, r, "", time.Time{}, content)
+	}
+}
+
+// patch appends the request body to the content of artifact id.
+func (h *blobHandler) patch(w http.ResponseWriter, r *http.Request, id string) {
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid Upload-Offset header", http.StatusBadRequest)
+		return
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	off, err := h.artifacts.Append(id, offset, data)
+	w.Header().Set("Tus-Resumable", ivcap.TusVersion)
+	w.Header().Set("Upload-Offset", strconv.FormatInt(off, 10))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,392 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcapfake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ivcap "github.com/reinventingscience/ivcap-core-api"
+	artifact "github.com/reinventingscience/ivcap-core-api/gen/artifact"
+	metadata "github.com/reinventingscience/ivcap-core-api/gen/metadata"
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+	metadatac "github.com/reinventingscience/ivcap-core-api/http/metadata"
+	orderc "github.com/reinventingscience/ivcap-core-api/http/order"
+	"github.com/reinventingscience/ivcap-core-api/ivcaperr"
+)
+
+const testAccount = "urn:ivcap:account:test"
+
+// newTestClient serves a new fake and returns a client for it acting for
+// testAccount.
+func newTestClient(t *testing.T) (*ivcap.Client, *Fake) {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(nil)
+	f := New(&Options{BaseURL: "http://" + srv.Listener.Addr().String()})
+	srv.Config.Handler = f.Handler()
+	srv.Start()
+	t.Cleanup(srv.Close)
+	c, err := ivcap.NewClient(srv.URL, ivcap.StaticToken(Token(testAccount, "consumer:read", "consumer:write")), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, f
+}
+
+// createService registers a service with parameters named params.
+func createService(t *testing.T, c *ivcap.Client, params ...string) string {
+	t.Helper()
+	name, typ, image := "test", "basic", "alpine"
+	desc := &service.ServiceDescriptionT{
+		Name:       &name,
+		ProviderID: "urn:ivcap:provider:test",
+		Parameters: []*service.ParameterDefT{},
+		Workflow:   &service.WorkflowT{Type: &typ, Basic: &service.BasicWorkflowOptsT{Image: image, Command: []string{"true"}}},
+	}
+	for i := range params {
+		desc.Parameters = append(desc.Parameters, &service.ParameterDefT{Name: &params[i]})
+	}
+	svc, err := c.Services().Create(context.Background(), desc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svc.ID
+}
+
+func TestIdempotencyKeys(t *testing.T) {
+	c, f := newTestClient(t)
+	ctx := context.Background()
+	req := &order.OrderRequestT{ServiceID: createService(t, c), Parameters: []*order.ParameterT{}}
+	key := ivcap.NewIdempotencyKey()
+	o1, err := c.Orders().CreateWithKey(ctx, req, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o2, err := c.Orders().CreateWithKey(ctx, req, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o3, err := c.Orders().CreateWithKey(ctx, req, ivcap.NewIdempotencyKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o1.ID != o2.ID || o1.ID == o3.ID {
+		t.Errorf("orders %s, %s, %s: want the first two to be the same", o1.ID, o2.ID, o3.ID)
+	}
+	found, err := c.Orders().FindByIdempotencyKey(ctx, req.ServiceID, key, time.Now().Add(-time.Minute))
+	if err != nil || found.ID != o1.ID {
+		t.Errorf("FindByIdempotencyKey = %v, %v", found, err)
+	}
+
+	// The key is per account.
+	fctx := WithAccount(ctx, "urn:ivcap:account:other")
+	o4, _, err := f.Orders.Create(orderc.WithIdempotencyKey(fctx, key), &order.CreatePayload{Orders: req})
+	if err != nil || o4.ID == o1.ID {
+		t.Errorf("order of another account = %v, %v", o4, err)
+	}
+
+	add := func() string {
+		res, err := c.Metadata().Add(metadatac.WithIdempotencyKey(ctx, key), &metadata.AddPayload{
+			EntityID:    "urn:ivcap:order:1",
+			Schema:      "urn:test:schema",
+			Aspect:      map[string]interface{}{"a": 1.0},
+			ContentType: "application/json",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.RecordID
+	}
+	if r1, r2 := add(), add(); r1 != r2 {
+		t.Errorf("records %s and %s added with the same key", r1, r2)
+	}
+}
+
+func TestServicesRoundTrip(t *testing.T) {
+	c, _ := newTestClient(t)
+	ctx := context.Background()
+	id := createService(t, c, "size")
+	svc, err := c.Services().Read(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if svc.Name == nil || *svc.Name != "test" || len(svc.Parameters) != 1 || *svc.Parameters[0].Name != "size" {
+		t.Errorf("read %+v", svc)
+	}
+	if svc.Account == nil || *svc.Account.ID != testAccount {
+		t.Errorf("account = %v", svc.Account)
+	}
+
+	name, typ := "renamed", "basic"
+	desc := &service.ServiceDescriptionT{
+		Name:       &name,
+		ProviderID: "urn:ivcap:provider:test",
+		Parameters: []*service.ParameterDefT{},
+		Workflow:   &service.WorkflowT{Type: &typ, Basic: &service.BasicWorkflowOptsT{Image: "alpine", Command: []string{"true"}}},
+	}
+	if _, err := c.Services().Update(ctx, id, desc, false); err != nil {
+		t.Fatal(err)
+	}
+	createService(t, c)
+	res, err := c.Services().List(ctx, &service.ListPayload{Limit: 10, Filter: ptr("name eq 'renamed'")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Services) != 1 || *res.Services[0].ID != id {
+		t.Errorf("listed %v", res.Services)
+	}
+	if _, err := c.Services().List(ctx, &service.ListPayload{Limit: 10, Filter: ptr("size eq 1")}); !errors.Is(err, ivcaperr.ErrInvalidParameter) {
+		t.Errorf("filter on an unknown field: err = %v", err)
+	}
+
+	if err := c.Services().Delete(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Services().Read(ctx, id); !errors.Is(err, ivcaperr.ErrNotFound) {
+		t.Errorf("read deleted service: err = %v", err)
+	}
+	if _, err := c.Services().Update(ctx, "urn:ivcap:service:missing", desc, false); !errors.Is(err, ivcaperr.ErrNotFound) {
+		t.Errorf("update missing service: err = %v", err)
+	}
+}
+
+func TestOrdersRoundTrip(t *testing.T) {
+	c, f := newTestClient(t)
+	ctx := context.Background()
+	sid := createService(t, c, "size")
+	var ids []string
+	for _, size := range []string{"1", "2", "3"} {
+		o, err := c.Orders().Create(ctx, &order.OrderRequestT{
+			ServiceID:  sid,
+			Name:       ptr("order " + size),
+			Parameters: []*order.ParameterT{{Name: ptr("size"), Value: &size}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, o.ID)
+	}
+	_, err := c.Orders().Create(ctx, &order.OrderRequestT{
+		ServiceID:  sid,
+		Parameters: []*order.ParameterT{{Name: ptr("color"), Value: ptr("red")}},
+	})
+	if !errors.Is(err, ivcaperr.ErrInvalidParameter) {
+		t.Errorf("undefined parameter: err = %v", err)
+	}
+
+	if err := f.Orders.SetStatus(ids[1], ivcap.OrderStatusExecuting); err != nil {
+		t.Fatal(err)
+	}
+	o, err := c.Orders().Read(ctx, ids[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *o.Status != ivcap.OrderStatusExecuting || *o.Name != "order 2" || *o.Parameters[0].Value != "2" || *o.Service.ID != sid {
+		t.Errorf("read %+v", o)
+	}
+
+	// Pages of one order each.
+	var listed []string
+	for it, err := range c.Orders().ListAll(ctx, &order.ListPayload{Limit: 1, OrderBy: ptr("name")}, 0) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		listed = append(listed, *it.ID)
+	}
+	if fmt.Sprint(listed) != fmt.Sprint(ids) {
+		t.Errorf("listed %v, want %v", listed, ids)
+	}
+	res, err := c.Orders().List(ctx, &order.ListPayload{Limit: 10, Filter: ptr("status eq 'executing'")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Orders) != 1 || *res.Orders[0].ID != ids[1] {
+		t.Errorf("listed %v", res.Orders)
+	}
+
+	f.Orders.AppendLog(ids[1], "main", "hello")
+	r, err := c.Orders().Logs(ctx, &order.DownloadLogRequestT{OrderID: ids[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(r)
+	r.Close()
+	if !strings.HasSuffix(string(b), " hello\n") {
+		t.Errorf("logs %q", b)
+	}
+
+	f.Orders.SetTop(ids[1], order.OrderTopResultItemCollection{{Container: "main", CPU: "100m", Memory: "1Mi"}})
+	top, err := c.Orders().Top(ctx, &order.OrderTopRequestT{OrderID: ids[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(top) != 1 || top[0].CPU != "100m" {
+		t.Errorf("top %v", top)
+	}
+
+	if _, err := c.Orders().Read(ctx, "urn:ivcap:order:missing"); !errors.Is(err, ivcaperr.ErrNotFound) {
+		t.Errorf("read missing order: err = %v", err)
+	}
+}
+
+func TestArtifactsRoundTrip(t *testing.T) {
+	c, f := newTestClient(t)
+	ctx := context.Background()
+	a, err := c.Artifacts().Upload(ctx, &artifact.UploadPayload{Name: ptr("result.txt"), ContentType: ptr("text/plain")}, strings.NewReader("some content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, err := c.Artifacts().Read(ctx, a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *st.Name != "result.txt" || *st.MimeType != "text/plain" || *st.Size != 12 {
+		t.Errorf("read %+v", st)
+	}
+	var b strings.Builder
+	if err := c.Artifacts().Download(ctx, a.ID, &b); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "some content" {
+		t.Errorf("downloaded %q", b.String())
+	}
+
+	// Products of orders are artifacts.
+	sid := createService(t, c)
+	o, err := c.Orders().Create(ctx, &order.OrderRequestT{ServiceID: sid, Parameters: []*order.ParameterT{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Orders.AddProduct(o.ID, a.ID, "out/result.txt"); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for p, err := range c.Orders().Products(ctx, o.ID) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, *p.Name)
+	}
+	if fmt.Sprint(names) != "[out/result.txt]" {
+		t.Errorf("products %v", names)
+	}
+}
+
+func TestMetadataRoundTrip(t *testing.T) {
+	c, _ := newTestClient(t)
+	ctx := context.Background()
+	add := func(entity string, size float64) string {
+		res, err := c.Metadata().Add(ctx, &metadata.AddPayload{
+			EntityID:    entity,
+			Schema:      "urn:test:schema",
+			Aspect:      map[string]interface{}{"size": size},
+			ContentType: "application/json",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.RecordID
+	}
+	r1 := add("urn:test:a", 1)
+	add("urn:test:b", 2)
+
+	rec, err := c.Metadata().Read(ctx, r1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *rec.Entity != "urn:test:a" || rec.Aspect.(map[string]interface{})["size"] != 1.0 || rec.Asserter == nil || *rec.Asserter != testAccount {
+		t.Errorf("read %+v", rec)
+	}
+	res, err := c.Metadata().List(ctx, &metadata.ListPayload{Limit: 10, Schema: ptr("urn:test:schema"), AspectPath: ptr("$.size")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Records) != 2 || res.Records[0].AspectContext == nil {
+		t.Errorf("listed %v", res.Records)
+	}
+
+	if err := c.Metadata().Revoke(ctx, r1); err != nil {
+		t.Fatal(err)
+	}
+	res, err = c.Metadata().List(ctx, &metadata.ListPayload{Limit: 10, EntityID: ptr("urn:test:a")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Records) != 0 {
+		t.Errorf("listed revoked record %v", res.Records)
+	}
+}
+
+func TestAuthRoundTrip(t *testing.T) {
+	_, f := newTestClient(t)
+	srv := httptest.NewServer(f.Handler())
+	defer srv.Close()
+	for _, tc := range []struct {
+		token string
+		kind  *ivcaperr.Kind
+	}{
+		{Token(testAccount, "consumer:read"), ivcaperr.ErrInvalidScopes},
+		{"not a token", ivcaperr.ErrUnauthorized},
+	} {
+		c, err := ivcap.NewClient(srv.URL, ivcap.StaticToken(tc.token), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = c.Orders().Create(context.Background(), &order.OrderRequestT{ServiceID: "urn:ivcap:service:x", Parameters: []*order.ParameterT{}})
+		if !errors.Is(err, tc.kind) {
+			t.Errorf("token %q: err = %v, want %v", tc.token, err, tc.kind)
+		}
+	}
+}
+
+func TestUnencodableAspect(t *testing.T) {
+	f := New(nil)
+	_, err := f.Metadata.Add(WithAccount(context.Background(), testAccount), &metadata.AddPayload{
+		EntityID:    "urn:test:a",
+		Schema:      "urn:test:schema",
+		Aspect:      map[string]interface{}{"size": math.NaN()},
+		ContentType: "application/json",
+	})
+	if err == nil || ivcaperr.KindOf(err) != nil {
+		t.Errorf("err = %v, want an internal error", err)
+	}
+}
+
+func TestClone(t *testing.T) {
+	name := "a"
+	desc := &service.ServiceDescriptionT{
+		Name:       &name,
+		Parameters: []*service.ParameterDefT{{Name: &name}},
+		Workflow:   &service.WorkflowT{Argo: map[string]interface{}{"spec": []interface{}{"x"}}},
+	}
+	c := clone(desc)
+	*c.Name = "b"
+	*c.Parameters[0].Name = "b"
+	c.Workflow.Argo.(map[string]interface{})["spec"].([]interface{})[0] = "y"
+	if name != "a" || desc.Workflow.Argo.(map[string]interface{})["spec"].([]interface{})[0] != "x" {
+		t.Errorf("clone shares state: %+v", desc)
+	}
+	if clone[service.ServiceDescriptionT](nil) != nil {
+		t.Error("clone(nil) != nil")
+	}
+}
@@ -0,0 +1,339 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcapfake
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/reinventingscience/ivcap-core-api/argo"
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+	"github.com/reinventingscience/ivcap-core-api/resource"
+
+	"goa.design/goa/v3/security"
+)
+
+// Services is an in-memory implementation of service.Service.
+type Services struct {
+	env   *env
+	mu    sync.RWMutex
+	items map[string]*serviceRec
+	order []string
+}
+
+var (
+	_ service.Service = (*Services)(nil)
+	_ service.Auther  = (*Services)(nil)
+)
+
+// serviceRec is a service together with its history.
+type serviceRec struct {
+	id       string
+	account  string
+	versions []serviceVersion
+	created  time.Time
+	deleted  time.Time
+}
+
+// serviceVersion is the description of a service from a point in time on.
+type serviceVersion struct {
+	from time.Time
+	desc *service.ServiceDescriptionT
+}
+
+// serviceFields lists the fields service list filters may use.
+var serviceFields = []string{"id", "name", "description", "provider-id", "provider-ref", "status", "created-at"}
+
+// JWTAuth implements service.Auther.
+func (s *Services) JWTAuth(ctx context.Context, token string, scheme *security.JWTScheme) (context.Context, error) {
+	ctx, kind, _ := s.env.authenticate(ctx, token, scheme)
+	switch kind {
+	case authUnauthorized:
+		return ctx, &service.UnauthorizedT{}
+	case authInvalidScopes:
+		return ctx, &service.InvalidScopesT{Message: invalidScopes}
+	}
+	return ctx, nil
+}
+
+// List implements service.Service.
+func (s *Services) List(ctx context.Context, p *service.ListPayload) (*service.ServiceListRT, error) {
+	q := listQuery{limit: p.Limit, filter: stringOf(p.Filter), orderBy: stringOf(p.OrderBy), desc: p.OrderDesc, atTime: p.AtTime, page: p.Page}
+	pg, perr := list(s.env, "/1/services", q, serviceFields, s.snapshot, func(sa serviceAt) fields {
+		st := sa.status
+		return fields{
+			"id":           st.ID,
+			"name":         optString(st.Name),
+			"description":  optString(st.Description),
+			"provider-id":  optString(st.Provider.ID),
+			"provider-ref": optString(st.ProviderRef),
+			"status":       optString(st.Status),
+			"created-at":   sa.created,
+		}
+	})
+	if perr != nil {
+		return nil, &service.InvalidParameterValue{Name: perr.name, Value: &perr.value, Message: perr.msg}
+	}
+	res := &service.ServiceListRT{
+		Services: []*service.ServiceListItem{},
+		AtTime:   *formatTime(pg.at),
+		Links:    &service.NavT{Self: &pg.self, First: &pg.first, Next: pg.next},
+	}
+	for _, sa := range pg.items {
+		st := sa.status
+		res.Services = append(res.Services, &service.ServiceListItem{
+			ID:          &st.ID,
+			Name:        st.Name,
+			Description: st.Description,
+			Provider:    st.Provider,
+			Links:       st.Links,
+		})
+	}
+	return res, nil
+}
+
+// serviceAt is the status of a service at some time.
+type serviceAt struct {
+	status  *service.ServiceStatusRT
+	created time.Time
+}
+
+// snapshot returns the services existing at time at in creation order.
+func (s *Services) snapshot(at time.Time, _ map[string]string) ([]serviceAt, *paramError) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var res []serviceAt
+	for _, id := range s.order {
+		r := s.items[id]
+		if st := r.statusAt(s.env, at); st != nil {
+			res = append(res, serviceAt{st, r.created})
+		}
+	}
+	return res, nil
+}
+
+// CreateService implements service.Service. Services with a provider
+// reference get an ID derived from the provider ID and reference, so
+// creating the same service twice fails with
+// *service.ResourceAlreadyCreatedT.
+func (s *Services) CreateService(ctx context.Context, p *service.CreateServicePayload) (*service.ServiceStatusRT, string, error) {
+	if err := checkServiceDescription(p.Services); err != nil {
+		return nil, "", err
+	}
+	id := newID("service")
+	if p.Services.ProviderRef != nil {
+		id = derivedID("service", p.Services.ProviderID, *p.Services.ProviderRef)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r := s.items[id]; r != nil && r.deleted.IsZero() {
+		return nil, "", &service.ResourceAlreadyCreatedT{ID: id, Message: "service already exists"}
+	}
+	st, err := s.create(ctx, id, p.Services)
+	if err != nil {
+		return nil, "", err
+	}
+	return st, "default", nil
+}
+
+// create adds a new service. It is called with s.mu held.
+func (s *Services) create(ctx context.Context, id string, desc *service.ServiceDescriptionT) (*service.ServiceStatusRT, error) {
+	now := s.env.now()
+	r := &serviceRec{
+		id:       id,
+		account:  Account(ctx),
+		versions: []serviceVersion{{from: now, desc: clone(desc)}},
+		created:  now,
+	}
+	if old := s.items[id]; old == nil {
+		s.order = append(s.order, id)
+	} else {
+		// A deleted service is recreated, keep its history for AtTime
+		// queries.
+		r.versions = append(old.versions, r.versions...)
+	}
+	if err := s.env.save(KindService, id, r); err != nil {
+		return nil, err
+	}
+	s.items[id] = r
+	return r.statusAt(s.env, now), nil
+}
+
+// Read implements service.Service.
+func (s *Services) Read(ctx context.Context, p *service.ReadPayload) (*service.ServiceStatusRT, string, error) {
+	st, ok := s.Status(p.ID)
+	if !ok {
+		return nil, "", &service.ResourceNotFoundT{ID: p.ID, Message: "service not found"}
+	}
+	return st, "default", nil
+}
+
+// Description returns the current description of service id.
+func (s *Services) Description(id string) (*service.ServiceDescriptionT, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r := s.items[id]
+	if r == nil || r.statusAt(s.env, s.env.now()) == nil {
+		return nil, false
+	}
+	return clone(r.versions[len(r.versions)-1].desc), true
+}
+
+// Status returns the current status of service id.
+func (s *Services) Status(id string) (*service.ServiceStatusRT, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r := s.items[id]
+	if r == nil {
+		return nil, false
+	}
+	st := r.statusAt(s.env, s.env.now())
+	return st, st != nil
+}
+
+// Update implements service.Service. Unless ForceCreate is set, updating a
+// service which does not exist fails with *service.ResourceNotFoundT.
+func (s *Services) Update(ctx context.Context, p *service.UpdatePayload) (*service.ServiceStatusRT, string, error) {
+	if p.ID == nil || *p.ID == "" {
+		return nil, "", &service.InvalidParameterValue{Name: "id", Message: "missing service ID"}
+	}
+	if err := checkServiceDescription(p.Services); err != nil {
+		return nil, "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.env.now()
+	r := s.items[*p.ID]
+	if r == nil || !r.deleted.IsZero() {
+		if p.ForceCreate == nil || !*p.ForceCreate {
+			return nil, "", &service.ResourceNotFoundT{ID: *p.ID, Message: "service not found"}
+		}
+		st, err := s.create(ctx, *p.ID, p.Services)
+		if err != nil {
+			return nil, "", err
+		}
+		return st, "default", nil
+	}
+	r.versions = append(r.versions, serviceVersion{from: now, desc: clone(p.Services)})
+	if err := s.env.save(KindService, r.id, r); err != nil {
+		return nil, "", err
+	}
+	return r.statusAt(s.env, now), "default", nil
+}
+
+// Delete implements service.Service.
+func (s *Services) Delete(ctx context.Context, p *service.DeletePayload) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.items[p.ID]
+	if r == nil || !r.deleted.IsZero() {
+		return &service.ResourceNotFoundT{ID: p.ID, Message: "service not found"}
+	}
+	r.deleted = s.env.now()
+	return s.env.save(KindService, r.id, r)
+}
+
+// statusAt returns the status of the service at time at or nil if it did
+// not exist then.
+func (r *serviceRec) statusAt(e *env, at time.Time) *service.ServiceStatusRT {
+	if !alive(r.created, r.deleted, at) {
+		return nil
+	}
+	var d *service.ServiceDescriptionT
+	for _, v := range r.versions {
+		if !v.from.After(at) {
+			d = v.desc
+		}
+	}
+	if d == nil {
+		return nil
+	}
+	st := &service.ServiceStatusRT{
+		ID:          r.id,
+		ProviderRef: d.ProviderRef,
+		Description: ptr(d.Description),
+		Status:      ptr("active"),
+		Metadata:    d.Metadata,
+		Provider:    &service.RefT{ID: ptr(d.ProviderID)},
+		Links:       &service.SelfT{Self: e.link("/1/services/%s", r.id)},
+		Name:        d.Name,
+		Tags:        d.Tags,
+		Parameters:  d.Parameters,
+	}
+	if r.account != "" {
+		st.Account = &service.RefT{ID: ptr(r.account)}
+	}
+	return clone(st)
+}
+
+// checkServiceDescription validates the parts of a service description the
+// generated decoders do not check.
+func checkServiceDescription(d *service.ServiceDescriptionT) error {
+	if d == nil {
+		return &service.InvalidParameterValue{Name: "services", Message: "missing service description"}
+	}
+	seen := map[string]bool{}
+	for _, pd := range d.Parameters {
+		if pd == nil || pd.Name == nil || *pd.Name == "" {
+			return &service.InvalidParameterValue{Name: "parameters", Message: "parameter without name"}
+		}
+		if seen[*pd.Name] {
+			return &service.InvalidParameterValue{Name: "parameters", Value: pd.Name, Message: "duplicate parameter"}
+		}
+		seen[*pd.Name] = true
+	}
+	if err := argo.ValidateService("", d); err != nil {
+		return &service.InvalidParameterValue{Name: "workflow.argo", Message: err.Error()}
+	}
+	if d.Workflow == nil || d.Workflow.Basic == nil {
+		return nil
+	}
+	b := d.Workflow.Basic
+	for name, r := range map[string]*service.ResourceMemoryT{
+		"workflow.basic.memory":            b.Memory,
+		"workflow.basic.cpu":               b.CPU,
+		"workflow.basic.ephemeral-storage": b.EphemeralStorage,
+	} {
+		if r == nil {
+			continue
+		}
+		if err := resource.ValidateRequirements(name, r.Request, r.Limit); err != nil {
+			return &service.InvalidParameterValue{Name: name, Message: err.Error()}
+		}
+	}
+	return nil
+}
+
+// derivedID returns a stable URN of the given kind for parts.
+func derivedID(kind string, parts ...string) string {
+	h := sha1.New()
+	for _, p := range parts {
+		fmt.Fprintf(h, "%d:%s|", len(p), p)
+	}
+	b := h.Sum(nil)[:16]
+	b[6] = b[6]&0x0f | 0x50
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("urn:ivcap:%s:%x-%x-%x-%x-%x", kind, b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func stringOf(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
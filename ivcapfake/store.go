@@ -0,0 +1,360 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcapfake
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+)
+
+// Kinds of records saved to a Store.
+const (
+	KindArtifact = "artifact"
+	KindOrder    = "order"
+	KindOrderLog = "order-log"
+	KindService  = "service"
+	KindMetadata = "metadata"
+)
+
+// Store persists the records of the fakes, see Options.Store. Records are
+// JSON documents identified by their kind and ID.
+type Store interface {
+	// Load calls fn for every record of kind in the order the records were
+	// first saved.
+	Load(kind string, fn func(id string, data []byte) error) error
+	// Save stores record id of kind, replacing an earlier version.
+	Save(kind, id string, data []byte) error
+}
+
+// BlobStore holds the content of artifacts, see Options.Blobs.
+type BlobStore interface {
+	// Append adds data to the end of the content of artifact id.
+	Append(id string, data []byte) error
+	// Open returns a reader for the content of artifact id, which is empty
+	// if nothing was appended yet.
+	Open(id string) (io.ReadSeekCloser, error)
+}
+
+// memBlobs keeps the content of artifacts in memory.
+type memBlobs struct {
+	mu sync.RWMutex
+	m  map[string][]byte
+}
+
+func (b *memBlobs) Append(id string, data []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.m == nil {
+		b.m = map[string][]byte{}
+	}
+	b.m[id] = append(b.m[id], data...)
+	return nil
+}
+
+func (b *memBlobs) Open(id string) (io.ReadSeekCloser, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return nopCloser{bytes.NewReader(b.m[id])}, nil
+}
+
+type nopCloser struct {
+	io.ReadSeeker
+}
+
+func (nopCloser) Close() error { return nil }
+
+// save stores v as record id of kind if a store is configured.
+func (e *env) save(kind, id string, v interface{}) error {
+	if e.opts.Store == nil {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := e.opts.Store.Save(kind, id, b); err != nil {
+		return fmt.Errorf("saving %s %s: %w", kind, id, err)
+	}
+	return nil
+}
+
+// load restores the records of all fakes from the store.
+func (f *Fake) load(s Store) error {
+	err := s.Load(KindArtifact, func(id string, data []byte) error {
+		r := &artifactRec{}
+		if err := json.Unmarshal(data, r); err != nil {
+			return err
+		}
+		f.Artifacts.items[r.id] = r
+		f.Artifacts.order = append(f.Artifacts.order, r.id)
+		return nil
+	})
+	if err == nil {
+		err = s.Load(KindService, func(id string, data []byte) error {
+			r := &serviceRec{}
+			if err := json.Unmarshal(data, r); err != nil {
+				return err
+			}
+			f.Services.items[r.id] = r
+			f.Services.order = append(f.Services.order, r.id)
+			return nil
+		})
+	}
+	if err == nil {
+		err = s.Load(KindOrder, func(id string, data []byte) error {
+			r := &orderRec{}
+			if err := json.Unmarshal(data, r); err != nil {
+				return err
+			}
+			f.Orders.items[r.id] = r
+			f.Orders.order = append(f.Orders.order, r.id)
+			if r.key != "" {
+				f.Orders.keys[r.key] = r.id
+			}
+			return nil
+		})
+	}
+	if err == nil {
+		err = s.Load(KindOrderLog, func(id string, data []byte) error {
+			var l logJSON
+			if err := json.Unmarshal(data, &l); err != nil {
+				return err
+			}
+			if r := f.Orders.items[l.Order]; r != nil {
+				r.logs = append(r.logs, logRec{l.At, l.Container, l.Text})
+			}
+			return nil
+		})
+	}
+	if err == nil {
+		err = s.Load(KindMetadata, func(id string, data []byte) error {
+			r := &metadataRec{}
+			if err := json.Unmarshal(data, r); err != nil {
+				return err
+			}
+			f.Metadata.items[r.id] = r
+			f.Metadata.order = append(f.Metadata.order, r.id)
+			if r.key != "" {
+				f.Metadata.keys[r.key] = r.id
+			}
+			return nil
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("loading records: %w", err)
+	}
+	return nil
+}
+
+// The JSON documents of the records. They mirror the record types, whose
+// fields are unexported.
+
+type artifactJSON struct {
+	ID       string    `json:"id"`
+	Name     *string   `json:"name,omitempty"`
+	MimeType *string   `json:"mime-type,omitempty"`
+	Policy   *string   `json:"policy,omitempty"`
+	Account  string    `json:"account,omitempty"`
+	Size     int64     `json:"size"`
+	Received int64     `json:"received"`
+	Etag     string    `json:"etag,omitempty"`
+	Created  time.Time `json:"created"`
+	Modified time.Time `json:"modified"`
+}
+
+func (r *artifactRec) MarshalJSON() ([]byte, error) {
+	return json.Marshal(artifactJSON{
+		ID:       r.id,
+		Name:     r.name,
+		MimeType: r.mimeType,
+		Policy:   r.policy,
+		Account:  r.account,
+		Size:     r.size,
+		Received: r.received,
+		Etag:     r.etag,
+		Created:  r.created,
+		Modified: r.modified,
+	})
+}
+
+func (r *artifactRec) UnmarshalJSON(b []byte) error {
+	var j artifactJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*r = artifactRec{
+		id:       j.ID,
+		name:     j.Name,
+		mimeType: j.MimeType,
+		policy:   j.Policy,
+		account:  j.Account,
+		size:     j.Size,
+		received: j.Received,
+		etag:     j.Etag,
+		created:  j.Created,
+		modified: j.Modified,
+	}
+	return nil
+}
+
+type serviceJSON struct {
+	ID       string               `json:"id"`
+	Account  string               `json:"account,omitempty"`
+	Versions []serviceVersionJSON `json:"versions"`
+	Created  time.Time            `json:"created"`
+	Deleted  time.Time            `json:"deleted"`
+}
+
+type serviceVersionJSON struct {
+	From time.Time                    `json:"from"`
+	Desc *service.ServiceDescriptionT `json:"desc"`
+}
+
+func (r *serviceRec) MarshalJSON() ([]byte, error) {
+	j := serviceJSON{ID: r.id, Account: r.account, Created: r.created, Deleted: r.deleted}
+	for _, v := range r.versions {
+		j.Versions = append(j.Versions, serviceVersionJSON{v.from, v.desc})
+	}
+	return json.Marshal(j)
+}
+
+func (r *serviceRec) UnmarshalJSON(b []byte) error {
+	var j serviceJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*r = serviceRec{id: j.ID, account: j.Account, created: j.Created, deleted: j.Deleted}
+	for _, v := range j.Versions {
+		r.versions = append(r.versions, serviceVersion{v.From, v.Desc})
+	}
+	return nil
+}
+
+type orderJSON struct {
+	ID        string                             `json:"id"`
+	Request   *order.OrderRequestT               `json:"request"`
+	Account   string                             `json:"account,omitempty"`
+	Key       string                             `json:"key,omitempty"`
+	OrderedAt time.Time                          `json:"ordered-at"`
+	Changes   []statusChangeJSON                 `json:"changes"`
+	Products  []productJSON                      `json:"products,omitempty"`
+	Top       order.OrderTopResultItemCollection `json:"top,omitempty"`
+}
+
+type statusChangeJSON struct {
+	At     time.Time `json:"at"`
+	Status string    `json:"status"`
+}
+
+type productJSON struct {
+	At      time.Time       `json:"at"`
+	Product *order.ProductT `json:"product"`
+}
+
+// logJSON is a log line of an order, which is saved as a record of its own
+// so that logging does not rewrite the order.
+type logJSON struct {
+	Order     string    `json:"order"`
+	At        time.Time `json:"at"`
+	Container string    `json:"container"`
+	Text      string    `json:"text"`
+}
+
+func (r *orderRec) MarshalJSON() ([]byte, error) {
+	j := orderJSON{ID: r.id, Request: r.req, Account: r.account, Key: r.key, OrderedAt: r.orderedAt, Top: r.top}
+	for _, c := range r.changes {
+		j.Changes = append(j.Changes, statusChangeJSON{c.at, c.status})
+	}
+	for _, p := range r.products {
+		j.Products = append(j.Products, productJSON{p.at, p.product})
+	}
+	return json.Marshal(j)
+}
+
+func (r *orderRec) UnmarshalJSON(b []byte) error {
+	var j orderJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*r = orderRec{id: j.ID, req: j.Request, account: j.Account, key: j.Key, orderedAt: j.OrderedAt, top: j.Top}
+	if r.req == nil {
+		return fmt.Errorf("order %s without request", j.ID)
+	}
+	for _, c := range j.Changes {
+		r.changes = append(r.changes, statusChange{c.At, c.Status})
+	}
+	for _, p := range j.Products {
+		r.products = append(r.products, productRec{p.At, p.Product})
+	}
+	return nil
+}
+
+type metadataJSON struct {
+	ID          string      `json:"id"`
+	Entity      string      `json:"entity"`
+	Schema      string      `json:"schema"`
+	Aspect      interface{} `json:"aspect"`
+	ContentType string      `json:"content-type"`
+	Policy      *string     `json:"policy,omitempty"`
+	Asserter    string      `json:"asserter,omitempty"`
+	Revoker     string      `json:"revoker,omitempty"`
+	Key         string      `json:"key,omitempty"`
+	ValidFrom   time.Time   `json:"valid-from"`
+	ValidTo     time.Time   `json:"valid-to"`
+}
+
+func (r *metadataRec) MarshalJSON() ([]byte, error) {
+	return json.Marshal(metadataJSON{
+		ID:          r.id,
+		Entity:      r.entity,
+		Schema:      r.schema,
+		Aspect:      r.aspect,
+		ContentType: r.contentType,
+		Policy:      r.policy,
+		Asserter:    r.asserter,
+		Revoker:     r.revoker,
+		Key:         r.key,
+		ValidFrom:   r.validFrom,
+		ValidTo:     r.validTo,
+	})
+}
+
+func (r *metadataRec) UnmarshalJSON(b []byte) error {
+	var j metadataJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*r = metadataRec{
+		id:          j.ID,
+		entity:      j.Entity,
+		schema:      j.Schema,
+		aspect:      j.Aspect,
+		contentType: j.ContentType,
+		policy:      j.Policy,
+		asserter:    j.Asserter,
+		revoker:     j.Revoker,
+		key:         j.Key,
+		validFrom:   j.ValidFrom,
+		validTo:     j.ValidTo,
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	artifact "github.com/reinventingscience/ivcap-core-api/gen/artifact"
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+	"github.com/reinventingscience/ivcap-core-api/ivcapfake"
+)
+
+// Executor executes the orders of services with a basic workflow, see
+// Config.Executor.
+type Executor interface {
+	// Execute runs the order of run and returns once it finished. The order
+	// is marked as executing before Execute is called. It succeeds if nil
+	// is returned and fails if the error wraps ErrFailed. Any other error
+	// marks it with an error, as does the cancellation of ctx.
+	Execute(ctx context.Context, run *Run) error
+}
+
+// ErrFailed is wrapped by the errors of Executors when the order itself
+// failed, e.g. its process exited with a non-zero status.
+var ErrFailed = errors.New("order failed")
+
+// Run is an order handed to an Executor.
+type Run struct {
+	// Order is the status of the order when its execution started.
+	Order *order.OrderStatusRT
+	// Service describes the service ordered.
+	Service *service.ServiceDescriptionT
+	// Dir is a directory for the files of the order, which executors create
+	// if they need it.
+	Dir string
+
+	fake *ivcapfake.Fake
+}
+
+// Params returns the parameters of the order by name.
+func (r *Run) Params() map[string]string {
+	params := map[string]string{}
+	for _, p := range r.Order.Parameters {
+		if p != nil && p.Name != nil && p.Value != nil {
+			params[*p.Name] = *p.Value
+		}
+	}
+	return params
+}
+
+// Log adds line to the logs of the order.
+func (r *Run) Log(line string) error {
+	return r.fake.Orders.AppendLog(r.Order.ID, containerName, line)
+}
+
+// AddProduct uploads the content read from body as an artifact of the
+// account of the order and adds it to its products under name.
+func (r *Run) AddProduct(ctx context.Context, name, mimeType string, body io.Reader) error {
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	if a := r.Order.Account; a != nil && a.ID != nil {
+		ctx = ivcapfake.WithAccount(ctx, *a.ID)
+	}
+	p := &artifact.UploadPayload{Name: &name, ContentType: &mimeType}
+	a, err := r.fake.Artifacts.Upload(ctx, p, io.NopCloser(body))
+	if err != nil {
+		return fmt.Errorf("uploading product %s: %w", name, err)
+	}
+	return r.fake.Orders.AddProduct(r.Order.ID, a.ID, name)
+}
+
+// uploadOutputs adds the files below dir to the products of run, named by
+// their path relative to dir.
+func uploadOutputs(ctx context.Context, run *Run, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		mimeType, _, _ := strings.Cut(mime.TypeByExtension(filepath.Ext(path)), ";")
+		return run.AddProduct(ctx, filepath.ToSlash(rel), mimeType, f)
+	})
+}
@@ -0,0 +1,48 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"fmt"
+
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+	"github.com/reinventingscience/ivcap-core-api/resource"
+)
+
+// limits are the resource limits of a process, zero if unlimited.
+type limits struct {
+	memory   int64 // bytes
+	milliCPU int64
+}
+
+// workflowLimits returns the memory and cpu limits of wf.
+func workflowLimits(wf *service.BasicWorkflowOptsT) (limits, error) {
+	var lim limits
+	if wf.Memory != nil && wf.Memory.Limit != nil {
+		q, err := resource.ParseQuantity(*wf.Memory.Limit)
+		if err != nil {
+			return lim, fmt.Errorf("memory limit: %w", err)
+		}
+		lim.memory = q.Value()
+	}
+	if wf.CPU != nil && wf.CPU.Limit != nil {
+		q, err := resource.ParseQuantity(*wf.CPU.Limit)
+		if err != nil {
+			return lim, fmt.Errorf("cpu limit: %w", err)
+		}
+		lim.milliCPU = q.MilliValue()
+	}
+	return lim, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package local
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+// cpuPeriod is the cgroup cpu period in microseconds.
+const cpuPeriod = 100000
+
+// sandbox confines a process to its limits, by a cgroup if a delegated
+// cgroup directory is configured and by rlimits otherwise. The process runs
+// in a process group of its own, which is killed when the order is canceled
+// and once the process exited.
+type sandbox struct {
+	lim    limits
+	cgroup string
+	fd     *os.File
+	pgid   int
+	// notes tell about limits that are not enforced.
+	notes []string
+}
+
+func newSandbox(cgroupDir, name string, lim limits) (*sandbox, error) {
+	s := &sandbox{lim: lim}
+	if lim == (limits{}) {
+		return s, nil
+	}
+	if cgroupDir == "" {
+		if lim.memory > 0 {
+			s.notes = append(s.notes, "memory limit enforced on the address space of processes instead of by a cgroup")
+		}
+		if lim.milliCPU > 0 {
+			s.notes = append(s.notes, "cpu limit not enforced without a cgroup")
+		}
+		return s, nil
+	}
+	s.cgroup = filepath.Join(cgroupDir, name)
+	if err := os.Mkdir(s.cgroup, 0o755); err != nil && !os.IsExist(err) {
+		return nil, fmt.Errorf("creating cgroup: %w", err)
+	}
+	err := s.set("memory.max", lim.memory, func() string { return strconv.FormatInt(lim.memory, 10) })
+	if err == nil {
+		err = s.set("cpu.max", lim.milliCPU, func() string {
+			return fmt.Sprintf("%d %d", max(lim.milliCPU*cpuPeriod/1000, 1000), cpuPeriod)
+		})
+	}
+	if err == nil {
+		s.fd, err = os.Open(s.cgroup)
+	}
+	if err != nil {
+		os.Remove(s.cgroup)
+		return nil, err
+	}
+	return s, nil
+}
+
+// set writes the value returned by v to file of the cgroup if limit is set.
+func (s *sandbox) set(file string, limit int64, v func() string) error {
+	if limit == 0 {
+		return nil
+	}
+	if err := os.WriteFile(filepath.Join(s.cgroup, file), []byte(v()), 0o644); err != nil {
+		return fmt.Errorf("setting cgroup limit: %w", err)
+	}
+	return nil
+}
+
+// prepare makes cmd start in a process group of its own and in the cgroup of
+// the sandbox. Without a cgroup, cmd is run by a shell which sets the
+// address space rlimit before executing the command, so that neither the
+// process nor its children ever run without the memory limit.
+func (s *sandbox) prepare(cmd *exec.Cmd) {
+	if s.fd == nil && s.lim.memory > 0 && cmd.Err == nil {
+		kib := (s.lim.memory + 1023) / 1024
+		script := fmt.Sprintf(`ulimit -v %d && exec "$0" "$@"`, kib)
+		cmd.Args = append([]string{"sh", "-c", script, cmd.Path}, cmd.Args[1:]...)
+		cmd.Path = "/bin/sh"
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+	if s.fd != nil {
+		cmd.SysProcAttr.UseCgroupFD = true
+		cmd.SysProcAttr.CgroupFD = int(s.fd.Fd())
+	}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
+
+// started records the process group of process pid.
+func (s *sandbox) started(pid int) error {
+	s.pgid = pid
+	return nil
+}
+
+// close kills what is left of the process group and removes the cgroup once
+// the process exited.
+func (s *sandbox) close() {
+	if s.pgid > 0 {
+		syscall.Kill(-s.pgid, syscall.SIGKILL)
+	}
+	if s.fd != nil {
+		s.fd.Close()
+		os.Remove(s.cgroup)
+	}
+}
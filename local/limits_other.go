@@ -0,0 +1,41 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !linux
+
+package local
+
+import (
+	"os/exec"
+	"runtime"
+)
+
+// sandbox only notes that limits are not enforced outside of Linux.
+type sandbox struct {
+	notes []string
+}
+
+func newSandbox(_, _ string, lim limits) (*sandbox, error) {
+	s := &sandbox{}
+	if lim != (limits{}) {
+		s.notes = append(s.notes, "resource limits not enforced on "+runtime.GOOS)
+	}
+	return s, nil
+}
+
+func (s *sandbox) prepare(cmd *exec.Cmd) {}
+
+func (s *sandbox) started(pid int) error { return nil }
+
+func (s *sandbox) close() {}
@@ -0,0 +1,337 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	ivcap "github.com/reinventingscience/ivcap-core-api"
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+	"github.com/reinventingscience/ivcap-core-api/ivcapfake"
+)
+
+// containerName is the container the logs of local orders are attributed
+// to.
+const containerName = "main"
+
+// runner executes orders in the background.
+type runner struct {
+	fake        *ivcapfake.Fake
+	dir         string
+	sim         *Simulation
+	simulateAll bool
+	executor    Executor
+	log         *slog.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// start executes order id in the background.
+func (r *runner) start(id string) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.run(id)
+	}()
+}
+
+// stop cancels the running orders and waits for them to finish.
+func (r *runner) stop() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// run executes order id, recording an error in its status and logs if that
+// fails.
+func (r *runner) run(id string) {
+	err := r.execute(r.ctx, id)
+	if err == nil {
+		return
+	}
+	if errors.Is(err, context.Canceled) {
+		err = errors.New("interrupted by server shutdown")
+	}
+	r.log.Warn("order failed", "order", id, "error", err)
+	r.fake.Orders.AppendLog(id, containerName, err.Error())
+	r.fake.Orders.SetStatus(id, ivcap.OrderStatusError)
+}
+
+func (r *runner) execute(ctx context.Context, id string) error {
+	st, ok := r.fake.Orders.Status(id)
+	if !ok {
+		return fmt.Errorf("order %s not found", id)
+	}
+	if err := r.fake.Orders.SetStatus(id, ivcap.OrderStatusScheduled); err != nil {
+		return err
+	}
+	if st.Service == nil || st.Service.ID == nil {
+		return errors.New("order refers to no service")
+	}
+	desc, ok := r.fake.Services.Description(*st.Service.ID)
+	if !ok {
+		return fmt.Errorf("service %s not found", *st.Service.ID)
+	}
+	wf, err := basicWorkflow(desc.Workflow)
+	if err != nil {
+		return err
+	}
+	if r.simulateAll || wf == nil || len(wf.Command) == 0 {
+		r.log.Info("simulating order", "order", id, "service", *st.Service.ID)
+		return r.simulate(ctx, st, r.sim)
+	}
+	r.log.Info("running order", "order", id, "service", *st.Service.ID, "command", wf.Command)
+	return r.runExecutor(ctx, st, desc)
+}
+
+// runExecutor executes order st of the service described by desc with the
+// executor of the runner and records the outcome in its status.
+func (r *runner) runExecutor(ctx context.Context, st *order.OrderStatusRT, desc *service.ServiceDescriptionT) error {
+	if err := r.fake.Orders.SetStatus(st.ID, ivcap.OrderStatusExecuting); err != nil {
+		return err
+	}
+	err := r.executor.Execute(ctx, &Run{
+		Order:   st,
+		Service: desc,
+		Dir:     filepath.Join(r.dir, unsafeChars.ReplaceAllString(st.ID, "_")),
+		fake:    r.fake,
+	})
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if errors.Is(err, ErrFailed) {
+		r.fake.Orders.AppendLog(st.ID, containerName, err.Error())
+		return r.fake.Orders.SetStatus(st.ID, ivcap.OrderStatusFailed)
+	}
+	if err != nil {
+		return err
+	}
+	return r.fake.Orders.SetStatus(st.ID, ivcap.OrderStatusSucceeded)
+}
+
+// basicWorkflow returns the options of a basic workflow, also accepting them
+// in the Opts left for backward compatibility, or nil for other workflows.
+func basicWorkflow(wf *service.WorkflowT) (*service.BasicWorkflowOptsT, error) {
+	if wf == nil || wf.Type == nil || *wf.Type != "basic" {
+		return nil, nil
+	}
+	if wf.Basic != nil || wf.Opts == nil {
+		return wf.Basic, nil
+	}
+	b, err := json.Marshal(wf.Opts)
+	if err != nil {
+		return nil, err
+	}
+	var opts service.BasicWorkflowOptsT
+	if err := json.Unmarshal(b, &opts); err != nil {
+		return nil, fmt.Errorf("invalid basic workflow options: %w", err)
+	}
+	return &opts, nil
+}
+
+// ProcessExecutor is the default Executor. It runs the command of a basic
+// workflow as a local process in the directory of the order, ignoring the
+// image. Of the environment of the server only PATH and HOME are passed on,
+// as it may hold credentials. The process also finds Env and
+//
+//	IVCAP_ORDER_ID      the ID of the order
+//	IVCAP_SERVICE_ID    the ID of the service
+//	IVCAP_OUTPUT_DIR    a directory for the products of the order
+//	IVCAP_PARAM_<NAME>  the value of every parameter, its name in upper case
+//	                    with characters other than letters and digits
+//	                    replaced by "_"
+//
+// Orders whose parameters map to the same variable, such as "a-b" and
+// "a_b", fail with an error.
+//
+// Both stdout and stderr become the logs of the order. Once the process
+// exited, every file in the output directory is uploaded as a product, also
+// if it failed. On Linux, processes it started are killed with it when the
+// order is canceled and once it exited.
+//
+// The memory and cpu limits of the workflow are enforced by a cgroup if
+// CgroupDir is set. Otherwise only memory is limited, by the address space
+// rlimit of the process on Linux.
+type ProcessExecutor struct {
+	// ParamArgs also passes the parameters as "--name=value" arguments
+	// following the command.
+	ParamArgs bool
+	// CgroupDir is a cgroup v2 directory writable by the server, with the
+	// memory and cpu controllers enabled in its cgroup.subtree_control.
+	// Every process runs in a cgroup of its own created below it.
+	CgroupDir string
+	// Env are further variables of the environment of processes, as
+	// "NAME=value".
+	Env []string
+}
+
+// passedEnv are the variables of the environment of the server passed on to
+// processes.
+var passedEnv = []string{"PATH", "HOME"}
+
+var _ Executor = (*ProcessExecutor)(nil)
+
+// Execute implements Executor.
+func (p *ProcessExecutor) Execute(ctx context.Context, run *Run) error {
+	wf, err := basicWorkflow(run.Service.Workflow)
+	if err != nil {
+		return err
+	}
+	if wf == nil || len(wf.Command) == 0 {
+		return errors.New("service has no basic workflow with a command")
+	}
+	lim, err := workflowLimits(wf)
+	if err != nil {
+		return err
+	}
+	out := filepath.Join(run.Dir, "out")
+	if err := os.MkdirAll(out, 0o755); err != nil {
+		return err
+	}
+	args := append([]string{}, wf.Command[1:]...)
+	var env []string
+	for _, k := range passedEnv {
+		if v, ok := os.LookupEnv(k); ok {
+			env = append(env, k+"="+v)
+		}
+	}
+	env = append(env, p.Env...)
+	env = append(env,
+		"IVCAP_ORDER_ID="+run.Order.ID,
+		"IVCAP_SERVICE_ID="+*run.Order.Service.ID,
+		"IVCAP_OUTPUT_DIR="+out,
+	)
+	params := map[string]string{}
+	for _, prm := range run.Order.Parameters {
+		if prm == nil || prm.Name == nil || prm.Value == nil {
+			continue
+		}
+		k := "IVCAP_PARAM_" + envName(*prm.Name)
+		if other, ok := params[k]; ok {
+			return fmt.Errorf("parameters %q and %q are both passed as %s", other, *prm.Name, k)
+		}
+		params[k] = *prm.Name
+		env = append(env, k+"="+*prm.Value)
+		if p.ParamArgs {
+			args = append(args, "--"+*prm.Name+"="+*prm.Value)
+		}
+	}
+	cmd := exec.CommandContext(ctx, wf.Command[0], args...)
+	cmd.Dir = run.Dir
+	cmd.Env = env
+
+	sb, err := newSandbox(p.CgroupDir, filepath.Base(run.Dir), lim)
+	if err != nil {
+		return err
+	}
+	defer sb.close()
+	for _, n := range sb.notes {
+		run.Log(n)
+	}
+	sb.prepare(cmd)
+	stdout, stderr := &logWriter{run: run}, &logWriter{run: run}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	// Children of the process may keep its output open after it exited.
+	cmd.WaitDelay = waitDelay
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	if err := sb.started(cmd.Process.Pid); err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return fmt.Errorf("limiting resources: %w", err)
+	}
+	err = cmd.Wait()
+	stdout.flush()
+	stderr.flush()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if errors.Is(err, exec.ErrWaitDelay) {
+		run.Log(fmt.Sprintf("output still open %s after the process exited, ignoring the rest", waitDelay))
+		err = nil
+	}
+	if err := uploadOutputs(ctx, run, out); err != nil {
+		return err
+	}
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		return fmt.Errorf("%w: %v", ErrFailed, exit)
+	}
+	return err
+}
+
+// waitDelay is how long the output of a process is read after it exited.
+const waitDelay = 5 * time.Second
+
+// maxLogLine is the length at which a line is split in the logs.
+const maxLogLine = 1 << 20
+
+// logWriter adds the lines written to it to the logs of run.
+type logWriter struct {
+	run *Run
+	buf []byte
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.run.Log(strings.TrimSuffix(string(w.buf[:i]), "\r"))
+		w.buf = w.buf[i+1:]
+	}
+	if len(w.buf) >= maxLogLine {
+		w.flush()
+	}
+	return len(p), nil
+}
+
+// flush logs what was written after the last line.
+func (w *logWriter) flush() {
+	if len(w.buf) > 0 {
+		w.run.Log(string(w.buf))
+	}
+	w.buf = nil
+}
+
+// envName turns parameter name into the suffix of an environment variable.
+func envName(name string) string {
+	return strings.Map(func(c rune) rune {
+		switch {
+		case c >= 'a' && c <= 'z':
+			return c - 'a' + 'A'
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+			return c
+		default:
+			return '_'
+		}
+	}, name)
+}
@@ -0,0 +1,237 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	ivcap "github.com/reinventingscience/ivcap-core-api"
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+	"github.com/reinventingscience/ivcap-core-api/ivcapfake"
+)
+
+const testAccount = "urn:ivcap:account:test"
+
+// testServer is a Server in a temporary directory executing basic workflows
+// with an executor under test.
+type testServer struct {
+	*Server
+	t   *testing.T
+	ctx context.Context
+}
+
+func newTestServer(t *testing.T, x Executor) *testServer {
+	t.Helper()
+	srv, err := New(&Config{Dir: t.TempDir(), Executor: x, Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	return &testServer{srv, t, ivcapfake.WithAccount(context.Background(), testAccount)}
+}
+
+// order registers a service running command and orders it with params,
+// name value pairs.
+func (s *testServer) order(wf *service.BasicWorkflowOptsT, params ...string) string {
+	s.t.Helper()
+	typ, name := "basic", "test"
+	desc := &service.ServiceDescriptionT{Name: &name, Workflow: &service.WorkflowT{Type: &typ, Basic: wf}}
+	req := &order.OrderRequestT{}
+	for i := 0; i < len(params); i += 2 {
+		desc.Parameters = append(desc.Parameters, &service.ParameterDefT{Name: &params[i]})
+		req.Parameters = append(req.Parameters, &order.ParameterT{Name: &params[i], Value: &params[i+1]})
+	}
+	svc, _, err := s.Fake.Services.CreateService(s.ctx, &service.CreateServicePayload{Services: desc})
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	req.ServiceID = svc.ID
+	o, _, err := s.Fake.Orders.Create(s.ctx, &order.CreatePayload{Orders: req})
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return o.ID
+}
+
+// wait returns the status of order id once it is terminal.
+func (s *testServer) wait(id string) *order.OrderStatusRT {
+	s.t.Helper()
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		st, _ := s.Fake.Orders.Status(id)
+		if st.Status != nil && ivcap.IsTerminalOrderStatus(*st.Status) {
+			return st
+		}
+	}
+	s.t.Fatalf("order %s did not finish", id)
+	return nil
+}
+
+// logs returns the log lines of order id without their prefix.
+func (s *testServer) logs(id string) []string {
+	s.t.Helper()
+	r, err := s.Fake.Orders.Logs(s.ctx, &order.LogsPayload{DownloadLogRequest: &order.DownloadLogRequestT{OrderID: id}})
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	b, _ := io.ReadAll(r)
+	var lines []string
+	for _, l := range strings.Split(strings.TrimSuffix(string(b), "\n"), "\n") {
+		// "[pod/container] time text"
+		if f := strings.SplitN(l, " ", 3); len(f) == 3 {
+			lines = append(lines, f[2])
+		}
+	}
+	return lines
+}
+
+// products returns the content of the products of st by name.
+func (s *testServer) products(st *order.OrderStatusRT) map[string]string {
+	s.t.Helper()
+	res := map[string]string{}
+	for _, p := range st.Products {
+		b, _, ok := s.Fake.Artifacts.Content(*p.ID)
+		if !ok {
+			s.t.Fatalf("product %s has no content", *p.Name)
+		}
+		res[*p.Name] = string(b)
+	}
+	return res
+}
+
+func shell(script string) *service.BasicWorkflowOptsT {
+	return &service.BasicWorkflowOptsT{Image: "alpine", Command: []string{"sh", "-c", script, "sh"}}
+}
+
+func TestProcessExecutor(t *testing.T) {
+	t.Setenv("IVCAP_TEST_SECRET", "s3cret")
+	s := newTestServer(t, &ProcessExecutor{ParamArgs: true, Env: []string{"EXTRA=1"}})
+	id := s.order(shell(`
+		echo "region=$IVCAP_PARAM_REGION max=$IVCAP_PARAM_MAX_SIZE args=$*"
+		echo "to stderr" >&2
+		echo '{"a": 1}' > "$IVCAP_OUTPUT_DIR/result.json"
+		mkdir "$IVCAP_OUTPUT_DIR/sub"
+		echo x > "$IVCAP_OUTPUT_DIR/sub/b.txt"
+		env | sort > "$IVCAP_OUTPUT_DIR/env"
+		printf 'no newline'
+	`), "region", "Lot 2", "max-size", "10")
+	st := s.wait(id)
+	if *st.Status != ivcap.OrderStatusSucceeded {
+		t.Fatalf("status = %s, logs %q", *st.Status, s.logs(id))
+	}
+
+	logs := s.logs(id)
+	sort.Strings(logs)
+	want := []string{"no newline", "region=Lot 2 max=10 args=--region=Lot 2 --max-size=10", "to stderr"}
+	if strings.Join(logs, "|") != strings.Join(want, "|") {
+		t.Errorf("logs = %q, want %q", logs, want)
+	}
+
+	products := s.products(st)
+	if p := products["result.json"]; p != "{\"a\": 1}\n" {
+		t.Errorf("result.json = %q", p)
+	}
+	if p := products["sub/b.txt"]; p != "x\n" {
+		t.Errorf("sub/b.txt = %q", p)
+	}
+	for _, p := range st.Products {
+		if *p.Name == "result.json" && (p.MimeType == nil || *p.MimeType != "application/json") {
+			t.Errorf("mime type of result.json = %v", p.MimeType)
+		}
+	}
+	env := products["env"]
+	for _, v := range []string{"EXTRA=1", "IVCAP_ORDER_ID=" + id, "IVCAP_SERVICE_ID=" + *st.Service.ID, "IVCAP_PARAM_REGION=Lot 2", "PATH="} {
+		if !strings.Contains(env, v) {
+			t.Errorf("environment misses %s:\n%s", v, env)
+		}
+	}
+	if strings.Contains(env, "s3cret") {
+		t.Errorf("environment of the server leaked:\n%s", env)
+	}
+}
+
+func TestProcessExecutorFailure(t *testing.T) {
+	s := newTestServer(t, &ProcessExecutor{})
+	id := s.order(shell(`echo partial > "$IVCAP_OUTPUT_DIR/partial.txt"; exit 3`))
+	st := s.wait(id)
+	if *st.Status != ivcap.OrderStatusFailed {
+		t.Errorf("status = %s, want failed", *st.Status)
+	}
+	if logs := s.logs(id); len(logs) == 0 || !strings.Contains(logs[len(logs)-1], "exit status 3") {
+		t.Errorf("logs = %q", logs)
+	}
+	if p := s.products(st)["partial.txt"]; p != "partial\n" {
+		t.Errorf("partial.txt = %q", p)
+	}
+}
+
+func TestProcessExecutorParamCollision(t *testing.T) {
+	s := newTestServer(t, &ProcessExecutor{})
+	id := s.order(shell("true"), "a-b", "1", "a_b", "2")
+	if st := s.wait(id); *st.Status != ivcap.OrderStatusError {
+		t.Errorf("status = %s, want error", *st.Status)
+	}
+	if logs := s.logs(id); len(logs) == 0 || !strings.Contains(logs[len(logs)-1], "IVCAP_PARAM_A_B") {
+		t.Errorf("logs = %q", logs)
+	}
+}
+
+func TestProcessExecutorCancel(t *testing.T) {
+	s := newTestServer(t, &ProcessExecutor{})
+	// The child started in the background inherits stdout.
+	id := s.order(shell("sleep 60 & echo started; sleep 60"))
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if logs := s.logs(id); len(logs) > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("order did not start")
+		}
+	}
+	start := time.Now()
+	s.Close()
+	if d := time.Since(start); d > waitDelay {
+		t.Errorf("stopping took %s", d)
+	}
+	st, _ := s.Fake.Orders.Status(id)
+	if *st.Status != ivcap.OrderStatusError {
+		t.Errorf("status = %s, want error", *st.Status)
+	}
+}
+
+func TestProcessExecutorMemoryLimit(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("memory limits are only enforced on Linux")
+	}
+	s := newTestServer(t, &ProcessExecutor{})
+	wf := shell("ulimit -v")
+	limit := "64Mi"
+	wf.Memory = &service.ResourceMemoryT{Limit: &limit}
+	id := s.order(wf)
+	if st := s.wait(id); *st.Status != ivcap.OrderStatusSucceeded {
+		t.Fatalf("status = %s, logs %q", *st.Status, s.logs(id))
+	}
+	// The note about the rlimit comes first.
+	if logs := s.logs(id); len(logs) != 2 || logs[1] != "65536" {
+		t.Errorf("logs = %q, want the address space limit in KiB", logs)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package local serves the IVCAP API on a developer's machine, without Argo,
+// Magda or Minio. It builds on the fakes of package ivcapfake, keeping their
+// records in a SQLite database and the content of artifacts in files, and
+// executes orders itself: services with a basic workflow run their command
+// with an Executor, by default as a local process, all others are simulated.
+//
+//	srv, err := local.New(&local.Config{Dir: ".ivcap-local"})
+//	...
+//	defer srv.Close()
+//	http.ListenAndServe("localhost:8088", srv.Handler())
+//
+// The ivcap-local command wraps this into a standalone server.
+package local
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	ivcap "github.com/reinventingscience/ivcap-core-api"
+	"github.com/reinventingscience/ivcap-core-api/ivcapfake"
+)
+
+// Config configures a Server.
+type Config struct {
+	// Dir holds the database, the content of artifacts and the work
+	// directories of orders. It is created if needed.
+	Dir string
+	// BaseURL is the URL the server is reachable at, used for the returned
+	// links. Defaults to ivcapfake.DefaultBaseURL.
+	BaseURL string
+	// Tokens maps bearer tokens to the scopes they grant, see
+	// ivcapfake.Options.
+	Tokens map[string][]string
+	// Simulation describes what simulated orders do. Defaults to
+	// DefaultSimulation.
+	Simulation *Simulation
+	// SimulateAll simulates all orders, also those of services with a basic
+	// workflow.
+	SimulateAll bool
+	// Executor executes the orders of services with a basic workflow unless
+	// SimulateAll is set. Defaults to a ProcessExecutor.
+	Executor Executor
+	// Logger receives messages about executed orders. Defaults to
+	// slog.Default().
+	Logger *slog.Logger
+}
+
+// Server serves the API from the records in its directory.
+type Server struct {
+	// Fake holds the records served, e.g. to add services at startup.
+	Fake *ivcapfake.Fake
+
+	store  *SQLiteStore
+	runner *runner
+}
+
+// New opens the records in cfg.Dir and returns a server for them. Orders
+// left unfinished by an earlier server are marked as failed with an error.
+func New(cfg *Config) (*Server, error) {
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("no directory configured")
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	blobs, err := NewDirBlobs(filepath.Join(cfg.Dir, "artifacts"))
+	if err != nil {
+		return nil, err
+	}
+	store, err := OpenSQLite(filepath.Join(cfg.Dir, "ivcap.db"))
+	if err != nil {
+		return nil, err
+	}
+	r := &runner{
+		dir:         filepath.Join(cfg.Dir, "orders"),
+		sim:         cfg.Simulation,
+		simulateAll: cfg.SimulateAll,
+		executor:    cfg.Executor,
+		log:         cfg.Logger,
+	}
+	if r.sim == nil {
+		r.sim = DefaultSimulation
+	}
+	if r.executor == nil {
+		r.executor = &ProcessExecutor{}
+	}
+	if r.log == nil {
+		r.log = slog.Default()
+	}
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	r.fake, err = ivcapfake.Open(&ivcapfake.Options{
+		BaseURL:      cfg.BaseURL,
+		Tokens:       cfg.Tokens,
+		Store:        store,
+		Blobs:        blobs,
+		OrderCreated: r.start,
+	})
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+	s := &Server{Fake: r.fake, store: store, runner: r}
+	if err := s.abandonUnfinished(); err != nil {
+		store.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// abandonUnfinished marks the orders that were still executing when an
+// earlier server stopped.
+func (s *Server) abandonUnfinished() error {
+	for _, id := range s.Fake.Orders.IDs() {
+		st, ok := s.Fake.Orders.Status(id)
+		if !ok || st.Status == nil || ivcap.IsTerminalOrderStatus(*st.Status) {
+			continue
+		}
+		if err := s.Fake.Orders.AppendLog(id, containerName, "abandoned by server restart"); err != nil {
+			return err
+		}
+		if err := s.Fake.Orders.SetStatus(id, ivcap.OrderStatusError); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler returns the HTTP handler serving the API.
+func (s *Server) Handler() http.Handler {
+	return s.Fake.Handler()
+}
+
+// Close stops the running orders, marking them with an error, and closes the
+// database.
+func (s *Server) Close() error {
+	s.runner.stop()
+	return s.store.Close()
+}
@@ -0,0 +1,194 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	ivcap "github.com/reinventingscience/ivcap-core-api"
+	metadata "github.com/reinventingscience/ivcap-core-api/gen/metadata"
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+	metadatac "github.com/reinventingscience/ivcap-core-api/http/metadata"
+	orderc "github.com/reinventingscience/ivcap-core-api/http/order"
+	"github.com/reinventingscience/ivcap-core-api/ivcapfake"
+)
+
+// snapshot is what a server returns for the records created by TestReopen.
+type snapshot struct {
+	service  *service.ServiceStatusRT
+	order    *order.OrderStatusRT
+	logs     []string
+	products map[string]string
+	record   *metadata.MetadataRecordRT
+	records  *metadata.ListMetaRT
+}
+
+func (s *testServer) snapshot(svcID, orderID, recordID, at string) *snapshot {
+	s.t.Helper()
+	var (
+		snap snapshot
+		err  error
+	)
+	if snap.service, _, err = s.Fake.Services.Read(s.ctx, &service.ReadPayload{ID: svcID}); err != nil {
+		s.t.Fatal(err)
+	}
+	if snap.order, _, err = s.Fake.Orders.Read(s.ctx, &order.ReadPayload{ID: orderID}); err != nil {
+		s.t.Fatal(err)
+	}
+	snap.logs = s.logs(orderID)
+	snap.products = s.products(snap.order)
+	if snap.record, err = s.Fake.Metadata.Read(s.ctx, &metadata.ReadPayload{ID: recordID}); err != nil {
+		s.t.Fatal(err)
+	}
+	if snap.records, err = s.Fake.Metadata.List(s.ctx, &metadata.ListPayload{EntityID: &orderID, AtTime: &at, Limit: 10}); err != nil {
+		s.t.Fatal(err)
+	}
+	return &snap
+}
+
+func TestReopen(t *testing.T) {
+	cfg := &Config{
+		Dir: t.TempDir(),
+		Simulation: &Simulation{
+			Logs:     []string{"size ${size}"},
+			Products: []SimulatedProduct{{Name: "out.txt", MimeType: "text/plain", Content: "order ${IVCAP_ORDER_ID}"}},
+		},
+		SimulateAll: true,
+		Logger:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	ctx := ivcapfake.WithAccount(context.Background(), testAccount)
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &testServer{srv, t, ctx}
+	typ, name, size := "basic", "test", "size"
+	svc, _, err := s.Fake.Services.CreateService(ctx, &service.CreateServicePayload{Services: &service.ServiceDescriptionT{
+		Name:       &name,
+		Workflow:   &service.WorkflowT{Type: &typ, Basic: &service.BasicWorkflowOptsT{Image: "alpine"}},
+		Parameters: []*service.ParameterDefT{{Name: &size}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &order.CreatePayload{Orders: &order.OrderRequestT{ServiceID: svc.ID, Name: &name, Parameters: []*order.ParameterT{{Name: &size, Value: &name}}}}
+	o, _, err := s.Fake.Orders.Create(orderc.WithIdempotencyKey(ctx, "order-1"), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := s.wait(o.ID); *st.Status != ivcap.OrderStatusSucceeded {
+		t.Fatalf("status = %s", *st.Status)
+	}
+	add := &metadata.AddPayload{EntityID: o.ID, Schema: "urn:example:schema:test.1", Aspect: map[string]interface{}{"size": 1.0}}
+	rec, err := s.Fake.Metadata.Add(metadatac.WithIdempotencyKey(ctx, "record-1"), add)
+	if err != nil {
+		t.Fatal(err)
+	}
+	revoked, err := s.Fake.Metadata.Add(ctx, &metadata.AddPayload{EntityID: o.ID, Schema: "urn:example:schema:test.1", Aspect: map[string]interface{}{"size": 2.0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Fake.Metadata.Revoke(ctx, &metadata.RevokePayload{ID: &revoked.RecordID}); err != nil {
+		t.Fatal(err)
+	}
+	at := time.Now().UTC().Format(time.RFC3339Nano)
+	want := s.snapshot(svc.ID, o.ID, rec.RecordID, at)
+	if err := srv.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	srv, err = New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	s.Server = srv
+	if got := s.snapshot(svc.ID, o.ID, rec.RecordID, at); !reflect.DeepEqual(got, want) {
+		t.Errorf("after reopening\n got %+v\nwant %+v", got, want)
+	}
+	if len(want.records.Records) != 1 {
+		t.Errorf("listed %d records, want the one not revoked", len(want.records.Records))
+	}
+
+	// Idempotency keys survive as well.
+	o2, _, err := s.Fake.Orders.Create(orderc.WithIdempotencyKey(ctx, "order-1"), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o2.ID != o.ID {
+		t.Errorf("repeated create returned order %s, want %s", o2.ID, o.ID)
+	}
+	rec2, err := s.Fake.Metadata.Add(metadatac.WithIdempotencyKey(ctx, "record-1"), add)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec2.RecordID != rec.RecordID {
+		t.Errorf("repeated add returned record %s, want %s", rec2.RecordID, rec.RecordID)
+	}
+	if ids := s.Fake.Orders.IDs(); len(ids) != 1 {
+		t.Errorf("orders = %v, want only %s", ids, o.ID)
+	}
+}
+
+func TestReopenAbandoned(t *testing.T) {
+	dir := t.TempDir()
+	ctx := ivcapfake.WithAccount(context.Background(), testAccount)
+
+	// Create an order with the fakes alone, so nothing executes it, as if
+	// the server had stopped while it was pending.
+	store, err := OpenSQLite(filepath.Join(dir, "ivcap.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := ivcapfake.Open(&ivcapfake.Options{Store: store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := "basic"
+	svc, _, err := f.Services.CreateService(ctx, &service.CreateServicePayload{Services: &service.ServiceDescriptionT{
+		Workflow: &service.WorkflowT{Type: &typ, Basic: &service.BasicWorkflowOptsT{Image: "alpine"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, _, err := f.Orders.Create(ctx, &order.CreatePayload{Orders: &order.OrderRequestT{ServiceID: svc.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	srv, err := New(&Config{Dir: dir, Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	s := &testServer{srv, t, ctx}
+	st, _ := s.Fake.Orders.Status(o.ID)
+	if st == nil || *st.Status != ivcap.OrderStatusError {
+		t.Fatalf("status = %+v, want %s", st, ivcap.OrderStatusError)
+	}
+	if logs := s.logs(o.ID); !reflect.DeepEqual(logs, []string{"abandoned by server restart"}) {
+		t.Errorf("logs = %q", logs)
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	ivcap "github.com/reinventingscience/ivcap-core-api"
+	artifact "github.com/reinventingscience/ivcap-core-api/gen/artifact"
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+	"github.com/reinventingscience/ivcap-core-api/ivcapfake"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Simulation describes what simulated orders do. In Logs and the Content of
+// products, "${name}" is replaced by the value of order parameter name and
+// "${IVCAP_ORDER_ID}" by the ID of the order.
+//
+//	duration: 5s
+//	logs:
+//	  - "processing ${region}"
+//	products:
+//	  - name: result.json
+//	    mime-type: application/json
+//	    content: '{"region": "${region}"}'
+//	  - name: map.png
+//	    file: testdata/map.png
+//	  - name: noise.bin
+//	    size: 1048576
+type Simulation struct {
+	// Duration is the time an order takes to execute.
+	Duration time.Duration `yaml:"duration"`
+	// Fail makes orders fail instead of succeed.
+	Fail bool `yaml:"fail"`
+	// Logs are the lines logged by an order.
+	Logs []string `yaml:"logs"`
+	// Products are uploaded as the products of every order.
+	Products []SimulatedProduct `yaml:"products"`
+}
+
+// SimulatedProduct is a product of a simulated order. Its content is taken
+// from Content, File or, if neither is set, Size random bytes.
+type SimulatedProduct struct {
+	Name     string `yaml:"name"`
+	MimeType string `yaml:"mime-type"`
+	Content  string `yaml:"content"`
+	File     string `yaml:"file"`
+	Size     int64  `yaml:"size"`
+}
+
+// DefaultSimulation is used for simulated orders unless a simulation is
+// configured. Orders succeed after a second without producing anything.
+var DefaultSimulation = &Simulation{Duration: time.Second}
+
+// LoadSimulation reads a simulation from a YAML or JSON file. Relative
+// product files are resolved against the directory of the file.
+func LoadSimulation(path string) (*Simulation, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Simulation
+	if err := yaml.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i, p := range s.Products {
+		if p.Name == "" {
+			return nil, fmt.Errorf("%s: product %d has no name", path, i+1)
+		}
+		if p.File != "" && !filepath.IsAbs(p.File) {
+			s.Products[i].File = filepath.Join(filepath.Dir(path), p.File)
+		}
+	}
+	return &s, nil
+}
+
+// simulate executes order st according to sim.
+func (r *runner) simulate(ctx context.Context, st *order.OrderStatusRT, sim *Simulation) error {
+	vars := orderVars(st)
+	expand := func(s string) string {
+		return os.Expand(s, func(k string) string { return vars[k] })
+	}
+	if err := r.fake.Orders.SetStatus(st.ID, ivcap.OrderStatusExecuting); err != nil {
+		return err
+	}
+	for _, l := range sim.Logs {
+		r.fake.Orders.AppendLog(st.ID, containerName, expand(l))
+	}
+	if err := sleep(ctx, sim.Duration); err != nil {
+		return err
+	}
+	for _, p := range sim.Products {
+		var (
+			body io.ReadCloser
+			err  error
+		)
+		switch {
+		case p.Content != "":
+			body = io.NopCloser(strings.NewReader(expand(p.Content)))
+		case p.File != "":
+			body, err = os.Open(p.File)
+		default:
+			body = io.NopCloser(io.LimitReader(rand.Reader, p.Size))
+		}
+		if err != nil {
+			return err
+		}
+		if err := r.addProduct(ctx, st, p.Name, p.MimeType, body); err != nil {
+			return err
+		}
+	}
+	status := ivcap.OrderStatusSucceeded
+	if sim.Fail {
+		status = ivcap.OrderStatusFailed
+	}
+	return r.fake.Orders.SetStatus(st.ID, status)
+}
+
+// addProduct uploads the content read from body as product name of order
+// st. body is closed.
+func (r *runner) addProduct(ctx context.Context, st *order.OrderStatusRT, name, mimeType string, body io.ReadCloser) error {
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	if st.Account != nil && st.Account.ID != nil {
+		ctx = ivcapfake.WithAccount(ctx, *st.Account.ID)
+	}
+	a, err := r.fake.Artifacts.Upload(ctx, &artifact.UploadPayload{Name: &name, ContentType: &mimeType}, body)
+	if err != nil {
+		return fmt.Errorf("uploading product %s: %w", name, err)
+	}
+	return r.fake.Orders.AddProduct(st.ID, a.ID, name)
+}
+
+// orderVars returns the parameters of order st by name together with its
+// ID as IVCAP_ORDER_ID.
+func orderVars(st *order.OrderStatusRT) map[string]string {
+	vars := map[string]string{"IVCAP_ORDER_ID": st.ID}
+	for _, p := range st.Parameters {
+		if p != nil && p.Name != nil && p.Value != nil {
+			vars[*p.Name] = *p.Value
+		}
+	}
+	return vars
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
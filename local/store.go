@@ -0,0 +1,152 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/reinventingscience/ivcap-core-api/ivcapfake"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteStore is an ivcapfake.Store keeping the records in a SQLite
+// database.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+var _ ivcapfake.Store = (*SQLiteStore)(nil)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS records (
+	seq  INTEGER PRIMARY KEY AUTOINCREMENT,
+	kind TEXT NOT NULL,
+	id   TEXT NOT NULL,
+	data BLOB NOT NULL,
+	UNIQUE (kind, id)
+)`
+
+// OpenSQLite opens the database at path, creating it if needed.
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+	// Records are saved while the fakes hold their locks, a single
+	// connection keeps writes in order.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// Load implements ivcapfake.Store.
+func (s *SQLiteStore) Load(kind string, fn func(id string, data []byte) error) error {
+	rows, err := s.db.Query(`SELECT id, data FROM records WHERE kind = ? ORDER BY seq`, kind)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			id   string
+			data []byte
+		)
+		if err := rows.Scan(&id, &data); err != nil {
+			return err
+		}
+		if err := fn(id, data); err != nil {
+			return fmt.Errorf("%s %s: %w", kind, id, err)
+		}
+	}
+	return rows.Err()
+}
+
+// Save implements ivcapfake.Store.
+func (s *SQLiteStore) Save(kind, id string, data []byte) error {
+	_, err := s.db.Exec(`INSERT INTO records (kind, id, data) VALUES (?, ?, ?)
+		ON CONFLICT (kind, id) DO UPDATE SET data = excluded.data`, kind, id, data)
+	return err
+}
+
+// Close closes the database.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// DirBlobs is an ivcapfake.BlobStore keeping the content of every artifact
+// in a file of its own.
+type DirBlobs struct {
+	dir string
+}
+
+var _ ivcapfake.BlobStore = (*DirBlobs)(nil)
+
+// NewDirBlobs returns a blob store keeping content in dir, which is created
+// if needed.
+func NewDirBlobs(dir string) (*DirBlobs, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &DirBlobs{dir: dir}, nil
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// path returns the file holding the content of artifact id.
+func (b *DirBlobs) path(id string) string {
+	return filepath.Join(b.dir, unsafeChars.ReplaceAllString(id, "_"))
+}
+
+// Append implements ivcapfake.BlobStore.
+func (b *DirBlobs) Append(id string, data []byte) error {
+	f, err := os.OpenFile(b.path(id), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Open implements ivcapfake.BlobStore.
+func (b *DirBlobs) Open(id string) (io.ReadSeekCloser, error) {
+	f, err := os.Open(b.path(id))
+	if os.IsNotExist(err) {
+		return emptyContent{bytes.NewReader(nil)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// emptyContent is the content of an artifact nothing was uploaded to yet.
+type emptyContent struct {
+	*bytes.Reader
+}
+
+func (emptyContent) Close() error { return nil }
@@ -0,0 +1,287 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	goahttp "goa.design/goa/v3/http"
+)
+
+// Redacted replaces secrets in logged requests and responses.
+const Redacted = "[REDACTED]"
+
+// DefaultRedactedFields are the JSON fields and query parameters whose
+// values are always redacted by NewLoggingDoer.
+var DefaultRedactedFields = []string{
+	"jwt", "token", "access_token", "refresh_token", "id_token",
+	"password", "client_secret", "secret", "policy", "policy-id",
+}
+
+// LogOptions controls what NewLoggingDoer logs.
+type LogOptions struct {
+	// Logger receives the log records. Defaults to slog.Default().
+	Logger *slog.Logger
+	// Level is the level of records of successful requests, typically
+	// slog.LevelDebug. Requests failing with a network error or a 5xx status
+	// are logged at slog.LevelWarn if Level is lower.
+	Level slog.Level
+	// Headers adds the request and response headers to the records.
+	Headers bool
+	// Bodies adds textual request and response bodies to the records.
+	Bodies bool
+	// MaxBodySize is the number of bytes of a body logged before it is
+	// truncated. Defaults to 2KiB.
+	MaxBodySize int
+	// RedactFields lists JSON fields and query parameters whose values are
+	// redacted in addition to DefaultRedactedFields. Names are matched case
+	// insensitively.
+	RedactFields []string
+}
+
+// loggingDoer logs every request sent through it.
+type loggingDoer struct {
+	doer   goahttp.Doer
+	opts   LogOptions
+	fields map[string]bool
+	field  *regexp.Regexp
+}
+
+// NewLoggingDoer returns a Doer sending requests through doer and logging
+// their method, path, status and duration and, if asked for in opts, their
+// headers and truncated bodies. Bearer tokens, JWTs, X-Policy headers and
+// the values of the JSON fields listed in DefaultRedactedFields and
+// opts.RedactFields are always redacted. opts may be nil. If doer is nil,
+// http.DefaultClient is used.
+func NewLoggingDoer(doer goahttp.Doer, opts *LogOptions) goahttp.Doer {
+	if doer == nil {
+		doer = http.DefaultClient
+	}
+	var o LogOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Logger == nil {
+		o.Logger = slog.Default()
+	}
+	if o.MaxBodySize <= 0 {
+		o.MaxBodySize = 2 << 10
+	}
+	d := &loggingDoer{doer: doer, opts: o, fields: map[string]bool{}}
+	var names []string
+	for _, f := range append(DefaultRedactedFields[:len(DefaultRedactedFields):len(DefaultRedactedFields)], o.RedactFields...) {
+		f = strings.ToLower(f)
+		if !d.fields[f] {
+			d.fields[f] = true
+			names = append(names, regexp.QuoteMeta(f))
+		}
+	}
+	// Matches `"field": value` where value is a string or a scalar. Values of
+	// nested objects and arrays are not matched.
+	d.field = regexp.MustCompile(`(?i)("(?:` + strings.Join(names, "|") + `)"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^\s,}\]"]+)`)
+	return d
+}
+
+// Do sends req and logs the outcome.
+func (d *loggingDoer) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("path", req.URL.Path),
+	}
+	if req.URL.RawQuery != "" {
+		attrs = append(attrs, slog.String("query", d.redactQuery(req.URL.Query())))
+	}
+	if d.opts.Headers {
+		attrs = append(attrs, slog.Any("request_headers", d.redactHeader(req.Header)))
+	}
+	var bodyAttrs []slog.Attr
+	if ct := req.Header.Get("Content-Type"); d.opts.Bodies && req.Body != nil && req.Body != http.NoBody && isText(ct) {
+		// Take the start of the body before sending it, as the transport may
+		// still be reading the body when Do returns. The rest is streamed.
+		b, n, body, perr := peek(req.Body, d.opts.MaxBodySize, req.ContentLength)
+		req = req.Clone(ctx)
+		req.Body = body
+		bodyAttrs = append(bodyAttrs, slog.String("request_body", d.redactBody(ct, b, n)))
+		if perr != nil {
+			bodyAttrs = append(bodyAttrs, slog.String("request_body_error", perr.Error()))
+		}
+	}
+
+	start := time.Now()
+	resp, err := d.doer.Do(req)
+	attrs = append(attrs, slog.Duration("duration", time.Since(start)))
+	attrs = append(attrs, bodyAttrs...)
+	level := d.opts.Level
+	if err != nil {
+		attrs = append(attrs, slog.String("error", redactJWTs(err.Error())))
+		d.log(ctx, max(level, slog.LevelWarn), attrs)
+		return resp, err
+	}
+	attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	if d.opts.Headers {
+		attrs = append(attrs, slog.Any("response_headers", d.redactHeader(resp.Header)))
+	}
+	if d.opts.Bodies && isJSON(resp.Header.Get("Content-Type")) {
+		body, n, rerr := peekBody(resp, d.opts.MaxBodySize)
+		if rerr != nil {
+			attrs = append(attrs, slog.String("error", rerr.Error()))
+		}
+		attrs = append(attrs, slog.String("response_body", d.redactBody("application/json", body, n)))
+	}
+	if resp.StatusCode >= 500 {
+		level = max(level, slog.LevelWarn)
+	}
+	d.log(ctx, level, attrs)
+	return resp, nil
+}
+
+func (d *loggingDoer) log(ctx context.Context, level slog.Level, attrs []slog.Attr) {
+	d.opts.Logger.LogAttrs(ctx, level, "ivcap http request", attrs...)
+}
+
+// redactHeader returns a copy of h with credentials redacted.
+func (d *loggingDoer) redactHeader(h http.Header) http.Header {
+	r := make(http.Header, len(h))
+	for k, vs := range h {
+		ck := http.CanonicalHeaderKey(k)
+		for _, v := range vs {
+			switch ck {
+			case "Authorization", "Proxy-Authorization":
+				if scheme, _, ok := strings.Cut(v, " "); ok {
+					v = scheme + " " + Redacted
+				} else {
+					v = Redacted
+				}
+			case "X-Policy", "Cookie", "Set-Cookie":
+				v = Redacted
+			default:
+				v = redactJWTs(v)
+			}
+			r[ck] = append(r[ck], v)
+		}
+	}
+	return r
+}
+
+// redactQuery returns q encoded with the values of redacted fields and JWTs
+// replaced.
+func (d *loggingDoer) redactQuery(q url.Values) string {
+	for k, vs := range q {
+		for i, v := range vs {
+			if d.fields[strings.ToLower(k)] {
+				vs[i] = Redacted
+			} else {
+				vs[i] = redactJWTs(v)
+			}
+		}
+	}
+	s, _ := url.QueryUnescape(q.Encode())
+	return s
+}
+
+// redactBody returns the first bytes b of a body of content type ct and n
+// bytes with secrets redacted and a note if it was truncated. n is -1 if the
+// size is unknown.
+func (d *loggingDoer) redactBody(ct string, b []byte, n int64) string {
+	var s string
+	if mt, _, _ := mime.ParseMediaType(ct); mt == "application/x-www-form-urlencoded" && n == int64(len(b)) {
+		q, _ := url.ParseQuery(string(b))
+		s = d.redactQuery(q)
+	} else {
+		s = redactJWTs(d.field.ReplaceAllString(string(b), `${1}"`+Redacted+`"`))
+	}
+	switch {
+	case n < 0:
+		s += "... (truncated)"
+	case n > int64(len(b)):
+		s += fmt.Sprintf("... (%d bytes)", n)
+	}
+	return s
+}
+
+var jwtPattern = regexp.MustCompile(`eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+(?:\.[A-Za-z0-9_-]*)?`)
+
+// redactJWTs replaces everything in s which looks like a JWT.
+func redactJWTs(s string) string {
+	return jwtPattern.ReplaceAllString(s, Redacted)
+}
+
+// peekBody returns the first max bytes of the response body and its size,
+// -1 if it is larger but of unknown size, and restores the body for the
+// caller.
+func peekBody(resp *http.Response, max int) ([]byte, int64, error) {
+	b, n, body, err := peek(resp.Body, max, resp.ContentLength)
+	resp.Body = body
+	return b, n, err
+}
+
+// peek reads the first max bytes of body, whose size is size or -1 if
+// unknown. It returns them with the size of the body, -1 if it is larger but
+// of unknown size, and a body reading all of it again, which ends with the
+// read error, if any.
+func peek(body io.ReadCloser, max int, size int64) ([]byte, int64, io.ReadCloser, error) {
+	b, err := io.ReadAll(io.LimitReader(body, int64(max)+1))
+	var rest io.Reader = body
+	if err != nil {
+		// Hand the error to the reader once it gets there
+		rest = &errReader{err}
+	}
+	body = &readCloser{io.MultiReader(bytes.NewReader(b), rest), body}
+	n := int64(len(b))
+	if n > int64(max) {
+		b, n = b[:max], size
+		if n <= int64(max) {
+			// Unknown, e.g. a request without Content-Length
+			n = -1
+		}
+	}
+	return b, n, body, err
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+type errReader struct{ err error }
+
+func (r *errReader) Read([]byte) (int, error) { return 0, r.err }
+
+// isText returns true if bodies of content type ct can be logged.
+func isText(ct string) bool {
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(mt, "text/") || isJSON(ct) ||
+		mt == "application/x-www-form-urlencoded" || strings.HasSuffix(mt, "+xml") || mt == "application/xml"
+}
+
+// isJSON returns true for JSON content types.
+func isJSON(ct string) bool {
+	mt, _, err := mime.ParseMediaType(ct)
+	return err == nil && (mt == "application/json" || strings.HasSuffix(mt, "+json"))
+}
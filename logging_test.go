@@ -0,0 +1,179 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// jwt looks like a JWT to redactJWTs.
+const jwt = "eyJhbGciOiJub25lIn0.eyJzdWIiOiIxIn0.sig"
+
+// logRecord sends req through a logging doer with opts to a server
+// answering with status, content type ct and body, and returns the logged
+// record and the request body the server received.
+func logRecord(t *testing.T, opts LogOptions, req *http.Request, status int, ct, body string) (map[string]interface{}, string) {
+	t.Helper()
+	var received string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		received = string(b)
+		w.Header().Set("Content-Type", ct)
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+	u := srv.URL + req.URL.RequestURI()
+	req.URL, _ = req.URL.Parse(u)
+	req.Host = ""
+
+	var buf bytes.Buffer
+	opts.Logger = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	resp, err := NewLoggingDoer(srv.Client(), &opts).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || string(got) != body {
+		t.Errorf("response body = %q, %v, want %q", got, err, body)
+	}
+	var rec map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("%v: %s", err, buf.Bytes())
+	}
+	return rec, received
+}
+
+func TestLoggingRedactsHeaders(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/1/orders?filter=x&access_token="+jwt+"&page="+jwt, nil)
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("X-Policy", "urn:ivcap:policy:secret")
+	req.Header.Set("X-Trace", "token "+jwt)
+	req.Header.Set("Accept", "application/json")
+	rec, _ := logRecord(t, LogOptions{Headers: true}, req, http.StatusOK, "application/json", `{}`)
+
+	if s := rec["status"]; s != float64(200) {
+		t.Errorf("status = %v", s)
+	}
+	if q := rec["query"]; q != "access_token="+Redacted+"&filter=x&page="+Redacted {
+		t.Errorf("query = %v", q)
+	}
+	h := rec["request_headers"].(map[string]interface{})
+	for k, want := range map[string]string{
+		"Authorization": "Bearer " + Redacted,
+		"X-Policy":      Redacted,
+		"X-Trace":       "token " + Redacted,
+		"Accept":        "application/json",
+	} {
+		if v := h[k].([]interface{})[0]; v != want {
+			t.Errorf("request header %s = %v, want %q", k, v, want)
+		}
+	}
+	rh := rec["response_headers"].(map[string]interface{})
+	if v := rh["Set-Cookie"].([]interface{})[0]; v != Redacted {
+		t.Errorf("response header Set-Cookie = %v", v)
+	}
+	if _, ok := rec["request_body"]; ok {
+		t.Error("body logged without Bodies")
+	}
+}
+
+func TestLoggingRedactsBodies(t *testing.T) {
+	sent := `{"name": "x", "policy-id": "urn:ivcap:policy:1", "nested": {"Password": "p\"w"}, "n": 1, "secret": 42}`
+	req, _ := http.NewRequest("POST", "/1/orders", strings.NewReader(sent))
+	req.Header.Set("Content-Type", "application/json")
+	opts := LogOptions{Bodies: true, RedactFields: []string{"name"}}
+	rec, received := logRecord(t, opts, req, http.StatusCreated, "application/json",
+		`{"id": "1", "token": "`+jwt+`", "other": "`+jwt+`"}`)
+
+	if received != sent {
+		t.Errorf("server received %q, want %q", received, sent)
+	}
+	want := `{"name": "[REDACTED]", "policy-id": "[REDACTED]", "nested": {"Password": "[REDACTED]"}, "n": 1, "secret": "[REDACTED]"}`
+	if b := rec["request_body"]; b != want {
+		t.Errorf("request_body = %v\nwant %s", b, want)
+	}
+	want = `{"id": "1", "token": "[REDACTED]", "other": "[REDACTED]"}`
+	if b := rec["response_body"]; b != want {
+		t.Errorf("response_body = %v\nwant %s", b, want)
+	}
+}
+
+func TestLoggingRedactsForms(t *testing.T) {
+	sent := "grant_type=refresh_token&refresh_token=abc&client_id=cli"
+	req, _ := http.NewRequest("POST", "/oauth/token", strings.NewReader(sent))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec, received := logRecord(t, LogOptions{Bodies: true}, req, http.StatusOK, "application/json", `{"access_token": "abc"}`)
+	if received != sent {
+		t.Errorf("server received %q", received)
+	}
+	if b := rec["request_body"]; b != "client_id=cli&grant_type=refresh_token&refresh_token="+Redacted {
+		t.Errorf("request_body = %v", b)
+	}
+	if b := rec["response_body"]; b != `{"access_token": "[REDACTED]"}` {
+		t.Errorf("response_body = %v", b)
+	}
+}
+
+func TestLoggingTruncatesBodies(t *testing.T) {
+	sent := strings.Repeat("a", 100)
+	for _, tc := range []struct {
+		name   string
+		body   io.Reader
+		wantRq string
+	}{
+		// The size of a bytes.Reader is known up front.
+		{"known size", strings.NewReader(sent), strings.Repeat("a", 10) + "... (100 bytes)"},
+		{"unknown size", io.MultiReader(strings.NewReader(sent)), strings.Repeat("a", 10) + "... (truncated)"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest("PUT", "/1/artifacts", tc.body)
+			req.Header.Set("Content-Type", "text/plain")
+			rec, received := logRecord(t, LogOptions{Bodies: true, MaxBodySize: 10}, req, http.StatusOK,
+				"application/json", `["`+strings.Repeat("b", 40)+`"]`)
+			if received != sent {
+				t.Errorf("server received %d bytes, want %d", len(received), len(sent))
+			}
+			if b := rec["request_body"]; b != tc.wantRq {
+				t.Errorf("request_body = %v, want %q", b, tc.wantRq)
+			}
+			if b := rec["response_body"]; b != `["bbbbbbbb... (44 bytes)` {
+				t.Errorf("response_body = %v", b)
+			}
+		})
+	}
+}
+
+func TestLoggingLevels(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/1/orders", nil)
+	rec, _ := logRecord(t, LogOptions{Level: slog.LevelDebug}, req, http.StatusBadGateway, "text/plain", "down")
+	if l := rec["level"]; l != "WARN" {
+		t.Errorf("level of a 502 = %v, want WARN", l)
+	}
+	req, _ = http.NewRequest("GET", "/1/orders", nil)
+	rec, _ = logRecord(t, LogOptions{Level: slog.LevelDebug}, req, http.StatusNotFound, "text/plain", "gone")
+	if l := rec["level"]; l != "DEBUG" {
+		t.Errorf("level of a 404 = %v, want DEBUG", l)
+	}
+}
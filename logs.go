@@ -0,0 +1,163 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"bufio"
+	"context"
+	"iter"
+	"strings"
+	"time"
+
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+)
+
+// tailInterval is the delay between log requests when following an order.
+var tailInterval = 2 * time.Second
+
+// LogLine is a single line of an order's log.
+type LogLine struct {
+	// Time the line was logged, zero if the line carries no timestamp
+	Time time.Time
+	// Container which produced the line
+	Container string
+	// Text of the line without timestamp and trailing newline
+	Text string
+}
+
+// TailLogs returns an iterator over the log lines of order orderID. If
+// container is not empty, only the logs of that container are returned. If
+// follow is set, the logs are requested again with an advancing start time
+// until the order has finished or ctx is done, similar to "tail -f". Lines
+// returned by more than one request are only yielded once. The ivcap-logs
+// command prints the lines on the command line.
+func (c *OrdersClient) TailLogs(ctx context.Context, orderID string, container string, follow bool) iter.Seq2[*LogLine, error] {
+	return func(yield func(*LogLine, error) bool) {
+		var t logTail
+		for {
+			finished := false
+			if follow {
+				st, err := c.Read(ctx, orderID)
+				if err != nil {
+					yield(nil, err)
+					return
+				}
+				// Logs written before the order finished are all in the
+				// next response, so that is the last one we need.
+				finished = st.Status != nil && IsTerminalOrderStatus(*st.Status)
+			}
+			req := &order.DownloadLogRequestT{OrderID: orderID, From: t.cursor}
+			if container != "" {
+				req.ContainerName = &container
+			}
+			if !t.fetch(ctx, c, req, container, yield) {
+				return
+			}
+			if !follow || finished {
+				return
+			}
+			if err := sleep(ctx, tailInterval); err != nil {
+				yield(nil, err)
+				return
+			}
+		}
+	}
+}
+
+// logTail keeps track of the lines already returned by TailLogs. The service
+// only accepts start times in whole seconds, so every response repeats the
+// lines of the second the previous one ended in. As logs are returned in
+// order, these are always the first lines of the response.
+type logTail struct {
+	// cursor is the start time of the next request
+	cursor *int64
+	// seen is the number of lines at or after cursor already returned
+	seen int
+}
+
+// fetch requests the logs described by req and yields the lines not returned
+// before. It returns false if iteration should stop.
+func (t *logTail) fetch(ctx context.Context, c *OrdersClient, req *order.DownloadLogRequestT, container string, yield func(*LogLine, error) bool) bool {
+	body, err := c.Logs(ctx, req)
+	if err != nil {
+		return yield(nil, err)
+	}
+	defer body.Close()
+
+	var (
+		n     int // lines at or after cursor
+		first int // index of the first line logged in second last
+		last  int64
+	)
+	if t.cursor != nil {
+		last = *t.cursor
+	}
+	sc := bufio.NewScanner(body)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		l := ParseLogLine(sc.Text(), container)
+		if !l.Time.IsZero() {
+			sec := l.Time.Unix()
+			if t.cursor != nil && sec < *t.cursor {
+				continue
+			}
+			if sec > last {
+				last, first = sec, n
+			}
+		}
+		n++
+		if n <= t.seen {
+			continue
+		}
+		if !yield(l, nil) {
+			return false
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return yield(nil, err)
+	}
+	if last > 0 && (t.cursor == nil || last > *t.cursor) {
+		t.cursor, t.seen = &last, n-first
+	} else {
+		t.seen = max(n, t.seen)
+	}
+	return true
+}
+
+// ParseLogLine parses a line as returned by the order logs endpoint. Lines
+// may start with an RFC 3339 timestamp and a "[pod/container]" style
+// prefix naming the container, as produced by "kubectl logs --timestamps
+// --prefix". container is used if the line does not name one.
+func ParseLogLine(s string, container string) *LogLine {
+	l := &LogLine{Container: container}
+	s = strings.TrimRight(s, "\r\n")
+	if strings.HasPrefix(s, "[") {
+		if i := strings.Index(s, "] "); i > 0 {
+			prefix := s[1:i]
+			l.Container = prefix[strings.LastIndexByte(prefix, '/')+1:]
+			s = s[i+2:]
+		}
+	}
+	if i := strings.IndexByte(s, ' '); i > 0 {
+		if ts, err := time.Parse(time.RFC3339Nano, s[:i]); err == nil {
+			l.Time = ts
+			s = s[i+1:]
+		}
+	} else if ts, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		l.Time, s = ts, ""
+	}
+	l.Text = s
+	return l
+}
@@ -0,0 +1,230 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// logDoer serves the status and the logs of an order whose log grows with
+// every logs request. Status reads return the next of statuses.
+type logDoer struct {
+	statuses []string
+	// lines[i] are the lines added before the i-th logs request
+	lines [][]testLogLine
+	log   []testLogLine
+	reads int
+	// froms are the start times of the logs requests
+	froms []*int64
+}
+
+type testLogLine struct {
+	at        time.Time
+	container string
+	text      string
+}
+
+func (d *logDoer) Do(req *http.Request) (*http.Response, error) {
+	var body string
+	switch {
+	case req.Method == http.MethodPost && req.URL.Path == "/1/orders/logs":
+		var lr struct {
+			From          *int64  `json:"from"`
+			ContainerName *string `json:"container-name"`
+			OrderID       string  `json:"order-id"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&lr); err != nil {
+			return nil, err
+		}
+		if n := len(d.froms); n < len(d.lines) {
+			d.log = append(d.log, d.lines[n]...)
+		}
+		d.froms = append(d.froms, lr.From)
+		var b strings.Builder
+		for _, l := range d.log {
+			if lr.From != nil && l.at.Unix() < *lr.From || lr.ContainerName != nil && l.container != *lr.ContainerName {
+				continue
+			}
+			fmt.Fprintf(&b, "[%s/%s] %s %s\n", lr.OrderID, l.container, l.at.Format(time.RFC3339Nano), l.text)
+		}
+		body = b.String()
+	case req.Method == http.MethodGet && req.URL.Path == "/1/orders/"+waitOrderID:
+		status := d.statuses[min(d.reads, len(d.statuses)-1)]
+		d.reads++
+		body = fmt.Sprintf(`{"id":%q,"status":%q,"parameters":[]}`, waitOrderID, status)
+	default:
+		return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func newLogClient(t *testing.T, d *logDoer) *OrdersClient {
+	c, err := NewClient("https://ivcap.test", StaticToken("token"), d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c.Orders()
+}
+
+// at returns a time ms milliseconds after a fixed second.
+func at(ms int) time.Time {
+	return time.Unix(1700000000, 0).Add(time.Duration(ms) * time.Millisecond).UTC()
+}
+
+func tail(t *testing.T, c *OrdersClient, container string, follow bool) []string {
+	t.Helper()
+	var got []string
+	for l, err := range c.TailLogs(context.Background(), waitOrderID, container, follow) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, fmt.Sprintf("%s %s %s", l.Time.Format("05.000"), l.Container, l.Text))
+	}
+	return got
+}
+
+func TestTailLogsFollow(t *testing.T) {
+	delays := recordSleeps(t)
+	d := &logDoer{
+		statuses: []string{"executing", "executing", "succeeded"},
+		lines: [][]testLogLine{
+			{{at(100), "main", "a"}, {at(500), "sidecar", "x"}, {at(500), "main", "b"}},
+			// c is logged in the second the previous response ended in
+			{{at(900), "main", "c"}, {at(1200), "main", "d"}},
+			{{at(2000), "main", "e"}},
+		},
+	}
+	got := tail(t, newLogClient(t, d), "", true)
+	want := []string{"20.100 main a", "20.500 sidecar x", "20.500 main b", "20.900 main c", "21.200 main d", "22.000 main e"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("lines = %q, want %q", got, want)
+	}
+	var froms []string
+	for _, f := range d.froms {
+		if f == nil {
+			froms = append(froms, "nil")
+		} else {
+			froms = append(froms, fmt.Sprint(*f-1700000000))
+		}
+	}
+	if s := strings.Join(froms, " "); s != "nil 0 1" {
+		t.Errorf("requested logs from %s, want nil 0 1", s)
+	}
+	if len(*delays) != 2 || (*delays)[0] != tailInterval {
+		t.Errorf("delays = %v, want 2 of %v", *delays, tailInterval)
+	}
+}
+
+func TestTailLogsContainer(t *testing.T) {
+	recordSleeps(t)
+	d := &logDoer{
+		statuses: []string{"succeeded"},
+		lines:    [][]testLogLine{{{at(100), "main", "a"}, {at(200), "sidecar", "x"}, {at(300), "main", "b"}}},
+	}
+	got := tail(t, newLogClient(t, d), "main", true)
+	if want := []string{"20.100 main a", "20.300 main b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lines = %q, want %q", got, want)
+	}
+}
+
+func TestTailLogsOnce(t *testing.T) {
+	d := &logDoer{
+		statuses: []string{"executing"},
+		lines:    [][]testLogLine{{{at(100), "main", "a"}}, {{at(200), "main", "b"}}},
+	}
+	got := tail(t, newLogClient(t, d), "", false)
+	if want := []string{"20.100 main a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lines = %q, want %q", got, want)
+	}
+	if d.reads != 0 || len(d.froms) != 1 {
+		t.Errorf("%d status reads and %d logs requests, want 0 and 1", d.reads, len(d.froms))
+	}
+}
+
+func TestTailLogsStop(t *testing.T) {
+	recordSleeps(t)
+	d := &logDoer{
+		statuses: []string{"executing"},
+		lines:    [][]testLogLine{{{at(100), "main", "a"}, {at(200), "main", "b"}}, {{at(1200), "main", "c"}}},
+	}
+	for range newLogClient(t, d).TailLogs(context.Background(), waitOrderID, "", true) {
+		break
+	}
+	if len(d.froms) != 1 {
+		t.Errorf("%d logs requests after stopping, want 1", len(d.froms))
+	}
+}
+
+func TestTailLogsErrors(t *testing.T) {
+	recordSleeps(t)
+	c := newLogClient(t, &logDoer{statuses: []string{"executing"}})
+	var errs []error
+	for l, err := range c.TailLogs(context.Background(), "urn:ivcap:order:unknown", "", true) {
+		if l != nil {
+			t.Errorf("line %+v", l)
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || errs[0] == nil {
+		t.Errorf("errors = %v, want one", errs)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sleep = func(context.Context, time.Duration) error {
+		cancel()
+		return ctx.Err()
+	}
+	d := &logDoer{statuses: []string{"executing"}, lines: [][]testLogLine{{{at(100), "main", "a"}}}}
+	var last error
+	for _, err := range newLogClient(t, d).TailLogs(ctx, waitOrderID, "", true) {
+		last = err
+	}
+	if !errors.Is(last, context.Canceled) {
+		t.Errorf("last error = %v, want context.Canceled", last)
+	}
+}
+
+func TestParseLogLine(t *testing.T) {
+	ts := time.Date(2023, 5, 1, 10, 0, 0, 123000000, time.UTC)
+	for _, tc := range []struct {
+		in   string
+		want LogLine
+	}{
+		{"[pod/main] 2023-05-01T10:00:00.123Z hello world\n", LogLine{ts, "main", "hello world"}},
+		{"2023-05-01T10:00:00.123Z hello", LogLine{ts, "default", "hello"}},
+		{"2023-05-01T10:00:00.123Z", LogLine{ts, "default", ""}},
+		{"[main] no timestamp\r\n", LogLine{time.Time{}, "main", "no timestamp"}},
+		{"plain text", LogLine{time.Time{}, "default", "plain text"}},
+		{"[unterminated prefix", LogLine{time.Time{}, "default", "[unterminated prefix"}},
+	} {
+		if got := ParseLogLine(tc.in, "default"); !reflect.DeepEqual(*got, tc.want) {
+			t.Errorf("ParseLogLine(%q) = %+v, want %+v", tc.in, *got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	metadata "github.com/reinventingscience/ivcap-core-api/gen/metadata"
+)
+
+// MetadataClient provides typed access to the metadata service.
+type MetadataClient struct {
+	client *metadata.Client
+}
+
+// Read returns metadata record id.
+func (c *MetadataClient) Read(ctx context.Context, id string) (*metadata.MetadataRecordRT, error) {
+	return c.client.Read(ctx, &metadata.ReadPayload{ID: id})
+}
+
+// List returns a single page of metadata records. p may be nil, in which case
+// the service defaults apply. A malformed filter or order-by is rejected
+// before the request is sent with an error wrapping the *query.SyntaxError.
+func (c *MetadataClient) List(ctx context.Context, p *metadata.ListPayload) (*metadata.ListMetaRT, error) {
+	var lp metadata.ListPayload
+	if p != nil {
+		lp = *p
+	}
+	if lp.Limit == 0 {
+		lp.Limit = 10
+	}
+	if err := checkListQuery(&lp.Filter, &lp.OrderBy); err != nil {
+		return nil, err
+	}
+	return c.client.List(ctx, &lp)
+}
+
+// ListAll returns an iterator over all metadata records matching p, following
+// the "next" links of the returned pages. At most maxItems records are
+// returned, or all of them if maxItems is zero. p may be nil.
+func (c *MetadataClient) ListAll(ctx context.Context, p *metadata.ListPayload, maxItems int) iter.Seq2[*metadata.MetadataListItemRT, error] {
+	fetch := func(ctx context.Context, page *string) ([]*metadata.MetadataListItemRT, *string, error) {
+		var lp metadata.ListPayload
+		if p != nil {
+			lp = *p
+		}
+		if page != nil {
+			lp.Page = page
+		}
+		res, err := c.List(ctx, &lp)
+		if err != nil {
+			return nil, nil, err
+		}
+		if res.Links == nil {
+			return res.Records, nil, nil
+		}
+		return res.Records, res.Links.Next, nil
+	}
+	return NewPager(fetch, maxItems).All(ctx)
+}
+
+// Add attaches a new metadata record to an entity. The content type defaults
+// to "application/json".
+func (c *MetadataClient) Add(ctx context.Context, p *metadata.AddPayload) (*metadata.AddMetaRT, error) {
+	if p == nil {
+		return nil, fmt.Errorf("missing metadata record")
+	}
+	ap := *p
+	if ap.ContentType == "" {
+		ap.ContentType = "application/json"
+	}
+	return c.client.Add(ctx, &ap)
+}
+
+// UpdateOne revokes the single active record for the entity/schema pair in p
+// and creates a new one.
+func (c *MetadataClient) UpdateOne(ctx context.Context, p *metadata.UpdateOnePayload) (*metadata.AddMetaRT, error) {
+	return c.client.UpdateOne(ctx, p)
+}
+
+// UpdateRecord revokes record p.ID and creates a new one, copying any field
+// not set in p from the revoked record.
+func (c *MetadataClient) UpdateRecord(ctx context.Context, p *metadata.UpdateRecordPayload) (*metadata.AddMetaRT, error) {
+	return c.client.UpdateRecord(ctx, p)
+}
+
+// Revoke retracts metadata record id.
+func (c *MetadataClient) Revoke(ctx context.Context, id string) error {
+	return c.client.Revoke(ctx, &metadata.RevokePayload{ID: &id})
+}
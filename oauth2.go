@@ -0,0 +1,245 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	goahttp "goa.design/goa/v3/http"
+)
+
+// stderr is where interactive prompts are written to.
+var stderr io.Writer = os.Stderr
+
+// second is the unit of the intervals and lifetimes in device authorization
+// responses.
+var second = time.Second
+
+// OAuth2Refresher refreshes tokens using the OAuth2 refresh token grant
+// (RFC 6749, section 6). If the token has no refresh token or the identity
+// provider rejects it as "invalid_grant", e.g. because it expired or was
+// revoked, it falls back to Fallback when set. Other errors are returned as
+// is, so that a provider which is briefly unreachable does not ask the user
+// to log in again.
+type OAuth2Refresher struct {
+	// TokenURL is the token endpoint of the identity provider.
+	TokenURL string
+	// ClientID identifies this application to the identity provider.
+	ClientID string
+	// Fallback is used when the refresh token is missing or no longer
+	// valid, e.g. a *DeviceFlow prompting the user to log in again.
+	Fallback Refresher
+	// Doer sends the token requests, http.DefaultClient if nil.
+	Doer goahttp.Doer
+}
+
+// Refresh exchanges the refresh token of old for a new token.
+func (r *OAuth2Refresher) Refresh(ctx context.Context, old *Token) (*Token, error) {
+	if old == nil || old.RefreshToken == "" {
+		return r.fallback(ctx, old, ErrNoToken)
+	}
+	t, err := RequestToken(ctx, r.Doer, r.TokenURL, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {old.RefreshToken},
+		"client_id":     {r.ClientID},
+	})
+	var oerr *OAuth2Error
+	if errors.As(err, &oerr) && oerr.Code == "invalid_grant" {
+		return r.fallback(ctx, old, err)
+	}
+	return t, err
+}
+
+func (r *OAuth2Refresher) fallback(ctx context.Context, old *Token, err error) (*Token, error) {
+	if r.Fallback == nil {
+		return nil, err
+	}
+	return r.Fallback.Refresh(ctx, old)
+}
+
+// DeviceFlow obtains tokens through the OAuth2 device authorization grant
+// (RFC 8628). The user is asked to visit a URL and enter a code, after which
+// the flow completes.
+type DeviceFlow struct {
+	// DeviceAuthURL is the device authorization endpoint of the identity
+	// provider.
+	DeviceAuthURL string
+	// TokenURL is the token endpoint of the identity provider.
+	TokenURL string
+	// ClientID identifies this application to the identity provider.
+	ClientID string
+	// Scopes requested. "offline_access" should be included to receive a
+	// refresh token.
+	Scopes []string
+	// Audience is sent as the "audience" parameter if set.
+	Audience string
+	// Prompt is called with the verification URL and the code the user has to
+	// enter there. It defaults to printing both to stderr.
+	Prompt func(verificationURI, userCode string)
+	// Doer sends the requests, http.DefaultClient if nil.
+	Doer goahttp.Doer
+}
+
+// OAuth2Error is returned when the identity provider rejects a request.
+type OAuth2Error struct {
+	// Code is the OAuth2 error code, e.g. "invalid_grant".
+	Code string `json:"error"`
+	// Description is an optional human readable description.
+	Description string `json:"error_description"`
+}
+
+// Error returns an error description.
+func (e *OAuth2Error) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("oauth2: %s: %s", e.Code, e.Description)
+	}
+	return "oauth2: " + e.Code
+}
+
+// Refresh runs the device authorization flow. It ignores old.
+func (f *DeviceFlow) Refresh(ctx context.Context, _ *Token) (*Token, error) {
+	return f.Login(ctx)
+}
+
+// Login runs the device authorization flow and blocks until the user has
+// completed it, it failed or ctx is done.
+func (f *DeviceFlow) Login(ctx context.Context) (*Token, error) {
+	form := url.Values{"client_id": {f.ClientID}}
+	if len(f.Scopes) > 0 {
+		form.Set("scope", strings.Join(f.Scopes, " "))
+	}
+	if f.Audience != "" {
+		form.Set("audience", f.Audience)
+	}
+	var da struct {
+		DeviceCode              string `json:"device_code"`
+		UserCode                string `json:"user_code"`
+		VerificationURI         string `json:"verification_uri"`
+		VerificationURIComplete string `json:"verification_uri_complete"`
+		ExpiresIn               int    `json:"expires_in"`
+		Interval                int    `json:"interval"`
+	}
+	if err := postForm(ctx, f.Doer, f.DeviceAuthURL, form, &da); err != nil {
+		return nil, err
+	}
+	prompt := f.Prompt
+	if prompt == nil {
+		prompt = func(uri, code string) {
+			fmt.Fprintf(stderr, "To log in, visit %s and enter the code %s\n", uri, code)
+		}
+	}
+	uri := da.VerificationURIComplete
+	if uri == "" {
+		uri = da.VerificationURI
+	}
+	prompt(uri, da.UserCode)
+
+	interval := time.Duration(da.Interval) * second
+	if interval <= 0 {
+		interval = 5 * second
+	}
+	var expired <-chan time.Time
+	if da.ExpiresIn > 0 {
+		t := time.NewTimer(time.Duration(da.ExpiresIn) * second)
+		defer t.Stop()
+		expired = t.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-expired:
+			return nil, &OAuth2Error{Code: "expired_token", Description: "the code was not entered in time"}
+		case <-time.After(interval):
+		}
+		t, err := RequestToken(ctx, f.Doer, f.TokenURL, url.Values{
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+			"device_code": {da.DeviceCode},
+			"client_id":   {f.ClientID},
+		})
+		var oerr *OAuth2Error
+		switch {
+		case err == nil:
+			return t, nil
+		case errors.As(err, &oerr) && oerr.Code == "authorization_pending":
+		case errors.As(err, &oerr) && oerr.Code == "slow_down":
+			interval += 5 * second
+		default:
+			return nil, err
+		}
+	}
+}
+
+// RequestToken posts form to the OAuth2 token endpoint at tokenURL and
+// converts the response into a Token. The grant type and its parameters are
+// taken from form.
+func RequestToken(ctx context.Context, doer goahttp.Doer, tokenURL string, form url.Values) (*Token, error) {
+	var tr struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int64  `json:"expires_in"`
+	}
+	if err := postForm(ctx, doer, tokenURL, form, &tr); err != nil {
+		return nil, err
+	}
+	if tr.AccessToken == "" {
+		return nil, fmt.Errorf("oauth2: token response from %s has no access token", tokenURL)
+	}
+	t := &Token{AccessToken: tr.AccessToken, RefreshToken: tr.RefreshToken}
+	if tr.ExpiresIn > 0 {
+		t.Expiry = time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+	return t, nil
+}
+
+// postForm posts a form encoded request to u and decodes the JSON response
+// into v. OAuth2 error responses are returned as *OAuth2Error.
+func postForm(ctx context.Context, doer goahttp.Doer, u string, form url.Values, v interface{}) error {
+	if doer == nil {
+		doer = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", u, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := doer.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		oerr := &OAuth2Error{}
+		if json.Unmarshal(body, oerr) == nil && oerr.Code != "" {
+			return oerr
+		}
+		return fmt.Errorf("oauth2: unexpected response from %s: %s", u, resp.Status)
+	}
+	return json.Unmarshal(body, v)
+}
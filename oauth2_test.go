@@ -0,0 +1,213 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeIdP is a device authorization server answering the token requests
+// with the next of its responses, repeating the last one.
+type fakeIdP struct {
+	*httptest.Server
+	t         *testing.T
+	expiresIn int
+	responses []string
+
+	mu    sync.Mutex
+	polls []time.Time
+}
+
+func newFakeIdP(t *testing.T, expiresIn int, responses ...string) *fakeIdP {
+	// Intervals and lifetimes are in milliseconds.
+	second = time.Millisecond
+	t.Cleanup(func() { second = time.Second })
+	idp := &fakeIdP{t: t, expiresIn: expiresIn, responses: responses}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/device", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("client_id") != "cli" || r.FormValue("scope") != "openid offline_access" || r.FormValue("audience") != "https://api.ivcap.test" {
+			t.Errorf("device request %v", r.Form)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"device_code":               "dev-code",
+			"user_code":                 "ABCD-EFGH",
+			"verification_uri":          "https://idp.test/activate",
+			"verification_uri_complete": "https://idp.test/activate?code=ABCD-EFGH",
+			"expires_in":                idp.expiresIn,
+			"interval":                  2,
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:device_code" || r.FormValue("device_code") != "dev-code" {
+			t.Errorf("token request %v", r.Form)
+		}
+		idp.mu.Lock()
+		res := idp.responses[min(len(idp.polls), len(idp.responses)-1)]
+		idp.polls = append(idp.polls, time.Now())
+		idp.mu.Unlock()
+		if res != "ok" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": res})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "access", "refresh_token": "refresh", "expires_in": 3600})
+	})
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+func (idp *fakeIdP) flow(prompt func(uri, code string)) *DeviceFlow {
+	return &DeviceFlow{
+		DeviceAuthURL: idp.URL + "/device",
+		TokenURL:      idp.URL + "/token",
+		ClientID:      "cli",
+		Scopes:        []string{"openid", "offline_access"},
+		Audience:      "https://api.ivcap.test",
+		Prompt:        prompt,
+	}
+}
+
+func TestDeviceFlowPending(t *testing.T) {
+	idp := newFakeIdP(t, 0, "authorization_pending", "authorization_pending", "ok")
+	var prompted string
+	tok, err := idp.flow(func(uri, code string) { prompted = uri + " " + code }).Login(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prompted != "https://idp.test/activate?code=ABCD-EFGH ABCD-EFGH" {
+		t.Errorf("prompted %q", prompted)
+	}
+	if tok.AccessToken != "access" || tok.RefreshToken != "refresh" || time.Until(tok.Expiry) < 59*time.Minute {
+		t.Errorf("token = %+v", tok)
+	}
+	if len(idp.polls) != 3 {
+		t.Errorf("polled %d times, want 3", len(idp.polls))
+	}
+}
+
+func TestDeviceFlowSlowDown(t *testing.T) {
+	idp := newFakeIdP(t, 0, "authorization_pending", "slow_down", "authorization_pending", "ok")
+	if _, err := idp.flow(func(string, string) {}).Login(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(idp.polls) != 4 {
+		t.Fatalf("polled %d times, want 4", len(idp.polls))
+	}
+	// The interval of 2 grows by 5 after slow_down.
+	if d := idp.polls[2].Sub(idp.polls[1]); d < 7*time.Millisecond {
+		t.Errorf("polled %s after slow_down, want at least 7ms", d)
+	}
+	if d := idp.polls[3].Sub(idp.polls[2]); d < 7*time.Millisecond {
+		t.Errorf("polled %s after slow_down, want the interval to stay increased", d)
+	}
+}
+
+func TestDeviceFlowExpiry(t *testing.T) {
+	idp := newFakeIdP(t, 20, "authorization_pending")
+	_, err := idp.flow(func(string, string) {}).Login(context.Background())
+	var oerr *OAuth2Error
+	if !errors.As(err, &oerr) || oerr.Code != "expired_token" {
+		t.Errorf("err = %v, want expired_token", err)
+	}
+	if len(idp.polls) == 0 {
+		t.Error("did not poll before the code expired")
+	}
+}
+
+func TestDeviceFlowErrors(t *testing.T) {
+	for _, code := range []string{"access_denied", "expired_token"} {
+		idp := newFakeIdP(t, 0, "authorization_pending", code)
+		_, err := idp.flow(func(string, string) {}).Login(context.Background())
+		var oerr *OAuth2Error
+		if !errors.As(err, &oerr) || oerr.Code != code {
+			t.Errorf("err = %v, want %s", err, code)
+		}
+	}
+}
+
+func TestDeviceFlowCancel(t *testing.T) {
+	idp := newFakeIdP(t, 0, "authorization_pending")
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := idp.flow(func(string, string) { cancel() }).Login(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestOAuth2RefresherFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      *Token
+		status   int
+		response string
+		fallback bool
+		want     string
+		wantCode string
+	}{
+		{"refreshed", &Token{RefreshToken: "refresh"}, http.StatusOK, `{"access_token":"new"}`, true, "new", ""},
+		{"invalid grant", &Token{RefreshToken: "refresh"}, http.StatusBadRequest, `{"error":"invalid_grant"}`, true, "login", ""},
+		{"invalid grant without fallback", &Token{RefreshToken: "refresh"}, http.StatusBadRequest, `{"error":"invalid_grant"}`, false, "", "invalid_grant"},
+		{"invalid client", &Token{RefreshToken: "refresh"}, http.StatusUnauthorized, `{"error":"invalid_client"}`, true, "", "invalid_client"},
+		{"unavailable", &Token{RefreshToken: "refresh"}, http.StatusServiceUnavailable, ``, true, "", ""},
+		{"no refresh token", &Token{AccessToken: "old"}, 0, ``, true, "login", ""},
+		{"no token", nil, 0, ``, true, "login", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if r.FormValue("grant_type") != "refresh_token" || r.FormValue("refresh_token") != "refresh" || r.FormValue("client_id") != "cli" {
+					t.Errorf("token request %v", r.Form)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.response))
+			}))
+			defer srv.Close()
+			r := &OAuth2Refresher{TokenURL: srv.URL, ClientID: "cli", Doer: srv.Client()}
+			if tt.fallback {
+				r.Fallback = refresherFunc(func(context.Context, *Token) (*Token, error) {
+					return &Token{AccessToken: "login"}, nil
+				})
+			}
+
+			tok, err := r.Refresh(context.Background(), tt.old)
+			switch {
+			case tt.want != "":
+				if err != nil || tok.AccessToken != tt.want {
+					t.Errorf("Refresh() = %+v, %v, want %s", tok, err, tt.want)
+				}
+			case err == nil:
+				t.Errorf("Refresh() = %+v, want an error", tok)
+			case tt.wantCode != "":
+				var oerr *OAuth2Error
+				if !errors.As(err, &oerr) || oerr.Code != tt.wantCode {
+					t.Errorf("err = %v, want %s", err, tt.wantCode)
+				}
+			}
+			if wantRequests := min(tt.status, 1); requests != wantRequests {
+				t.Errorf("sent %d token requests, want %d", requests, wantRequests)
+			}
+		})
+	}
+}
@@ -0,0 +1,370 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package orderbuilder generates Go types for placing orders with a specific
+// service. The generated type has one typed field per parameter defined by
+// the service and a ToOrderRequest method turning it into an
+// order.OrderRequestT.
+package orderbuilder
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strings"
+	"text/template"
+	"unicode"
+
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+)
+
+// Config describes the code to generate.
+type Config struct {
+	// Package is the name of the generated package.
+	Package string
+	// TypeName is the name of the generated struct. Defaults to the
+	// service name followed by "Order".
+	TypeName string
+	// ServiceID is the ID of the service orders are placed with.
+	ServiceID string
+	// ServiceName is the name of the service.
+	ServiceName string
+	// Description of the service.
+	Description string
+	// Parameters defined by the service.
+	Parameters []*service.ParameterDefT
+	// Generator names the program generating the code, used in the
+	// "Code generated" header. Defaults to "ivcap-gen-order".
+	Generator string
+}
+
+// FromService returns the configuration for the service described by st.
+func FromService(st *service.ServiceStatusRT) *Config {
+	return &Config{
+		ServiceID:   st.ID,
+		ServiceName: deref(st.Name),
+		Description: deref(st.Description),
+		Parameters:  st.Parameters,
+	}
+}
+
+// FromDescription returns the configuration for the service description d
+// registered as service id.
+func FromDescription(id string, d *service.ServiceDescriptionT) *Config {
+	return &Config{
+		ServiceID:   id,
+		ServiceName: deref(d.Name),
+		Description: d.Description,
+		Parameters:  d.Parameters,
+	}
+}
+
+// Generate writes the formatted Go source for cfg to w.
+func Generate(w io.Writer, cfg *Config) error {
+	if cfg.ServiceID == "" {
+		return fmt.Errorf("missing service ID")
+	}
+	if cfg.Package == "" {
+		return fmt.Errorf("missing package name")
+	}
+	data := &file{Config: *cfg}
+	if data.TypeName == "" {
+		data.TypeName = goName(cfg.ServiceName) + "Order"
+		if data.TypeName == "Order" {
+			return fmt.Errorf("missing type name, service has no name")
+		}
+	}
+	if data.Generator == "" {
+		data.Generator = "ivcap-gen-order"
+	}
+	var (
+		fields = map[string]bool{"Name": true, "Tags": true, "PolicyID": true, "ToOrderRequest": true}
+		consts = map[string]bool{"ServiceID": true}
+	)
+	for _, d := range cfg.Parameters {
+		if d == nil || d.Name == nil || *d.Name == "" {
+			return fmt.Errorf("parameter without name")
+		}
+		f := newField(d, fields, consts)
+		if f.Constant {
+			data.Constants = append(data.Constants, f)
+			continue
+		}
+		data.Fields = append(data.Fields, f)
+		switch f.GoType {
+		case "int64", "float64", "bool":
+			data.NeedsStrconv = true
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := fileTmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("formatting generated code: %w\n%s", err, buf.Bytes())
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+type file struct {
+	Config
+	Fields       []*field
+	Constants    []*field
+	NeedsStrconv bool
+}
+
+type field struct {
+	Name     string
+	Param    string
+	GoType   string
+	Pointer  bool
+	Unary    bool
+	Constant bool
+	Doc      []string
+	Options  []option
+}
+
+type option struct {
+	Name  string
+	Value string
+	Doc   string
+}
+
+// newField maps the parameter definition d to a struct field. Field names are
+// unique within fields, the names of generated constants within consts.
+func newField(d *service.ParameterDefT, fields, consts map[string]bool) *field {
+	f := &field{
+		Param:    *d.Name,
+		Unary:    isTrue(d.Unary),
+		Constant: isTrue(d.Constant),
+	}
+	if f.Constant {
+		f.Name = uniqueName(goName(*d.Name), consts)
+	} else {
+		f.Name = uniqueName(goName(*d.Name), fields)
+	}
+	switch strings.ToLower(deref(d.Type)) {
+	case "int":
+		f.GoType = "int64"
+	case "float":
+		f.GoType = "float64"
+	case "bool":
+		f.GoType = "bool"
+	default:
+		f.GoType = "string"
+	}
+	if f.Unary {
+		f.GoType = "bool"
+	}
+	f.Pointer = !f.Unary && (isTrue(d.Optional) || d.Default != nil)
+
+	label := deref(d.Label)
+	if label == "" {
+		label = f.Param
+	}
+	f.Doc = append(f.Doc, fmt.Sprintf("%s is the %q parameter.", f.Name, label))
+	if s := strings.TrimSpace(deref(d.Description)); s != "" {
+		f.Doc = append(f.Doc, strings.Split(s, "\n")...)
+	}
+	if d.Unit != nil && *d.Unit != "" {
+		f.Doc = append(f.Doc, "Unit: "+*d.Unit)
+	}
+	if d.Default != nil {
+		f.Doc = append(f.Doc, fmt.Sprintf("Defaults to %q if nil.", *d.Default))
+	} else if f.Pointer {
+		f.Doc = append(f.Doc, "Optional.")
+	}
+	if f.Unary {
+		f.Doc = append(f.Doc, "Flag, only sent if set.")
+	}
+	if f.Constant {
+		f.Doc = []string{fmt.Sprintf("%s is the value of the constant %q parameter.", f.Name, label)}
+	}
+	for _, o := range d.Options {
+		if o == nil || o.Value == nil {
+			continue
+		}
+		f.Options = append(f.Options, option{
+			Name:  uniqueName(f.Name+goName(*o.Value), consts),
+			Value: *o.Value,
+			Doc:   strings.Join(strings.Fields(deref(o.Description)), " "),
+		})
+	}
+	if f.Constant {
+		f.Options = []option{{Value: deref(d.Default)}}
+	}
+	return f
+}
+
+var fileTmpl = template.Must(template.New("file").Funcs(template.FuncMap{
+	"comment": comment,
+	"pair": func(typ, expr string) map[string]string {
+		return map[string]string{"Type": typ, "Expr": expr}
+	},
+}).Parse(`// Code generated by {{ .Generator }}. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+{{- if .NeedsStrconv }}
+	"strconv"
+{{ end }}
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+)
+
+// {{ .TypeName }}ServiceID is the ID of the {{ printf "%q" .ServiceName }} service.
+const {{ .TypeName }}ServiceID = {{ printf "%q" .ServiceID }}
+{{ range .Fields }}{{ if .Options }}
+// Values of {{ $.TypeName }}.{{ .Name }}.
+const (
+{{- range .Options }}
+{{- if .Doc }}
+	{{ comment .Doc }}{{ end }}
+	{{ $.TypeName }}{{ .Name }} = {{ printf "%q" .Value }}
+{{- end }}
+)
+{{ end }}{{ end }}
+{{- if .Constants }}
+// Constant parameters of the service. Their values cannot be changed, so they
+// are not part of {{ .TypeName }}.
+const (
+{{- range .Constants }}
+	{{ comment (index .Doc 0) }}
+	{{ $.TypeName }}{{ .Name }} = {{ printf "%q" (index .Options 0).Value }}
+{{- end }}
+)
+{{ end }}
+// {{ .TypeName }} holds the parameters of an order for the {{ printf "%q" .ServiceName }}
+// service.
+{{- if .Description }}
+//
+{{ comment .Description }}
+{{- end }}
+type {{ .TypeName }} struct {
+	// Name is an optional name for the order.
+	Name *string ` + "`json:\"-\"`" + `
+	// Tags are optional tags for the order.
+	Tags []string ` + "`json:\"-\"`" + `
+	// PolicyID optionally controls access to the order and its products.
+	PolicyID *string ` + "`json:\"-\"`" + `
+{{ range .Fields }}
+{{- range .Doc }}
+	{{ comment . }}
+{{- end }}
+	{{ .Name }} {{ if .Pointer }}*{{ end }}{{ .GoType }} ` + "`json:\"{{ .Param }}{{ if or .Pointer .Unary }},omitempty{{ end }}\"`" + `
+{{- end }}
+}
+
+// ToOrderRequest returns the request for placing the order described by o.
+func (o *{{ .TypeName }}) ToOrderRequest() *order.OrderRequestT {
+	req := &order.OrderRequestT{
+		ServiceID: {{ .TypeName }}ServiceID,
+		Name:      o.Name,
+		Tags:      o.Tags,
+		PolicyID:  o.PolicyID,
+	}
+{{- if .Fields }}
+	add := func(name, value string) {
+		req.Parameters = append(req.Parameters, &order.ParameterT{Name: &name, Value: &value})
+	}
+{{- end }}
+{{- range .Fields }}
+{{- if .Unary }}
+	if o.{{ .Name }} {
+		add({{ printf "%q" .Param }}, "true")
+	}
+{{- else if .Pointer }}
+	if o.{{ .Name }} != nil {
+		add({{ printf "%q" .Param }}, {{ template "format" (printf "*o.%s" .Name | pair .GoType) }})
+	}
+{{- else }}
+	add({{ printf "%q" .Param }}, {{ template "format" (printf "o.%s" .Name | pair .GoType) }})
+{{- end }}
+{{- end }}
+	return req
+}
+{{ define "format" }}
+{{- if eq .Type "int64" }}strconv.FormatInt({{ .Expr }}, 10)
+{{- else if eq .Type "float64" }}strconv.FormatFloat({{ .Expr }}, 'g', -1, 64)
+{{- else if eq .Type "bool" }}strconv.FormatBool({{ .Expr }})
+{{- else }}{{ .Expr }}{{ end }}
+{{- end }}
+`))
+
+// comment turns s into a Go line comment, prefixing every line with "//".
+func comment(s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight("// "+l, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// commonInitialisms are written in upper case in Go names.
+var commonInitialisms = map[string]bool{
+	"API": true, "CPU": true, "CSV": true, "HTML": true, "HTTP": true, "ID": true,
+	"JSON": true, "TTL": true, "UI": true, "URI": true, "URL": true, "URN": true,
+	"UUID": true, "XML": true,
+}
+
+// goName turns a parameter or service name into an exported Go identifier,
+// e.g. "max-cloud-cover" into "MaxCloudCover".
+func goName(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, w := range words {
+		if u := strings.ToUpper(w); commonInitialisms[u] {
+			b.WriteString(u)
+			continue
+		}
+		r := []rune(w)
+		b.WriteString(strings.ToUpper(string(r[0])) + string(r[1:]))
+	}
+	name := b.String()
+	if name != "" && unicode.IsDigit([]rune(name)[0]) {
+		name = "P" + name
+	}
+	return name
+}
+
+// uniqueName returns name, or name with a numeric suffix if it is already
+// taken, and marks the result as taken.
+func uniqueName(name string, taken map[string]bool) string {
+	if name == "" {
+		name = "Param"
+	}
+	n := name
+	for i := 2; taken[n]; i++ {
+		n = fmt.Sprintf("%s%d", name, i)
+	}
+	taken[n] = true
+	return n
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
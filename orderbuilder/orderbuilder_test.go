@@ -0,0 +1,96 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orderbuilder
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"go/format"
+	"os"
+	"testing"
+
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// TestGolden generates the order type for the service described in
+// testdata/fire_risk.json and compares it with testdata/fire_risk.golden.
+func TestGolden(t *testing.T) {
+	b, err := os.ReadFile("testdata/fire_risk.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var desc service.ServiceDescriptionT
+	if err := json.Unmarshal(b, &desc); err != nil {
+		t.Fatal(err)
+	}
+	cfg := FromDescription("urn:ivcap:service:0d6ce3f6-5b1b-4bcd-95a5-2a7ca4b0e3ab", &desc)
+	cfg.Package = "firerisk"
+	var out bytes.Buffer
+	if err := Generate(&out, cfg); err != nil {
+		t.Fatal(err)
+	}
+	const golden = "testdata/fire_risk.golden"
+	if *update {
+		if err := os.WriteFile(golden, out.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != string(want) {
+		t.Errorf("generated code differs from %s, run go test -update and git diff to compare:\n%s", golden, out.Bytes())
+	}
+	if src, err := format.Source(want); err != nil {
+		t.Errorf("%s: %v", golden, err)
+	} else if !bytes.Equal(src, want) {
+		t.Errorf("%s is not formatted with gofmt", golden)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	name := "test"
+	for _, tc := range []struct {
+		cfg  *Config
+		want string
+	}{
+		{&Config{Package: "p", ServiceName: name}, "missing service ID"},
+		{&Config{ServiceID: "urn:ivcap:service:1", ServiceName: name}, "missing package name"},
+		{&Config{ServiceID: "urn:ivcap:service:1", Package: "p"}, "missing type name, service has no name"},
+		{&Config{ServiceID: "urn:ivcap:service:1", Package: "p", ServiceName: name, Parameters: []*service.ParameterDefT{{}}}, "parameter without name"},
+	} {
+		if err := Generate(&bytes.Buffer{}, tc.cfg); err == nil || err.Error() != tc.want {
+			t.Errorf("Generate(%+v) = %v, want %s", tc.cfg, err, tc.want)
+		}
+	}
+}
+
+func TestGoName(t *testing.T) {
+	for in, want := range map[string]string{
+		"max-cloud-cover": "MaxCloudCover",
+		"api_url":         "APIURL",
+		"3d":              "P3d",
+		"fire risk":       "FireRisk",
+		"--":              "",
+	} {
+		if got := goName(in); got != want {
+			t.Errorf("goName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"context"
+	"io"
+	"iter"
+
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+
+	goahttp "goa.design/goa/v3/http"
+)
+
+// OrdersClient provides typed access to the order service.
+type OrdersClient struct {
+	client    *order.Client
+	doer      goahttp.Doer
+	artifacts *ArtifactsClient
+}
+
+// Read returns the status of order id.
+func (c *OrdersClient) Read(ctx context.Context, id string) (*order.OrderStatusRT, error) {
+	return c.client.Read(ctx, &order.ReadPayload{ID: id})
+}
+
+// List returns a single page of orders. p may be nil, in which case the
+// service defaults apply. A malformed filter or order-by is rejected before
+// the request is sent with an error wrapping the *query.SyntaxError.
+func (c *OrdersClient) List(ctx context.Context, p *order.ListPayload) (*order.OrderListRT, error) {
+	var lp order.ListPayload
+	if p != nil {
+		lp = *p
+	}
+	if lp.Limit == 0 {
+		lp.Limit = 10
+	}
+	if err := checkListQuery(lp.Filter, lp.OrderBy); err != nil {
+		return nil, err
+	}
+	return c.client.List(ctx, &lp)
+}
+
+// ListAll returns an iterator over all orders matching p, following the
+// "next" links of the returned pages. At most maxItems orders are returned,
+// or all of them if maxItems is zero. p may be nil.
+func (c *OrdersClient) ListAll(ctx context.Context, p *order.ListPayload, maxItems int) iter.Seq2[*order.OrderListItem, error] {
+	fetch := func(ctx context.Context, page *string) ([]*order.OrderListItem, *string, error) {
+		var lp order.ListPayload
+		if p != nil {
+			lp = *p
+		}
+		if page != nil {
+			lp.Page = page
+		}
+		res, err := c.List(ctx, &lp)
+		if err != nil {
+			return nil, nil, err
+		}
+		if res.Links == nil {
+			return res.Orders, nil, nil
+		}
+		return res.Orders, res.Links.Next, nil
+	}
+	return NewPager(fetch, maxItems).All(ctx)
+}
+
+// Create places a new order and returns its initial status.
+func (c *OrdersClient) Create(ctx context.Context, req *order.OrderRequestT) (*order.OrderStatusRT, error) {
+	return c.client.Create(ctx, &order.CreatePayload{Orders: req})
+}
+
+// Logs returns the logs of an order for the window described by req. The
+// caller is responsible for closing the returned reader.
+func (c *OrdersClient) Logs(ctx context.Context, req *order.DownloadLogRequestT) (io.ReadCloser, error) {
+	return c.client.Logs(ctx, &order.LogsPayload{DownloadLogRequest: req})
+}
+
+// Top returns the current resource usage of the containers of an order.
+func (c *OrdersClient) Top(ctx context.Context, req *order.OrderTopRequestT) (order.OrderTopResultItemCollection, error) {
+	return c.client.Top(ctx, &order.TopPayload{OrderTopRequest: req})
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"context"
+	"iter"
+	"net/url"
+)
+
+// PageFunc fetches a single page of a list endpoint. page is nil for the
+// first page and otherwise the page token taken from the previous page's
+// "next" link. It returns the items of the page and the page's "next" link,
+// which is nil on the last page.
+type PageFunc[T any] func(ctx context.Context, page *string) (items []T, next *string, err error)
+
+// Pager iterates over all items of a list endpoint by following its "next"
+// links.
+type Pager[T any] struct {
+	fetch PageFunc[T]
+	// MaxItems caps the number of items returned. Zero means no cap.
+	MaxItems int
+}
+
+// NewPager returns a pager fetching pages through fetch and returning at most
+// maxItems items. A maxItems of zero means no cap.
+func NewPager[T any](fetch PageFunc[T], maxItems int) *Pager[T] {
+	return &Pager[T]{fetch: fetch, MaxItems: maxItems}
+}
+
+// All returns an iterator over the items of all pages. Iteration stops after
+// the last page, once MaxItems items were returned, or at the first error,
+// which is yielded together with the zero value of T. If ctx is done, its
+// error is yielded.
+func (p *Pager[T]) All(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var (
+			zero  T
+			page  *string
+			count int
+		)
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+			items, next, err := p.fetch(ctx, page)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if err := ctx.Err(); err != nil {
+					yield(zero, err)
+					return
+				}
+				if !yield(item, nil) {
+					return
+				}
+				count++
+				if p.MaxItems > 0 && count >= p.MaxItems {
+					return
+				}
+			}
+			if next == nil || *next == "" || len(items) == 0 {
+				return
+			}
+			page = pageToken(*next)
+		}
+	}
+}
+
+// pageToken extracts the value of the "page" query parameter from a "next"
+// link. If the link carries no such parameter, it is used as is.
+func pageToken(next string) *string {
+	if u, err := url.Parse(next); err == nil {
+		if pg := u.Query().Get("page"); pg != "" {
+			return &pg
+		}
+	}
+	return &next
+}
@@ -0,0 +1,198 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+)
+
+// Parameter types understood by ValidateParameters. Values of other types are
+// accepted as is.
+const (
+	ParamTypeString     = "string"
+	ParamTypeInt        = "int"
+	ParamTypeFloat      = "float"
+	ParamTypeBool       = "bool"
+	ParamTypeOption     = "option"
+	ParamTypeURL        = "url"
+	ParamTypeArtifact   = "artifact"
+	ParamTypeCollection = "collection"
+)
+
+// ValidateParameters checks the parameters of req against the parameter
+// definitions of svc. Every parameter must be defined by the service, have a
+// value of the declared type and, if the definition lists options, be one of
+// them. Constant parameters must not differ from their default. Missing
+// parameters are filled in with their default, and missing parameters which
+// are neither optional nor have a default are reported.
+//
+// All problems are returned together as ParameterErrors. A nil svc or req is
+// reported as an error of its own.
+func ValidateParameters(svc *service.ServiceStatusRT, req *order.OrderRequestT) error {
+	if svc == nil {
+		return fmt.Errorf("missing service")
+	}
+	if req == nil {
+		return fmt.Errorf("missing order request")
+	}
+	var (
+		errs ParameterErrors
+		defs = map[string]*service.ParameterDefT{}
+		seen = map[string]bool{}
+	)
+	for _, d := range svc.Parameters {
+		if d != nil && d.Name != nil {
+			defs[*d.Name] = d
+		}
+	}
+	for _, p := range req.Parameters {
+		if p == nil || p.Name == nil {
+			errs = append(errs, &order.InvalidParameterValue{Message: "parameter without name"})
+			continue
+		}
+		name := *p.Name
+		d, ok := defs[name]
+		switch {
+		case !ok:
+			errs = append(errs, &order.InvalidParameterValue{Name: name, Value: p.Value, Message: fmt.Sprintf("unknown parameter, service %s does not define it", svc.ID)})
+		case seen[name]:
+			errs = append(errs, &order.InvalidParameterValue{Name: name, Value: p.Value, Message: "parameter given more than once"})
+		default:
+			if err := checkParameter(d, p.Value); err != nil {
+				errs = append(errs, &order.InvalidParameterValue{Name: name, Value: p.Value, Message: err.Error()})
+			}
+		}
+		seen[name] = true
+	}
+	for _, d := range svc.Parameters {
+		if d == nil || d.Name == nil || seen[*d.Name] {
+			continue
+		}
+		switch {
+		case d.Default != nil:
+			req.Parameters = append(req.Parameters, &order.ParameterT{Name: d.Name, Value: d.Default})
+		case !isTrue(d.Optional) && !isTrue(d.Unary):
+			errs = append(errs, &order.InvalidParameterValue{Name: *d.Name, Message: "missing required parameter"})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// ParameterErrors lists the problems found by ValidateParameters. Use
+// errors.As to obtain the individual *order.InvalidParameterValue errors.
+type ParameterErrors []*order.InvalidParameterValue
+
+// Error returns an error description.
+func (e ParameterErrors) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid order parameters:")
+	for _, p := range e {
+		b.WriteString("\n  ")
+		if p.Name != "" {
+			b.WriteString(p.Name)
+			b.WriteString(": ")
+		}
+		b.WriteString(p.Message)
+		if p.Value != nil {
+			fmt.Fprintf(&b, " (got %q)", *p.Value)
+		}
+	}
+	return b.String()
+}
+
+// Unwrap returns the individual errors.
+func (e ParameterErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, p := range e {
+		errs[i] = p
+	}
+	return errs
+}
+
+// checkParameter checks value against the definition d.
+func checkParameter(d *service.ParameterDefT, value *string) error {
+	if value == nil {
+		if isTrue(d.Unary) || isTrue(d.Optional) {
+			return nil
+		}
+		return fmt.Errorf("missing value")
+	}
+	v := *value
+	if isTrue(d.Constant) && d.Default != nil && v != *d.Default {
+		return fmt.Errorf("parameter is constant and must be %q", *d.Default)
+	}
+	if len(d.Options) > 0 {
+		var opts []string
+		for _, o := range d.Options {
+			if o != nil && o.Value != nil {
+				opts = append(opts, *o.Value)
+			}
+		}
+		if !slices.Contains(opts, v) {
+			return fmt.Errorf("must be one of %q", opts)
+		}
+	}
+	switch strings.ToLower(stringOf(d.Type)) {
+	case ParamTypeInt:
+		if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+			return fmt.Errorf("must be an int")
+		}
+	case ParamTypeFloat:
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			return fmt.Errorf("must be a float")
+		}
+	case ParamTypeBool:
+		if isTrue(d.Unary) && v == "" {
+			return nil
+		}
+		if _, err := strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("must be a bool")
+		}
+	case ParamTypeURL, "uri":
+		if u, err := url.Parse(v); err != nil || u.Scheme == "" || (u.Host == "" && u.Opaque == "") {
+			return fmt.Errorf("must be an absolute URL")
+		}
+	case ParamTypeArtifact:
+		if !isURN(v, "artifact") {
+			return fmt.Errorf("must be an artifact URN (urn:ivcap:artifact:...)")
+		}
+	case ParamTypeCollection:
+		if !isURN(v, "collection") {
+			return fmt.Errorf("must be a collection URN (urn:ivcap:collection:...)")
+		}
+	}
+	return nil
+}
+
+// isURN returns true if s is an IVCAP URN of the given kind, e.g.
+// "urn:ivcap:artifact:123e4567-e89b-12d3-a456-426614174000".
+func isURN(s string, kind string) bool {
+	id, ok := strings.CutPrefix(s, "urn:ivcap:"+kind+":")
+	return ok && id != "" && !strings.ContainsAny(id, " \t\n")
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
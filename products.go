@@ -0,0 +1,346 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+	orderc "github.com/reinventingscience/ivcap-core-api/http/order"
+	"github.com/reinventingscience/ivcap-core-api/ivcaperr"
+
+	goahttp "goa.design/goa/v3/http"
+)
+
+// ProductManifestFile is the name of the manifest FetchProducts writes into
+// the target directory.
+const ProductManifestFile = "manifest.json"
+
+// FetchOptions controls FetchProducts.
+type FetchOptions struct {
+	// Workers is the number of concurrent downloads. Defaults to 4.
+	Workers int
+	// Progress, if set, is called after each product was either downloaded
+	// or skipped, or failed with err. It may be called concurrently.
+	Progress func(p *order.ProductT, skipped bool, err error)
+}
+
+// ProductManifest records which file holds which product of an order.
+type ProductManifest struct {
+	// OrderID is the ID of the order the products belong to
+	OrderID string `json:"order-id"`
+	// Products maps product IDs to the local files. Products without an ID
+	// are keyed by their position in the order, e.g. "#3".
+	Products map[string]*ProductFile `json:"products"`
+}
+
+// ProductFile describes a downloaded product.
+type ProductFile struct {
+	// File is the path of the product relative to the manifest
+	File string `json:"file"`
+	// Name of the product
+	Name string `json:"name,omitempty"`
+	// MimeType of the product
+	MimeType string `json:"mime-type,omitempty"`
+	// Size of the product in bytes, -1 if unknown
+	Size int64 `json:"size"`
+	// Etag of the downloaded content
+	Etag string `json:"etag,omitempty"`
+}
+
+// Products returns an iterator over all products of order id, following the
+// order's product links.
+func (c *OrdersClient) Products(ctx context.Context, id string) iter.Seq2[*order.ProductT, error] {
+	return func(yield func(*order.ProductT, error) bool) {
+		res, err := c.Read(ctx, id)
+		for {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, p := range res.Products {
+				if err := ctx.Err(); err != nil {
+					yield(nil, err)
+					return
+				}
+				if !yield(p, nil) {
+					return
+				}
+			}
+			nav := res.ProductLinks
+			if nav == nil || nav.Next == nil || *nav.Next == "" || len(res.Products) == 0 {
+				return
+			}
+			res, err = c.readLink(ctx, *nav.Next)
+		}
+	}
+}
+
+// FetchProducts downloads all products of order id into dir, running at most
+// opts.Workers downloads at a time. Products whose Etag matches the file
+// already present in dir are skipped, which makes it cheap to call
+// FetchProducts again after a partial failure. Once all downloads finished,
+// the manifest is written to ProductManifestFile in dir and returned. Failed
+// products are left out of the manifest and reported in the returned error.
+// opts may be nil.
+func (c *OrdersClient) FetchProducts(ctx context.Context, id string, dir string, opts *FetchOptions) (*ProductManifest, error) {
+	var o FetchOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Workers <= 0 {
+		o.Workers = 4
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	prev := readProductManifest(filepath.Join(dir, ProductManifestFile), id)
+
+	var products []*order.ProductT
+	for p, err := range c.Products(ctx, id) {
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	files := productFileNames(products, prev)
+
+	var (
+		mu       sync.Mutex
+		errs     []error
+		manifest = &ProductManifest{OrderID: id, Products: map[string]*ProductFile{}}
+		jobs     = make(chan int)
+		wg       sync.WaitGroup
+	)
+	for range min(o.Workers, len(products)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				p, key := products[i], productKey(i, products[i])
+				pf := &ProductFile{
+					File:     files[p],
+					Name:     stringOf(p.Name),
+					MimeType: stringOf(p.MimeType),
+					Size:     sizeOf(p.Size),
+					Etag:     stringOf(p.Etag),
+				}
+				skipped, err := c.fetchProduct(ctx, p, filepath.Join(dir, pf.File), prev.Products[key])
+				if o.Progress != nil {
+					o.Progress(p, skipped, err)
+				}
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("product %s: %w", key, err))
+				} else {
+					manifest.Products[key] = pf
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range products {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := writeProductManifest(filepath.Join(dir, ProductManifestFile), manifest); err != nil {
+		errs = append(errs, err)
+	}
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return manifest, errors.Join(errs...)
+}
+
+// fetchProduct downloads p to path unless the file there already holds the
+// content tagged with p.Etag. It returns true if the download was skipped.
+func (c *OrdersClient) fetchProduct(ctx context.Context, p *order.ProductT, path string, prev *ProductFile) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	if p.Links == nil || p.Links.Data == nil || *p.Links.Data == "" {
+		return false, fmt.Errorf("no data link")
+	}
+	etag, size := stringOf(p.Etag), sizeOf(p.Size)
+	if upToDate(path, etag, size, prev) {
+		return true, nil
+	}
+	err := c.artifacts.downloadLinkToFile(ctx, c.artifacts.resolve(*p.Links.Data), size, etag, path)
+	return false, err
+}
+
+// upToDate returns true if the file at path holds the content tagged with
+// etag. MD5 based ETags are checked against the file's content, others
+// against the ETag recorded in the previous manifest.
+func upToDate(path string, etag string, size int64, prev *ProductFile) bool {
+	if etag == "" {
+		return false
+	}
+	fi, err := os.Stat(path)
+	if err != nil || !fi.Mode().IsRegular() || (size >= 0 && fi.Size() != size) {
+		return false
+	}
+	if !isMD5(etag) {
+		return prev != nil && prev.Etag == etag
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return false
+	}
+	return strings.EqualFold(hex.EncodeToString(h.Sum(nil)), unquoteETag(etag))
+}
+
+// productKey returns the key of product p, the i-th product of its order, in
+// a ProductManifest.
+func productKey(i int, p *order.ProductT) string {
+	if id := stringOf(p.ID); id != "" {
+		return id
+	}
+	return "#" + strconv.Itoa(i)
+}
+
+// productFileNames assigns each product a unique file name. Products listed
+// in the previous manifest keep their file unless it is not a safe file name,
+// others are named after the product, falling back to its ID. The names the
+// files have while they are downloaded are reserved as well, so a product
+// named "a.part" does not collide with the download of a product "a".
+func productFileNames(products []*order.ProductT, prev *ProductManifest) map[*order.ProductT]string {
+	files := make(map[*order.ProductT]string, len(products))
+	taken := map[string]bool{ProductManifestFile: true}
+	free := func(name string) bool {
+		return !taken[name] && !taken[name+partSuffix]
+	}
+	assign := func(p *order.ProductT, name string) {
+		files[p] = name
+		taken[name] = true
+		taken[name+partSuffix] = true
+	}
+	var fresh []*order.ProductT
+	for i, p := range products {
+		if pf := prev.Products[productKey(i, p)]; pf != nil && pf.File != "" && safeFileName(pf.File) == pf.File && free(pf.File) {
+			assign(p, pf.File)
+		} else {
+			fresh = append(fresh, p)
+		}
+	}
+	for _, p := range fresh {
+		name := safeFileName(stringOf(p.Name))
+		if name == "" {
+			name = safeFileName(stringOf(p.ID))
+		}
+		if name == "" {
+			name = "product"
+		}
+		ext := filepath.Ext(name)
+		base := strings.TrimSuffix(name, ext)
+		for i := 1; !free(name); i++ {
+			name = base + "-" + strconv.Itoa(i) + ext
+		}
+		assign(p, name)
+	}
+	return files
+}
+
+// safeFileName turns s into a name which stays inside the target directory.
+func safeFileName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', 0:
+			return '_'
+		}
+		return r
+	}, s)
+	if s == "." || s == ".." {
+		return ""
+	}
+	return s
+}
+
+// readProductManifest returns the manifest at path if it belongs to order id,
+// or an empty one otherwise.
+func readProductManifest(path string, id string) *ProductManifest {
+	m := &ProductManifest{}
+	if b, err := os.ReadFile(path); err == nil {
+		if json.Unmarshal(b, m) != nil || m.OrderID != id {
+			m = &ProductManifest{}
+		}
+	}
+	return m
+}
+
+// writeProductManifest atomically writes m to path.
+func writeProductManifest(path string, m *ProductManifest) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".manifest-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// readLink fetches an order through a link returned by the service, such as
+// the "next" link of its products.
+func (c *OrdersClient) readLink(ctx context.Context, link string) (*order.OrderStatusRT, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.artifacts.resolve(link), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return nil, ivcaperr.Wrap("order", "read", link, goahttp.ErrRequestError("order", "read", err))
+	}
+	res, err := orderc.DecodeReadResponse(goahttp.ResponseDecoder, false)(resp)
+	if err != nil {
+		return nil, ivcaperr.Wrap("order", "read", link, err)
+	}
+	return res.(*order.OrderStatusRT), nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"reflect"
+	"testing"
+
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+)
+
+func TestProductFileNames(t *testing.T) {
+	str := func(s string) *string { return &s }
+	product := func(id, name string) *order.ProductT {
+		p := &order.ProductT{ID: str(id)}
+		if name != "" {
+			p.Name = str(name)
+		}
+		return p
+	}
+	for _, tc := range []struct {
+		name     string
+		products []*order.ProductT
+		prev     map[string]string
+		want     []string
+	}{
+		{
+			"duplicates",
+			[]*order.ProductT{product("1", "a.txt"), product("2", "a.txt"), product("3", "a.txt")},
+			nil,
+			[]string{"a.txt", "a-1.txt", "a-2.txt"},
+		},
+		{
+			"part files",
+			[]*order.ProductT{product("1", "a"), product("2", "a.part"), product("3", "b.part"), product("4", "b")},
+			nil,
+			[]string{"a", "a-1.part", "b.part", "b-1"},
+		},
+		{
+			"manifest",
+			[]*order.ProductT{product("1", "manifest.json"), product("2", "manifest.json.part")},
+			nil,
+			[]string{"manifest-1.json", "manifest.json.part"},
+		},
+		{
+			"unsafe",
+			[]*order.ProductT{product("urn:ivcap:artifact:1", ""), product("2", "../x"), product("", ".."), {}},
+			nil,
+			[]string{"urn_ivcap_artifact_1", ".._x", "product", "product-1"},
+		},
+		{
+			"previous manifest",
+			[]*order.ProductT{product("1", "a"), product("2", "b"), product("3", "c")},
+			map[string]string{"1": "old-a", "2": "../b", "3": "old-a.part"},
+			[]string{"old-a", "b", "c"},
+		},
+	} {
+		prev := &ProductManifest{Products: map[string]*ProductFile{}}
+		for k, f := range tc.prev {
+			prev.Products[k] = &ProductFile{File: f}
+		}
+		files := productFileNames(tc.products, prev)
+		var got []string
+		for _, p := range tc.products {
+			got = append(got, files[p])
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: files = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"fmt"
+
+	"github.com/reinventingscience/ivcap-core-api/query"
+)
+
+// checkListQuery validates the filter and order-by parameters of a list
+// request so that syntax errors are reported before the request is sent. The
+// returned error names the offending parameter and wraps the
+// *query.SyntaxError.
+func checkListQuery(filter, orderBy *string) error {
+	if filter != nil && *filter != "" {
+		if err := query.Validate(*filter); err != nil {
+			return fmt.Errorf("invalid filter %q: %w", *filter, err)
+		}
+	}
+	if orderBy != nil && *orderBy != "" {
+		if err := query.ValidateOrderBy(*orderBy); err != nil {
+			return fmt.Errorf("invalid order-by %q: %w", *orderBy, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package query builds and validates the OData style expressions accepted by
+// the 'filter' and 'order-by' parameters of the IVCAP list endpoints.
+//
+//	f := query.And(
+//		query.Eq("status", "executing"),
+//		query.StartsWith("name", "fire"),
+//	)
+//	p := &order.ListPayload{Filter: query.Filter(f)}
+package query
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Expr is a boolean filter expression.
+type Expr interface {
+	// String returns the expression in the syntax expected by the service.
+	String() string
+	// precedence orders the logical operators when printing.
+	precedence() int
+}
+
+// Op is a comparison operator.
+type Op string
+
+// Supported comparison operators.
+const (
+	OpEq Op = "eq"
+	OpNe Op = "ne"
+	OpGt Op = "gt"
+	OpGe Op = "ge"
+	OpLt Op = "lt"
+	OpLe Op = "le"
+	// OpLike matches a string against a pattern in which "%" stands for any
+	// sequence of characters, e.g. "name ~= 'Scott%'".
+	OpLike Op = "~="
+)
+
+// Func is a string function usable in filters.
+type Func string
+
+// Supported string functions.
+const (
+	FuncContains   Func = "contains"
+	FuncStartsWith Func = "startswith"
+	FuncEndsWith   Func = "endswith"
+)
+
+// Comparison compares a field with a literal value, e.g. "name eq 'x'".
+type Comparison struct {
+	Field string
+	Op    Op
+	Value Literal
+}
+
+// Call applies a string function to a field, e.g. "contains(name,'x')".
+type Call struct {
+	Func  Func
+	Field string
+	Value Literal
+}
+
+// InList tests whether a field equals one of a list of values, e.g.
+// "status in ('pending','executing')".
+type InList struct {
+	Field  string
+	Values []Literal
+}
+
+// Logical combines expressions with "and" or "or".
+type Logical struct {
+	// Op is either "and" or "or"
+	Op    string
+	Exprs []Expr
+}
+
+// Negation negates an expression.
+type Negation struct {
+	Expr Expr
+}
+
+// Literal is a value in a filter expression in its encoded form, e.g. "'x'",
+// "42", "true", "null" or an unquoted date-time such as
+// "2023-01-01T00:00:00Z".
+type Literal string
+
+// Eq returns "field eq value".
+func Eq(field string, value interface{}) Expr { return compare(field, OpEq, value) }
+
+// Ne returns "field ne value".
+func Ne(field string, value interface{}) Expr { return compare(field, OpNe, value) }
+
+// Gt returns "field gt value".
+func Gt(field string, value interface{}) Expr { return compare(field, OpGt, value) }
+
+// Ge returns "field ge value".
+func Ge(field string, value interface{}) Expr { return compare(field, OpGe, value) }
+
+// Lt returns "field lt value".
+func Lt(field string, value interface{}) Expr { return compare(field, OpLt, value) }
+
+// Le returns "field le value".
+func Le(field string, value interface{}) Expr { return compare(field, OpLe, value) }
+
+// Like returns "field ~= 'pattern'".
+func Like(field, pattern string) Expr { return compare(field, OpLike, pattern) }
+
+// In returns "field in (values...)".
+func In(field string, values ...interface{}) Expr {
+	in := &InList{Field: field, Values: make([]Literal, len(values))}
+	for i, v := range values {
+		in.Values[i] = Value(v)
+	}
+	return in
+}
+
+// Contains returns "contains(field,'s')".
+func Contains(field, s string) Expr { return &Call{FuncContains, field, Value(s)} }
+
+// StartsWith returns "startswith(field,'s')".
+func StartsWith(field, s string) Expr { return &Call{FuncStartsWith, field, Value(s)} }
+
+// EndsWith returns "endswith(field,'s')".
+func EndsWith(field, s string) Expr { return &Call{FuncEndsWith, field, Value(s)} }
+
+// And returns the conjunction of exprs. Nil expressions are skipped, so
+// optional conditions can be passed in directly.
+func And(exprs ...Expr) Expr { return logical("and", exprs) }
+
+// Or returns the disjunction of exprs. Nil expressions are skipped.
+func Or(exprs ...Expr) Expr { return logical("or", exprs) }
+
+// Not returns the negation of e.
+func Not(e Expr) Expr { return &Negation{e} }
+
+// Filter returns the encoded expression suitable for the Filter field of a
+// list payload, or nil if e is nil.
+func Filter(e Expr) *string {
+	if e == nil {
+		return nil
+	}
+	s := e.String()
+	return &s
+}
+
+// Value encodes v as a literal. Strings and times are quoted, times using
+// RFC 3339. Values of other types are encoded as strings using fmt.
+func Value(v interface{}) Literal {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case Literal:
+		return x
+	case string:
+		return quote(x)
+	case bool:
+		return Literal(strconv.FormatBool(x))
+	case int:
+		return Literal(strconv.FormatInt(int64(x), 10))
+	case int32:
+		return Literal(strconv.FormatInt(int64(x), 10))
+	case int64:
+		return Literal(strconv.FormatInt(x, 10))
+	case uint:
+		return Literal(strconv.FormatUint(uint64(x), 10))
+	case uint64:
+		return Literal(strconv.FormatUint(x, 10))
+	case float32:
+		return formatFloat(float64(x))
+	case float64:
+		return formatFloat(x)
+	case time.Time:
+		return quote(x.Format(time.RFC3339))
+	case fmt.Stringer:
+		return quote(x.String())
+	default:
+		return quote(fmt.Sprint(x))
+	}
+}
+
+func (c *Comparison) String() string {
+	return fmt.Sprintf("%s %s %s", c.Field, c.Op, c.Value)
+}
+
+func (c *Call) String() string {
+	return fmt.Sprintf("%s(%s,%s)", c.Func, c.Field, c.Value)
+}
+
+func (in *InList) String() string {
+	vs := make([]string, len(in.Values))
+	for i, v := range in.Values {
+		vs[i] = string(v)
+	}
+	return fmt.Sprintf("%s in (%s)", in.Field, strings.Join(vs, ","))
+}
+
+func (l *Logical) String() string {
+	parts := make([]string, len(l.Exprs))
+	for i, e := range l.Exprs {
+		parts[i] = wrap(e, l.precedence())
+	}
+	return strings.Join(parts, " "+l.Op+" ")
+}
+
+// String always puts the operand of "not" in parentheses unless it is a
+// function call, as "not" binds tighter than the comparison operators.
+func (n *Negation) String() string {
+	if _, ok := n.Expr.(*Call); ok {
+		return "not " + n.Expr.String()
+	}
+	return "not (" + n.Expr.String() + ")"
+}
+
+func (c *Comparison) precedence() int { return 4 }
+func (c *Call) precedence() int       { return 4 }
+func (in *InList) precedence() int    { return 4 }
+func (n *Negation) precedence() int   { return 3 }
+func (l *Logical) precedence() int {
+	if l.Op == "and" {
+		return 2
+	}
+	return 1
+}
+
+// wrap prints e, in parentheses if it binds weaker than its parent.
+func wrap(e Expr, parent int) string {
+	if e.precedence() < parent {
+		return "(" + e.String() + ")"
+	}
+	return e.String()
+}
+
+func compare(field string, op Op, value interface{}) Expr {
+	return &Comparison{Field: field, Op: op, Value: Value(value)}
+}
+
+func logical(op string, exprs []Expr) Expr {
+	l := &Logical{Op: op}
+	for _, e := range exprs {
+		if e != nil {
+			l.Exprs = append(l.Exprs, e)
+		}
+	}
+	switch len(l.Exprs) {
+	case 0:
+		return nil
+	case 1:
+		return l.Exprs[0]
+	}
+	return l
+}
+
+func quote(s string) Literal {
+	return Literal("'" + strings.ReplaceAll(s, "'", "''") + "'")
+}
+
+func formatFloat(f float64) Literal {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return quote(strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	return Literal(strconv.FormatFloat(f, 'g', -1, 64))
+}
@@ -0,0 +1,61 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBuilders(t *testing.T) {
+	at := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, tc := range []struct {
+		e    Expr
+		want string
+	}{
+		{Eq("status", "succeeded"), "status eq 'succeeded'"},
+		{Ne("name", "it's"), "name ne 'it''s'"},
+		{Gt("size", 10), "size gt 10"},
+		{Ge("size", int64(-3)), "size ge -3"},
+		{Lt("ratio", 0.5), "ratio lt 0.5"},
+		{Le("ordered-at", at), "ordered-at le '2023-01-02T03:04:05Z'"},
+		{Eq("done", true), "done eq true"},
+		{Eq("policy", nil), "policy eq null"},
+		{Eq("x", math.Inf(1)), "x eq '+Inf'"},
+		{Eq("raw", Literal("2023-01-01")), "raw eq 2023-01-01"},
+		{Like("name", "Scott%"), "name ~= 'Scott%'"},
+		{In("status", "pending", 1), "status in ('pending',1)"},
+		{Contains("name", "x"), "contains(name,'x')"},
+		{Not(StartsWith("name", "x")), "not startswith(name,'x')"},
+		{Not(Eq("a", 1)), "not (a eq 1)"},
+		{And(Eq("a", 1), nil, Or(Eq("b", 2), Eq("c", 3))), "a eq 1 and (b eq 2 or c eq 3)"},
+		{Or(And(Eq("a", 1), Eq("b", 2)), EndsWith("c", "z")), "a eq 1 and b eq 2 or endswith(c,'z')"},
+		{And(nil, Eq("a", 1)), "a eq 1"},
+	} {
+		if s := tc.e.String(); s != tc.want {
+			t.Errorf("got %q, want %q", s, tc.want)
+		}
+		if e, err := Parse(tc.want); err != nil || e.String() != tc.want {
+			t.Errorf("Parse(%q) = %v, %v", tc.want, e, err)
+		}
+	}
+	if And() != nil || Filter(And(nil)) != nil {
+		t.Error("empty conjunction is not nil")
+	}
+	if f := Filter(Eq("a", 1)); f == nil || *f != "a eq 1" {
+		t.Errorf("Filter = %v", f)
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokDateTime
+	tokOperator
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokEOF:
+		return "end of input"
+	case tokIdent:
+		return "name"
+	case tokString:
+		return "string"
+	case tokNumber:
+		return "number"
+	case tokDateTime:
+		return "date-time"
+	case tokOperator:
+		return "operator"
+	case tokLParen:
+		return "'('"
+	case tokRParen:
+		return "')'"
+	case tokComma:
+		return "','"
+	}
+	return "unknown token"
+}
+
+type token struct {
+	kind tokenKind
+	// text is the identifier, the unquoted string, the number, the date-time
+	// or the operator
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokIdent, tokNumber, tokDateTime, tokOperator:
+		return fmt.Sprintf("%q", t.text)
+	case tokString:
+		return fmt.Sprintf("string '%s'", t.text)
+	}
+	return t.kind.String()
+}
+
+type lexer struct {
+	input string
+	pos   int
+}
+
+func newLexer(s string) *lexer {
+	return &lexer{input: s}
+}
+
+func (l *lexer) errorf(pos int, format string, args ...interface{}) error {
+	return &SyntaxError{Input: l.input, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.input) && (l.input[l.pos] == ' ' || l.input[l.pos] == '\t' || l.input[l.pos] == '\n') {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos >= len(l.input) {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+	c := l.input[l.pos]
+	switch {
+	case c == '(':
+		l.pos++
+		return token{kind: tokLParen, pos: start}, nil
+	case c == ')':
+		l.pos++
+		return token{kind: tokRParen, pos: start}, nil
+	case c == ',':
+		l.pos++
+		return token{kind: tokComma, pos: start}, nil
+	case c == '\'':
+		return l.lexString()
+	case c == '~' && strings.HasPrefix(l.input[l.pos:], "~="):
+		l.pos += 2
+		return token{kind: tokOperator, text: "~=", pos: start}, nil
+	case c >= '0' && c <= '9':
+		if m := dateTime.FindString(l.input[l.pos:]); m != "" && !l.identPartAt(l.pos+len(m)) {
+			l.pos += len(m)
+			return token{kind: tokDateTime, text: m, pos: start}, nil
+		}
+		return l.lexNumber()
+	case c == '-' || c == '+':
+		return l.lexNumber()
+	}
+	r, _ := utf8.DecodeRuneInString(l.input[l.pos:])
+	if !isIdentStart(r) {
+		return token{}, l.errorf(start, "unexpected character %q", r)
+	}
+	for l.pos < len(l.input) {
+		r, n := utf8.DecodeRuneInString(l.input[l.pos:])
+		if !isIdentPart(r) {
+			break
+		}
+		l.pos += n
+	}
+	return token{kind: tokIdent, text: l.input[start:l.pos], pos: start}, nil
+}
+
+// lexString reads a single quoted string in which quotes are escaped by
+// doubling them.
+func (l *lexer) lexString() (token, error) {
+	start := l.pos
+	l.pos++
+	var b strings.Builder
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		l.pos++
+		if c != '\'' {
+			b.WriteByte(c)
+			continue
+		}
+		if l.pos < len(l.input) && l.input[l.pos] == '\'' {
+			b.WriteByte('\'')
+			l.pos++
+			continue
+		}
+		return token{kind: tokString, text: b.String(), pos: start}, nil
+	}
+	return token{}, l.errorf(start, "unterminated string")
+}
+
+// dateTime matches the unquoted dates and date-times accepted by the service,
+// e.g. "2023-01-01" or "2023-01-01T00:00:00Z".
+var dateTime = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(T\d{2}:\d{2}(:\d{2}(\.\d+)?)?(Z|[+-]\d{2}:\d{2})?)?`)
+
+func (l *lexer) lexNumber() (token, error) {
+	start := l.pos
+	if c := l.input[l.pos]; c == '-' || c == '+' {
+		l.pos++
+	}
+	// digits counts the digits of the mantissa, expDigits those of the
+	// exponent
+	digits, expDigits, dot, exp := 0, 0, false, false
+loop:
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		switch {
+		case c >= '0' && c <= '9' && exp:
+			expDigits++
+		case c >= '0' && c <= '9':
+			digits++
+		case c == '.' && !dot && !exp:
+			dot = true
+		case (c == 'e' || c == 'E') && !exp && digits > 0:
+			exp = true
+			if l.pos+1 < len(l.input) && (l.input[l.pos+1] == '-' || l.input[l.pos+1] == '+') {
+				l.pos++
+			}
+		default:
+			if isIdentPart(rune(c)) {
+				return token{}, l.errorf(start, "invalid number")
+			}
+			break loop
+		}
+		l.pos++
+	}
+	if digits == 0 || exp && expDigits == 0 {
+		return token{}, l.errorf(start, "invalid number")
+	}
+	return token{kind: tokNumber, text: l.input[start:l.pos], pos: start}, nil
+}
+
+// identPartAt returns true if the character at pos may be part of a name.
+func (l *lexer) identPartAt(pos int) bool {
+	if pos >= len(l.input) {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(l.input[pos:])
+	return isIdentPart(r)
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r)
+}
+
+// isIdentPart allows the dashes, dots and slashes used in IVCAP field names
+// and property paths, e.g. "ordered-at" or "links/self".
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || unicode.IsDigit(r) || r == '-' || r == '.' || r == '/'
+}
@@ -0,0 +1,117 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Direction is the sort direction of an order-by term.
+type Direction string
+
+// Sort directions.
+const (
+	Ascending  Direction = "asc"
+	Descending Direction = "desc"
+)
+
+// Order is a single term of an order-by clause.
+type Order struct {
+	Field     string
+	Direction Direction
+}
+
+// Asc sorts by field in ascending order.
+func Asc(field string) Order { return Order{field, Ascending} }
+
+// Desc sorts by field in descending order.
+func Desc(field string) Order { return Order{field, Descending} }
+
+// String returns the term, e.g. "ordered-at desc".
+func (o Order) String() string {
+	if o.Direction == "" {
+		return o.Field
+	}
+	return o.Field + " " + string(o.Direction)
+}
+
+// OrderBy returns the encoded order-by clause suitable for the OrderBy field
+// of a list payload, or nil if no terms are given.
+func OrderBy(terms ...Order) *string {
+	if len(terms) == 0 {
+		return nil
+	}
+	parts := make([]string, len(terms))
+	for i, t := range terms {
+		parts[i] = t.String()
+	}
+	s := strings.Join(parts, ",")
+	return &s
+}
+
+// ParseOrderBy parses an order-by clause such as "name,ordered-at desc".
+func ParseOrderBy(s string) ([]Order, error) {
+	var (
+		res   []Order
+		pos   int
+		input = s
+	)
+	for _, part := range strings.Split(s, ",") {
+		fields := strings.Fields(part)
+		switch {
+		case len(fields) == 0:
+			return nil, &SyntaxError{Input: input, Pos: pos, Msg: "empty order-by term"}
+		case len(fields) > 2:
+			return nil, &SyntaxError{Input: input, Pos: pos + strings.Index(part, fields[2]), Msg: fmt.Sprintf("unexpected %q", fields[2])}
+		}
+		l := newLexer(fields[0])
+		if t, err := l.next(); err != nil || t.kind != tokIdent || l.pos != len(fields[0]) {
+			return nil, &SyntaxError{Input: input, Pos: pos + strings.Index(part, fields[0]), Msg: fmt.Sprintf("invalid field name %q", fields[0])}
+		}
+		o := Order{Field: fields[0]}
+		if len(fields) == 2 {
+			o.Direction = Direction(strings.ToLower(fields[1]))
+			if o.Direction != Ascending && o.Direction != Descending {
+				return nil, &SyntaxError{Input: input, Pos: pos + strings.LastIndex(part, fields[1]), Msg: fmt.Sprintf("invalid direction %q", fields[1])}
+			}
+		}
+		res = append(res, o)
+		pos += len(part) + 1
+	}
+	return res, nil
+}
+
+// ValidateOrderBy returns a *SyntaxError if s is not a valid order-by
+// clause. If fields is not empty, only the listed field names are accepted.
+func ValidateOrderBy(s string, fields ...string) error {
+	terms, err := ParseOrderBy(s)
+	if err != nil || len(fields) == 0 {
+		return err
+	}
+	pos := 0
+	for i, part := range strings.Split(s, ",") {
+		t := terms[i]
+		found := false
+		for _, f := range fields {
+			found = found || f == t.Field
+		}
+		if !found {
+			return &SyntaxError{Input: s, Pos: pos + strings.Index(part, t.Field), Msg: fmt.Sprintf("unknown field %q", t.Field)}
+		}
+		pos += len(part) + 1
+	}
+	return nil
+}
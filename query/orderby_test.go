@@ -0,0 +1,85 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseOrderBy(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want []Order
+		out  string
+	}{
+		{"name", []Order{{"name", ""}}, "name"},
+		{"name asc", []Order{Asc("name")}, "name asc"},
+		{" ordered-at DESC , name", []Order{Desc("ordered-at"), {"name", ""}}, "ordered-at desc,name"},
+		{"links/self desc,a.b asc", []Order{Desc("links/self"), Asc("a.b")}, "links/self desc,a.b asc"},
+	} {
+		got, err := ParseOrderBy(tc.in)
+		if err != nil {
+			t.Errorf("ParseOrderBy(%q): %v", tc.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ParseOrderBy(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+		if s := OrderBy(got...); *s != tc.out {
+			t.Errorf("OrderBy(%v) = %q, want %q", got, *s, tc.out)
+		}
+	}
+	if OrderBy() != nil {
+		t.Error("OrderBy() is not nil")
+	}
+}
+
+func TestParseOrderByErrors(t *testing.T) {
+	for _, tc := range []struct {
+		in  string
+		pos int
+		msg string
+	}{
+		{"", 0, "empty order-by term"},
+		{"name,", 5, "empty order-by term"},
+		{"name, 'x'", 6, `invalid field name "'x'"`},
+		{"a,9b asc", 2, `invalid field name "9b"`},
+		{"a(b)", 0, `invalid field name "a(b)"`},
+		{"name, size up", 11, `invalid direction "up"`},
+		{"name asc desc", 9, `unexpected "desc"`},
+	} {
+		_, err := ParseOrderBy(tc.in)
+		var serr *SyntaxError
+		if !errors.As(err, &serr) || serr.Pos != tc.pos || serr.Msg != tc.msg {
+			t.Errorf("ParseOrderBy(%q): err = %v, want at %d %q", tc.in, err, tc.pos, tc.msg)
+		}
+	}
+}
+
+func TestValidateOrderBy(t *testing.T) {
+	if err := ValidateOrderBy("name desc,status", "name", "status"); err != nil {
+		t.Error(err)
+	}
+	var serr *SyntaxError
+	err := ValidateOrderBy("ab,a desc", "ab")
+	if !errors.As(err, &serr) || serr.Pos != 3 || serr.Msg != `unknown field "a"` {
+		t.Errorf("err = %v, want unknown field at 3", err)
+	}
+	if err := ValidateOrderBy("name up", "name"); err == nil {
+		t.Error("ValidateOrderBy accepted an invalid direction")
+	}
+}
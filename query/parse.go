@@ -0,0 +1,297 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SyntaxError reports an invalid filter or order-by expression.
+type SyntaxError struct {
+	// Input is the expression being parsed
+	Input string
+	// Pos is the byte offset of the error in Input
+	Pos int
+	// Msg describes the error
+	Msg string
+}
+
+// Error returns an error description.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d in %q: %s", e.Pos, e.Input, e.Msg)
+}
+
+// Parse parses a filter expression.
+func Parse(s string) (Expr, error) {
+	p := &parser{lex: newLexer(s)}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %s", p.tok)
+	}
+	return e, nil
+}
+
+// Validate returns a *SyntaxError if filter is not a valid filter
+// expression. If fields is not empty, only the listed field names are
+// accepted.
+func Validate(filter string, fields ...string) error {
+	e, err := Parse(filter)
+	if err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return checkFields(filter, e, fields)
+}
+
+func checkFields(input string, e Expr, fields []string) error {
+	var name string
+	switch x := e.(type) {
+	case *Comparison:
+		name = x.Field
+	case *Call:
+		name = x.Field
+	case *InList:
+		name = x.Field
+	case *Negation:
+		return checkFields(input, x.Expr, fields)
+	case *Logical:
+		for _, c := range x.Exprs {
+			if err := checkFields(input, c, fields); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, f := range fields {
+		if f == name {
+			return nil
+		}
+	}
+	return &SyntaxError{Input: input, Pos: fieldPos(input, name), Msg: fmt.Sprintf("unknown field %q", name)}
+}
+
+// fieldPos returns the position of the first name token in input which
+// equals name, skipping strings and other tokens containing it.
+func fieldPos(input, name string) int {
+	l := newLexer(input)
+	for {
+		t, err := l.next()
+		if err != nil || t.kind == tokEOF {
+			return strings.Index(input, name)
+		}
+		if t.kind == tokIdent && t.text == name {
+			return t.pos
+		}
+	}
+}
+
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+func (p *parser) next() error {
+	t, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = t
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Input: p.lex.input, Pos: p.tok.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// isKeyword returns true if the current token is the identifier kw.
+func (p *parser) isKeyword(kw string) bool {
+	return p.tok.kind == tokIdent && strings.EqualFold(p.tok.text, kw)
+}
+
+func (p *parser) expect(kind tokenKind) (token, error) {
+	t := p.tok
+	if t.kind != kind {
+		return t, p.errorf("expected %s, found %s", kind, t)
+	}
+	return t, p.next()
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	return p.parseLogical("or", p.parseAnd)
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	return p.parseLogical("and", p.parseUnary)
+}
+
+func (p *parser) parseLogical(op string, operand func() (Expr, error)) (Expr, error) {
+	e, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	exprs := []Expr{e}
+	for p.isKeyword(op) {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		e, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, e)
+	}
+	return logical(op, exprs), nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	if p.isKeyword("not") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not(e), nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	switch p.tok.kind {
+	case tokLParen:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRParen); err != nil {
+			return nil, err
+		}
+		return e, nil
+	case tokIdent:
+	default:
+		return nil, p.errorf("expected field name or '(', found %s", p.tok)
+	}
+	name, err := p.expect(tokIdent)
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokLParen {
+		return p.parseCall(name)
+	}
+	if p.tok.kind != tokIdent && p.tok.kind != tokOperator {
+		return nil, p.errorf("expected operator after %q, found %s", name.text, p.tok)
+	}
+	if p.isKeyword("in") {
+		return p.parseIn(name)
+	}
+	op := Op(strings.ToLower(p.tok.text))
+	switch op {
+	case OpEq, OpNe, OpGt, OpGe, OpLt, OpLe, OpLike:
+	default:
+		return nil, p.errorf("unknown operator %q", p.tok.text)
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	v, err := p.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+	return &Comparison{Field: name.text, Op: op, Value: v}, nil
+}
+
+func (p *parser) parseCall(name token) (Expr, error) {
+	fn := Func(strings.ToLower(name.text))
+	switch fn {
+	case FuncContains, FuncStartsWith, FuncEndsWith:
+	default:
+		return nil, &SyntaxError{Input: p.lex.input, Pos: name.pos, Msg: fmt.Sprintf("unknown function %q", name.text)}
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	field, err := p.expect(tokIdent)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokComma); err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokString {
+		return nil, p.errorf("%s expects a string argument, found %s", fn, p.tok)
+	}
+	v, err := p.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokRParen); err != nil {
+		return nil, err
+	}
+	return &Call{Func: fn, Field: field.text, Value: v}, nil
+}
+
+// parseIn parses the list of values following "name in".
+func (p *parser) parseIn(name token) (Expr, error) {
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokLParen); err != nil {
+		return nil, err
+	}
+	in := &InList{Field: name.text}
+	for {
+		v, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		in.Values = append(in.Values, v)
+		if p.tok.kind != tokComma {
+			break
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.expect(tokRParen); err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+func (p *parser) parseLiteral() (Literal, error) {
+	t := p.tok
+	switch {
+	case t.kind == tokString:
+		return quote(t.text), p.next()
+	case t.kind == tokNumber, t.kind == tokDateTime:
+		return Literal(t.text), p.next()
+	case p.isKeyword("true"), p.isKeyword("false"), p.isKeyword("null"):
+		return Literal(strings.ToLower(t.text)), p.next()
+	}
+	return "", p.errorf("expected value, found %s", t)
+}
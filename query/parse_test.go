@@ -0,0 +1,144 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseString(t *testing.T) {
+	for _, tc := range []struct {
+		in, out string
+	}{
+		{"status eq 'succeeded'", ""},
+		{"name ~= 'Scott%'", ""},
+		{"size ge 1.5e3", ""},
+		{"size lt -2", ""},
+		{"size gt +2.", "size gt +2."},
+		{"x eq 1E-3", ""},
+		{"ordered-at gt 2023-01-01T10:00:00Z", ""},
+		{"created_at le 2023-01-01", ""},
+		{"links/self ne null", ""},
+		{"a eq TRUE", "a eq true"},
+		{"name eq 'it''s'", ""},
+		{"status in ('pending','executing')", ""},
+		{"status IN ( 'pending' , 1 )", "status in ('pending',1)"},
+		{"contains(name,'x')", ""},
+		{"StartsWith( name , 'x' )", "startswith(name,'x')"},
+		{"not contains(name,'x')", ""},
+		{"not a eq 1", "not (a eq 1)"},
+		{"NOT (a eq 1 or b eq 2)", "not (a eq 1 or b eq 2)"},
+		{"a eq 1 and b eq 2 or c eq 3", ""},
+		{"a eq 1 and (b eq 2 or c eq 3)", ""},
+		{"(a eq 1 and b eq 2) or c eq 3", "a eq 1 and b eq 2 or c eq 3"},
+		{"((a eq 1))", "a eq 1"},
+		{"a eq 1 AND b eq 2 and c eq 3", "a eq 1 and b eq 2 and c eq 3"},
+		{"\ta   eq\n1", "a eq 1"},
+	} {
+		want := tc.out
+		if want == "" {
+			want = tc.in
+		}
+		e, err := Parse(tc.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tc.in, err)
+			continue
+		}
+		if s := e.String(); s != want {
+			t.Errorf("Parse(%q) = %q, want %q", tc.in, s, want)
+		}
+		// The printed expression parses to the same expression.
+		e2, err := Parse(want)
+		if err != nil || e2.String() != want {
+			t.Errorf("Parse(%q) = %v, %v", want, e2, err)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		in  string
+		pos int
+		msg string
+	}{
+		{"", 0, "expected field name or '(', found end of input"},
+		{"a", 1, `expected operator after "a", found end of input`},
+		{"a eq", 4, "expected value, found end of input"},
+		{"a foo 1", 2, `unknown operator "foo"`},
+		{"a eq 1 b", 7, `unexpected "b"`},
+		{"a eq 1 and", 10, "expected field name or '(', found end of input"},
+		{"(a eq 1", 7, "expected ')', found end of input"},
+		{"a eq 'x", 5, "unterminated string"},
+		{"a eq 1e", 5, "invalid number"},
+		{"a eq 1e+", 5, "invalid number"},
+		{"a eq 1.2.3", 5, "invalid number"},
+		{"a eq 12ab", 5, "invalid number"},
+		{"a eq -", 5, "invalid number"},
+		{"a eq 1 # b", 7, `unexpected character '#'`},
+		{"a in ()", 6, "expected value, found ')'"},
+		{"a in (1,)", 8, "expected value, found ')'"},
+		{"a in 1", 5, `expected '(', found "1"`},
+		{"size(a,'x')", 0, `unknown function "size"`},
+		{"contains(a,1)", 11, `contains expects a string argument, found "1"`},
+		{"contains(a 'x')", 11, "expected ',', found string 'x'"},
+		{"contains('a','x')", 9, "expected name, found string 'a'"},
+		{"1 eq a", 0, `expected field name or '(', found "1"`},
+	} {
+		_, err := Parse(tc.in)
+		var serr *SyntaxError
+		if !errors.As(err, &serr) {
+			t.Errorf("Parse(%q): err = %v, want a syntax error", tc.in, err)
+			continue
+		}
+		if serr.Pos != tc.pos || serr.Msg != tc.msg || serr.Input != tc.in {
+			t.Errorf("Parse(%q): error at %d %q, want at %d %q", tc.in, serr.Pos, serr.Msg, tc.pos, tc.msg)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	fields := []string{"name", "status", "ordered-at"}
+	for _, tc := range []struct {
+		in  string
+		pos int // -1 if valid
+	}{
+		{"name eq 'x' and status in ('a') or not contains(name,'y')", -1},
+		{"ordered-at gt 2023-01-01", -1},
+		{"size gt 1", 0},
+		{"name eq 'x' and (status eq 'a' or size gt 1)", 34},
+		{"name eq 'size' or not size gt 1", 22},
+		{"startswith(account,'a')", 11},
+		{"account in ('a')", 0},
+	} {
+		err := Validate(tc.in, fields...)
+		if tc.pos < 0 {
+			if err != nil {
+				t.Errorf("Validate(%q): %v", tc.in, err)
+			}
+			continue
+		}
+		var serr *SyntaxError
+		if !errors.As(err, &serr) || serr.Pos != tc.pos {
+			t.Errorf("Validate(%q): err = %v, want unknown field at %d", tc.in, err, tc.pos)
+		}
+	}
+	if err := Validate("anything eq 1"); err != nil {
+		t.Errorf("Validate without fields: %v", err)
+	}
+	if err := Validate("a eq", fields...); err == nil {
+		t.Error("Validate accepted a syntax error")
+	}
+}
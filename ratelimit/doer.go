@@ -0,0 +1,108 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"io"
+	"net/http"
+
+	artifactc "github.com/reinventingscience/ivcap-core-api/http/artifact"
+	metadatac "github.com/reinventingscience/ivcap-core-api/http/metadata"
+	orderc "github.com/reinventingscience/ivcap-core-api/http/order"
+	servicec "github.com/reinventingscience/ivcap-core-api/http/service"
+
+	goahttp "goa.design/goa/v3/http"
+)
+
+// Doer returns a Doer sending requests through doer once the limits of
+// method of service allow it. The request counts as in flight until its
+// response body is closed.
+func (l *Limits) Doer(service, method string, doer goahttp.Doer) goahttp.Doer {
+	return &limitedDoer{doer: doer, limits: l, service: service, method: method}
+}
+
+// InstallOrder wraps the per endpoint doers of c with the limits of the
+// order service.
+func (l *Limits) InstallOrder(c *orderc.Client) {
+	c.ReadDoer = l.Doer("order", "read", c.ReadDoer)
+	c.ListDoer = l.Doer("order", "list", c.ListDoer)
+	c.CreateDoer = l.Doer("order", "create", c.CreateDoer)
+	c.LogsDoer = l.Doer("order", "logs", c.LogsDoer)
+	c.TopDoer = l.Doer("order", "top", c.TopDoer)
+}
+
+// InstallArtifact wraps the per endpoint doers of c with the limits of the
+// artifact service.
+func (l *Limits) InstallArtifact(c *artifactc.Client) {
+	c.ListDoer = l.Doer("artifact", "list", c.ListDoer)
+	c.ReadDoer = l.Doer("artifact", "read", c.ReadDoer)
+	c.UploadDoer = l.Doer("artifact", "upload", c.UploadDoer)
+}
+
+// InstallService wraps the per endpoint doers of c with the limits of the
+// service service.
+func (l *Limits) InstallService(c *servicec.Client) {
+	c.ListDoer = l.Doer("service", "list", c.ListDoer)
+	c.CreateServiceDoer = l.Doer("service", "create_service", c.CreateServiceDoer)
+	c.ReadDoer = l.Doer("service", "read", c.ReadDoer)
+	c.UpdateDoer = l.Doer("service", "update", c.UpdateDoer)
+	c.DeleteDoer = l.Doer("service", "delete", c.DeleteDoer)
+}
+
+// InstallMetadata wraps the per endpoint doers of c with the limits of the
+// metadata service.
+func (l *Limits) InstallMetadata(c *metadatac.Client) {
+	c.ReadDoer = l.Doer("metadata", "read", c.ReadDoer)
+	c.ListDoer = l.Doer("metadata", "list", c.ListDoer)
+	c.AddDoer = l.Doer("metadata", "add", c.AddDoer)
+	c.UpdateOneDoer = l.Doer("metadata", "update_one", c.UpdateOneDoer)
+	c.UpdateRecordDoer = l.Doer("metadata", "update_record", c.UpdateRecordDoer)
+	c.RevokeDoer = l.Doer("metadata", "revoke", c.RevokeDoer)
+}
+
+// limitedDoer implements Limits.Doer.
+type limitedDoer struct {
+	doer    goahttp.Doer
+	limits  *Limits
+	service string
+	method  string
+}
+
+// Do sends req once the limits allow it.
+func (d *limitedDoer) Do(req *http.Request) (*http.Response, error) {
+	release, err := d.limits.Acquire(req.Context(), d.service, d.method)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := d.doer.Do(req)
+	if err != nil || resp.Body == nil {
+		release()
+		return resp, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releasingBody releases the slot of a request once its body is closed.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
@@ -0,0 +1,371 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ratelimit limits the rate and concurrency of requests to the IVCAP
+// services on the client side. Limits apply per endpoint, per service or
+// both, each combining a token bucket with a cap on the number of requests
+// in flight.
+//
+// With the ivcap client, install the limits as endpoint middleware:
+//
+//	l, err := ratelimit.New(&ratelimit.Config{
+//		Endpoints: map[string]*ratelimit.Limit{
+//			"metadata.add": {Rate: 20, MaxInFlight: 4},
+//		},
+//	})
+//	...
+//	c, err := ivcap.NewClient(url, ts, doer, l.Endpoint)
+//
+// The generated HTTP clients can be limited directly by wrapping their
+// per endpoint doers, see InstallOrder and friends.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	artifact "github.com/reinventingscience/ivcap-core-api/gen/artifact"
+	metadata "github.com/reinventingscience/ivcap-core-api/gen/metadata"
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+
+	goa "goa.design/goa/v3/pkg"
+)
+
+// Limit restricts the requests to an endpoint or service.
+type Limit struct {
+	// Rate is the sustained number of requests per second. Zero means no
+	// rate limit.
+	Rate float64
+	// Burst is the number of requests which may be sent at once after a
+	// quiet period. Defaults to Rate, at least 1.
+	Burst int
+	// MaxInFlight is the number of requests which may be waiting for a
+	// response at the same time. Zero means no limit.
+	MaxInFlight int
+}
+
+// DefaultLimit applies to every service when New is called without a
+// config. It is well below the throttling thresholds of the IVCAP
+// deployments while not slowing down interactive use.
+var DefaultLimit = Limit{Rate: 50, Burst: 100, MaxInFlight: 16}
+
+// Config assigns limits to endpoints and services. A request has to pass
+// the limit of its endpoint and the limit of its service.
+type Config struct {
+	// Services maps service names, e.g. "metadata", to the limit shared by
+	// all endpoints of the service.
+	Services map[string]*Limit
+	// Endpoints maps endpoint names of the form "<service>.<method>", e.g.
+	// "metadata.add", to the limit of the endpoint.
+	Endpoints map[string]*Limit
+	// Default is the limit of every service not listed in Services. Nil
+	// means no limit.
+	Default *Limit
+	// OnWait is called for every request with the time it was held back,
+	// for instance telemetry.Instrumentation.QueueTime.
+	OnWait func(ctx context.Context, service, method string, wait time.Duration)
+}
+
+// methods lists the methods of every service.
+var methods = map[string][]string{
+	artifact.ServiceName: artifact.MethodNames[:],
+	metadata.ServiceName: metadata.MethodNames[:],
+	order.ServiceName:    order.MethodNames[:],
+	service.ServiceName:  service.MethodNames[:],
+}
+
+// Limits enforces the limits of a Config. It is safe for concurrent use.
+type Limits struct {
+	services  map[string]*limiter
+	endpoints map[string]*limiter
+	onWait    func(ctx context.Context, service, method string, wait time.Duration)
+
+	mu    sync.Mutex
+	stats map[string]*Stats
+}
+
+// Stats reports the requests passing through the limits of an endpoint.
+type Stats struct {
+	// Requests is the number of requests sent
+	Requests int64
+	// Waiting is the number of requests currently held back
+	Waiting int
+	// InFlight is the number of requests currently waiting for a response
+	InFlight int
+	// TotalWait is the time all requests were held back
+	TotalWait time.Duration
+	// MaxWait is the longest time a request was held back
+	MaxWait time.Duration
+}
+
+// New returns the limits described by cfg. If cfg is nil, DefaultLimit
+// applies to every service. An error is returned for unknown service or
+// endpoint names and invalid limits.
+func New(cfg *Config) (*Limits, error) {
+	if cfg == nil {
+		cfg = &Config{Default: &DefaultLimit}
+	}
+	l := &Limits{
+		services:  map[string]*limiter{},
+		endpoints: map[string]*limiter{},
+		onWait:    cfg.OnWait,
+		stats:     map[string]*Stats{},
+	}
+	for name, lim := range cfg.Services {
+		if _, ok := methods[name]; !ok {
+			return nil, fmt.Errorf("unknown service %q", name)
+		}
+		if err := l.add(l.services, name, lim); err != nil {
+			return nil, err
+		}
+	}
+	for name, lim := range cfg.Endpoints {
+		svc, m, _ := strings.Cut(name, ".")
+		if !knownMethod(svc, m) {
+			return nil, fmt.Errorf("unknown endpoint %q, expected <service>.<method>", name)
+		}
+		if err := l.add(l.endpoints, name, lim); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Default != nil {
+		for name := range methods {
+			if _, ok := l.services[name]; !ok {
+				if err := l.add(l.services, name, cfg.Default); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return l, nil
+}
+
+func (l *Limits) add(m map[string]*limiter, name string, lim *Limit) error {
+	if lim == nil {
+		return nil
+	}
+	if lim.Rate < 0 || lim.Burst < 0 || lim.MaxInFlight < 0 || math.IsNaN(lim.Rate) || math.IsInf(lim.Rate, 0) {
+		return fmt.Errorf("invalid limit for %q: %+v", name, *lim)
+	}
+	m[name] = newLimiter(lim)
+	return nil
+}
+
+func knownMethod(svc, method string) bool {
+	for _, m := range methods[svc] {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// Acquire waits until a request to method of service may be sent. The
+// returned function must be called once the response was received. If ctx
+// is done first, its error is returned.
+func (l *Limits) Acquire(ctx context.Context, service, method string) (release func(), err error) {
+	key := service + "." + method
+	var ls []*limiter
+	if el := l.endpoints[key]; el != nil {
+		ls = append(ls, el)
+	}
+	if sl := l.services[service]; sl != nil {
+		ls = append(ls, sl)
+	}
+
+	st := l.begin(key)
+	start := now()
+	var held []*limiter
+	releaseAll := func() {
+		for _, lim := range held {
+			lim.release()
+		}
+	}
+	// Slots are taken before tokens, so requests waiting for a slot do not
+	// use up the rate.
+	for _, lim := range ls {
+		if err := lim.acquire(ctx); err != nil {
+			releaseAll()
+			l.end(st, now().Sub(start), false)
+			return nil, err
+		}
+		held = append(held, lim)
+	}
+	for _, lim := range ls {
+		if err := lim.wait(ctx); err != nil {
+			releaseAll()
+			l.end(st, now().Sub(start), false)
+			return nil, err
+		}
+	}
+	wait := now().Sub(start)
+	l.end(st, wait, true)
+	if l.onWait != nil {
+		l.onWait(ctx, service, method, wait)
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			releaseAll()
+			l.mu.Lock()
+			st.InFlight--
+			l.mu.Unlock()
+		})
+	}, nil
+}
+
+func (l *Limits) begin(key string) *Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	st := l.stats[key]
+	if st == nil {
+		st = &Stats{}
+		l.stats[key] = st
+	}
+	st.Waiting++
+	return st
+}
+
+func (l *Limits) end(st *Stats, wait time.Duration, sent bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	st.Waiting--
+	st.TotalWait += wait
+	st.MaxWait = max(st.MaxWait, wait)
+	if sent {
+		st.Requests++
+		st.InFlight++
+	}
+}
+
+// Stats returns the statistics of every endpoint used so far, keyed by
+// "<service>.<method>".
+func (l *Limits) Stats() map[string]Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	res := make(map[string]Stats, len(l.stats))
+	for k, st := range l.stats {
+		res[k] = *st
+	}
+	return res
+}
+
+// Endpoint returns an endpoint calling e once the limits of method of
+// service allow it. Its signature matches ivcap.Middleware, so it can be
+// passed to ivcap.NewClient directly. The request counts as in flight until
+// e returns; streamed response bodies are not accounted for.
+func (l *Limits) Endpoint(service, method string, e goa.Endpoint) goa.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		release, err := l.Acquire(ctx, service, method)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return e(ctx, req)
+	}
+}
+
+// now and newTimer give the time to the limiters. Tests replace them with a
+// fake clock.
+var (
+	now      = time.Now
+	newTimer = func(d time.Duration) (<-chan time.Time, func() bool) {
+		t := time.NewTimer(d)
+		return t.C, t.Stop
+	}
+)
+
+// limiter combines a token bucket with a semaphore.
+type limiter struct {
+	slots chan struct{}
+
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(lim *Limit) *limiter {
+	l := &limiter{rate: lim.Rate}
+	if lim.MaxInFlight > 0 {
+		l.slots = make(chan struct{}, lim.MaxInFlight)
+	}
+	if lim.Rate > 0 {
+		l.burst = float64(lim.Burst)
+		if l.burst == 0 {
+			l.burst = math.Max(1, math.Ceil(lim.Rate))
+		}
+		l.tokens = l.burst
+		l.last = now()
+	}
+	return l
+}
+
+// acquire takes a slot.
+func (l *limiter) acquire(ctx context.Context) error {
+	if l.slots == nil {
+		return nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release returns a slot.
+func (l *limiter) release() {
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+// wait takes a token, waiting for it to become available.
+func (l *limiter) wait(ctx context.Context) error {
+	if l.rate <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	t := now()
+	l.tokens = math.Min(l.burst, l.tokens+t.Sub(l.last).Seconds()*l.rate)
+	l.last = t
+	// Reserve the token, going into debt if there is none
+	l.tokens--
+	var d time.Duration
+	if l.tokens < 0 {
+		d = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+	if d == 0 {
+		return nil
+	}
+	c, stop := newTimer(d)
+	defer stop()
+	select {
+	case <-c:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,313 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock stands in for now and newTimer. Its time only moves on advance.
+type fakeClock struct {
+	mu     sync.Mutex
+	t      time.Time
+	timers map[*fakeTimer]bool
+}
+
+type fakeTimer struct {
+	at time.Time
+	c  chan time.Time
+}
+
+func useFakeClock(t *testing.T) *fakeClock {
+	c := &fakeClock{t: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), timers: map[*fakeTimer]bool{}}
+	origNow, origTimer := now, newTimer
+	now = c.now
+	newTimer = c.newTimer
+	t.Cleanup(func() { now, newTimer = origNow, origTimer })
+	return c
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) newTimer(d time.Duration) (<-chan time.Time, func() bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ft := &fakeTimer{at: c.t.Add(d), c: make(chan time.Time, 1)}
+	c.timers[ft] = true
+	return ft.c, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		active := c.timers[ft]
+		delete(c.timers, ft)
+		return active
+	}
+}
+
+// advance moves the time forward by d, firing the timers due.
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+	for ft := range c.timers {
+		if !ft.at.After(c.t) {
+			ft.c <- c.t
+			delete(c.timers, ft)
+		}
+	}
+}
+
+// waitTimers returns the delays of the pending timers once there are n.
+func (c *fakeClock) waitTimers(t *testing.T, n int) []time.Duration {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		c.mu.Lock()
+		if len(c.timers) == n {
+			var ds []time.Duration
+			for ft := range c.timers {
+				ds = append(ds, ft.at.Sub(c.t))
+			}
+			c.mu.Unlock()
+			return ds
+		}
+		c.mu.Unlock()
+	}
+	t.Fatalf("no %d pending timers", n)
+	return nil
+}
+
+// acquire calls l.Acquire for order.read in the background.
+func acquire(ctx context.Context, l *Limits) chan error {
+	done := make(chan error, 1)
+	go func() {
+		_, err := l.Acquire(ctx, "order", "read")
+		done <- err
+	}()
+	return done
+}
+
+// blocked fails the test if the call behind done returned.
+func blocked(t *testing.T, done chan error) {
+	t.Helper()
+	select {
+	case err := <-done:
+		t.Fatalf("returned %v while it should wait", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+// returned returns the result of the call behind done.
+func returned(t *testing.T, done chan error) error {
+	t.Helper()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("still waiting")
+		return nil
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	clock := useFakeClock(t)
+	l, err := New(&Config{Endpoints: map[string]*Limit{"order.read": {Rate: 2, Burst: 3}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for round := 0; round < 2; round++ {
+		// A full bucket lets a burst through at once.
+		for i := 0; i < 3; i++ {
+			if _, err := l.Acquire(ctx, "order", "read"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		done := acquire(ctx, l)
+		if ds := clock.waitTimers(t, 1); ds[0] != 500*time.Millisecond {
+			t.Errorf("round %d: waiting %v for a token, want 500ms", round, ds[0])
+		}
+		clock.advance(499 * time.Millisecond)
+		blocked(t, done)
+		clock.advance(time.Millisecond)
+		if err := returned(t, done); err != nil {
+			t.Fatal(err)
+		}
+		// The bucket refills up to the burst only.
+		clock.advance(time.Hour)
+	}
+	st := l.Stats()["order.read"]
+	if st.Requests != 8 || st.Waiting != 0 || st.MaxWait != 500*time.Millisecond || st.TotalWait != time.Second {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+func TestTokenBucketCanceled(t *testing.T) {
+	clock := useFakeClock(t)
+	l, err := New(&Config{Endpoints: map[string]*Limit{"order.read": {Rate: 1}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Acquire(context.Background(), "order", "read"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := acquire(ctx, l)
+	clock.waitTimers(t, 1)
+	cancel()
+	if err := returned(t, done); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	// The canceled request gave its token back: after a second there is
+	// one again.
+	clock.advance(time.Second)
+	if _, err := l.Acquire(context.Background(), "order", "read"); err != nil {
+		t.Fatal(err)
+	}
+	clock.waitTimers(t, 0)
+	if st := l.Stats()["order.read"]; st.Requests != 2 || st.Waiting != 0 || st.InFlight != 2 {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+func TestSemaphore(t *testing.T) {
+	useFakeClock(t)
+	l, err := New(&Config{Services: map[string]*Limit{"order": {MaxInFlight: 1}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	release, err := l.Acquire(context.Background(), "order", "list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The limit is shared by all endpoints of the service.
+	done := acquire(context.Background(), l)
+	blocked(t, done)
+	release()
+	release() // releasing twice has no effect
+	if err := returned(t, done); err != nil {
+		t.Fatal(err)
+	}
+
+	// Canceled while waiting for a slot.
+	ctx, cancel := context.WithCancel(context.Background())
+	done = acquire(ctx, l)
+	blocked(t, done)
+	cancel()
+	if err := returned(t, done); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if st := l.Stats()["order.read"]; st.Requests != 1 || st.Waiting != 0 || st.InFlight != 1 {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+func TestSemaphoreReleasedOnTokenWaitCanceled(t *testing.T) {
+	clock := useFakeClock(t)
+	l, err := New(&Config{Endpoints: map[string]*Limit{"order.read": {Rate: 1, MaxInFlight: 2}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	release, err := l.Acquire(context.Background(), "order", "read")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	// Holds a slot while waiting for a token.
+	ctx, cancel := context.WithCancel(context.Background())
+	done := acquire(ctx, l)
+	clock.waitTimers(t, 1)
+	cancel()
+	if err := returned(t, done); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if n := len(l.endpoints["order.read"].slots); n != 0 {
+		t.Errorf("%d slots still taken", n)
+	}
+}
+
+// doerFunc turns a function into a Doer.
+type doerFunc func(*http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestDoerReleases(t *testing.T) {
+	useFakeClock(t)
+	l, err := New(&Config{Services: map[string]*Limit{"order": {MaxInFlight: 1}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inFlight := func() int { return len(l.services["order"].slots) }
+	req, _ := http.NewRequest(http.MethodGet, "https://ivcap.test/1/orders", nil)
+
+	failing := l.Doer("order", "list", doerFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	}))
+	if _, err := failing.Do(req); err == nil {
+		t.Fatal("no error")
+	}
+	if n := inFlight(); n != 0 {
+		t.Errorf("failed request holds %d slots", n)
+	}
+
+	ok := l.Doer("order", "list", doerFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("{}")), Request: req}, nil
+	}))
+	resp, err := ok.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := inFlight(); n != 1 {
+		t.Errorf("request holds %d slots before its body is closed, want 1", n)
+	}
+	resp.Body.Close()
+	if n := inFlight(); n != 0 {
+		t.Errorf("request holds %d slots after its body is closed", n)
+	}
+
+	e := l.Endpoint("order", "list", func(context.Context, interface{}) (interface{}, error) {
+		return nil, errors.New("bad request")
+	})
+	if _, err := e(context.Background(), nil); err == nil {
+		t.Fatal("no error")
+	}
+	if n := inFlight(); n != 0 {
+		t.Errorf("failed endpoint holds %d slots", n)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	for _, cfg := range []*Config{
+		{Services: map[string]*Limit{"orders": {Rate: 1}}},
+		{Endpoints: map[string]*Limit{"order": {Rate: 1}}},
+		{Endpoints: map[string]*Limit{"order.delete": {Rate: 1}}},
+		{Endpoints: map[string]*Limit{"order.read": {Rate: -1}}},
+		{Services: map[string]*Limit{"order": {MaxInFlight: -1}}},
+		{Default: &Limit{Burst: -1}},
+	} {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("New(%+v) accepted", cfg)
+		}
+	}
+}
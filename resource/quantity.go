@@ -0,0 +1,328 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package resource implements the Kubernetes resource quantities used to
+// describe the CPU, memory and storage requests and limits of services, and
+// returned by the order "top" method, such as "100Mi" or "250m".
+package resource
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Format is the notation a quantity was written in.
+type Format string
+
+const (
+	// BinarySI uses power of two suffixes, e.g. "Ki", "Mi" or "Gi".
+	BinarySI Format = "BinarySI"
+	// DecimalSI uses power of ten suffixes, e.g. "m", "k" or "M".
+	DecimalSI Format = "DecimalSI"
+	// DecimalExponent uses an exponent, e.g. "1e3".
+	DecimalExponent Format = "DecimalExponent"
+)
+
+// Quantity is a fixed point number with a precision of 10^-9 (one "n"), the
+// smallest unit Kubernetes quantities can express. The zero value is zero.
+type Quantity struct {
+	// nanos holds the value in units of 10^-9, nil means zero
+	nanos  *big.Int
+	format Format
+}
+
+// QuantityError is returned when a string is not a valid quantity.
+type QuantityError struct {
+	Value  string
+	Reason string
+}
+
+// Error returns an error description.
+func (e *QuantityError) Error() string {
+	return fmt.Sprintf("invalid quantity %q: %s", e.Value, e.Reason)
+}
+
+var (
+	nano = big.NewInt(1_000_000_000)
+
+	binarySuffixes = map[string]int64{
+		"Ki": 1 << 10, "Mi": 1 << 20, "Gi": 1 << 30, "Ti": 1 << 40, "Pi": 1 << 50, "Ei": 1 << 60,
+	}
+	decimalSuffixes = map[string]int{
+		"n": -9, "u": -6, "m": -3, "": 0, "k": 3, "M": 6, "G": 9, "T": 12, "P": 15, "E": 18,
+	}
+)
+
+// ParseQuantity parses s, e.g. "100Mi", "0.5", "250m" or "1e3". Values finer
+// than 1n are rounded up, as Kubernetes does.
+func ParseQuantity(s string) (Quantity, error) {
+	if s == "" {
+		return Quantity{}, &QuantityError{s, "empty"}
+	}
+	num, suffix := splitQuantity(s)
+	if !strings.ContainsAny(num, "0123456789") {
+		return Quantity{}, &QuantityError{s, "missing number"}
+	}
+	v, ok := new(big.Rat).SetString(num)
+	if !ok {
+		return Quantity{}, &QuantityError{s, "malformed number"}
+	}
+
+	var format Format
+	if m, ok := binarySuffixes[suffix]; ok {
+		format = BinarySI
+		v.Mul(v, new(big.Rat).SetInt64(m))
+	} else if exp, ok := decimalSuffixes[suffix]; ok {
+		format = DecimalSI
+		v.Mul(v, pow10(exp))
+	} else if suffix[0] == 'e' || suffix[0] == 'E' {
+		format = DecimalExponent
+		exp, err := strconv.Atoi(suffix[1:])
+		if err != nil {
+			return Quantity{}, &QuantityError{s, fmt.Sprintf("malformed exponent %q", suffix)}
+		}
+		if exp > 100 || exp < -100 {
+			return Quantity{}, &QuantityError{s, "exponent out of range"}
+		}
+		v.Mul(v, pow10(exp))
+	} else {
+		return Quantity{}, &QuantityError{s, fmt.Sprintf("unknown suffix %q", suffix)}
+	}
+
+	v.Mul(v, new(big.Rat).SetInt(nano))
+	n := new(big.Int).Quo(v.Num(), v.Denom())
+	if new(big.Int).Mul(n, v.Denom()).Cmp(v.Num()) != 0 && v.Sign() > 0 {
+		n.Add(n, big.NewInt(1))
+	}
+	return Quantity{nanos: n, format: format}, nil
+}
+
+// MustParse is like ParseQuantity but panics if s is not a valid quantity.
+func MustParse(s string) Quantity {
+	q, err := ParseQuantity(s)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// Format returns the notation q was parsed from.
+func (q Quantity) Format() Format {
+	return q.format
+}
+
+// IsZero returns true if q is zero.
+func (q Quantity) IsZero() bool {
+	return q.nanos == nil || q.nanos.Sign() == 0
+}
+
+// Sign returns -1, 0 or 1 depending on the sign of q.
+func (q Quantity) Sign() int {
+	if q.nanos == nil {
+		return 0
+	}
+	return q.nanos.Sign()
+}
+
+// Float64 returns q as a float, e.g. 0.25 for "250m". Large values may lose
+// precision.
+func (q Quantity) Float64() float64 {
+	if q.nanos == nil {
+		return 0
+	}
+	f, _ := new(big.Rat).SetFrac(q.nanos, nano).Float64()
+	return f
+}
+
+// Value returns q rounded up to a whole number, e.g. 1 for "250m". Values
+// outside the range of an int64 are clamped.
+func (q Quantity) Value() int64 {
+	return q.scaled(nano)
+}
+
+// MilliValue returns q in units of 10^-3 rounded up, e.g. 250 for "250m".
+// Values outside the range of an int64 are clamped.
+func (q Quantity) MilliValue() int64 {
+	return q.scaled(big.NewInt(1_000_000))
+}
+
+// scaled returns q divided by 10^-9 * d, rounded up.
+func (q Quantity) scaled(d *big.Int) int64 {
+	if q.nanos == nil {
+		return 0
+	}
+	n, r := new(big.Int).QuoRem(q.nanos, d, new(big.Int))
+	if r.Sign() > 0 {
+		n.Add(n, big.NewInt(1))
+	}
+	switch {
+	case n.IsInt64():
+		return n.Int64()
+	case n.Sign() > 0:
+		return math.MaxInt64
+	default:
+		return math.MinInt64
+	}
+}
+
+// String formats q in the notation it was parsed from, using the largest
+// suffix which represents q exactly, e.g. "1536Mi" for "1.5Gi". Binary
+// quantities which are not a whole multiple of 1Ki are written as decimal.
+func (q Quantity) String() string {
+	n := q.int()
+	if n.Sign() == 0 {
+		return "0"
+	}
+	if q.format == BinarySI {
+		if v, r := new(big.Int).QuoRem(n, nano, new(big.Int)); r.Sign() == 0 {
+			for _, sfx := range []string{"Ei", "Pi", "Ti", "Gi", "Mi", "Ki"} {
+				m, r := new(big.Int).QuoRem(v, big.NewInt(binarySuffixes[sfx]), new(big.Int))
+				if r.Sign() == 0 {
+					return m.String() + sfx
+				}
+			}
+		}
+	}
+	// Largest power of ten, in steps of three, dividing n
+	exp := -9
+	for exp < 18 && new(big.Int).Rem(n, pow10Int(exp+3+9)).Sign() == 0 {
+		exp += 3
+	}
+	m := new(big.Int).Quo(n, pow10Int(exp+9))
+	if q.format == DecimalExponent {
+		if exp == 0 {
+			return m.String()
+		}
+		return m.String() + "e" + strconv.Itoa(exp)
+	}
+	return m.String() + decimalSuffix(exp)
+}
+
+// Cmp compares q and other and returns -1, 0 or +1 depending on whether q is
+// less than, equal to or greater than other.
+func (q Quantity) Cmp(other Quantity) int {
+	return q.int().Cmp(other.int())
+}
+
+// Equal returns true if q and other have the same value, regardless of their
+// format.
+func (q Quantity) Equal(other Quantity) bool {
+	return q.Cmp(other) == 0
+}
+
+// Add returns q + other in the format of q.
+func (q Quantity) Add(other Quantity) Quantity {
+	return q.with(new(big.Int).Add(q.int(), other.int()), other)
+}
+
+// Sub returns q - other in the format of q.
+func (q Quantity) Sub(other Quantity) Quantity {
+	return q.with(new(big.Int).Sub(q.int(), other.int()), other)
+}
+
+// Mul returns q * n.
+func (q Quantity) Mul(n int64) Quantity {
+	return q.with(new(big.Int).Mul(q.int(), big.NewInt(n)), q)
+}
+
+// Neg returns -q.
+func (q Quantity) Neg() Quantity {
+	return q.with(new(big.Int).Neg(q.int()), q)
+}
+
+// MarshalText implements encoding.TextMarshaler, so quantities are written as
+// strings in JSON.
+func (q Quantity) MarshalText() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (q *Quantity) UnmarshalText(b []byte) error {
+	v, err := ParseQuantity(string(b))
+	if err != nil {
+		return err
+	}
+	*q = v
+	return nil
+}
+
+// int returns the value of q in nanos.
+func (q Quantity) int() *big.Int {
+	if q.nanos == nil {
+		return new(big.Int)
+	}
+	return q.nanos
+}
+
+// with returns a quantity of value n in the format of q, or of other if q is
+// the zero value.
+func (q Quantity) with(n *big.Int, other Quantity) Quantity {
+	f := q.format
+	if f == "" {
+		f = other.format
+	}
+	return Quantity{nanos: n, format: f}
+}
+
+// decimalSuffix returns the suffix for 10^exp.
+func decimalSuffix(exp int) string {
+	for sfx, e := range decimalSuffixes {
+		if e == exp {
+			return sfx
+		}
+	}
+	return "e" + strconv.Itoa(exp)
+}
+
+// splitQuantity splits s into its number and suffix.
+func splitQuantity(s string) (num, suffix string) {
+	i := 0
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+	}
+	return s[:i], s[i:]
+}
+
+// pow10 returns 10^exp.
+func pow10(exp int) *big.Rat {
+	p := pow10Int(abs(exp))
+	if exp < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), p)
+	}
+	return new(big.Rat).SetInt(p)
+}
+
+// pow10Int returns 10^exp for exp >= 0.
+func pow10Int(exp int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
@@ -0,0 +1,138 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseQuantity(t *testing.T) {
+	for _, tc := range []struct {
+		in     string
+		milli  int64
+		format Format
+		str    string
+	}{
+		{"1", 1000, DecimalSI, "1"},
+		{"250m", 250, DecimalSI, "250m"},
+		{"0.5", 500, DecimalSI, "500m"},
+		{"1000", 1_000_000, DecimalSI, "1k"},
+		{"1.5k", 1_500_000, DecimalSI, "1500"},
+		{"100Mi", 100 << 20 * 1000, BinarySI, "100Mi"},
+		{"1.5Gi", 1536 << 20 * 1000, BinarySI, "1536Mi"},
+		{"0.5Ki", 512_000, BinarySI, "512"},
+		{"1e3", 1_000_000, DecimalExponent, "1e3"},
+		{"1.5E3", 1_500_000, DecimalExponent, "1500"},
+		{"2e-3", 2, DecimalExponent, "2e-3"},
+		{"+3", 3000, DecimalSI, "3"},
+		{"-250m", -250, DecimalSI, "-250m"},
+		{"1n", 1, DecimalSI, "1n"},
+		{"0", 0, DecimalSI, "0"},
+	} {
+		q, err := ParseQuantity(tc.in)
+		if err != nil {
+			t.Errorf("ParseQuantity(%q): %v", tc.in, err)
+			continue
+		}
+		if got := q.MilliValue(); got != tc.milli {
+			t.Errorf("ParseQuantity(%q).MilliValue() = %d, want %d", tc.in, got, tc.milli)
+		}
+		if got := q.Format(); got != tc.format {
+			t.Errorf("ParseQuantity(%q).Format() = %s, want %s", tc.in, got, tc.format)
+		}
+		if got := q.String(); got != tc.str {
+			t.Errorf("ParseQuantity(%q).String() = %q, want %q", tc.in, got, tc.str)
+		}
+		// String must parse back to the same value.
+		if back, err := ParseQuantity(q.String()); err != nil || !back.Equal(q) {
+			t.Errorf("ParseQuantity(%q) = %v, %v, want %v", q.String(), back, err, q)
+		}
+	}
+}
+
+func TestParseQuantityErrors(t *testing.T) {
+	for _, tc := range []struct{ in, reason string }{
+		{"", "empty"},
+		{"Mi", "missing number"},
+		{"-", "missing number"},
+		{"1.2.3", "unknown suffix"},
+		{"1e", "malformed exponent"},
+		{"1ex", "malformed exponent"},
+		{"1e1000", "exponent out of range"},
+		{"1Gb", "unknown suffix"},
+		{"1 Gi", "unknown suffix"},
+	} {
+		_, err := ParseQuantity(tc.in)
+		var qerr *QuantityError
+		if !errors.As(err, &qerr) || !strings.Contains(qerr.Reason, tc.reason) {
+			t.Errorf("ParseQuantity(%q) = %v, want a QuantityError about %q", tc.in, err, tc.reason)
+		}
+	}
+}
+
+func TestRounding(t *testing.T) {
+	q := MustParse("0.1n")
+	if q.Cmp(MustParse("1n")) != 0 {
+		t.Errorf("0.1n = %s, want it rounded up to 1n", q)
+	}
+	if v := MustParse("250m").Value(); v != 1 {
+		t.Errorf("250m.Value() = %d, want 1", v)
+	}
+}
+
+func TestCmp(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"1", "1000m", 0},
+		{"1Ki", "1024", 0},
+		{"1k", "1Ki", -1},
+		{"1Gi", "1G", 1},
+		{"500m", "0.5", 0},
+		{"1e3", "1k", 0},
+		{"-1", "0", -1},
+		{"0", "0Mi", 0},
+	} {
+		if got := MustParse(tc.a).Cmp(MustParse(tc.b)); got != tc.want {
+			t.Errorf("Cmp(%s, %s) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+		if got := MustParse(tc.b).Cmp(MustParse(tc.a)); got != -tc.want {
+			t.Errorf("Cmp(%s, %s) = %d, want %d", tc.b, tc.a, got, -tc.want)
+		}
+	}
+	var zero Quantity
+	if zero.Cmp(MustParse("0")) != 0 || !zero.IsZero() {
+		t.Error("the zero value is not zero")
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	if got := MustParse("1Gi").Add(MustParse("512Mi")).String(); got != "1536Mi" {
+		t.Errorf("1Gi + 512Mi = %s", got)
+	}
+	if got := MustParse("1").Sub(MustParse("250m")).String(); got != "750m" {
+		t.Errorf("1 - 250m = %s", got)
+	}
+	if got := MustParse("100Mi").Mul(3).String(); got != "300Mi" {
+		t.Errorf("100Mi * 3 = %s", got)
+	}
+	var zero Quantity
+	if got := zero.Add(MustParse("1Ki")); got.Format() != BinarySI || got.String() != "1Ki" {
+		t.Errorf("0 + 1Ki = %s in %s", got, got.Format())
+	}
+}
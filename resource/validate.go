@@ -0,0 +1,58 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	goa "goa.design/goa/v3/pkg"
+)
+
+// FormatQuantity is the goa format name used in validation errors.
+const FormatQuantity goa.Format = "quantity"
+
+// ValidateRequirements checks the request and limit of the resource at path
+// name, e.g. "body.workflow.basic.cpu". Both are optional, but must be valid,
+// non-negative quantities if set, and the request must not exceed the limit.
+// Errors are reported as goa validation errors.
+func ValidateRequirements(name string, request, limit *string) (err error) {
+	var (
+		req, lim     Quantity
+		reqOK, limOK bool
+	)
+	if request != nil {
+		req, reqOK, err = validateQuantity(err, name+".request", *request)
+	}
+	if limit != nil {
+		lim, limOK, err = validateQuantity(err, name+".limit", *limit)
+	}
+	if reqOK && limOK && req.Cmp(lim) > 0 {
+		err = goa.MergeErrors(err, goa.PermanentError(goa.InvalidRange,
+			"%s.request must be lesser or equal than %s.limit (%s) but got value %q", name, name, *limit, *request))
+	}
+	return
+}
+
+// validateQuantity parses the quantity s at path name and merges any problem
+// into err.
+func validateQuantity(err error, name string, s string) (Quantity, bool, error) {
+	q, perr := ParseQuantity(s)
+	if perr != nil {
+		return q, false, goa.MergeErrors(err, goa.InvalidFormatError(name, s, FormatQuantity, perr))
+	}
+	if q.Sign() < 0 {
+		return q, false, goa.MergeErrors(err, goa.PermanentError(goa.InvalidRange,
+			"%s must be greater or equal than 0 but got value %q", name, s))
+	}
+	return q, true, err
+}
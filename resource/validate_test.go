@@ -0,0 +1,49 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateRequirements(t *testing.T) {
+	str := func(s string) *string { return &s }
+	for _, tc := range []struct {
+		name           string
+		request, limit *string
+		want           string
+	}{
+		{"none", nil, nil, ""},
+		{"request only", str("100m"), nil, ""},
+		{"limit only", nil, str("1Gi"), ""},
+		{"request below limit", str("500Mi"), str("1Gi"), ""},
+		{"request equal to limit", str("1Gi"), str("1024Mi"), ""},
+		{"request above limit", str("2"), str("1500m"), `cpu.request must be lesser or equal than cpu.limit (1500m) but got value "2"`},
+		{"malformed request", str("lots"), str("1"), `cpu.request`},
+		{"malformed limit", str("1"), str("1 cpu"), `cpu.limit`},
+		{"negative limit", nil, str("-1"), `cpu.limit must be greater or equal than 0`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateRequirements("cpu", tc.request, tc.limit)
+			switch {
+			case tc.want == "" && err != nil:
+				t.Errorf("error = %v", err)
+			case tc.want != "" && (err == nil || !strings.Contains(err.Error(), tc.want)):
+				t.Errorf("error = %v, want one containing %q", err, tc.want)
+			}
+		})
+	}
+}
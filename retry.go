@@ -0,0 +1,211 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	goahttp "goa.design/goa/v3/http"
+)
+
+// IdempotencyKeyHeader is the request header carrying an idempotency key.
+// Requests with such a key are safe to retry, as the service performs them
+// at most once.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// RetryPolicy controls which requests NewRetryDoer retries and how long it
+// waits in between.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries of a request. Defaults to
+	// 3; set to a negative value to disable retries.
+	MaxRetries int
+	// InitialBackoff is the delay before the first retry. Defaults to 500ms.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between retries, including delays asked for
+	// by a Retry-After header. Defaults to 30s.
+	MaxBackoff time.Duration
+	// Multiplier grows the delay after every retry. Defaults to 2.
+	Multiplier float64
+	// MaxBufferedBody is the size up to which request bodies are kept in
+	// memory so they can be sent again. Requests with larger bodies are only
+	// retried if they provide GetBody. Defaults to 1MiB.
+	MaxBufferedBody int64
+	// Safe returns true if req may be sent more than once. Defaults to
+	// IsSafeRequest.
+	Safe func(req *http.Request) bool
+}
+
+// retryDoer retries requests failing with a transient error.
+type retryDoer struct {
+	doer   goahttp.Doer
+	policy RetryPolicy
+}
+
+// NewRetryDoer returns a Doer sending requests through doer and retrying them
+// with exponential backoff if they fail with a network error or a 429, 502,
+// 503 or 504 response. A Retry-After header in the response is honoured. Only
+// requests policy.Safe accepts are retried, by default those reading data and
+// those carrying an idempotency key. policy may be nil.
+func NewRetryDoer(doer goahttp.Doer, policy *RetryPolicy) goahttp.Doer {
+	if doer == nil {
+		doer = http.DefaultClient
+	}
+	var p RetryPolicy
+	if policy != nil {
+		p = *policy
+	}
+	if p.MaxRetries == 0 {
+		p.MaxRetries = 3
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = 500 * time.Millisecond
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = 30 * time.Second
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = 2
+	}
+	if p.MaxBufferedBody <= 0 {
+		p.MaxBufferedBody = 1 << 20
+	}
+	if p.Safe == nil {
+		p.Safe = IsSafeRequest
+	}
+	return &retryDoer{doer: doer, policy: p}
+}
+
+// IsSafeRequest returns true if sending req more than once has no other
+// effect than sending it once. This is the case for GET, HEAD and OPTIONS
+// requests, the order "logs" and "top" methods, which are POST requests only
+// reading data, and requests carrying an idempotency key.
+func IsSafeRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	case http.MethodPost:
+		switch strings.TrimSuffix(req.URL.Path, "/") {
+		case "/1/orders/logs", "/1/orders/top":
+			return true
+		}
+	}
+	return req.Header.Get(IdempotencyKeyHeader) != ""
+}
+
+// Do sends req, retrying it as long as the policy allows.
+func (d *retryDoer) Do(req *http.Request) (*http.Response, error) {
+	p := &d.policy
+	if p.MaxRetries < 0 || !p.Safe(req) {
+		return d.doer.Do(req)
+	}
+	getBody, err := replayableBody(req, p.MaxBufferedBody)
+	if err != nil {
+		return nil, err
+	}
+	backoff := p.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		resp, err := d.doer.Do(req)
+		if attempt >= p.MaxRetries || getBody == nil || !shouldRetry(req, resp, err) {
+			return resp, err
+		}
+		delay := jitter(backoff, 0.2)
+		if resp != nil {
+			if ra, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+				delay = ra
+			}
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		if err := sleep(req.Context(), min(delay, p.MaxBackoff)); err != nil {
+			return nil, err
+		}
+		backoff = min(time.Duration(float64(backoff)*p.Multiplier), p.MaxBackoff)
+
+		next := req.Clone(req.Context())
+		if next.Body, err = getBody(); err != nil {
+			return nil, err
+		}
+		req = next
+	}
+}
+
+// replayableBody returns a function returning a fresh copy of the body of
+// req. If req has no GetBody, a body of up to limit bytes is read into memory
+// and req.Body replaced. It returns nil if the body cannot be replayed.
+func replayableBody(req *http.Request, limit int64) (func() (io.ReadCloser, error), error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return func() (io.ReadCloser, error) { return http.NoBody, nil }, nil
+	}
+	if req.GetBody != nil {
+		return req.GetBody, nil
+	}
+	if req.ContentLength > limit {
+		return nil, nil
+	}
+	b, err := io.ReadAll(io.LimitReader(req.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > limit {
+		// Too large, send it once as is
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(b), req.Body), req.Body}
+		return nil, nil
+	}
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}
+	return req.GetBody, nil
+}
+
+// shouldRetry returns true if the outcome of sending req is a transient
+// failure.
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses the value of a Retry-After header, which is either a
+// number of seconds or an HTTP date.
+func retryAfter(h string) (time.Duration, bool) {
+	if h == "" {
+		return 0, false
+	}
+	if s, err := strconv.Atoi(strings.TrimSpace(h)); err == nil && s >= 0 {
+		return time.Duration(s) * time.Second, true
+	}
+	if t, err := http.ParseTime(h); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,263 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordSleeps makes sleep return at once, recording the delays asked for.
+func recordSleeps(t *testing.T) *[]time.Duration {
+	var delays []time.Duration
+	orig := sleep
+	sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return ctx.Err()
+	}
+	t.Cleanup(func() { sleep = orig })
+	return &delays
+}
+
+// scriptDoer answers the requests it receives with the next of its
+// responses, given as status codes, or with err for a status of 0. It keeps
+// the bodies received.
+type scriptDoer struct {
+	statuses []int
+	header   http.Header
+	err      error
+	bodies   []string
+}
+
+func (d *scriptDoer) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+	}
+	d.bodies = append(d.bodies, string(body))
+	status := d.statuses[min(len(d.bodies), len(d.statuses))-1]
+	if status == 0 {
+		return nil, d.err
+	}
+	return &http.Response{StatusCode: status, Header: d.header, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+}
+
+func newRequest(t *testing.T, method, path string, body io.Reader) *http.Request {
+	req, err := http.NewRequest(method, "https://ivcap.test"+path, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func TestIsSafeRequest(t *testing.T) {
+	for _, tc := range []struct {
+		method, path, key string
+		want              bool
+	}{
+		{http.MethodGet, "/1/orders", "", true},
+		{http.MethodHead, "/1/artifacts/1/blob", "", true},
+		{http.MethodOptions, "/1/orders", "", true},
+		{http.MethodPost, "/1/orders", "", false},
+		{http.MethodPost, "/1/orders/logs", "", true},
+		{http.MethodPost, "/1/orders/top/", "", true},
+		{http.MethodPost, "/1/orders", "k1", true},
+		{http.MethodPut, "/1/services/1", "", false},
+		{http.MethodDelete, "/1/services/1", "", false},
+		{http.MethodDelete, "/1/services/1", "k1", true},
+		{http.MethodPatch, "/1/artifacts/1/blob", "", false},
+	} {
+		req := newRequest(t, tc.method, tc.path, nil)
+		if tc.key != "" {
+			req.Header.Set(IdempotencyKeyHeader, tc.key)
+		}
+		if got := IsSafeRequest(req); got != tc.want {
+			t.Errorf("IsSafeRequest(%s %s, key %q) = %v, want %v", tc.method, tc.path, tc.key, got, tc.want)
+		}
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	delays := recordSleeps(t)
+	d := &scriptDoer{statuses: []int{503, 502, 504, 429, 200}}
+	policy := &RetryPolicy{MaxRetries: 4, InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+	resp, err := NewRetryDoer(d, policy).Do(newRequest(t, http.MethodGet, "/1/orders", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 200 || len(d.bodies) != 5 {
+		t.Fatalf("status %d after %d attempts, want 200 after 5", resp.StatusCode, len(d.bodies))
+	}
+	// The backoff doubles up to MaxBackoff and is randomized by 20%, but
+	// never exceeds MaxBackoff.
+	for i, base := range []time.Duration{100, 200, 300, 300} {
+		base *= time.Millisecond
+		lo, hi := base*8/10, min(base*12/10, policy.MaxBackoff)
+		if got := (*delays)[i]; got < lo || got > hi {
+			t.Errorf("delay %d = %v, want between %v and %v", i, got, lo, hi)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	for _, tc := range []struct {
+		header string
+		max    time.Duration
+		want   time.Duration
+	}{
+		{"2", time.Minute, 2 * time.Second},
+		{" 0 ", time.Minute, 0},
+		{"120", time.Minute, time.Minute},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), time.Minute, 0},
+	} {
+		delays := recordSleeps(t)
+		d := &scriptDoer{statuses: []int{429, 200}, header: http.Header{"Retry-After": {tc.header}}}
+		policy := &RetryPolicy{InitialBackoff: time.Millisecond, MaxBackoff: tc.max}
+		if _, err := NewRetryDoer(d, policy).Do(newRequest(t, http.MethodGet, "/1/orders", nil)); err != nil {
+			t.Fatal(err)
+		}
+		if len(*delays) != 1 || (*delays)[0] != tc.want {
+			t.Errorf("Retry-After %q: delays %v, want [%v]", tc.header, *delays, tc.want)
+		}
+	}
+
+	// An HTTP date is relative to now.
+	d, ok := retryAfter(time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat))
+	if !ok || d <= 8*time.Second || d > 10*time.Second {
+		t.Errorf("Retry-After in 10s = %v, %v", d, ok)
+	}
+	if _, ok := retryAfter("soon"); ok {
+		t.Error("accepted Retry-After: soon")
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	errDown := errors.New("connection refused")
+	for _, tc := range []struct {
+		name     string
+		req      func() *http.Request
+		doer     *scriptDoer
+		policy   *RetryPolicy
+		attempts int
+	}{
+		{
+			"max retries",
+			func() *http.Request { return newRequest(t, http.MethodGet, "/1/orders", nil) },
+			&scriptDoer{statuses: []int{503}},
+			&RetryPolicy{MaxRetries: 2},
+			3,
+		},
+		{
+			"disabled",
+			func() *http.Request { return newRequest(t, http.MethodGet, "/1/orders", nil) },
+			&scriptDoer{statuses: []int{503}},
+			&RetryPolicy{MaxRetries: -1},
+			1,
+		},
+		{
+			"not transient",
+			func() *http.Request { return newRequest(t, http.MethodGet, "/1/orders", nil) },
+			&scriptDoer{statuses: []int{500, 200}},
+			nil,
+			1,
+		},
+		{
+			"not safe",
+			func() *http.Request { return newRequest(t, http.MethodPost, "/1/orders", strings.NewReader("{}")) },
+			&scriptDoer{statuses: []int{503, 200}},
+			nil,
+			1,
+		},
+		{
+			"network error",
+			func() *http.Request { return newRequest(t, http.MethodGet, "/1/orders", nil) },
+			&scriptDoer{statuses: []int{0}, err: errDown},
+			&RetryPolicy{MaxRetries: 1},
+			2,
+		},
+		{
+			"canceled",
+			func() *http.Request {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return newRequest(t, http.MethodGet, "/1/orders", nil).WithContext(ctx)
+			},
+			&scriptDoer{statuses: []int{0}, err: context.Canceled},
+			nil,
+			1,
+		},
+	} {
+		recordSleeps(t)
+		NewRetryDoer(tc.doer, tc.policy).Do(tc.req())
+		if len(tc.doer.bodies) != tc.attempts {
+			t.Errorf("%s: %d attempts, want %d", tc.name, len(tc.doer.bodies), tc.attempts)
+		}
+	}
+}
+
+// onlyReader hides the type of the reader it wraps, so that
+// http.NewRequest does not set GetBody.
+type onlyReader struct {
+	io.Reader
+}
+
+func TestRetryBody(t *testing.T) {
+	const limit = 1 << 20
+	full := bytes.Repeat([]byte("x"), limit)
+	for _, tc := range []struct {
+		name     string
+		body     io.Reader
+		size     int
+		attempts int
+	}{
+		{"buffered", onlyReader{bytes.NewReader(full)}, limit, 2},
+		{"too large", onlyReader{io.MultiReader(bytes.NewReader(full), strings.NewReader("y"))}, limit + 1, 1},
+		// http.NewRequest sets GetBody for a bytes.Reader.
+		{"replayable", bytes.NewReader(append(full, 'y')), limit + 1, 2},
+	} {
+		recordSleeps(t)
+		d := &scriptDoer{statuses: []int{503, 200}}
+		req := newRequest(t, http.MethodPost, "/1/orders", tc.body)
+		req.Header.Set(IdempotencyKeyHeader, "k1")
+		resp, err := NewRetryDoer(d, nil).Do(req)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if len(d.bodies) != tc.attempts {
+			t.Errorf("%s: %d attempts, want %d", tc.name, len(d.bodies), tc.attempts)
+		}
+		want := 200
+		if tc.attempts == 1 {
+			want = 503
+		}
+		if resp.StatusCode != want {
+			t.Errorf("%s: status %d, want %d", tc.name, resp.StatusCode, want)
+		}
+		for i, b := range d.bodies {
+			if len(b) != tc.size {
+				t.Errorf("%s: attempt %d sent %d bytes, want %d", tc.name, i+1, len(b), tc.size)
+			}
+		}
+	}
+}
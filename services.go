@@ -0,0 +1,115 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"context"
+	"iter"
+
+	service "github.com/reinventingscience/ivcap-core-api/gen/service"
+	servicec "github.com/reinventingscience/ivcap-core-api/http/service"
+	"github.com/reinventingscience/ivcap-core-api/ivcaperr"
+)
+
+// ServicesClient provides typed access to the service service.
+type ServicesClient struct {
+	client *service.Client
+}
+
+// List returns a single page of services. p may be nil, in which case the
+// service defaults apply. A malformed filter or order-by is rejected before
+// the request is sent with an error wrapping the *query.SyntaxError.
+func (c *ServicesClient) List(ctx context.Context, p *service.ListPayload) (*service.ServiceListRT, error) {
+	var lp service.ListPayload
+	if p != nil {
+		lp = *p
+	}
+	if lp.Limit == 0 {
+		lp.Limit = 10
+	}
+	if err := checkListQuery(lp.Filter, lp.OrderBy); err != nil {
+		return nil, err
+	}
+	return c.client.List(ctx, &lp)
+}
+
+// ListAll returns an iterator over all services matching p, following the
+// "next" links of the returned pages. At most maxItems services are returned,
+// or all of them if maxItems is zero. p may be nil.
+func (c *ServicesClient) ListAll(ctx context.Context, p *service.ListPayload, maxItems int) iter.Seq2[*service.ServiceListItem, error] {
+	fetch := func(ctx context.Context, page *string) ([]*service.ServiceListItem, *string, error) {
+		var lp service.ListPayload
+		if p != nil {
+			lp = *p
+		}
+		if page != nil {
+			lp.Page = page
+		}
+		res, err := c.List(ctx, &lp)
+		if err != nil {
+			return nil, nil, err
+		}
+		if res.Links == nil {
+			return res.Services, nil, nil
+		}
+		return res.Services, res.Links.Next, nil
+	}
+	return NewPager(fetch, maxItems).All(ctx)
+}
+
+// Create registers a new service and returns its status. Malformed resource
+// requests and limits, and Argo workflows failing argo.ValidateService, are
+// rejected before the request is sent with an *ivcaperr.Error wrapping a
+// *service.InvalidParameterValue.
+func (c *ServicesClient) Create(ctx context.Context, desc *service.ServiceDescriptionT) (*service.ServiceStatusRT, error) {
+	if err := checkWorkflow("create_service", desc); err != nil {
+		return nil, err
+	}
+	return c.client.CreateService(ctx, &service.CreateServicePayload{Services: desc})
+}
+
+// Read returns the status of service id.
+func (c *ServicesClient) Read(ctx context.Context, id string) (*service.ServiceStatusRT, error) {
+	return c.client.Read(ctx, &service.ReadPayload{ID: id})
+}
+
+// Update replaces the description of service id. If forceCreate is set, the
+// service is created when it does not exist yet. The workflow is checked as
+// in Create.
+func (c *ServicesClient) Update(ctx context.Context, id string, desc *service.ServiceDescriptionT, forceCreate bool) (*service.ServiceStatusRT, error) {
+	if err := checkWorkflow("update", desc); err != nil {
+		return nil, err
+	}
+	return c.client.Update(ctx, &service.UpdatePayload{
+		ID:          &id,
+		ForceCreate: &forceCreate,
+		Services:    desc,
+	})
+}
+
+// Delete removes service id.
+func (c *ServicesClient) Delete(ctx context.Context, id string) error {
+	return c.client.Delete(ctx, &service.DeletePayload{ID: id})
+}
+
+// checkWorkflow validates the resource requests and limits of a basic
+// workflow and the definition of an Argo workflow for a call of method, see
+// servicec.ValidateDescription.
+func checkWorkflow(method string, desc *service.ServiceDescriptionT) error {
+	if err := servicec.ValidateDescription("", desc); err != nil {
+		return ivcaperr.Wrap("service", method, "", &service.InvalidParameterValue{Name: "workflow", Message: err.Error()})
+	}
+	return nil
+}
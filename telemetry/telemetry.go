@@ -0,0 +1,242 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package telemetry instruments the IVCAP clients with OpenTelemetry. Every
+// call of a service method is recorded as a span named
+// "ivcap.<service>.<method>", the W3C trace context is propagated to the
+// services and the latency and errors of each method are recorded as
+// metrics:
+//
+//	tel, err := telemetry.New(nil)
+//	...
+//	c, err := ivcap.NewClient(url, ts, tel.Doer(nil), tel.Endpoint)
+//
+// Instrumentation is opt-in, a client created without it records nothing.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/reinventingscience/ivcap-core-api/ivcaperr"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	goahttp "goa.design/goa/v3/http"
+	goa "goa.design/goa/v3/pkg"
+)
+
+// ScopeName is the instrumentation scope of the tracer and meter.
+const ScopeName = "github.com/reinventingscience/ivcap-core-api/telemetry"
+
+// Attribute keys used on spans and metrics.
+const (
+	// ServiceKey is the name of the IVCAP service, e.g. "order".
+	ServiceKey = attribute.Key("ivcap.service")
+	// MethodKey is the name of the service method, e.g. "read".
+	MethodKey = attribute.Key("ivcap.method")
+	// ErrorKey is the goa error name of a failed call, e.g. "not-found".
+	ErrorKey = attribute.Key("ivcap.error")
+)
+
+// Config selects the OpenTelemetry providers to use. Unset fields default
+// to the global providers and the W3C trace context propagator.
+type Config struct {
+	// TracerProvider creates the tracer for spans
+	TracerProvider trace.TracerProvider
+	// MeterProvider creates the meter for metrics
+	MeterProvider metric.MeterProvider
+	// Propagator injects the trace context into requests
+	Propagator propagation.TextMapPropagator
+}
+
+// Instrumentation records spans and metrics for IVCAP client calls.
+type Instrumentation struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	duration   metric.Float64Histogram
+	errors     metric.Int64Counter
+	queue      metric.Float64Histogram
+}
+
+// New returns an instrumentation using the providers in cfg, which may be
+// nil.
+func New(cfg *Config) (*Instrumentation, error) {
+	var c Config
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.TracerProvider == nil {
+		c.TracerProvider = otel.GetTracerProvider()
+	}
+	if c.MeterProvider == nil {
+		c.MeterProvider = otel.GetMeterProvider()
+	}
+	if c.Propagator == nil {
+		c.Propagator = propagation.TraceContext{}
+	}
+	meter := c.MeterProvider.Meter(ScopeName)
+	duration, err := meter.Float64Histogram("ivcap.client.duration",
+		metric.WithDescription("Duration of IVCAP service method calls"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60))
+	if err != nil {
+		return nil, err
+	}
+	errs, err := meter.Int64Counter("ivcap.client.errors",
+		metric.WithDescription("Number of failed IVCAP service method calls"),
+		metric.WithUnit("{error}"))
+	if err != nil {
+		return nil, err
+	}
+	queue, err := meter.Float64Histogram("ivcap.client.queue_time",
+		metric.WithDescription("Time IVCAP service method calls were held back by client side limits"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60))
+	if err != nil {
+		return nil, err
+	}
+	return &Instrumentation{
+		tracer:     c.TracerProvider.Tracer(ScopeName),
+		propagator: c.Propagator,
+		duration:   duration,
+		errors:     errs,
+		queue:      queue,
+	}, nil
+}
+
+// SpanName returns the name of the span recorded for method of service,
+// which are the ServiceName and one of the MethodNames of the generated
+// service package.
+func SpanName(service, method string) string {
+	return "ivcap." + service + "." + method
+}
+
+// Endpoint returns an endpoint calling e within a span for method of
+// service, recording its duration and, if it fails, its error. Its
+// signature matches ivcap.Middleware, so it can be passed to
+// ivcap.NewClient directly.
+func (i *Instrumentation) Endpoint(service, method string, e goa.Endpoint) goa.Endpoint {
+	name := SpanName(service, method)
+	attrs := []attribute.KeyValue{ServiceKey.String(service), MethodKey.String(method)}
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		ctx, span := i.tracer.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attrs...))
+		defer span.End()
+		start := time.Now()
+		res, err := e(ctx, req)
+		mattrs := attrs
+		if err != nil {
+			en := ErrorName(err)
+			mattrs = append(attrs[:len(attrs):len(attrs)], ErrorKey.String(en))
+			i.errors.Add(ctx, 1, metric.WithAttributes(mattrs...))
+			span.SetAttributes(ErrorKey.String(en))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, en)
+		}
+		i.duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(mattrs...))
+		return res, err
+	}
+}
+
+// QueueTime records the time a call of method of service was held back
+// before it was sent. Its signature matches ratelimit.Config.OnWait.
+func (i *Instrumentation) QueueTime(ctx context.Context, service, method string, wait time.Duration) {
+	i.queue.Record(ctx, wait.Seconds(), metric.WithAttributes(ServiceKey.String(service), MethodKey.String(method)))
+}
+
+// Doer returns a Doer which sends requests through doer after injecting the
+// trace context of their context into the request headers. Each request is
+// recorded as a child span of the calling method. If doer is nil,
+// http.DefaultClient is used.
+func (i *Instrumentation) Doer(doer goahttp.Doer) goahttp.Doer {
+	if doer == nil {
+		doer = http.DefaultClient
+	}
+	return &tracingDoer{doer: doer, i: i}
+}
+
+// tracingDoer implements Instrumentation.Doer.
+type tracingDoer struct {
+	doer goahttp.Doer
+	i    *Instrumentation
+}
+
+// Do sends req within a span for the HTTP request.
+func (d *tracingDoer) Do(req *http.Request) (*http.Response, error) {
+	ctx, span := d.i.tracer.Start(req.Context(), req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.full", redactURL(req)),
+			attribute.String("server.address", req.URL.Hostname()),
+		))
+	defer span.End()
+	req = req.WithContext(ctx)
+	d.i.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	resp, err := d.doer.Do(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+	return resp, nil
+}
+
+// redactURL returns the URL of req without user info and query, which may
+// carry credentials or personal data.
+func redactURL(req *http.Request) string {
+	u := *req.URL
+	u.User = nil
+	u.RawQuery = ""
+	u.ForceQuery = false
+	return u.String()
+}
+
+// ErrorName returns the goa error name of err, such as "not-found". Errors
+// without a goa error name are named after their ivcaperr kind, e.g.
+// "transport-error", or "unknown".
+func ErrorName(err error) string {
+	// The generated InvalidScopesT returns its message as error name, which
+	// would make every message a metric series of its own.
+	if ivcaperr.KindOf(err) == ivcaperr.ErrInvalidScopes {
+		return "invalid-scopes"
+	}
+	var ge interface{ GoaErrorName() string }
+	if errors.As(err, &ge) && ge.GoaErrorName() != "" {
+		return ge.GoaErrorName()
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "deadline-exceeded"
+	}
+	if k := ivcaperr.KindOf(err); k != nil {
+		return strings.ReplaceAll(k.Error(), " ", "-")
+	}
+	return "unknown"
+}
@@ -0,0 +1,207 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// expiryDelta is subtracted from a token's expiry so that it is refreshed
+// before the server starts rejecting it.
+const expiryDelta = 30 * time.Second
+
+// TokenSource provides the JWT used to authenticate requests.
+type TokenSource interface {
+	// Token returns a valid JWT or an error if none can be obtained.
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticToken is a TokenSource which always returns the same JWT.
+type StaticToken string
+
+// Token returns the static JWT.
+func (t StaticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// Token holds an access token together with the information needed to
+// refresh it.
+type Token struct {
+	// AccessToken is the JWT sent in the Authorization header.
+	AccessToken string `json:"access_token"`
+	// RefreshToken is used to obtain a new access token once it expired.
+	RefreshToken string `json:"refresh_token,omitempty"`
+	// Expiry is the time the access token expires. If zero, the "exp" claim
+	// of the access token is used.
+	Expiry time.Time `json:"expiry,omitempty"`
+}
+
+// Valid returns true if t carries an access token which has not expired yet.
+func (t *Token) Valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	exp := t.Expiry
+	if exp.IsZero() {
+		exp = jwtExpiry(t.AccessToken)
+	}
+	return exp.IsZero() || time.Now().Add(expiryDelta).Before(exp)
+}
+
+// Refresher obtains a new token, typically from an OAuth2 token endpoint.
+type Refresher interface {
+	// Refresh returns a new token. old is the last known token and may be nil.
+	Refresh(ctx context.Context, old *Token) (*Token, error)
+}
+
+// ErrNoToken is returned by a RefreshingTokenSource when it holds no token
+// and no Refresher was configured.
+var ErrNoToken = errors.New("no valid token available")
+
+// RefreshingTokenSource is a TokenSource which keeps returning the same token
+// until it expires and then obtains a new one from its Refresher. Tokens are
+// persisted to the optional TokenCache so they survive process restarts. It
+// is safe for concurrent use. Concurrent callers share a single refresh, which
+// runs without holding any lock as it may wait for the user to log in.
+type RefreshingTokenSource struct {
+	refresher Refresher
+	cache     TokenCache
+
+	mu  sync.Mutex
+	tok *Token
+	// refresh is the refresh in progress, nil if there is none.
+	refresh *refreshCall
+}
+
+// refreshCall is a refresh other callers of Token can wait for.
+type refreshCall struct {
+	// done is closed once the refresh completed and err is set.
+	done chan struct{}
+	err  error
+}
+
+// NewRefreshingTokenSource returns a token source starting with initial. If
+// initial is nil, the token is loaded from cache. Either refresher or cache may
+// be nil.
+func NewRefreshingTokenSource(initial *Token, refresher Refresher, cache TokenCache) *RefreshingTokenSource {
+	ts := &RefreshingTokenSource{refresher: refresher, cache: cache, tok: initial}
+	if ts.tok == nil && cache != nil {
+		if t, err := cache.Load(); err == nil {
+			ts.tok = t
+		}
+	}
+	return ts
+}
+
+// Token returns the current access token, refreshing it first if it has
+// expired. If another call is already refreshing it, Token waits for that
+// refresh instead of starting its own.
+func (s *RefreshingTokenSource) Token(ctx context.Context) (string, error) {
+	for {
+		s.mu.Lock()
+		if s.tok.Valid() {
+			tok := s.tok.AccessToken
+			s.mu.Unlock()
+			return tok, nil
+		}
+		if s.refresher == nil {
+			s.mu.Unlock()
+			return "", ErrNoToken
+		}
+		call := s.refresh
+		if call == nil {
+			return s.refreshToken(ctx)
+		}
+		s.mu.Unlock()
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+		if call.err != nil && !errors.Is(call.err, context.Canceled) && !errors.Is(call.err, context.DeadlineExceeded) {
+			return "", call.err
+		}
+		// Either the refresh succeeded or it was abandoned by its caller,
+		// in which case we start another one.
+	}
+}
+
+// refreshToken obtains a new token from the refresher. It is called with
+// s.mu held and releases it while the refresher runs.
+func (s *RefreshingTokenSource) refreshToken(ctx context.Context) (string, error) {
+	call := &refreshCall{done: make(chan struct{})}
+	s.refresh = call
+	old := s.tok
+	s.mu.Unlock()
+
+	t, err := s.refresher.Refresh(ctx, old)
+	s.mu.Lock()
+	s.refresh = nil
+	if err == nil {
+		if t.RefreshToken == "" && old != nil {
+			// Servers may omit the refresh token if it did not change.
+			t.RefreshToken = old.RefreshToken
+		}
+		s.tok = t
+	}
+	s.mu.Unlock()
+	call.err = err
+	close(call.done)
+	if err != nil {
+		return "", err
+	}
+	if s.cache != nil {
+		if err := s.cache.Save(t); err != nil {
+			return "", err
+		}
+	}
+	return t.AccessToken, nil
+}
+
+// Invalidate discards the current access token so that the next call to
+// Token refreshes it. The refresh token is kept.
+func (s *RefreshingTokenSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tok != nil {
+		s.tok = &Token{RefreshToken: s.tok.RefreshToken}
+	}
+}
+
+// jwtExpiry returns the time of the "exp" claim in jwt, or the zero time if
+// it cannot be determined.
+func jwtExpiry(jwt string) time.Time {
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	b, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(b, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0)
+}
@@ -0,0 +1,82 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// TokenCache persists tokens between runs.
+type TokenCache interface {
+	// Load returns the cached token.
+	Load() (*Token, error)
+	// Save replaces the cached token with t.
+	Save(t *Token) error
+}
+
+// FileTokenCache is a TokenCache storing the token as JSON in a file only
+// readable by the current user.
+type FileTokenCache string
+
+// DefaultTokenCache returns a FileTokenCache in the user's configuration
+// directory.
+func DefaultTokenCache() (FileTokenCache, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return FileTokenCache(filepath.Join(dir, "ivcap", "token.json")), nil
+}
+
+// Load reads the token from the file.
+func (c FileTokenCache) Load() (*Token, error) {
+	b, err := os.ReadFile(string(c))
+	if err != nil {
+		return nil, err
+	}
+	var t Token
+	if err := json.Unmarshal(b, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Save writes t to the file. The file is replaced atomically so concurrent
+// readers never observe a partially written token.
+func (c FileTokenCache) Save(t *Token) error {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	path := string(c)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".token-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
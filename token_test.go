@@ -0,0 +1,240 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type refresherFunc func(ctx context.Context, old *Token) (*Token, error)
+
+func (f refresherFunc) Refresh(ctx context.Context, old *Token) (*Token, error) {
+	return f(ctx, old)
+}
+
+// blockingRefresher returns "access-N" from its Nth call once release is
+// closed. Each call sends the token it was given to entered first.
+type blockingRefresher struct {
+	entered chan *Token
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func newBlockingRefresher() *blockingRefresher {
+	return &blockingRefresher{entered: make(chan *Token, 10), release: make(chan struct{})}
+}
+
+func (r *blockingRefresher) Refresh(ctx context.Context, old *Token) (*Token, error) {
+	n := r.calls.Add(1)
+	r.entered <- old
+	select {
+	case <-r.release:
+		return &Token{AccessToken: fmt.Sprintf("access-%d", n), Expiry: time.Now().Add(time.Hour)}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (r *blockingRefresher) waitEntered(t *testing.T) *Token {
+	t.Helper()
+	select {
+	case old := <-r.entered:
+		return old
+	case <-time.After(5 * time.Second):
+		t.Fatal("refresher not called")
+		return nil
+	}
+}
+
+type memCache struct {
+	mu    sync.Mutex
+	saved []*Token
+}
+
+func (c *memCache) Load() (*Token, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.saved) == 0 {
+		return nil, errors.New("empty")
+	}
+	return c.saved[len(c.saved)-1], nil
+}
+
+func (c *memCache) Save(t *Token) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.saved = append(c.saved, t)
+	return nil
+}
+
+var expired = time.Now().Add(-time.Hour)
+
+func TestRefreshingTokenSource(t *testing.T) {
+	noRefresh := refresherFunc(func(context.Context, *Token) (*Token, error) {
+		t.Error("unexpected refresh")
+		return nil, errors.New("unexpected refresh")
+	})
+	cache := &memCache{saved: []*Token{{AccessToken: "cached"}}}
+	tests := []struct {
+		name      string
+		initial   *Token
+		refresher Refresher
+		cache     TokenCache
+		want      string
+		wantErr   error
+	}{
+		{"valid", &Token{AccessToken: "valid", Expiry: time.Now().Add(time.Hour)}, noRefresh, nil, "valid", nil},
+		{"cached", nil, noRefresh, cache, "cached", nil},
+		{"expired without refresher", &Token{AccessToken: "old", Expiry: expired}, nil, nil, "", ErrNoToken},
+		{"none without refresher", nil, nil, nil, "", ErrNoToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := NewRefreshingTokenSource(tt.initial, tt.refresher, tt.cache)
+			got, err := ts.Token(context.Background())
+			if got != tt.want || !errors.Is(err, tt.wantErr) {
+				t.Errorf("Token() = %q, %v, want %q, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRefreshingTokenSourceRefresh(t *testing.T) {
+	var calls int
+	r := refresherFunc(func(_ context.Context, old *Token) (*Token, error) {
+		calls++
+		if old.RefreshToken != "refresh" {
+			t.Errorf("refreshing %+v", old)
+		}
+		// The refresh token is omitted as it did not change.
+		return &Token{AccessToken: "new", Expiry: time.Now().Add(time.Hour)}, nil
+	})
+	cache := &memCache{}
+	ts := NewRefreshingTokenSource(&Token{AccessToken: "old", RefreshToken: "refresh", Expiry: expired}, r, cache)
+	for range 2 {
+		if got, err := ts.Token(context.Background()); got != "new" || err != nil {
+			t.Fatalf("Token() = %q, %v, want new", got, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("refreshed %d times, want 1", calls)
+	}
+	if len(cache.saved) != 1 || cache.saved[0].AccessToken != "new" || cache.saved[0].RefreshToken != "refresh" {
+		t.Errorf("cache holds %+v", cache.saved)
+	}
+
+	// After Invalidate the refresh token is used again.
+	ts.Invalidate()
+	if got, err := ts.Token(context.Background()); got != "new" || err != nil {
+		t.Fatalf("Token() = %q, %v, want new", got, err)
+	}
+	if calls != 2 {
+		t.Errorf("refreshed %d times, want 2", calls)
+	}
+}
+
+func TestRefreshingTokenSourceError(t *testing.T) {
+	errRefresh := errors.New("refresh failed")
+	ts := NewRefreshingTokenSource(nil, refresherFunc(func(context.Context, *Token) (*Token, error) {
+		return nil, errRefresh
+	}), nil)
+	if _, err := ts.Token(context.Background()); err != errRefresh {
+		t.Errorf("err = %v, want %v", err, errRefresh)
+	}
+}
+
+func TestRefreshingTokenSourceConcurrent(t *testing.T) {
+	r := newBlockingRefresher()
+	ts := NewRefreshingTokenSource(&Token{AccessToken: "old", RefreshToken: "refresh", Expiry: expired}, r, nil)
+	results := make(chan string)
+	for range 5 {
+		go func() {
+			tok, err := ts.Token(context.Background())
+			if err != nil {
+				t.Error(err)
+			}
+			results <- tok
+		}()
+	}
+	r.waitEntered(t)
+
+	// The token source is not locked while the refresh, which may prompt
+	// the user, is in progress.
+	done := make(chan struct{})
+	go func() {
+		ts.Invalidate()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := ts.Token(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want context.Canceled", err)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("blocked by the refresh in progress")
+	}
+
+	close(r.release)
+	for range 5 {
+		if tok := <-results; tok != "access-1" {
+			t.Errorf("Token() = %q, want access-1", tok)
+		}
+	}
+	if n := r.calls.Load(); n != 1 {
+		t.Errorf("refreshed %d times, want 1", n)
+	}
+}
+
+func TestRefreshingTokenSourceAbandonedRefresh(t *testing.T) {
+	r := newBlockingRefresher()
+	ts := NewRefreshingTokenSource(&Token{AccessToken: "old", RefreshToken: "refresh", Expiry: expired}, r, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		_, err := ts.Token(ctx)
+		first <- err
+	}()
+	r.waitEntered(t)
+	other := make(chan string)
+	go func() {
+		tok, err := ts.Token(context.Background())
+		if err != nil {
+			t.Error(err)
+		}
+		other <- tok
+	}()
+
+	// The refresh is canceled by its caller, the other caller refreshes
+	// the token itself.
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if old := r.waitEntered(t); old == nil || old.RefreshToken != "refresh" {
+		t.Errorf("refreshing %+v", old)
+	}
+	close(r.release)
+	if tok := <-other; tok != "access-2" {
+		t.Errorf("Token() = %q, want access-2", tok)
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+	"github.com/reinventingscience/ivcap-core-api/resource"
+)
+
+// TopSample is the resource usage of a container at one point in time. CPU is
+// measured in cores, all other values in bytes.
+type TopSample struct {
+	Time             time.Time `json:"time"`
+	CPU              float64   `json:"cpu"`
+	Memory           float64   `json:"memory"`
+	Storage          float64   `json:"storage"`
+	EphemeralStorage float64   `json:"ephemeral-storage"`
+}
+
+// TopSampler records the resource usage of the containers of an order over
+// time by periodically calling Top.
+type TopSampler struct {
+	client  *OrdersClient
+	orderID string
+	// Interval between samples. Defaults to 10s.
+	Interval time.Duration
+	// OnSample, if set, is called for every sample taken.
+	OnSample func(container string, s TopSample)
+
+	mu     sync.Mutex
+	series map[string][]TopSample
+}
+
+// NewTopSampler returns a sampler for order orderID taking a sample every
+// interval. Call Run to start sampling.
+func (c *OrdersClient) NewTopSampler(orderID string, interval time.Duration) *TopSampler {
+	return &TopSampler{
+		client:   c,
+		orderID:  orderID,
+		Interval: interval,
+		series:   map[string][]TopSample{},
+	}
+}
+
+// Run takes samples until the order has finished, in which case it returns
+// nil, or until ctx is done or a request fails.
+func (s *TopSampler) Run(ctx context.Context) error {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	for {
+		st, err := s.client.Read(ctx, s.orderID)
+		if err != nil {
+			return err
+		}
+		if st.Status != nil && IsTerminalOrderStatus(*st.Status) {
+			return nil
+		}
+		if err := s.Sample(ctx); err != nil {
+			return err
+		}
+		if err := sleep(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
+
+// Sample takes a single sample of all containers of the order.
+func (s *TopSampler) Sample(ctx context.Context) error {
+	res, err := s.client.Top(ctx, &order.OrderTopRequestT{OrderID: s.orderID})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, item := range res {
+		ts, err := parseTopResult(item, now)
+		if err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.series[item.Container] = append(s.series[item.Container], ts)
+		s.mu.Unlock()
+		if s.OnSample != nil {
+			s.OnSample(item.Container, ts)
+		}
+	}
+	return nil
+}
+
+// Containers returns the names of all containers sampled so far in
+// alphabetical order.
+func (s *TopSampler) Containers() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Sorted(maps.Keys(s.series))
+}
+
+// Series returns a copy of the samples taken so far, keyed by container.
+func (s *TopSampler) Series() map[string][]TopSample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := make(map[string][]TopSample, len(s.series))
+	for c, ts := range s.series {
+		res[c] = slices.Clone(ts)
+	}
+	return res
+}
+
+// Peak returns the highest value of each resource sampled for container. Its
+// Time is the time of the last sample.
+func (s *TopSampler) Peak(container string) TopSample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var p TopSample
+	for _, ts := range s.series[container] {
+		p.Time = ts.Time
+		p.CPU = max(p.CPU, ts.CPU)
+		p.Memory = max(p.Memory, ts.Memory)
+		p.Storage = max(p.Storage, ts.Storage)
+		p.EphemeralStorage = max(p.EphemeralStorage, ts.EphemeralStorage)
+	}
+	return p
+}
+
+// WriteCSV writes all samples to w as CSV with the columns time, container,
+// cpu, memory, storage and ephemeral-storage. Rows are ordered by container
+// and time.
+func (s *TopSampler) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"time", "container", "cpu", "memory", "storage", "ephemeral-storage"}); err != nil {
+		return err
+	}
+	series := s.Series()
+	for _, c := range slices.Sorted(maps.Keys(series)) {
+		for _, ts := range series[c] {
+			row := []string{
+				ts.Time.UTC().Format(time.RFC3339Nano),
+				c,
+				formatFloat(ts.CPU),
+				formatFloat(ts.Memory),
+				formatFloat(ts.Storage),
+				formatFloat(ts.EphemeralStorage),
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes all samples to w as a JSON object with the order ID and
+// the samples keyed by container.
+func (s *TopSampler) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(struct {
+		OrderID    string                 `json:"order-id"`
+		Containers map[string][]TopSample `json:"containers"`
+	}{s.orderID, s.Series()})
+}
+
+// parseTopResult converts the quantities of item into a sample. Missing
+// values are recorded as zero.
+func parseTopResult(item *order.OrderTopResultItem, t time.Time) (TopSample, error) {
+	ts := TopSample{Time: t}
+	for _, f := range []struct {
+		name  string
+		value string
+		dst   *float64
+	}{
+		{"cpu", item.CPU, &ts.CPU},
+		{"memory", item.Memory, &ts.Memory},
+		{"storage", item.Storage, &ts.Storage},
+		{"ephemeral-storage", item.EphemeralStorage, &ts.EphemeralStorage},
+	} {
+		if f.value == "" {
+			continue
+		}
+		q, err := resource.ParseQuantity(f.value)
+		if err != nil {
+			return ts, fmt.Errorf("container %s: %s: %w", item.Container, f.name, err)
+		}
+		*f.dst = q.Float64()
+	}
+	return ts, nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
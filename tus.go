@@ -0,0 +1,338 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	artifact "github.com/reinventingscience/ivcap-core-api/gen/artifact"
+	"github.com/reinventingscience/ivcap-core-api/ivcaperr"
+)
+
+// TusVersion is the version of the TUS protocol spoken by the upload client.
+const TusVersion = "1.0.0"
+
+// DefaultChunkSize is the number of bytes sent per PATCH request unless
+// UploadOptions.ChunkSize is set.
+const DefaultChunkSize = 8 << 20
+
+// UploadOptions controls a resumable upload.
+type UploadOptions struct {
+	// ChunkSize is the number of bytes sent per request, DefaultChunkSize if
+	// zero.
+	ChunkSize int64
+	// StateFile persists the progress of the upload. If the file exists when
+	// the upload starts and belongs to the same content, the upload resumes
+	// from where it stopped. The file is removed once the upload completed.
+	// If empty, the progress is only kept in memory.
+	StateFile string
+	// Fingerprint identifies the uploaded content, e.g. a file path together
+	// with its modification time. A state file is only used for resuming if
+	// its fingerprint matches.
+	Fingerprint string
+	// MaxRetries is the number of times a failed chunk is retried before the
+	// upload is aborted. Zero means 3.
+	MaxRetries int
+	// Progress is called after every chunk with the number of bytes uploaded
+	// so far and the total size.
+	Progress func(offset, size int64)
+}
+
+// uploadRetryDelay is multiplied by the number of the retry to get the time
+// waited before retrying a failed chunk.
+var uploadRetryDelay = time.Second
+
+// uploadState is the content of the upload state file.
+type uploadState struct {
+	ArtifactID  string `json:"artifact-id"`
+	Location    string `json:"location"`
+	Size        int64  `json:"size"`
+	Offset      int64  `json:"offset"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// UploadResumable uploads size bytes read from r using the TUS resumable
+// upload protocol. The artifact is created first with the headers taken from
+// p, then its content is sent in chunks. Failed chunks are retried after
+// asking the server for the current offset. With opts.StateFile set, an
+// upload interrupted by a process restart resumes where it stopped. p and
+// opts may be nil.
+func (c *ArtifactsClient) UploadResumable(ctx context.Context, p *artifact.UploadPayload, r io.ReadSeeker, size int64, opts *UploadOptions) (*artifact.ArtifactStatusRT, error) {
+	if opts == nil {
+		opts = &UploadOptions{}
+	}
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	maxRetries := opts.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = 3
+	}
+
+	st := c.loadUploadState(ctx, opts, size)
+	if st == nil {
+		var err error
+		if st, err = c.createUpload(ctx, p, size, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	buf := make([]byte, chunkSize)
+	retries := 0
+	for st.Offset < size {
+		if _, err := r.Seek(st.Offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+		n, err := io.ReadFull(r, buf[:min(chunkSize, size-st.Offset)])
+		if err != nil {
+			return nil, fmt.Errorf("reading upload content at offset %d: %w", st.Offset, err)
+		}
+		off, err := c.patchUpload(ctx, st.Location, st.Offset, buf[:n])
+		if err == nil && off <= st.Offset {
+			// A server accepting the chunk without storing any of it would
+			// otherwise be sent the same chunk forever.
+			err = fmt.Errorf("upload of artifact %s: server did not advance Upload-Offset beyond %d", st.ArtifactID, st.Offset)
+		}
+		if err != nil {
+			if ctx.Err() != nil || retries >= maxRetries || errors.Is(err, errUploadGone) {
+				return nil, err
+			}
+			retries++
+			if err := sleep(ctx, time.Duration(retries)*uploadRetryDelay); err != nil {
+				return nil, err
+			}
+			if off, err = c.headUpload(ctx, st.Location); err != nil {
+				continue
+			}
+		} else {
+			retries = 0
+		}
+		st.Offset = off
+		if err := saveUploadState(opts.StateFile, st); err != nil {
+			return nil, err
+		}
+		if opts.Progress != nil {
+			opts.Progress(st.Offset, size)
+		}
+	}
+	if opts.StateFile != "" {
+		os.Remove(opts.StateFile)
+	}
+	return c.Read(ctx, st.ArtifactID)
+}
+
+// UploadFile uploads the file at path with UploadResumable. Unless set in
+// opts, the upload state is kept in the user's cache directory so that an
+// interrupted upload of the same, unmodified file is resumed.
+func (c *ArtifactsClient) UploadFile(ctx context.Context, path string, p *artifact.UploadPayload, opts *UploadOptions) (*artifact.ArtifactStatusRT, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	var o UploadOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Fingerprint == "" {
+		abs, _ := filepath.Abs(path)
+		o.Fingerprint = fmt.Sprintf("%s|%d|%d", abs, fi.Size(), fi.ModTime().UnixNano())
+	}
+	if o.StateFile == "" {
+		if dir, err := os.UserCacheDir(); err == nil {
+			sum := sha256.Sum256([]byte(o.Fingerprint))
+			o.StateFile = filepath.Join(dir, "ivcap", "uploads", hex.EncodeToString(sum[:8])+".json")
+		}
+	}
+	var up artifact.UploadPayload
+	if p != nil {
+		up = *p
+	}
+	if up.Name == nil {
+		name := filepath.Base(path)
+		up.Name = &name
+	}
+	return c.UploadResumable(ctx, &up, f, fi.Size(), &o)
+}
+
+// createUpload creates the artifact together with its TUS upload resource.
+func (c *ArtifactsClient) createUpload(ctx context.Context, p *artifact.UploadPayload, size int64, opts *UploadOptions) (*uploadState, error) {
+	var up artifact.UploadPayload
+	if p != nil {
+		up = *p
+	}
+	length := int(size)
+	version := TusVersion
+	up.UploadLength = &length
+	up.TusResumable = &version
+	if up.XContentType == nil {
+		up.XContentType = up.ContentType
+	}
+	if up.XContentLength == nil {
+		up.XContentLength = &length
+	}
+	up.ContentType = nil
+	up.ContentLength = nil
+	res, err := c.client.Upload(ctx, &up, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	if res.Location == nil || *res.Location == "" {
+		return nil, fmt.Errorf("upload of artifact %s: server did not return an upload location", res.ID)
+	}
+	st := &uploadState{
+		ArtifactID:  res.ID,
+		Location:    c.resolve(*res.Location),
+		Size:        size,
+		Fingerprint: opts.Fingerprint,
+	}
+	if res.TusOffset != nil {
+		st.Offset = *res.TusOffset
+	}
+	return st, saveUploadState(opts.StateFile, st)
+}
+
+// loadUploadState returns the state of a previous upload of the same content
+// or nil if there is none which can be resumed.
+func (c *ArtifactsClient) loadUploadState(ctx context.Context, opts *UploadOptions, size int64) *uploadState {
+	if opts.StateFile == "" {
+		return nil
+	}
+	b, err := os.ReadFile(opts.StateFile)
+	if err != nil {
+		return nil
+	}
+	var st uploadState
+	if json.Unmarshal(b, &st) != nil || st.Size != size || st.Fingerprint != opts.Fingerprint || st.Location == "" {
+		return nil
+	}
+	off, err := c.headUpload(ctx, st.Location)
+	if err != nil {
+		return nil
+	}
+	st.Offset = off
+	return &st
+}
+
+func saveUploadState(path string, st *uploadState) error {
+	if path == "" {
+		return nil
+	}
+	b, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o600)
+}
+
+// errUploadGone is returned when the server no longer knows the upload.
+var errUploadGone = errors.New("upload resource no longer exists")
+
+// headUpload asks the server for the current offset of the upload.
+func (c *ArtifactsClient) headUpload(ctx context.Context, location string) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, "HEAD", location, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Tus-Resumable", TusVersion)
+	req.Header.Set("Cache-Control", "no-store")
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return uploadOffset(resp)
+	case http.StatusNotFound, http.StatusGone, http.StatusForbidden:
+		return 0, errUploadGone
+	}
+	return 0, ivcaperr.FromStatus("artifact", "upload", location, resp.StatusCode, "")
+}
+
+// patchUpload sends chunk starting at offset and returns the new offset.
+func (c *ArtifactsClient) patchUpload(ctx context.Context, location string, offset int64, chunk []byte) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, "PATCH", location, bytes.NewReader(chunk))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Tus-Resumable", TusVersion)
+	req.Header.Set("Content-Type", "application/offset+octet-stream")
+	req.Header.Set("Upload-Offset", strconv.FormatInt(offset, 10))
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusOK:
+		return uploadOffset(resp)
+	case http.StatusNotFound, http.StatusGone:
+		return 0, errUploadGone
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return 0, ivcaperr.FromStatus("artifact", "upload", location, resp.StatusCode, string(body))
+}
+
+func uploadOffset(resp *http.Response) (int64, error) {
+	h := resp.Header.Get("Upload-Offset")
+	off, err := strconv.ParseInt(h, 10, 64)
+	if err != nil || off < 0 {
+		return 0, fmt.Errorf("invalid Upload-Offset header %q", h)
+	}
+	return off, nil
+}
+
+// resolve turns a link returned by the service into an absolute URL.
+func (c *ArtifactsClient) resolve(link string) string {
+	u, err := c.base.Parse(link)
+	if err != nil {
+		return link
+	}
+	return u.String()
+}
+
+// sleep waits for d or until ctx is done. Tests replace it to observe the
+// delays without waiting.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
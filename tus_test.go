@@ -0,0 +1,240 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// tusServer implements the parts of the artifact service and the TUS
+// protocol used by UploadResumable for a single upload.
+type tusServer struct {
+	t *testing.T
+
+	mu      sync.Mutex
+	data    []byte
+	size    int64
+	creates int
+	patches int
+	// beforePatch is called with the number of the PATCH request, starting at
+	// 1. If it returns false, the request is not handled any further.
+	beforePatch func(n int, w http.ResponseWriter, chunk []byte) bool
+}
+
+func newTusServer(t *testing.T) (*tusServer, *ArtifactsClient) {
+	s := &tusServer{t: t}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	c, err := NewClient(srv.URL, StaticToken("token"), srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := uploadRetryDelay
+	uploadRetryDelay = time.Millisecond
+	t.Cleanup(func() { uploadRetryDelay = old })
+	return s, c.Artifacts()
+}
+
+func (s *tusServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/1/artifacts":
+		s.creates++
+		size, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+		if err != nil {
+			http.Error(w, "missing Upload-Length", http.StatusBadRequest)
+			return
+		}
+		s.size, s.data = size, nil
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/uploads/1")
+		w.Header().Set("Tus-Resumable", TusVersion)
+		w.Header().Set("Upload-Offset", "0")
+		w.WriteHeader(http.StatusCreated)
+		s.writeStatus(w, "pending")
+	case r.Method == "HEAD" && r.URL.Path == "/uploads/1":
+		w.Header().Set("Upload-Offset", strconv.Itoa(len(s.data)))
+		w.WriteHeader(http.StatusOK)
+	case r.Method == "PATCH" && r.URL.Path == "/uploads/1":
+		s.patches++
+		chunk, err := io.ReadAll(r.Body)
+		if err != nil {
+			return
+		}
+		if s.beforePatch != nil && !s.beforePatch(s.patches, w, chunk) {
+			return
+		}
+		if off := r.Header.Get("Upload-Offset"); off != strconv.Itoa(len(s.data)) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		s.data = append(s.data, chunk...)
+		w.Header().Set("Upload-Offset", strconv.Itoa(len(s.data)))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == "GET" && r.URL.Path == "/1/artifacts/urn:ivcap:artifact:1":
+		w.Header().Set("Content-Type", "application/json")
+		status := "partial"
+		if int64(len(s.data)) == s.size {
+			status = "ready"
+		}
+		s.writeStatus(w, status)
+	default:
+		s.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *tusServer) writeStatus(w io.Writer, status string) {
+	fmt.Fprintf(w, `{"id":"urn:ivcap:artifact:1","status":%q,"links":{"self":"/1/artifacts/urn:ivcap:artifact:1"}}`, status)
+}
+
+func content(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i % 251)
+	}
+	return b
+}
+
+func TestUploadResumable(t *testing.T) {
+	s, c := newTusServer(t)
+	data := content(2500)
+	var progress []int64
+	res, err := c.UploadResumable(context.Background(), nil, bytes.NewReader(data), int64(len(data)), &UploadOptions{
+		ChunkSize: 1000,
+		Progress:  func(off, size int64) { progress = append(progress, off) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != "ready" {
+		t.Errorf("status = %q, want ready", res.Status)
+	}
+	if !bytes.Equal(s.data, data) {
+		t.Errorf("server received %d bytes which differ from the content", len(s.data))
+	}
+	if s.creates != 1 || s.patches != 3 {
+		t.Errorf("got %d creates and %d patches, want 1 and 3", s.creates, s.patches)
+	}
+	if fmt.Sprint(progress) != "[1000 2000 2500]" {
+		t.Errorf("progress = %v", progress)
+	}
+}
+
+func TestUploadResumableRetriesInterruptedChunk(t *testing.T) {
+	s, c := newTusServer(t)
+	// The second chunk is cut off after half of it was stored.
+	s.beforePatch = func(n int, w http.ResponseWriter, chunk []byte) bool {
+		if n != 2 {
+			return true
+		}
+		s.data = append(s.data, chunk[:len(chunk)/2]...)
+		w.WriteHeader(http.StatusBadGateway)
+		return false
+	}
+	data := content(2500)
+	_, err := c.UploadResumable(context.Background(), nil, bytes.NewReader(data), int64(len(data)), &UploadOptions{ChunkSize: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(s.data, data) {
+		t.Errorf("server received %d bytes which differ from the content", len(s.data))
+	}
+}
+
+func TestUploadResumableResumesFromStateFile(t *testing.T) {
+	s, c := newTusServer(t)
+	data := content(2500)
+	opts := &UploadOptions{
+		ChunkSize:   1000,
+		StateFile:   filepath.Join(t.TempDir(), "state.json"),
+		Fingerprint: "content",
+	}
+
+	// The first attempt is interrupted after the first chunk.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opts.Progress = func(off, size int64) { cancel() }
+	if _, err := c.UploadResumable(ctx, nil, bytes.NewReader(data), int64(len(data)), opts); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if len(s.data) != 1000 {
+		t.Fatalf("server received %d bytes, want 1000", len(s.data))
+	}
+
+	opts.Progress = nil
+	if _, err := c.UploadResumable(context.Background(), nil, bytes.NewReader(data), int64(len(data)), opts); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(s.data, data) {
+		t.Errorf("server received %d bytes which differ from the content", len(s.data))
+	}
+	if s.creates != 1 || s.patches != 3 {
+		t.Errorf("got %d creates and %d patches, want 1 and 3", s.creates, s.patches)
+	}
+}
+
+func TestUploadResumableOffsetMismatch(t *testing.T) {
+	s, c := newTusServer(t)
+	// The server loses the first chunk before the second arrives, so that
+	// the offset sent by the client no longer matches.
+	s.beforePatch = func(n int, w http.ResponseWriter, chunk []byte) bool {
+		if n == 2 {
+			s.data = nil
+		}
+		return true
+	}
+	data := content(2500)
+	_, err := c.UploadResumable(context.Background(), nil, bytes.NewReader(data), int64(len(data)), &UploadOptions{ChunkSize: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(s.data, data) {
+		t.Errorf("server received %d bytes which differ from the content", len(s.data))
+	}
+	if s.patches != 5 {
+		t.Errorf("got %d patches, want 5", s.patches)
+	}
+}
+
+func TestUploadResumableOffsetNotAdvancing(t *testing.T) {
+	s, c := newTusServer(t)
+	// The server acknowledges every chunk without storing it.
+	s.beforePatch = func(n int, w http.ResponseWriter, chunk []byte) bool {
+		w.Header().Set("Upload-Offset", strconv.Itoa(len(s.data)))
+		w.WriteHeader(http.StatusNoContent)
+		return false
+	}
+	data := content(2500)
+	_, err := c.UploadResumable(context.Background(), nil, bytes.NewReader(data), int64(len(data)), &UploadOptions{ChunkSize: 1000, MaxRetries: 2})
+	if err == nil {
+		t.Fatal("upload succeeded without the server storing any content")
+	}
+	if s.patches != 3 {
+		t.Errorf("got %d patches, want 3", s.patches)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	order "github.com/reinventingscience/ivcap-core-api/gen/order"
+	"github.com/reinventingscience/ivcap-core-api/ivcaperr"
+)
+
+// Order status values reported in OrderStatusRT.Status.
+const (
+	OrderStatusUnknown   = "unknown"
+	OrderStatusPending   = "pending"
+	OrderStatusScheduled = "scheduled"
+	OrderStatusExecuting = "executing"
+	OrderStatusSucceeded = "succeeded"
+	OrderStatusFailed    = "failed"
+	OrderStatusError     = "error"
+)
+
+// IsTerminalOrderStatus returns true if an order with the given status will
+// not change anymore.
+func IsTerminalOrderStatus(status string) bool {
+	switch status {
+	case OrderStatusSucceeded, OrderStatusFailed, OrderStatusError:
+		return true
+	}
+	return false
+}
+
+// WaitOptions controls how Wait polls an order.
+type WaitOptions struct {
+	// InitialInterval is the delay before the first poll and after every
+	// status change. Defaults to 1s.
+	InitialInterval time.Duration
+	// MaxInterval caps the delay between polls. Defaults to 30s.
+	MaxInterval time.Duration
+	// Multiplier grows the delay after every poll without a status change.
+	// Defaults to 1.5.
+	Multiplier float64
+	// Jitter randomizes each delay by up to the given fraction in either
+	// direction. Defaults to 0.2; set to a negative value to disable.
+	Jitter float64
+	// MaxReadErrors is the number of consecutive polls failing with an error
+	// worth retrying, see ivcaperr.Retryable, after which Wait gives up and
+	// returns the error. Other errors are returned at once. Defaults to 3,
+	// like RetryPolicy.MaxRetries; set to a negative value to return the
+	// first error.
+	MaxReadErrors int
+	// OnChange is called whenever the order status changes, including once
+	// for the initial status.
+	OnChange func(OrderEvent)
+	// Events receives the same events as OnChange. Wait blocks until an event
+	// is received or the context is done. The channel is not closed by Wait.
+	Events chan<- OrderEvent
+}
+
+// OrderEvent reports a change of an order's status.
+type OrderEvent struct {
+	// OrderID is the ID of the order
+	OrderID string
+	// Previous status, empty for the first event
+	Previous string
+	// Status is the new status
+	Status string
+	// Order is the order record as returned by Read
+	Order *order.OrderStatusRT
+	// Time the change was observed
+	Time time.Time
+}
+
+// OrderFailedError is returned by Wait when an order ends in the "failed" or
+// "error" state.
+type OrderFailedError struct {
+	// Order is the final order record
+	Order *order.OrderStatusRT
+	// Status is the final status
+	Status string
+}
+
+// Error returns an error description.
+func (e *OrderFailedError) Error() string {
+	return fmt.Sprintf("order %s finished with status %q", e.Order.ID, e.Status)
+}
+
+// Wait polls order id until it reaches a terminal state. It returns the final
+// order record if the order succeeded and an *OrderFailedError if it failed.
+// Polls failing with a transient error are repeated, see
+// WaitOptions.MaxReadErrors. opts may be nil.
+func (c *OrdersClient) Wait(ctx context.Context, id string, opts *WaitOptions) (*order.OrderStatusRT, error) {
+	var o WaitOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.InitialInterval <= 0 {
+		o.InitialInterval = time.Second
+	}
+	if o.MaxInterval <= 0 {
+		o.MaxInterval = 30 * time.Second
+	}
+	if o.Multiplier < 1 {
+		o.Multiplier = 1.5
+	}
+	if o.Jitter == 0 {
+		o.Jitter = 0.2
+	}
+	if o.MaxReadErrors == 0 {
+		o.MaxReadErrors = 3
+	}
+
+	var (
+		prev       string
+		first      = true
+		interval   = o.InitialInterval
+		readErrors int
+	)
+	// next waits for the next poll, backing off further.
+	next := func() error {
+		if err := sleep(ctx, jitter(interval, o.Jitter)); err != nil {
+			return err
+		}
+		interval = min(time.Duration(float64(interval)*o.Multiplier), o.MaxInterval)
+		return nil
+	}
+	for {
+		res, err := c.Read(ctx, id)
+		if err != nil {
+			if readErrors >= o.MaxReadErrors || !ivcaperr.Retryable(err) {
+				return nil, err
+			}
+			readErrors++
+			if err := next(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		readErrors = 0
+		status := OrderStatusUnknown
+		if res.Status != nil {
+			status = *res.Status
+		}
+		if first || status != prev {
+			ev := OrderEvent{OrderID: id, Previous: prev, Status: status, Order: res, Time: time.Now()}
+			if o.OnChange != nil {
+				o.OnChange(ev)
+			}
+			if o.Events != nil {
+				select {
+				case o.Events <- ev:
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			}
+			if !first {
+				interval = o.InitialInterval
+			}
+			first, prev = false, status
+		}
+		switch status {
+		case OrderStatusSucceeded:
+			return res, nil
+		case OrderStatusFailed, OrderStatusError:
+			return res, &OrderFailedError{Order: res, Status: status}
+		}
+		if err := next(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// jitter randomizes d by up to the fraction f in either direction.
+func jitter(d time.Duration, f float64) time.Duration {
+	if f <= 0 {
+		return d
+	}
+	return time.Duration(float64(d) * (1 + f*(2*rand.Float64()-1)))
+}
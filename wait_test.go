@@ -0,0 +1,210 @@
+// Copyright 2023 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/reinventingscience/ivcap-core-api/ivcaperr"
+)
+
+const waitOrderID = "6f4e4a02-45b4-4b51-9b1c-1f3c1b1f2a10"
+
+// statusDoer answers every request with an order in the next of its
+// statuses, repeating the last one once they are used up.
+type statusDoer struct {
+	mu       sync.Mutex
+	statuses []string
+	reads    int
+}
+
+func (d *statusDoer) Do(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	status := d.statuses[min(d.reads, len(d.statuses)-1)]
+	d.reads++
+	body := fmt.Sprintf(`{"id":%q,"status":%q,"parameters":[]}`, waitOrderID, status)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func newWaitClient(t *testing.T, statuses ...string) (*OrdersClient, *statusDoer) {
+	d := &statusDoer{statuses: statuses}
+	c, err := NewClient("https://ivcap.test", StaticToken("token"), d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c.Orders(), d
+}
+
+// fastWait polls without delay.
+var fastWait = WaitOptions{InitialInterval: time.Microsecond, MaxInterval: time.Microsecond, Jitter: -1}
+
+func TestWaitSucceeded(t *testing.T) {
+	c, d := newWaitClient(t, "pending", "pending", "executing", "executing", "succeeded")
+	var events []OrderEvent
+	opts := fastWait
+	opts.OnChange = func(ev OrderEvent) { events = append(events, ev) }
+	res, err := c.Wait(context.Background(), waitOrderID, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status == nil || *res.Status != OrderStatusSucceeded {
+		t.Errorf("status = %v, want succeeded", res.Status)
+	}
+	if d.reads != 5 {
+		t.Errorf("read the order %d times, want 5", d.reads)
+	}
+	var got []string
+	for _, ev := range events {
+		got = append(got, ev.Previous+">"+ev.Status)
+		if ev.OrderID != waitOrderID || ev.Order == nil || ev.Time.IsZero() {
+			t.Errorf("incomplete event %+v", ev)
+		}
+	}
+	if s := strings.Join(got, " "); s != ">pending pending>executing executing>succeeded" {
+		t.Errorf("events = %s", s)
+	}
+}
+
+func TestWaitFailed(t *testing.T) {
+	for _, status := range []string{OrderStatusFailed, OrderStatusError} {
+		c, _ := newWaitClient(t, "executing", status)
+		res, err := c.Wait(context.Background(), waitOrderID, &fastWait)
+		var ferr *OrderFailedError
+		if !errors.As(err, &ferr) {
+			t.Fatalf("err = %v, want *OrderFailedError", err)
+		}
+		if ferr.Status != status || ferr.Order == nil || ferr.Order.ID != waitOrderID {
+			t.Errorf("error = %+v", ferr)
+		}
+		if res == nil || res.Status == nil || *res.Status != status {
+			t.Errorf("Wait did not return the final order record with status %s", status)
+		}
+	}
+}
+
+func TestWaitCanceled(t *testing.T) {
+	c, _ := newWaitClient(t, "executing")
+	ctx, cancel := context.WithCancel(context.Background())
+	opts := fastWait
+	opts.OnChange = func(OrderEvent) { cancel() }
+	if _, err := c.Wait(ctx, waitOrderID, &opts); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestWaitEvents(t *testing.T) {
+	c, _ := newWaitClient(t, "pending", "executing", "succeeded")
+	events := make(chan OrderEvent)
+	done := make(chan error, 1)
+	opts := fastWait
+	opts.Events = events
+	go func() {
+		_, err := c.Wait(context.Background(), waitOrderID, &opts)
+		done <- err
+	}()
+	for _, want := range []string{"pending", "executing", "succeeded"} {
+		if ev := <-events; ev.Status != want {
+			t.Errorf("event status = %s, want %s", ev.Status, want)
+		}
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWaitCanceledWhileSendingEvent(t *testing.T) {
+	c, _ := newWaitClient(t, "pending")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	opts := fastWait
+	// Nobody receives from the channel.
+	opts.Events = make(chan OrderEvent)
+	if _, err := c.Wait(ctx, waitOrderID, &opts); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+// failingDoer fails the first requests with the given statuses, 0 standing
+// for a network error, before passing requests on to next.
+type failingDoer struct {
+	statuses []int
+	next     *statusDoer
+}
+
+func (d *failingDoer) Do(req *http.Request) (*http.Response, error) {
+	if len(d.statuses) == 0 {
+		return d.next.Do(req)
+	}
+	status := d.statuses[0]
+	d.statuses = d.statuses[1:]
+	if status == 0 {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	}
+	return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+}
+
+func TestWaitReadErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		statuses []int
+		max      int
+		want     *ivcaperr.Kind
+	}{
+		{"transient", []int{503, 0, 429}, 0, nil},
+		{"too many", []int{503, 502, 504, 503}, 0, ivcaperr.ErrUnavailable},
+		{"disabled", []int{0}, -1, ivcaperr.ErrTransport},
+		{"unauthorized", []int{401}, 0, ivcaperr.ErrUnauthorized},
+		{"server error", []int{500}, 0, ivcaperr.ErrInvalidResponse},
+	} {
+		d := &failingDoer{statuses: tc.statuses, next: &statusDoer{statuses: []string{"succeeded"}}}
+		c, err := NewClient("https://ivcap.test", StaticToken("token"), d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts := fastWait
+		opts.MaxReadErrors = tc.max
+		_, err = c.Orders().Wait(context.Background(), waitOrderID, &opts)
+		if tc.want == nil {
+			if err != nil {
+				t.Errorf("%s: %v", tc.name, err)
+			}
+			continue
+		}
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+		if d.next.reads != 0 {
+			t.Errorf("%s: polled again after the error", tc.name)
+		}
+	}
+}